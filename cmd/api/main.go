@@ -13,6 +13,7 @@ import (
 	"pannypal/internal/pkg/logger"
 	"pannypal/internal/pkg/rabbitmq"
 	"pannypal/internal/pkg/redis"
+	s3aws "pannypal/internal/pkg/storage/s3"
 	"pannypal/internal/pkg/validation"
 	serverApp "pannypal/internal/server"
 	"sync"
@@ -81,12 +82,13 @@ func main() {
 		cancel()
 		return
 	}
-	// s3Client, err := setupS3(ctx, env, redis)
-	// if err != nil {
-	// 	logger.Error.Println("Error setting up S3", err)
-	// 	cancel()
-	// 	return
-	// }
+	// Setup file storage
+	storage, err := setupStorage(ctx, env, redis)
+	if err != nil {
+		logger.Error.Println("Error setting up storage", err)
+		cancel()
+		return
+	}
 	// Setup Server
 	aiClient := setupAi(ctx)
 
@@ -99,7 +101,7 @@ func main() {
 		Wg:     &wg,
 		Rb:     rabbit,
 		Ai:     aiClient,
-		// S3:     &s3Client,
+		S3:     &storage,
 	})
 
 }
@@ -135,13 +137,36 @@ func setupDB(env *config.Config) (*database.Database, error) {
 	})
 }
 
-// func setupS3(ctx context.Context, env *config.Config, redis redis.IRedis) (s3aws.Is3, error) {
-// 	return s3aws.NewS3Client(ctx, s3aws.S3Config{
-// 		AWSRegion:          env.AWSREGION,
-// 		AWSAccessKeyID:     env.AWSACCESSKEYID,
-// 		AWSSecretAccessKey: env.AWSSECRETACCESSKEY,
-// 	}, env.AWSBUCKETNAME, redis)
-// }
+// setupStorage uses S3 when STORAGE_DRIVER=s3, otherwise files are kept on the local disk
+// and served by the API, which is enough for development
+func setupStorage(ctx context.Context, env *config.Config, redis redis.IRedis) (s3aws.Is3, error) {
+	if helper.GetEnv("STORAGE_DRIVER") == "s3" {
+		client, err := s3aws.NewS3Client(ctx, s3aws.S3Config{
+			AWSRegion:          helper.GetEnv("AWS_REGION"),
+			AWSAccessKeyID:     helper.GetEnv("AWS_ACCESS_KEY_ID"),
+			AWSSecretAccessKey: helper.GetEnv("AWS_SECRET_ACCESS_KEY"),
+		}, helper.GetEnv("AWS_BUCKET_NAME"), redis)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+
+	basePath := helper.GetEnv("STORAGE_LOCAL_PATH")
+	if basePath == "" {
+		basePath = "storage"
+	}
+	baseURL := helper.GetEnv("STORAGE_LOCAL_URL")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%d/api/files", env.AppPort)
+	}
+
+	client, err := s3aws.NewLocalClient(basePath, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
 
 func setupAi(ctx context.Context) *ai.AiClient {
 	apiKey := helper.GetEnv("GEMINI_API_KEY")
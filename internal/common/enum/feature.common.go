@@ -3,15 +3,38 @@ package enum
 type FeatureType string
 
 const (
-	FeatureTypeAIcashflow FeatureType = "AI_CASHFLOW"
+	FeatureTypeAIcashflow      FeatureType = "AI_CASHFLOW"
+	FeatureTypeAIcashflowSaved FeatureType = "AI_CASHFLOW_SAVED"
 )
 
 type WebhookIncomingTag string
 
 const (
 	TagKeuangan WebhookIncomingTag = "#keuangan"
+	TagSaldo    WebhookIncomingTag = "#saldo"
+	TagHariIni  WebhookIncomingTag = "#hariini"
+	TagBulanIni WebhookIncomingTag = "#bulanini"
+	TagBudget   WebhookIncomingTag = "#budget"
+	TagLast     WebhookIncomingTag = "#last"
+	TagUndo     WebhookIncomingTag = "#undo"
+	TagTanya    WebhookIncomingTag = "#tanya"
+	TagHutang   WebhookIncomingTag = "#hutang"
+	TagCari     WebhookIncomingTag = "#cari"
+	TagTabungan WebhookIncomingTag = "#tabungan"
 )
 
+func (e WebhookIncomingTag) ToString() string {
+	return string(e)
+}
+
+func (e WebhookIncomingTag) IsValid() bool {
+	switch e {
+	case TagKeuangan, TagSaldo, TagHariIni, TagBulanIni, TagBudget, TagLast, TagUndo, TagTanya, TagHutang, TagCari, TagTabungan:
+		return true
+	}
+	return false
+}
+
 func TagForFeature(tag WebhookIncomingTag) FeatureType {
 	switch tag {
 	case TagKeuangan:
@@ -0,0 +1,48 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccountType string
+
+const (
+	AccountTypeCash       AccountType = "CASH"
+	AccountTypeBank       AccountType = "BANK"
+	AccountTypeEWallet    AccountType = "EWALLET"
+	AccountTypeCreditCard AccountType = "CREDIT_CARD"
+	AccountTypeOther      AccountType = "OTHER"
+)
+
+// Account is where a user's money sits, a bank account, cash or an e-wallet
+type Account struct {
+	gorm.Model
+	UserID         uint        `gorm:"not null;index" json:"user_id"`
+	Name           string      `gorm:"type:varchar(100);not null" json:"name"`
+	Type           AccountType `gorm:"type:varchar(20);not null" json:"type"`
+	Currency       string      `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	OpeningBalance types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"opening_balance"`
+	IsArchived     bool        `gorm:"not null;default:false" json:"is_archived"`
+
+	// Relations
+	User User `json:"-"`
+}
+
+// AccountReconciliation records a balance the user stated for an account and how it
+// compared to the balance computed from transactions
+type AccountReconciliation struct {
+	gorm.Model
+	AccountID               uint        `gorm:"not null;index" json:"account_id"`
+	StatedBalance           types.Money `gorm:"type:decimal(15,2);not null" json:"stated_balance"`
+	ComputedBalance         types.Money `gorm:"type:decimal(15,2);not null" json:"computed_balance"`
+	Difference              types.Money `gorm:"type:decimal(15,2);not null" json:"difference"` // Stated minus computed
+	AsOf                    time.Time   `gorm:"not null" json:"as_of"`
+	AdjustmentTransactionID *uint       `json:"adjustment_transaction_id"`
+	Note                    string      `gorm:"type:text" json:"note"`
+
+	// Relations
+	Account Account `json:"-"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDigestHour is when the daily digest goes out unless the user picks another hour
+const DefaultDigestHour = 20
+
+// BudgetAlert is a threshold a budget crossed, each threshold is alerted once per budget. SentAt stays
+// nil while the alert waits for quiet hours to end or for the daily digest.
+type BudgetAlert struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	BudgetID  uint       `gorm:"not null;uniqueIndex:idx_budget_alerts_threshold" json:"budget_id"`
+	Threshold int        `gorm:"not null;uniqueIndex:idx_budget_alerts_threshold" json:"threshold"` // Percentage of the budget
+	SentAt    *time.Time `gorm:"index" json:"sent_at"`
+
+	// Relations
+	Budget Budget `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// AlertSetting is how a user gets budget alerts. Quiet hours run from QuietStart up to QuietEnd and may
+// wrap past midnight, with DailyDigest alerts of personal budgets wait for one message at DigestHour.
+type AlertSetting struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	QuietStart   *int       `json:"quiet_start"` // Hour 0-23
+	QuietEnd     *int       `json:"quiet_end"`
+	DailyDigest  bool       `gorm:"not null;default:false" json:"daily_digest"`
+	DigestHour   int        `gorm:"not null;default:20" json:"digest_hour"`
+	LastDigestAt *time.Time `json:"last_digest_at"`
+
+	// Relations
+	User User `json:"-"`
+}
+
+// IsQuiet reports whether t falls in the user's quiet hours
+func (s AlertSetting) IsQuiet(t time.Time) bool {
+	if s.QuietStart == nil || s.QuietEnd == nil || *s.QuietStart == *s.QuietEnd {
+		return false
+	}
+	hour := t.Hour()
+	if *s.QuietStart < *s.QuietEnd {
+		return hour >= *s.QuietStart && hour < *s.QuietEnd
+	}
+	return hour >= *s.QuietStart || hour < *s.QuietEnd
+}
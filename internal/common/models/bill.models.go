@@ -0,0 +1,49 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bill is a monthly bill whose amount varies, such as electricity or a credit card statement. It falls
+// due on DueDay, clamped to the month length, and is paid by an expense to Payee or in its category.
+// Due dates before StartDate are never reminded nor overdue.
+type Bill struct {
+	gorm.Model
+	UserID           uint        `gorm:"not null;index" json:"user_id"`
+	Name             string      `gorm:"type:varchar(100);not null" json:"name"`
+	Payee            string      `gorm:"type:varchar(150)" json:"payee"` // Matched against the merchant and description of expenses
+	DueDay           int         `gorm:"not null" json:"due_day"`        // 1-31
+	ExpectedAmount   types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"expected_amount"`
+	Currency         string      `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	CategoryID       *uint       `gorm:"index" json:"category_id"`
+	AccountID        *uint       `gorm:"index" json:"account_id"`
+	RemindDaysBefore int         `gorm:"not null;default:3" json:"remind_days_before"`
+	StartDate        time.Time   `gorm:"type:date;not null" json:"start_date"`
+	IsActive         bool        `gorm:"not null;default:true;index" json:"is_active"`
+
+	// Relations
+	User     User      `json:"-"`
+	Category *Category `json:"category,omitempty"`
+	Account  *Account  `json:"account,omitempty"`
+}
+
+// BillPayment is what happened to one due date of a bill: the reminder, the overdue notice and the
+// payment. It is created by the first of them.
+type BillPayment struct {
+	ID            uint        `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	BillID        uint        `gorm:"not null;uniqueIndex:idx_bill_payments_due" json:"bill_id"`
+	DueDate       time.Time   `gorm:"type:date;not null;uniqueIndex:idx_bill_payments_due" json:"due_date"`
+	TransactionID *uint       `gorm:"uniqueIndex" json:"transaction_id"`
+	Amount        types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"amount"` // What was paid
+	PaidAt        *time.Time  `json:"paid_at"`
+	RemindedAt    *time.Time  `json:"reminded_at"`
+	OverdueSentAt *time.Time  `json:"overdue_sent_at"`
+
+	// Relations
+	Bill Bill `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
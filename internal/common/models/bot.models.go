@@ -14,6 +14,8 @@ type MessageToReply struct {
 	Messsage    string           `gorm:"type:text" json:"message"`
 	Additional  *json.RawMessage `gorm:"type:jsonb" json:"additional"`
 	Participant *string          `gorm:"type:varchar(100)" json:"participant"`
+	SplitSpec   *json.RawMessage `gorm:"type:jsonb" json:"split_spec"`  // Split requested with a #keuangan draft
+	Attachments *json.RawMessage `gorm:"type:jsonb" json:"attachments"` // Receipt sent with the draft, attached on save
 }
 
 type AccountBot struct {
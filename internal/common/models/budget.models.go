@@ -0,0 +1,169 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetPeriodWeekly    BudgetPeriod = "WEEKLY"
+	BudgetPeriodMonthly   BudgetPeriod = "MONTHLY"
+	BudgetPeriodQuarterly BudgetPeriod = "QUARTERLY"
+	BudgetPeriodYearly    BudgetPeriod = "YEARLY"
+)
+
+// Budget limits the spending in a category over one period, the next period is a budget of its own.
+// Month and Year are those of PeriodStart.
+type Budget struct {
+	gorm.Model
+	UserID      uint         `gorm:"not null;index" json:"user_id"`
+	CategoryID  uint         `gorm:"not null;index" json:"category_id"`
+	LedgerID    *uint        `gorm:"index" json:"ledger_id"`                    // Set for group budgets
+	Amount      types.Money  `gorm:"type:decimal(15,2);not null" json:"amount"` // Menggunakan decimal untuk uang
+	Month       int          `gorm:"not null" json:"month"`                     // 1-12
+	Year        int          `gorm:"not null" json:"year"`                      // 2023, 2024
+	Period      BudgetPeriod `gorm:"type:varchar(10);not null;default:'MONTHLY'" json:"period"`
+	StartDay    int          `gorm:"not null;default:1" json:"start_day"` // Day of the month periods start on, the weekday (0 = Sunday) for weekly budgets
+	PeriodStart time.Time    `gorm:"type:date;index" json:"period_start"`
+	PeriodEnd   time.Time    `gorm:"type:date" json:"period_end"`            // Last day of the period
+	Rollover    bool         `gorm:"not null;default:false" json:"rollover"` // The previous period's leftover, or overspending, carries into this one
+	Alerts      *string      `gorm:"type:varchar(50)" json:"-"`              // Percentages alerted on WhatsApp when crossed, nil for DefaultAlertThresholds
+
+	// Relations
+	User     User     `json:"-"`
+	Category Category `json:"category"`
+}
+
+// BudgetTemplate is a budget the worker sets again for every period, NextPeriodStart is the first day of
+// the next period it has not set a budget for. With AdjustToAverage the budget follows the spending of the
+// three months before the period and Amount is only used when nothing was spent.
+type BudgetTemplate struct {
+	gorm.Model
+	UserID          uint         `gorm:"not null;index" json:"user_id"`
+	CategoryID      uint         `gorm:"not null;index" json:"category_id"`
+	LedgerID        *uint        `gorm:"index" json:"ledger_id"` // Set for group budgets
+	Amount          types.Money  `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Period          BudgetPeriod `gorm:"type:varchar(10);not null;default:'MONTHLY'" json:"period"`
+	StartDay        int          `gorm:"not null;default:1" json:"start_day"`
+	Rollover        bool         `gorm:"not null;default:false" json:"rollover"`
+	AdjustToAverage bool         `gorm:"not null;default:false" json:"adjust_to_average"`
+	Alerts          *string      `gorm:"type:varchar(50)" json:"-"`
+	NextPeriodStart time.Time    `gorm:"type:date;not null;index" json:"next_period_start"`
+	IsActive        bool         `gorm:"not null;default:true;index" json:"is_active"`
+
+	// Relations
+	User     User     `json:"-"`
+	Category Category `json:"category"`
+}
+
+// DefaultAlertThresholds are the percentages a budget alerts at unless it is given its own
+var DefaultAlertThresholds = []int{80, 100}
+
+// AlertThresholds returns the percentages the budget alerts at, lowest first
+func (b Budget) AlertThresholds() []int {
+	return ParseAlertThresholds(b.Alerts)
+}
+
+// ParseAlertThresholds reads thresholds stored as "50,80,100", nil is DefaultAlertThresholds and an
+// empty string turns alerts off
+func ParseAlertThresholds(value *string) []int {
+	if value == nil {
+		return append([]int{}, DefaultAlertThresholds...)
+	}
+	thresholds := []int{}
+	for _, part := range strings.Split(*value, ",") {
+		if threshold, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && threshold > 0 {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// FormatAlertThresholds stores thresholds as "50,80,100"
+func FormatAlertThresholds(thresholds []int) *string {
+	sorted := append([]int{}, thresholds...)
+	sort.Ints(sorted)
+	parts := make([]string, 0, len(sorted))
+	for i, threshold := range sorted {
+		if i > 0 && threshold == sorted[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.Itoa(threshold))
+	}
+	value := strings.Join(parts, ",")
+	return &value
+}
+
+// SetPeriod places the budget in the period holding date
+func (b *Budget) SetPeriod(date time.Time) {
+	b.PeriodStart, b.PeriodEnd = b.Period.Window(b.StartDay, date)
+	b.Month = int(b.PeriodStart.Month())
+	b.Year = b.PeriodStart.Year()
+}
+
+// Valid reports whether the start day fits the period, weekly budgets take a weekday and the others
+// a day of the month
+func (p BudgetPeriod) Valid(startDay int) bool {
+	switch p {
+	case BudgetPeriodWeekly:
+		return startDay >= 0 && startDay <= 6
+	case BudgetPeriodMonthly, BudgetPeriodQuarterly, BudgetPeriodYearly:
+		return startDay >= 1 && startDay <= 31
+	}
+	return false
+}
+
+// Window returns the first and last day of the period holding date. Days past the end of a shorter
+// month start the period on its last day, quarters start in January, April, July and October and
+// years in January.
+func (p BudgetPeriod) Window(startDay int, date time.Time) (time.Time, time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	if p == BudgetPeriodWeekly {
+		start := day.AddDate(0, 0, -((int(day.Weekday()) - startDay + 7) % 7))
+		return start, start.AddDate(0, 0, 6)
+	}
+
+	months := p.months()
+	month := day.Month()
+	switch p {
+	case BudgetPeriodQuarterly:
+		month = (month-1)/3*3 + 1
+	case BudgetPeriodYearly:
+		month = time.January
+	}
+
+	start := budgetDate(day.Year(), month, startDay, day.Location())
+	if day.Before(start) {
+		start = budgetDate(day.Year(), month-time.Month(months), startDay, day.Location())
+	}
+	next := budgetDate(start.Year(), start.Month()+time.Month(months), startDay, day.Location())
+	return start, next.AddDate(0, 0, -1)
+}
+
+func (p BudgetPeriod) months() int {
+	switch p {
+	case BudgetPeriodQuarterly:
+		return 3
+	case BudgetPeriodYearly:
+		return 12
+	}
+	return 1
+}
+
+// budgetDate clamps the day to the month length, the 31st of February is the 28th or 29th
+func budgetDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetPeriodWindow(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		period    BudgetPeriod
+		startDay  int
+		date      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{BudgetPeriodMonthly, 1, date(2024, 3, 15), date(2024, 3, 1), date(2024, 3, 31)},
+		{BudgetPeriodMonthly, 25, date(2024, 3, 10), date(2024, 2, 25), date(2024, 3, 24)},
+		{BudgetPeriodMonthly, 25, date(2024, 3, 25), date(2024, 3, 25), date(2024, 4, 24)},
+		{BudgetPeriodMonthly, 31, date(2024, 2, 10), date(2024, 1, 31), date(2024, 2, 28)},
+		{BudgetPeriodMonthly, 31, date(2024, 2, 29), date(2024, 2, 29), date(2024, 3, 30)},
+		{BudgetPeriodWeekly, 1, date(2024, 3, 13), date(2024, 3, 11), date(2024, 3, 17)},
+		{BudgetPeriodWeekly, 1, date(2024, 3, 17), date(2024, 3, 11), date(2024, 3, 17)},
+		{BudgetPeriodWeekly, 0, date(2024, 3, 13), date(2024, 3, 10), date(2024, 3, 16)},
+		{BudgetPeriodQuarterly, 1, date(2024, 5, 20), date(2024, 4, 1), date(2024, 6, 30)},
+		{BudgetPeriodQuarterly, 15, date(2024, 4, 10), date(2024, 1, 15), date(2024, 4, 14)},
+		{BudgetPeriodYearly, 1, date(2024, 7, 4), date(2024, 1, 1), date(2024, 12, 31)},
+		{BudgetPeriodYearly, 10, date(2024, 1, 5), date(2023, 1, 10), date(2024, 1, 9)},
+	}
+
+	for _, tt := range tests {
+		start, end := tt.period.Window(tt.startDay, tt.date)
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("%s/%d Window(%s) = %s - %s, want %s - %s", tt.period, tt.startDay, tt.date.Format(time.DateOnly),
+				start.Format(time.DateOnly), end.Format(time.DateOnly),
+				tt.wantStart.Format(time.DateOnly), tt.wantEnd.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestBudgetSetPeriod(t *testing.T) {
+	budget := Budget{Period: BudgetPeriodMonthly, StartDay: 25}
+	budget.SetPeriod(time.Date(2024, 1, 10, 15, 30, 0, 0, time.UTC))
+
+	if budget.Month != 12 || budget.Year != 2023 {
+		t.Errorf("SetPeriod month/year = %d/%d, want 12/2023", budget.Month, budget.Year)
+	}
+	if want := time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC); !budget.PeriodEnd.Equal(want) {
+		t.Errorf("SetPeriod end = %s, want %s", budget.PeriodEnd, want)
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import "gorm.io/gorm"
+
+// CategoryKind says which transactions a category can hold
+type CategoryKind string
+
+const (
+	CategoryKindExpense CategoryKind = "EXPENSE"
+	CategoryKindIncome  CategoryKind = "INCOME"
+	CategoryKindBoth    CategoryKind = "BOTH"
+)
+
+// FallbackCategoryName is the category a transaction lands in when no other fits
+const FallbackCategoryName = "Lainnya"
+
+// Allows reports whether transactions of the type can be put in the category, transfers have no category
+func (k CategoryKind) Allows(transactionType TransactionType) bool {
+	switch transactionType {
+	case TypeExpense:
+		return k == CategoryKindExpense || k == CategoryKindBoth
+	case TypeIncome:
+		return k == CategoryKindIncome || k == CategoryKindBoth
+	}
+	return false
+}
+
+// Category belongs to one user, categories without a user are the template copied to every new user.
+// Categories are two levels deep: a top-level category may have subcategories, a subcategory may not.
+type Category struct {
+	gorm.Model
+	UserID   *uint        `gorm:"index" json:"user_id"`   // Nil for the template
+	ParentID *uint        `gorm:"index" json:"parent_id"` // Nil for a top-level category
+	Name     string       `gorm:"type:varchar(100);not null" json:"name"`
+	Kind     CategoryKind `gorm:"type:varchar(10);not null;default:'EXPENSE'" json:"kind"`
+	Icon     string       `gorm:"type:varchar(50)" json:"icon"` // Emoji or icon name for the dashboard
+	Color    string       `gorm:"type:varchar(7)" json:"color"` // #RRGGBB
+
+	// Relations
+	Parent        *Category     `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
+	Subcategories []Category    `gorm:"foreignKey:ParentID" json:"subcategories,omitempty"`
+	Budgets       []Budget      `gorm:"foreignKey:CategoryID" json:"budgets,omitempty"`
+	Transactions  []Transaction `gorm:"foreignKey:CategoryID" json:"transactions,omitempty"`
+}
+
+// CategoryTemplate is a category of the built-in template, used when the template is empty
+type CategoryTemplate struct {
+	Name          string
+	Kind          CategoryKind
+	Icon          string
+	Color         string
+	Subcategories []CategoryTemplate
+}
+
+var DefaultCategoryTemplate = []CategoryTemplate{
+	{Name: "Makanan & Minuman", Kind: CategoryKindExpense, Icon: "🍔", Color: "#F97316", Subcategories: []CategoryTemplate{
+		{Name: "Kopi", Kind: CategoryKindExpense, Icon: "☕", Color: "#F97316"},
+		{Name: "Restoran", Kind: CategoryKindExpense, Icon: "🍽️", Color: "#F97316"},
+		{Name: "Belanja Dapur", Kind: CategoryKindExpense, Icon: "🥬", Color: "#F97316"},
+	}},
+	{Name: "Transportasi", Kind: CategoryKindExpense, Icon: "🚗", Color: "#3B82F6", Subcategories: []CategoryTemplate{
+		{Name: "Bensin", Kind: CategoryKindExpense, Icon: "⛽", Color: "#3B82F6"},
+		{Name: "Ojek & Taksi", Kind: CategoryKindExpense, Icon: "🛵", Color: "#3B82F6"},
+		{Name: "Parkir & Tol", Kind: CategoryKindExpense, Icon: "🅿️", Color: "#3B82F6"},
+	}},
+	{Name: "Belanja", Kind: CategoryKindExpense, Icon: "🛍️", Color: "#EC4899", Subcategories: []CategoryTemplate{
+		{Name: "Pakaian", Kind: CategoryKindExpense, Icon: "👕", Color: "#EC4899"},
+		{Name: "Elektronik", Kind: CategoryKindExpense, Icon: "📱", Color: "#EC4899"},
+	}},
+	{Name: "Tagihan", Kind: CategoryKindExpense, Icon: "🧾", Color: "#EAB308", Subcategories: []CategoryTemplate{
+		{Name: "Listrik", Kind: CategoryKindExpense, Icon: "💡", Color: "#EAB308"},
+		{Name: "Air", Kind: CategoryKindExpense, Icon: "🚰", Color: "#EAB308"},
+		{Name: "Internet & Pulsa", Kind: CategoryKindExpense, Icon: "📶", Color: "#EAB308"},
+	}},
+	{Name: "Rumah", Kind: CategoryKindExpense, Icon: "🏠", Color: "#8B5CF6"},
+	{Name: "Kesehatan", Kind: CategoryKindExpense, Icon: "💊", Color: "#EF4444"},
+	{Name: "Hiburan", Kind: CategoryKindExpense, Icon: "🎬", Color: "#06B6D4", Subcategories: []CategoryTemplate{
+		{Name: "Langganan", Kind: CategoryKindExpense, Icon: "📺", Color: "#06B6D4"},
+	}},
+	{Name: "Pendidikan", Kind: CategoryKindExpense, Icon: "📚", Color: "#10B981"},
+	{Name: "Gaji", Kind: CategoryKindIncome, Icon: "💼", Color: "#22C55E"},
+	{Name: "Bonus", Kind: CategoryKindIncome, Icon: "🎁", Color: "#84CC16"},
+	{Name: "Investasi", Kind: CategoryKindBoth, Icon: "📈", Color: "#14B8A6"},
+	{Name: FallbackCategoryName, Kind: CategoryKindBoth, Icon: "📦", Color: "#6B7280"},
+}
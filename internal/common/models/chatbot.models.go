@@ -7,9 +7,10 @@ import (
 // ChatConversation represents a chat session between user and AI
 type ChatConversation struct {
 	gorm.Model
-	SessionID string `gorm:"type:varchar(100);uniqueIndex;not null" json:"session_id"` // UUID for one chat session
-	Title     string `gorm:"type:varchar(255)" json:"title"`                           // Auto-generated from first query
-	IsActive  bool   `gorm:"default:true" json:"is_active"`                            // Session is still active or finished
+	SessionID string  `gorm:"type:varchar(100);uniqueIndex;not null" json:"session_id"` // UUID for one chat session
+	Title     string  `gorm:"type:varchar(255)" json:"title"`                           // Auto-generated from first query
+	IsActive  bool    `gorm:"default:true" json:"is_active"`                            // Session is still active or finished
+	ChatID    *string `gorm:"type:varchar(100);index" json:"chat_id,omitempty"`         // WhatsApp chat bound to this session, nil for dashboard chats
 
 	// Relations
 	Messages []ChatMessage `gorm:"foreignKey:ConversationID" json:"messages,omitempty"`
@@ -0,0 +1,25 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EnvelopeAllocation moves money between envelopes, an envelope is a top-level expense category. A nil
+// FromCategoryID assigns money that is ready to assign, a nil ToCategoryID gives it back.
+type EnvelopeAllocation struct {
+	gorm.Model
+	UserID         uint        `gorm:"not null;index" json:"user_id"`
+	FromCategoryID *uint       `gorm:"index" json:"from_category_id"`
+	ToCategoryID   *uint       `gorm:"index" json:"to_category_id"`
+	Amount         types.Money `gorm:"type:decimal(15,2);not null" json:"amount"` // In the user's base currency
+	Note           string      `gorm:"type:varchar(255)" json:"note"`
+	Date           time.Time   `gorm:"type:date;not null;index" json:"date"`
+
+	// Relations
+	User         User      `json:"-"`
+	FromCategory *Category `gorm:"foreignKey:FromCategoryID" json:"from_category,omitempty"`
+	ToCategory   *Category `gorm:"foreignKey:ToCategoryID" json:"to_category,omitempty"`
+}
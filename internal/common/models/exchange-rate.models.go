@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultCurrency is used when neither the transaction, its account nor the user names one
+	DefaultCurrency = "IDR"
+	// ExchangeRatePivot is the currency every rate is quoted against, converting between two
+	// other currencies goes through it
+	ExchangeRatePivot = "USD"
+)
+
+// ExchangeRate is the price of the pivot currency in Currency on a day, 1 USD = Rate IDR
+type ExchangeRate struct {
+	gorm.Model
+	Currency string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rates_currency_date" json:"currency"`
+	Date     time.Time `gorm:"type:date;not null;uniqueIndex:idx_exchange_rates_currency_date" json:"date"`
+	Rate     float64   `gorm:"type:decimal(24,10);not null" json:"rate"`
+	Source   string    `gorm:"type:varchar(50)" json:"source"` // Provider that supplied the rate
+}
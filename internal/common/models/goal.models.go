@@ -0,0 +1,44 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavingsGoal is money the user saves towards, amounts are in the user's base currency. Besides the
+// contributions added by hand, transactions count from StartDate on when they carry the goal's tag, are
+// in its category or move money in or out of its account.
+type SavingsGoal struct {
+	gorm.Model
+	UserID       uint        `gorm:"not null;index" json:"user_id"`
+	Name         string      `gorm:"type:varchar(100);not null" json:"name"`
+	TargetAmount types.Money `gorm:"type:decimal(15,2);not null" json:"target_amount"`
+	Deadline     *time.Time  `gorm:"type:date" json:"deadline"`
+	StartDate    time.Time   `gorm:"type:date;not null" json:"start_date"`
+	AccountID    *uint       `gorm:"index" json:"account_id"`
+	CategoryID   *uint       `gorm:"index" json:"category_id"`
+	TagID        *uint       `gorm:"index" json:"tag_id"`
+	Icon         string      `gorm:"type:varchar(50)" json:"icon"`
+	Color        string      `gorm:"type:varchar(20)" json:"color"`
+
+	// Relations
+	User     User      `json:"-"`
+	Account  *Account  `json:"account,omitempty"`
+	Category *Category `json:"category,omitempty"`
+	Tag      *Tag      `json:"tag,omitempty"`
+}
+
+// GoalContribution is money added to a goal by hand, a negative amount takes money out
+type GoalContribution struct {
+	gorm.Model
+	GoalID uint        `gorm:"not null;index" json:"goal_id"`
+	UserID uint        `gorm:"not null;index" json:"user_id"`
+	Amount types.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Note   string      `gorm:"type:varchar(255)" json:"note"`
+	Date   time.Time   `gorm:"type:date;not null;index" json:"date"`
+
+	// Relations
+	Goal SavingsGoal `json:"-"`
+}
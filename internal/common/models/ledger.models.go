@@ -0,0 +1,25 @@
+package models
+
+import "gorm.io/gorm"
+
+// Ledger is a shared book bound to a WhatsApp group chat
+type Ledger struct {
+	gorm.Model
+	ChatID string `gorm:"type:varchar(100);uniqueIndex;not null" json:"chat_id"`
+	Name   string `gorm:"type:varchar(100)" json:"name"`
+
+	// Relations
+	Members      []LedgerMember `gorm:"foreignKey:LedgerID" json:"members,omitempty"`
+	Transactions []Transaction  `gorm:"foreignKey:LedgerID" json:"transactions,omitempty"`
+}
+
+// LedgerMember is a group participant that has written to the ledger
+type LedgerMember struct {
+	gorm.Model
+	LedgerID uint `gorm:"not null;uniqueIndex:idx_ledger_member" json:"ledger_id"`
+	UserID   uint `gorm:"not null;uniqueIndex:idx_ledger_member" json:"user_id"`
+
+	// Relations
+	Ledger Ledger `json:"-"`
+	User   User   `json:"user"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoanInterestType string
+
+const (
+	LoanInterestAnnuity LoanInterestType = "ANNUITY" // Equal installments, interest on the outstanding principal (KPR)
+	LoanInterestFlat    LoanInterestType = "FLAT"    // Interest on the original principal every month (motorbike credit)
+)
+
+// Loan is money the user owes and repays in monthly installments. InterestRate is the yearly rate in
+// percent, the first installment is due on FirstDueDate and the rest on the same day of the following months.
+type Loan struct {
+	gorm.Model
+	UserID       uint             `gorm:"not null;index" json:"user_id"`
+	Name         string           `gorm:"type:varchar(100);not null" json:"name"`
+	Lender       string           `gorm:"type:varchar(100)" json:"lender"`
+	Principal    types.Money      `gorm:"type:decimal(15,2);not null" json:"principal"`
+	Currency     string           `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	InterestRate float64          `gorm:"type:decimal(7,4);not null;default:0" json:"interest_rate"`
+	InterestType LoanInterestType `gorm:"type:varchar(10);not null;default:'ANNUITY'" json:"interest_type"`
+	TenorMonths  int              `gorm:"not null" json:"tenor_months"`
+	FirstDueDate time.Time        `gorm:"type:date;not null" json:"first_due_date"`
+	AccountID    *uint            `gorm:"index" json:"account_id"`  // Account the installments are paid from
+	CategoryID   *uint            `gorm:"index" json:"category_id"` // Category of the installment expenses
+	PaidOffAt    *time.Time       `json:"paid_off_at"`
+
+	// Relations
+	User         User              `json:"-"`
+	Account      *Account          `json:"account,omitempty"`
+	Category     *Category         `json:"category,omitempty"`
+	Installments []LoanInstallment `gorm:"foreignKey:LoanID;constraint:OnDelete:CASCADE" json:"installments,omitempty"`
+}
+
+// LoanInstallment is one month of the amortization schedule, it is paid once linked to an expense
+// transaction. The schedule is rebuilt when the loan terms change, so installments are not soft deleted.
+type LoanInstallment struct {
+	ID            uint        `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	LoanID        uint        `gorm:"not null;uniqueIndex:idx_loan_installments_number" json:"loan_id"`
+	Number        int         `gorm:"not null;uniqueIndex:idx_loan_installments_number" json:"number"`
+	DueDate       time.Time   `gorm:"type:date;not null;index" json:"due_date"`
+	Amount        types.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Principal     types.Money `gorm:"type:decimal(15,2);not null" json:"principal"`
+	Interest      types.Money `gorm:"type:decimal(15,2);not null" json:"interest"`
+	Balance       types.Money `gorm:"type:decimal(15,2);not null" json:"balance"` // Principal outstanding after this installment
+	TransactionID *uint       `gorm:"uniqueIndex" json:"transaction_id"`
+	PaidAt        *time.Time  `json:"paid_at"`
+
+	// Relations
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AssetType string
+
+const (
+	AssetTypeInvestment AssetType = "INVESTMENT" // Stocks, mutual funds, gold, deposits
+	AssetTypeProperty   AssetType = "PROPERTY"
+	AssetTypeVehicle    AssetType = "VEHICLE"
+	AssetTypeOther      AssetType = "OTHER"
+	AssetTypeLiability  AssetType = "LIABILITY" // Money owed that is neither a loan nor a credit card
+)
+
+// IsLiability tells whether the entry counts against the net worth
+func (t AssetType) IsLiability() bool {
+	return t == AssetTypeLiability
+}
+
+// Asset is something of value the user enters by hand, or money owed when Type is LIABILITY. Accounts,
+// loans and credit cards count towards the net worth on their own and are not entered here.
+type Asset struct {
+	gorm.Model
+	UserID   uint        `gorm:"not null;index" json:"user_id"`
+	Name     string      `gorm:"type:varchar(100);not null" json:"name"`
+	Type     AssetType   `gorm:"type:varchar(20);not null" json:"type"`
+	Value    types.Money `gorm:"type:decimal(15,2);not null" json:"value"`
+	Currency string      `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	ValuedAt time.Time   `gorm:"type:date;not null" json:"valued_at"` // When Value was last updated
+	Note     string      `gorm:"type:text" json:"note"`
+
+	// Relations
+	User User `json:"-"`
+}
+
+// NetWorthSnapshot is the user's net worth on a day in their base currency, the worker takes one a day
+type NetWorthSnapshot struct {
+	ID               uint        `gorm:"primarykey" json:"id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	UserID           uint        `gorm:"not null;uniqueIndex:idx_net_worth_snapshots_date" json:"user_id"`
+	Date             time.Time   `gorm:"type:date;not null;uniqueIndex:idx_net_worth_snapshots_date" json:"date"`
+	Currency         string      `gorm:"type:varchar(3);not null" json:"currency"`
+	Accounts         types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"accounts"` // Accounts with money in them
+	Investments      types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"investments"`
+	Property         types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"property"`
+	Vehicles         types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"vehicles"`
+	OtherAssets      types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"other_assets"`
+	CreditCards      types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"credit_cards"` // Credit cards in debt
+	Loans            types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"loans"`        // Outstanding loan principal
+	OtherLiabilities types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"other_liabilities"`
+	TotalAssets      types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"total_assets"`
+	TotalLiabilities types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"total_liabilities"`
+	NetWorth         types.Money `gorm:"type:decimal(15,2);not null;default:0" json:"net_worth"`
+
+	// Relations
+	User User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecurringFrequency string
+
+const (
+	FrequencyWeekly  RecurringFrequency = "WEEKLY"
+	FrequencyMonthly RecurringFrequency = "MONTHLY"
+	FrequencyYearly  RecurringFrequency = "YEARLY"
+)
+
+type RecurringMode string
+
+const (
+	RecurringModeAuto  RecurringMode = "AUTO"  // Occurrences are saved as transactions right away
+	RecurringModeDraft RecurringMode = "DRAFT" // Occurrences are sent to WhatsApp for confirmation
+)
+
+// RecurringRule is a transaction that repeats on a schedule such as rent, salary or a subscription.
+// Monthly rules run on DayOfMonth, weekly rules on DayOfWeek and yearly rules on MonthOfYear/DayOfMonth,
+// every Interval periods.
+type RecurringRule struct {
+	gorm.Model
+	UserID         uint               `gorm:"not null;index" json:"user_id"`
+	CategoryID     *uint              `gorm:"index" json:"category_id"`
+	AccountID      *uint              `gorm:"index" json:"account_id"`
+	Type           TransactionType    `gorm:"type:varchar(10);not null" json:"type"`
+	Amount         types.Money        `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency       string             `gorm:"type:varchar(3)" json:"currency"` // Empty uses the account's or the user's currency
+	Description    string             `gorm:"type:text" json:"description"`
+	Frequency      RecurringFrequency `gorm:"type:varchar(10);not null" json:"frequency"`
+	Interval       int                `gorm:"not null;default:1" json:"interval"`
+	DayOfMonth     *int               `json:"day_of_month"` // 1-31, clamped to the last day of shorter months
+	DayOfWeek      *int               `json:"day_of_week"`  // 0 = Sunday
+	MonthOfYear    *int               `json:"month_of_year"`
+	StartDate      time.Time          `gorm:"not null" json:"start_date"`
+	EndDate        *time.Time         `json:"end_date"`
+	NextRunAt      time.Time          `gorm:"not null;index" json:"next_run_at"`
+	LastRunAt      *time.Time         `json:"last_run_at"`
+	Mode           RecurringMode      `gorm:"type:varchar(10);not null;default:'AUTO'" json:"mode"`
+	IsActive       bool               `gorm:"not null;default:true;index" json:"is_active"`
+	IsSubscription bool               `gorm:"not null;default:false" json:"is_subscription"`
+
+	// Relations
+	User     User     `json:"-"`
+	Category Category `json:"category"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RevisionAction string
+
+const (
+	RevisionActionCreate  RevisionAction = "CREATE"
+	RevisionActionUpdate  RevisionAction = "UPDATE"
+	RevisionActionDelete  RevisionAction = "DELETE"
+	RevisionActionRestore RevisionAction = "RESTORE"
+)
+
+type RevisionActorType string
+
+const (
+	RevisionActorUser   RevisionActorType = "USER"   // Someone using the API
+	RevisionActorBot    RevisionActorType = "BOT"    // The chat bot acting on a user's message
+	RevisionActorSystem RevisionActorType = "SYSTEM" // A scheduled job
+)
+
+// Where a change came from
+const (
+	RevisionSourceAPI            = "API"
+	RevisionSourceBulk           = "BULK"
+	RevisionSourceTrash          = "TRASH"
+	RevisionSourceCategory       = "CATEGORY" // Cascade of deleting or restoring a category
+	RevisionSourceReconciliation = "RECONCILIATION"
+	RevisionSourceWhatsapp       = "WHATSAPP"
+	RevisionSourceRecurring      = "RECURRING"
+	RevisionSourceMerge          = "DUPLICATE_MERGE" // Duplicates folded into the transaction kept
+	RevisionSourceLoan           = "LOAN"            // Installment payment recorded from a loan
+	RevisionSourceBill           = "BILL"            // Bill payment recorded from a bill
+)
+
+// RevisionActor is who made a change: an API user, the bot on behalf of a chat message or a system job
+type RevisionActor struct {
+	ActorType RevisionActorType `gorm:"type:varchar(10);not null" json:"actor_type"`
+	ActorID   *uint             `gorm:"index" json:"actor_id"`               // User behind the change, nil for system jobs
+	Channel   string            `gorm:"type:varchar(100)" json:"channel"`    // Bot chat or job name
+	MessageID string            `gorm:"type:varchar(100)" json:"message_id"` // Chat message a bot change answered
+	Source    string            `gorm:"type:varchar(30);not null" json:"source"`
+}
+
+// TransactionRevision is one change to a transaction. The log is append-only, rows are never
+// updated or soft deleted and only go away when the transaction is purged from the trash.
+type TransactionRevision struct {
+	ID            uint           `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time      `gorm:"not null;index" json:"created_at"`
+	TransactionID uint           `gorm:"not null;index" json:"transaction_id"`
+	UserID        uint           `gorm:"not null;index" json:"user_id"` // Owner of the transaction
+	Action        RevisionAction `gorm:"type:varchar(10);not null" json:"action"`
+	RevisionActor `gorm:"embedded"`
+	Before        *json.RawMessage `gorm:"type:jsonb" json:"before"` // Nil for a created transaction
+	After         *json.RawMessage `gorm:"type:jsonb" json:"after"`  // Nil for a deleted transaction
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	types "pannypal/internal/common/type"
+
+	"gorm.io/gorm"
+)
+
+type SplitMethod string
+
+const (
+	SplitMethodEqual      SplitMethod = "EQUAL"
+	SplitMethodPercentage SplitMethod = "PERCENTAGE"
+	SplitMethodExact      SplitMethod = "EXACT"
+)
+
+type DebtType string
+
+const (
+	DebtTypeShare      DebtType = "SHARE"      // Created from a split, the debtor owes the creditor
+	DebtTypeSettlement DebtType = "SETTLEMENT" // Repayment from the debtor to the creditor
+)
+
+// Split divides a transaction paid by one user between several users
+type Split struct {
+	gorm.Model
+	TransactionID uint        `gorm:"not null;index" json:"transaction_id"`
+	PayerID       uint        `gorm:"not null;index" json:"payer_id"`
+	LedgerID      *uint       `gorm:"index" json:"ledger_id"`
+	Method        SplitMethod `gorm:"type:varchar(20);not null" json:"method"`
+	TotalAmount   types.Money `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	Parts         int         `json:"parts"` // People sharing an EQUAL split, unnamed ones are absorbed by the payer
+
+	// Relations
+	Transaction Transaction  `json:"transaction"`
+	Payer       User         `gorm:"foreignKey:PayerID" json:"payer"`
+	Shares      []SplitShare `gorm:"foreignKey:SplitID" json:"shares"`
+}
+
+// SplitShare is the part of a split owed by one user, the payer keeps a share too
+type SplitShare struct {
+	gorm.Model
+	SplitID    uint        `gorm:"not null;index" json:"split_id"`
+	UserID     uint        `gorm:"not null;index" json:"user_id"`
+	Amount     types.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Percentage *float64    `gorm:"type:decimal(5,2)" json:"percentage,omitempty"`
+
+	// Relations
+	User User `json:"user"`
+}
+
+// Debt is an entry of the debts ledger between two users
+type Debt struct {
+	gorm.Model
+	DebtorID   uint        `gorm:"not null;index" json:"debtor_id"`
+	CreditorID uint        `gorm:"not null;index" json:"creditor_id"`
+	SplitID    *uint       `gorm:"index" json:"split_id"`
+	Type       DebtType    `gorm:"type:varchar(20);not null" json:"type"`
+	Amount     types.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Note       string      `gorm:"type:text" json:"note"`
+
+	// Relations
+	Debtor   User `gorm:"foreignKey:DebtorID" json:"debtor"`
+	Creditor User `gorm:"foreignKey:CreditorID" json:"creditor"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+type Tag struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name   string `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_name" json:"name"` // Lowercase, without the leading #
+}
+
+type Attachment struct {
+	gorm.Model
+	TransactionID uint   `gorm:"not null;index" json:"transaction_id"`
+	UserID        uint   `gorm:"not null;index" json:"user_id"`
+	FileKey       string `gorm:"type:varchar(255);not null" json:"-"` // Object key in the storage backend
+	FileName      string `gorm:"type:varchar(255)" json:"file_name"`
+	ContentType   string `gorm:"type:varchar(100)" json:"content_type"`
+	Size          int64  `gorm:"not null;default:0" json:"size"`
+	Source        string `gorm:"type:varchar(20)" json:"source"` // API / WHATSAPP
+
+	URL string `gorm:"-" json:"url,omitempty"` // Filled from the storage backend when returned
+}
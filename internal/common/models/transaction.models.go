@@ -1,6 +1,7 @@
 package models
 
 import (
+	types "pannypal/internal/common/type"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,8 +12,9 @@ import (
 type TransactionType string
 
 const (
-	TypeIncome  TransactionType = "INCOME"
-	TypeExpense TransactionType = "EXPENSE"
+	TypeIncome   TransactionType = "INCOME"
+	TypeExpense  TransactionType = "EXPENSE"
+	TypeTransfer TransactionType = "TRANSFER" // Moves money from AccountID to ToAccountID
 )
 
 // --- Structs ---
@@ -22,43 +24,40 @@ type User struct {
 	PhoneNumber string `gorm:"type:varchar(50);uniqueIndex;not null" json:"phone_number"`
 	Name        string `gorm:"type:varchar(100)" json:"name"`
 
-	// Relations (Has Many)
-	Budgets      []Budget      `gorm:"foreignKey:UserID" json:"budgets,omitempty"`
-	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions,omitempty"`
-}
+	// Reports and analytics are converted into this currency
+	BaseCurrency string `gorm:"type:varchar(3);not null;default:'IDR'" json:"base_currency"`
 
-type Category struct {
-	gorm.Model
-	Name string `gorm:"type:varchar(100);not null" json:"name"`
+	// Private chat of the user, used by the worker to send reminders and drafts
+	WhatsappChatID string `gorm:"type:varchar(100)" json:"-"`
+	BotAccountID   string `gorm:"type:varchar(100)" json:"-"`
 
-	// Relations
-	Budgets      []Budget      `gorm:"foreignKey:CategoryID" json:"budgets,omitempty"`
-	Transactions []Transaction `gorm:"foreignKey:CategoryID" json:"transactions,omitempty"`
-}
+	// Envelope budgeting counts income and spending from this day, nil while the mode is off
+	EnvelopeStart *time.Time `gorm:"type:date" json:"envelope_start"`
 
-type Budget struct {
-	gorm.Model
-	UserID     uint    `gorm:"not null;index" json:"user_id"`
-	CategoryID uint    `gorm:"not null;index" json:"category_id"`
-	Amount     float64 `gorm:"type:decimal(15,2);not null" json:"amount"` // Menggunakan decimal untuk uang
-	Month      int     `gorm:"not null" json:"month"`                     // 1-12
-	Year       int     `gorm:"not null" json:"year"`                      // 2023, 2024
-
-	// Relations
-	User     User     `json:"-"`
-	Category Category `json:"category"`
+	// Relations (Has Many)
+	Budgets      []Budget      `gorm:"foreignKey:UserID" json:"budgets,omitempty"`
+	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions,omitempty"`
 }
 
 type Transaction struct {
 	gorm.Model
 	UserID          uint            `gorm:"not null;index" json:"user_id"`
 	CategoryID      *uint           `gorm:"index" json:"category_id"`
-	Amount          float64         `gorm:"type:decimal(15,2);not null" json:"amount"`
+	LedgerID        *uint           `gorm:"index" json:"ledger_id"` // Set when recorded in a group chat
+	AccountID       *uint           `gorm:"index" json:"account_id"`
+	ToAccountID     *uint           `gorm:"index" json:"to_account_id"` // Destination of a TRANSFER
+	RecurringRuleID *uint           `gorm:"index" json:"recurring_rule_id"`
+	Amount          types.Money     `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency        string          `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"` // ISO 4217 code of Amount
 	Description     string          `gorm:"type:text" json:"description"`
+	Merchant        string          `gorm:"type:varchar(150)" json:"merchant"`
+	Notes           string          `gorm:"type:text" json:"notes"`
 	TransactionDate time.Time       `gorm:"not null" json:"transaction_date"`
-	Type            TransactionType `gorm:"type:varchar(10);not null" json:"type"` // INCOME / EXPENSE
+	Type            TransactionType `gorm:"type:varchar(10);not null" json:"type"` // INCOME / EXPENSE / TRANSFER
 
 	// Relations
-	User     User     `json:"-"`
-	Category Category `json:"category"`
+	User        User         `json:"-"`
+	Category    Category     `json:"category"`
+	Tags        []Tag        `gorm:"many2many:transaction_tags" json:"tags,omitempty"`
+	Attachments []Attachment `gorm:"foreignKey:TransactionID" json:"attachments,omitempty"`
 }
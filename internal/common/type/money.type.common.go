@@ -0,0 +1,248 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of minor units in one major unit, amounts keep two decimals
+const MoneyScale = 100
+
+// Money is an exact amount in minor units, Rp. 1.250,50 is 125050. It is stored as decimal(15,2)
+// and travels in JSON as a plain number, so sums never pick up float rounding.
+type Money int64
+
+// NewMoney builds an amount from whole units
+func NewMoney(major int64) Money {
+	return Money(major * MoneyScale)
+}
+
+// MoneyFromFloat rounds a float to the nearest minor unit, only for values that are floats
+// already such as converted or averaged amounts
+func MoneyFromFloat(value float64) Money {
+	return Money(math.Round(value * MoneyScale))
+}
+
+// ParseMoney parses a decimal like "1250000", "-12.5" or "0.125" exactly,
+// digits past the second decimal are rounded half away from zero
+func ParseMoney(value string) (Money, error) {
+	text := strings.TrimSpace(value)
+	if text == "" {
+		return 0, fmt.Errorf("invalid money amount: %q", value)
+	}
+
+	negative := false
+	switch text[0] {
+	case '-':
+		negative = true
+		text = text[1:]
+	case '+':
+		text = text[1:]
+	}
+	// One sign only, "--5" is not 5
+	if text == "" || text[0] == '-' || text[0] == '+' {
+		return 0, fmt.Errorf("invalid money amount: %q", value)
+	}
+
+	// Exponents come from numeric columns or JSON numbers such as 1.5e6
+	if strings.ContainsAny(text, "eE") {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid money amount: %q", value)
+		}
+		m := MoneyFromFloat(f)
+		if negative {
+			m = -m
+		}
+		return m, nil
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("invalid money amount: %q", value)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount: %q", value)
+	}
+
+	minor := int64(0)
+	roundUp := false
+	for i, digit := range fraction {
+		if digit < '0' || digit > '9' {
+			return 0, fmt.Errorf("invalid money amount: %q", value)
+		}
+		switch {
+		case i < 2:
+			minor = minor*10 + int64(digit-'0')
+		case i == 2:
+			roundUp = digit >= '5'
+		}
+	}
+	for i := len(fraction); i < 2; i++ {
+		minor *= 10
+	}
+	if roundUp {
+		minor++
+	}
+
+	if major > (math.MaxInt64-minor)/MoneyScale {
+		return 0, fmt.Errorf("money amount out of range: %q", value)
+	}
+
+	result := Money(major*MoneyScale + minor)
+	if negative {
+		result = -result
+	}
+	return result, nil
+}
+
+// Float64 is for ratios and charts only, never add floats back into Money
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// Major drops the minor units, 12.75 is 12
+func (m Money) Major() int64 {
+	return int64(m) / MoneyScale
+}
+
+// Minor is the amount in minor units
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Mul multiplies by a factor such as an exchange rate, rounding to the nearest minor unit
+func (m Money) Mul(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Allocate splits the amount by weights, the minor units left over by rounding go to the first
+// parts so the pieces always add back up to the amount
+func (m Money) Allocate(weights []int64) []Money {
+	parts := make([]Money, len(weights))
+	total := int64(0)
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return parts
+	}
+
+	allocated := Money(0)
+	for i, w := range weights {
+		parts[i] = Money(int64(m) * w / total)
+		allocated += parts[i]
+	}
+
+	step := Money(1)
+	if m < 0 {
+		step = -1
+	}
+	for i := 0; allocated != m && len(parts) > 0; i = (i + 1) % len(parts) {
+		if weights[i] == 0 {
+			continue
+		}
+		parts[i] += step
+		allocated += step
+	}
+	return parts
+}
+
+// String renders the amount as a plain decimal, "1250000" or "12.50"
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	if value%MoneyScale == 0 {
+		return fmt.Sprintf("%s%d", sign, value/MoneyScale)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/MoneyScale, value%MoneyScale)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a quoted decimal
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" || text == "" {
+		*m = 0
+		return nil
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam binds query and form values
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := ParseMoney(param)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string, the columns are decimal(15,2)
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads decimal columns and aggregates such as SUM() without going through float64
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = NewMoney(v)
+		return nil
+	case float64:
+		*m = MoneyFromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+var _ json.Marshaler = Money(0)
@@ -0,0 +1,40 @@
+package types
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{value: "1250000", want: 125000000},
+		{value: "-12.5", want: -1250},
+		{value: "+12.5", want: 1250},
+		{value: " 0.125 ", want: 13},
+		{value: "-0.125", want: -13},
+		{value: ".5", want: 50},
+		{value: "7.", want: 700},
+		{value: "1.5e6", want: 150000000},
+		{value: "", wantErr: true},
+		{value: "-", wantErr: true},
+		{value: ".", wantErr: true},
+		{value: "--5", wantErr: true},
+		{value: "+-5", wantErr: true},
+		{value: "-+5e3", wantErr: true},
+		{value: "1.2.3", wantErr: true},
+		{value: "12a", wantErr: true},
+		{value: "92233720368547758.08", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	accountService "pannypal/internal/service/account"
+	"pannypal/internal/service/account/dto"
+)
+
+type Handler struct {
+	ctx            context.Context
+	rabbitmq       *rabbitmq.ConnectionManager
+	accountService accountService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateAccount(c *gin.Context)
+	GetAccounts(c *gin.Context)
+	GetAccountByID(c *gin.Context)
+	UpdateAccount(c *gin.Context)
+	DeleteAccount(c *gin.Context)
+	GetAccountBalances(c *gin.Context)
+	GetAccountBalance(c *gin.Context)
+	ReconcileAccount(c *gin.Context)
+	GetReconciliations(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, accountService accountService.IService) IHandler {
+	return &Handler{
+		ctx:            ctx,
+		rabbitmq:       rabbitmq,
+		accountService: accountService,
+	}
+}
+
+// CreateAccount godoc
+// @Summary Create account
+// @Description Create an account or wallet such as a bank account, cash or an e-wallet
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param account body dto.CreateAccountRequest true "Account data"
+// @Success 201 {object} dto.AccountResponse "Account created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /accounts [post]
+func (h *Handler) CreateAccount(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateAccountRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.CreateAccountRequest(payload))
+}
+
+// GetAccounts godoc
+// @Summary Get accounts
+// @Description Get the user's accounts
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param include_archived query bool false "Include archived accounts"
+// @Success 200 {object} dto.AccountListResponse "Accounts retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /accounts [get]
+func (h *Handler) GetAccounts(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetAccountsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.GetAccountsRequest(payload))
+}
+
+// GetAccountByID godoc
+// @Summary Get account by ID
+// @Description Get a single account
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.AccountResponse "Account retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /accounts/{id} [get]
+func (h *Handler) GetAccountByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.accountService.GetAccountByIDRequest(uint(accountID), phoneNumber))
+}
+
+// UpdateAccount godoc
+// @Summary Update account
+// @Description Update or archive an account. All fields are optional
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param phone_number query string true "User's phone number"
+// @Param account body dto.UpdateAccountRequest true "Updated account data"
+// @Success 200 {object} dto.AccountResponse "Account updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /accounts/{id} [put]
+func (h *Handler) UpdateAccount(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateAccountRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.UpdateAccountRequest(uint(accountID), payload, phoneNumber))
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Delete an account, archive it instead to keep it in balances history
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Account deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /accounts/{id} [delete]
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.accountService.DeleteAccountRequest(uint(accountID), phoneNumber))
+}
+
+// GetAccountBalances godoc
+// @Summary Get account balances
+// @Description Get the balance of every account with totals per currency
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param as_of query string false "Balance at the end of this date (YYYY-MM-DD)"
+// @Success 200 {object} dto.AccountBalanceListResponse "Account balances retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /accounts/balances [get]
+func (h *Handler) GetAccountBalances(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.AccountBalanceRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.GetAccountBalancesRequest(payload))
+}
+
+// GetAccountBalance godoc
+// @Summary Get account balance
+// @Description Get the running balance of an account: opening balance, income, expense and transfers
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param phone_number query string true "User's phone number"
+// @Param as_of query string false "Balance at the end of this date (YYYY-MM-DD)"
+// @Success 200 {object} dto.AccountBalanceResponse "Account balance retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /accounts/{id}/balance [get]
+func (h *Handler) GetAccountBalance(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.AccountBalanceRequest
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.GetAccountBalanceRequest(uint(accountID), payload))
+}
+
+// ReconcileAccount godoc
+// @Summary Reconcile account
+// @Description Compare a stated balance with the computed one, optionally booking the difference as an adjustment
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param reconciliation body dto.ReconcileAccountRequest true "Reconciliation data"
+// @Success 201 {object} dto.ReconciliationResponse "Account reconciled successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /accounts/{id}/reconcile [post]
+func (h *Handler) ReconcileAccount(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.ReconcileAccountRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.accountService.ReconcileAccountRequest(uint(accountID), payload))
+}
+
+// GetReconciliations godoc
+// @Summary Get reconciliations
+// @Description Get the latest reconciliations of an account
+// @Tags Account APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.ReconciliationListResponse "Reconciliations retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /accounts/{id}/reconciliations [get]
+func (h *Handler) GetReconciliations(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid account ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.accountService.GetReconciliationsRequest(uint(accountID), phoneNumber))
+}
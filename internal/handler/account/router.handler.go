@@ -0,0 +1,16 @@
+package account
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/accounts")
+	group.POST("", h.CreateAccount)
+	group.GET("", h.GetAccounts)
+	group.GET("/balances", h.GetAccountBalances)
+	group.GET("/:id", h.GetAccountByID)
+	group.PUT("/:id", h.UpdateAccount)
+	group.DELETE("/:id", h.DeleteAccount)
+	group.GET("/:id/balance", h.GetAccountBalance)
+	group.POST("/:id/reconcile", h.ReconcileAccount)
+	group.GET("/:id/reconciliations", h.GetReconciliations)
+}
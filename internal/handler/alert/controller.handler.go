@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	alertService "pannypal/internal/service/alert"
+	"pannypal/internal/service/alert/dto"
+)
+
+type Handler struct {
+	ctx          context.Context
+	rabbitmq     *rabbitmq.ConnectionManager
+	alertService alertService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	GetAlertSetting(c *gin.Context)
+	UpdateAlertSetting(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, alertService alertService.IService) IHandler {
+	return &Handler{
+		ctx:          ctx,
+		rabbitmq:     rabbitmq,
+		alertService: alertService,
+	}
+}
+
+// GetAlertSetting godoc
+// @Summary Get alert settings
+// @Description Get the user's quiet hours and daily digest for budget alerts
+// @Tags Alert APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.AlertSettingResponse "Alert settings retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /alerts/settings [get]
+func (h *Handler) GetAlertSetting(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetAlertSettingRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.alertService.GetAlertSettingRequest(payload))
+}
+
+// UpdateAlertSetting godoc
+// @Summary Update alert settings
+// @Description Replace the user's quiet hours, during which budget alerts wait, and the daily digest that sends personal budget alerts once a day
+// @Tags Alert APIs
+// @Accept json
+// @Produce json
+// @Param setting body dto.UpdateAlertSettingRequest true "Alert settings"
+// @Success 200 {object} dto.AlertSettingResponse "Alert settings updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /alerts/settings [put]
+func (h *Handler) UpdateAlertSetting(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.UpdateAlertSettingRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.alertService.UpdateAlertSettingRequest(payload))
+}
@@ -0,0 +1,9 @@
+package alert
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/alerts")
+	group.GET("/settings", h.GetAlertSetting)
+	group.PUT("/settings", h.UpdateAlertSetting)
+}
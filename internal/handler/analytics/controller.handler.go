@@ -25,7 +25,9 @@ type IHandler interface {
 	GetMonthlyAnalytics(c *gin.Context)
 	GetYearlyAnalytics(c *gin.Context)
 	GetCategoryAnalytics(c *gin.Context)
+	GetTagAnalytics(c *gin.Context)
 	GetDashboardAnalytics(c *gin.Context)
+	GetNetWorth(c *gin.Context)
 }
 
 func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, analyticsService analyticsService.IService) IHandler {
@@ -153,6 +155,46 @@ func (h *Handler) GetCategoryAnalytics(c *gin.Context) {
 	send(h.analyticsService.GetCategoryAnalyticsRequest(payload))
 }
 
+// GetTagAnalytics godoc
+// @Summary Get tag analytics
+// @Description Get spending/income by tag, a transaction with several tags counts towards each of them
+// @Tags Analytics APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string false "User's phone number"
+// @Param start_date query string false "Analysis from this date (format: 2006-01-02)"
+// @Param end_date query string false "Analysis until this date (format: 2006-01-02)"
+// @Param type query string false "INCOME or EXPENSE"
+// @Success 200 {object} dto.TagAnalyticsResponse "Tag analytics retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /analytics/tags [get]
+func (h *Handler) GetTagAnalytics(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.TagAnalyticsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.analyticsService.GetTagAnalyticsRequest(payload))
+}
+
 // GetDashboardAnalytics godoc
 // @Summary Get dashboard analytics
 // @Description Get total balance, income/expense and their changes from previous period
@@ -191,3 +233,44 @@ func (h *Handler) GetDashboardAnalytics(c *gin.Context) {
 
 	send(h.analyticsService.GetDashboardAnalyticsRequest(payload))
 }
+
+// GetNetWorth godoc
+// @Summary Get net worth
+// @Description Get the user's current net worth from accounts, assets entered by hand, credit cards and loans, with a time series of the daily snapshots taken by the worker
+// @Tags Analytics APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param start_date query string false "Start date (format: 2006-01-02), defaults to a year before the end date"
+// @Param end_date query string false "End date (format: 2006-01-02), defaults to today"
+// @Param interval query string false "DAILY, WEEKLY or MONTHLY (default)"
+// @Success 200 {object} dto.NetWorthResponse "Net worth retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /analytics/net-worth [get]
+func (h *Handler) GetNetWorth(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.NetWorthRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.analyticsService.GetNetWorthRequest(payload))
+}
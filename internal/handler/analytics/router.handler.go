@@ -7,5 +7,7 @@ func (h *Handler) NewRoutes(e *gin.RouterGroup) {
 	group.GET("/monthly", h.GetMonthlyAnalytics)
 	group.GET("/yearly", h.GetYearlyAnalytics)
 	group.GET("/categories", h.GetCategoryAnalytics)
+	group.GET("/tags", h.GetTagAnalytics)
 	group.GET("/dashboard", h.GetDashboardAnalytics)
+	group.GET("/net-worth", h.GetNetWorth)
 }
@@ -0,0 +1,257 @@
+package asset
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	assetService "pannypal/internal/service/asset"
+	"pannypal/internal/service/asset/dto"
+)
+
+type Handler struct {
+	ctx          context.Context
+	rabbitmq     *rabbitmq.ConnectionManager
+	assetService assetService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateAsset(c *gin.Context)
+	GetAssets(c *gin.Context)
+	GetAssetByID(c *gin.Context)
+	UpdateAsset(c *gin.Context)
+	DeleteAsset(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, assetService assetService.IService) IHandler {
+	return &Handler{
+		ctx:          ctx,
+		rabbitmq:     rabbitmq,
+		assetService: assetService,
+	}
+}
+
+// CreateAsset godoc
+// @Summary Create asset
+// @Description Create an investment, property, vehicle or other asset entered by hand. LIABILITY is money owed that is neither a loan nor a credit card
+// @Tags Asset APIs
+// @Accept json
+// @Produce json
+// @Param asset body dto.CreateAssetRequest true "Asset data"
+// @Success 201 {object} dto.AssetResponse "Asset created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /assets [post]
+func (h *Handler) CreateAsset(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateAssetRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.assetService.CreateAssetRequest(payload))
+}
+
+// GetAssets godoc
+// @Summary Get assets
+// @Description Get the assets and liabilities the user entered by hand with their totals per currency
+// @Tags Asset APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.AssetListResponse "Assets retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /assets [get]
+func (h *Handler) GetAssets(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetAssetsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.assetService.GetAssetsRequest(payload))
+}
+
+// GetAssetByID godoc
+// @Summary Get asset by ID
+// @Description Get an asset entered by hand
+// @Tags Asset APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.AssetResponse "Asset retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /assets/{id} [get]
+func (h *Handler) GetAssetByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid asset ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.assetService.GetAssetByIDRequest(uint(assetID), phoneNumber))
+}
+
+// UpdateAsset godoc
+// @Summary Update asset
+// @Description Update an asset. All fields are optional, a new value without valued_at is dated today
+// @Tags Asset APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param phone_number query string true "User's phone number"
+// @Param asset body dto.UpdateAssetRequest true "Updated asset data"
+// @Success 200 {object} dto.AssetResponse "Asset updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /assets/{id} [put]
+func (h *Handler) UpdateAsset(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid asset ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateAssetRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.assetService.UpdateAssetRequest(uint(assetID), payload, phoneNumber))
+}
+
+// DeleteAsset godoc
+// @Summary Delete asset
+// @Description Delete an asset, past net worth snapshots keep its value
+// @Tags Asset APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Asset deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /assets/{id} [delete]
+func (h *Handler) DeleteAsset(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid asset ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.assetService.DeleteAssetRequest(uint(assetID), phoneNumber))
+}
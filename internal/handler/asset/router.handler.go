@@ -0,0 +1,12 @@
+package asset
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/assets")
+	group.POST("", h.CreateAsset)
+	group.GET("", h.GetAssets)
+	group.GET("/:id", h.GetAssetByID)
+	group.PUT("/:id", h.UpdateAsset)
+	group.DELETE("/:id", h.DeleteAsset)
+}
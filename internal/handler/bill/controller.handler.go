@@ -0,0 +1,361 @@
+package bill
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	billService "pannypal/internal/service/bill"
+	"pannypal/internal/service/bill/dto"
+)
+
+type Handler struct {
+	ctx         context.Context
+	rabbitmq    *rabbitmq.ConnectionManager
+	billService billService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateBill(c *gin.Context)
+	GetBills(c *gin.Context)
+	GetOverdueBills(c *gin.Context)
+	GetBillByID(c *gin.Context)
+	UpdateBill(c *gin.Context)
+	DeleteBill(c *gin.Context)
+	PayBill(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, billService billService.IService) IHandler {
+	return &Handler{
+		ctx:         ctx,
+		rabbitmq:    rabbitmq,
+		billService: billService,
+	}
+}
+
+// CreateBill godoc
+// @Summary Create bill
+// @Description Create a monthly bill whose amount varies, such as electricity or a credit card. The worker sends a WhatsApp reminder remind_days_before the due day and marks the bill paid when an expense to the payee or in the category is recorded
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param bill body dto.CreateBillRequest true "Bill data"
+// @Success 201 {object} dto.BillDetailResponse "Bill created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills [post]
+func (h *Handler) CreateBill(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateBillRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.billService.CreateBillRequest(payload))
+}
+
+// GetBills godoc
+// @Summary Get bills
+// @Description Get the user's bills with their next due date and how many due dates are overdue
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.BillListResponse "Bills retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills [get]
+func (h *Handler) GetBills(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetBillsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.billService.GetBillsRequest(payload))
+}
+
+// GetOverdueBills godoc
+// @Summary Get overdue bills
+// @Description Get the unpaid due dates of the user's active bills that have passed, with the days overdue and the expected total per currency
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.OverdueReportResponse "Overdue bills retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills/overdue [get]
+func (h *Handler) GetOverdueBills(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetBillsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.billService.GetOverdueBillsRequest(payload))
+}
+
+// GetBillByID godoc
+// @Summary Get bill by ID
+// @Description Get a bill with its due dates of the last year up to the next one
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Bill ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.BillDetailResponse "Bill retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills/{id} [get]
+func (h *Handler) GetBillByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.billService.GetBillByIDRequest(uint(billID), phoneNumber))
+}
+
+// UpdateBill godoc
+// @Summary Update bill
+// @Description Update a bill. All fields are optional, is_active false stops its reminders. 0 for account_id or category_id unlinks them
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Bill ID"
+// @Param phone_number query string true "User's phone number"
+// @Param bill body dto.UpdateBillRequest true "Updated bill data"
+// @Success 200 {object} dto.BillDetailResponse "Bill updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills/{id} [put]
+func (h *Handler) UpdateBill(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateBillRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.billService.UpdateBillRequest(uint(billID), payload, phoneNumber))
+}
+
+// DeleteBill godoc
+// @Summary Delete bill
+// @Description Delete a bill with its payment history, the paying transactions are kept
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Bill ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Bill deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /bills/{id} [delete]
+func (h *Handler) DeleteBill(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.billService.DeleteBillRequest(uint(billID), phoneNumber))
+}
+
+// PayBill godoc
+// @Summary Pay bill
+// @Description Mark a due date of the bill paid, the earliest unpaid one unless due_date is given. Links one of the user's expense transactions, without transaction_id an expense of amount is recorded on the bill's account and category
+// @Tags Bill APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Bill ID"
+// @Param phone_number query string true "User's phone number"
+// @Param payment body dto.PayBillRequest true "Payment data"
+// @Success 200 {object} dto.BillDetailResponse "Bill paid successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Conflict"
+// @Router /bills/{id}/pay [post]
+func (h *Handler) PayBill(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid bill ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.PayBillRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.billService.PayBillRequest(uint(billID), payload, phoneNumber))
+}
@@ -0,0 +1,14 @@
+package bill
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/bills")
+	group.POST("", h.CreateBill)
+	group.GET("", h.GetBills)
+	group.GET("/overdue", h.GetOverdueBills)
+	group.GET("/:id", h.GetBillByID)
+	group.PUT("/:id", h.UpdateBill)
+	group.DELETE("/:id", h.DeleteBill)
+	group.POST("/:id/pay", h.PayBill)
+}
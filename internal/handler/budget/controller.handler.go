@@ -29,6 +29,10 @@ type IHandler interface {
 	UpdateBudget(c *gin.Context)
 	DeleteBudget(c *gin.Context)
 	GetBudgetStatus(c *gin.Context)
+	CreateBudgetTemplate(c *gin.Context)
+	GetBudgetTemplates(c *gin.Context)
+	UpdateBudgetTemplate(c *gin.Context)
+	DeleteBudgetTemplate(c *gin.Context)
 }
 
 func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, budgetService budgetService.IService) IHandler {
@@ -41,7 +45,10 @@ func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, budge
 
 // CreateBudget godoc
 // @Summary Create budget
-// @Description Set monthly budget for a category
+// @Description Set a budget for a category over a weekly, monthly, quarterly or yearly period. start_day moves the start of
+// @Description the period, a monthly budget with start_day 25 runs from the 25th to the 24th. The budget is for the period
+// @Description holding date, or the one starting in month/year, or the current one. With rollover what the previous period
+// @Description left over, or overspent, is added to this one
 // @Tags Budget APIs
 // @Accept json
 // @Produce json
@@ -83,9 +90,11 @@ func (h *Handler) CreateBudget(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param phone_number query string false "User's phone number"
-// @Param month query int false "Filter by month"
-// @Param year query int false "Filter by year"
+// @Param ledger_id query int false "Group ledger ID, returns the group budgets"
+// @Param month query int false "Filter by the month the period starts in"
+// @Param year query int false "Filter by the year the period starts in"
 // @Param category_id query int false "Filter by category"
+// @Param period query string false "Filter by period" Enums(WEEKLY, MONTHLY, QUARTERLY, YEARLY)
 // @Success 200 {object} types.Response "Budgets retrieved successfully"
 // @Failure 400 {object} types.Response "Bad Request"
 // @Router /budgets [get]
@@ -260,13 +269,16 @@ func (h *Handler) DeleteBudget(c *gin.Context) {
 
 // GetBudgetStatus godoc
 // @Summary Get budget status
-// @Description Check budget usage status
+// @Description Check the usage of the budgets whose period holds date, today by default, or overlaps month/year.
+// @Description Spending is counted over each budget's own period, available_amount includes what rolled over
 // @Tags Budget APIs
 // @Accept json
 // @Produce json
 // @Param phone_number query string false "User's phone number"
-// @Param month query int false "Filter by month (1-12)"
-// @Param year query int false "Filter by year"
+// @Param ledger_id query int false "Group ledger ID, returns the group budget status"
+// @Param date query string false "Day the budgets are active on (YYYY-MM-DD)"
+// @Param month query int false "Budgets overlapping this month (1-12), needs year"
+// @Param year query int false "Budgets overlapping this year, or the month of it"
 // @Success 200 {object} dto.BudgetStatusListResponse "Budget status retrieved successfully"
 // @Failure 400 {object} types.Response "Bad Request"
 // @Router /budgets/status [get]
@@ -296,3 +308,186 @@ func (h *Handler) GetBudgetStatus(c *gin.Context) {
 
 	send(h.budgetService.GetBudgetStatusRequest(payload))
 }
+
+// CreateBudgetTemplate godoc
+// @Summary Create budget template
+// @Description Set a budget that the worker creates again at the start of every period, the current period gets its budget right away.
+// @Description With adjust_to_average the amount follows the average spending of the three months before each period
+// @Tags Budget APIs
+// @Accept json
+// @Produce json
+// @Param template body dto.CreateBudgetTemplateRequest true "Budget template data"
+// @Success 201 {object} dto.BudgetTemplateResponse "Budget template created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /budgets/templates [post]
+func (h *Handler) CreateBudgetTemplate(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateBudgetTemplateRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.budgetService.CreateBudgetTemplateRequest(payload))
+}
+
+// GetBudgetTemplates godoc
+// @Summary Get budget templates
+// @Description Get the user's budget templates, or the group's with ledger_id
+// @Tags Budget APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param ledger_id query int false "Group ledger ID, returns the group's templates"
+// @Success 200 {object} dto.BudgetTemplateListResponse "Budget templates retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /budgets/templates [get]
+func (h *Handler) GetBudgetTemplates(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetBudgetTemplatesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.budgetService.GetBudgetTemplatesRequest(payload))
+}
+
+// UpdateBudgetTemplate godoc
+// @Summary Update budget template
+// @Description Update a budget template, changes apply from the next budget it creates. is_active false pauses it
+// @Tags Budget APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget template ID"
+// @Param phone_number query string true "User's phone number"
+// @Param template body dto.UpdateBudgetTemplateRequest true "Updated budget template data"
+// @Success 200 {object} dto.BudgetTemplateResponse "Budget template updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /budgets/templates/{id} [put]
+func (h *Handler) UpdateBudgetTemplate(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	id := c.Param("id")
+	templateID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid budget template ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateBudgetTemplateRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.budgetService.UpdateBudgetTemplateRequest(uint(templateID), payload, phoneNumber))
+}
+
+// DeleteBudgetTemplate godoc
+// @Summary Delete budget template
+// @Description Delete a budget template, the budgets it already created stay
+// @Tags Budget APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget template ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Budget template deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /budgets/templates/{id} [delete]
+func (h *Handler) DeleteBudgetTemplate(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	id := c.Param("id")
+	templateID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid budget template ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.budgetService.DeleteBudgetTemplateRequest(uint(templateID), phoneNumber))
+}
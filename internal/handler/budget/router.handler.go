@@ -10,4 +10,8 @@ func (h *Handler) NewRoutes(e *gin.RouterGroup) {
 	group.PUT("/:id", h.UpdateBudget)
 	group.DELETE("/:id", h.DeleteBudget)
 	group.GET("/status", h.GetBudgetStatus)
+	group.POST("/templates", h.CreateBudgetTemplate)
+	group.GET("/templates", h.GetBudgetTemplates)
+	group.PUT("/templates/:id", h.UpdateBudgetTemplate)
+	group.DELETE("/templates/:id", h.DeleteBudgetTemplate)
 }
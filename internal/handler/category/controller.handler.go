@@ -28,6 +28,7 @@ type IHandler interface {
 	GetCategoryByID(c *gin.Context)
 	UpdateCategory(c *gin.Context)
 	DeleteCategory(c *gin.Context)
+	MergeCategory(c *gin.Context)
 }
 
 func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, categoryService categoryService.IService) IHandler {
@@ -40,7 +41,8 @@ func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, categ
 
 // CreateCategory godoc
 // @Summary Create new category
-// @Description Create a new category for transactions
+// @Description Create a category for the user, or a template category when phone_number is left out.
+// @Description With parent_id it becomes a subcategory, only top-level categories can have subcategories
 // @Tags Category APIs
 // @Accept json
 // @Produce json
@@ -76,27 +78,56 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 }
 
 // GetCategories godoc
-// @Summary Get all categories
-// @Description Get list of all categories
+// @Summary Get categories
+// @Description Get the user's categories as a tree of top-level categories and their subcategories,
+// @Description or the template new users start from when phone_number is left out
 // @Tags Category APIs
 // @Accept json
 // @Produce json
+// @Param phone_number query string false "User's phone number"
+// @Param type query string false "Only categories that can hold this transaction type (INCOME/EXPENSE)"
 // @Success 200 {object} dto.CategoryListResponse "Categories retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
 // @Router /categories [get]
 func (h *Handler) GetCategories(c *gin.Context) {
 	send := c.MustGet("send").(func(r *types.Response))
-	send(h.categoryService.GetCategoriesRequest())
+	var payload dto.GetCategoriesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.categoryService.GetCategoriesRequest(payload))
 }
 
 // GetCategoryByID godoc
 // @Summary Get category by ID
-// @Description Get specific category by ID
+// @Description Get a category with its subcategories, phone_number is left out for template categories
 // @Tags Category APIs
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param phone_number query string false "User's phone number"
 // @Success 200 {object} dto.CategoryResponse "Category retrieved successfully"
 // @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
 // @Failure 404 {object} types.Response "Not Found"
 // @Router /categories/{id} [get]
 func (h *Handler) GetCategoryByID(c *gin.Context) {
@@ -113,19 +144,22 @@ func (h *Handler) GetCategoryByID(c *gin.Context) {
 		return
 	}
 
-	send(h.categoryService.GetCategoryByIDRequest(uint(categoryID)))
+	send(h.categoryService.GetCategoryByIDRequest(uint(categoryID), c.Query("phone_number")))
 }
 
 // UpdateCategory godoc
 // @Summary Update category
-// @Description Update existing category
+// @Description Update a category, parent_id 0 moves a subcategory to the top level. Subcategories hold a subset of
+// @Description the transaction types of their parent, phone_number is left out for template categories
 // @Tags Category APIs
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param phone_number query string false "User's phone number"
 // @Param category body dto.UpdateCategoryRequest true "Updated category data"
 // @Success 200 {object} dto.CategoryResponse "Category updated successfully"
 // @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
 // @Failure 404 {object} types.Response "Not Found"
 // @Router /categories/{id} [put]
 func (h *Handler) UpdateCategory(c *gin.Context) {
@@ -163,19 +197,26 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	send(h.categoryService.UpdateCategoryRequest(uint(categoryID), payload))
+	send(h.categoryService.UpdateCategoryRequest(uint(categoryID), payload, c.Query("phone_number")))
 }
 
 // DeleteCategory godoc
 // @Summary Delete category
-// @Description Delete category by ID
+// @Description Move a category to the trash with its budgets. Its transactions lose their category (uncategorize, the default),
+// @Description move to reassign_to (reassign) or go to the trash with it (delete). Restoring the category brings back what was trashed with it.
+// @Description A category with subcategories cannot be deleted
 // @Tags Category APIs
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
-// @Success 200 {object} types.Response "Category deleted successfully"
+// @Param phone_number query string false "User's phone number, left out for template categories"
+// @Param on_transactions query string false "What happens to the category's transactions" Enums(uncategorize, reassign, delete)
+// @Param reassign_to query int false "Target category ID, required for reassign"
+// @Success 200 {object} dto.DeleteCategoryResponse "Category deleted successfully"
 // @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
 // @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Category has subcategories"
 // @Router /categories/{id} [delete]
 func (h *Handler) DeleteCategory(c *gin.Context) {
 	send := c.MustGet("send").(func(r *types.Response))
@@ -191,5 +232,80 @@ func (h *Handler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	send(h.categoryService.DeleteCategoryRequest(uint(categoryID)))
+	var payload dto.DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.categoryService.DeleteCategoryRequest(uint(categoryID), payload))
+}
+
+// MergeCategory godoc
+// @Summary Merge category
+// @Description Move the category's transactions, budgets, recurring rules and subcategories to target_id in one go and move
+// @Description the category to the trash. A budget for a period the target already has one for is added to it.
+// @Description With preview the counts are returned and nothing is changed
+// @Tags Category APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID to merge away"
+// @Param merge body dto.MergeCategoryRequest true "Target category and preview flag"
+// @Success 200 {object} dto.MergeCategoryResponse "Category merged successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Subcategories cannot move under the target"
+// @Router /categories/{id}/merge [post]
+func (h *Handler) MergeCategory(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	id := c.Param("id")
+	categoryID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid category ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.categoryService.MergeCategoryRequest(uint(categoryID), payload))
 }
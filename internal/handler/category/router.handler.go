@@ -9,4 +9,5 @@ func (h *Handler) NewRoutes(e *gin.RouterGroup) {
 	group.GET("/:id", h.GetCategoryByID)
 	group.PUT("/:id", h.UpdateCategory)
 	group.DELETE("/:id", h.DeleteCategory)
+	group.POST("/:id/merge", h.MergeCategory)
 }
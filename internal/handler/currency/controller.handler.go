@@ -0,0 +1,168 @@
+package currency
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	currencyService "pannypal/internal/service/currency"
+	"pannypal/internal/service/currency/dto"
+)
+
+type Handler struct {
+	ctx             context.Context
+	rabbitmq        *rabbitmq.ConnectionManager
+	currencyService currencyService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	GetRates(c *gin.Context)
+	RefreshRates(c *gin.Context)
+	Convert(c *gin.Context)
+	UpdateBaseCurrency(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, currencyService currencyService.IService) IHandler {
+	return &Handler{
+		ctx:             ctx,
+		rabbitmq:        rabbitmq,
+		currencyService: currencyService,
+	}
+}
+
+// GetRates godoc
+// @Summary Get exchange rates
+// @Description Get the exchange rates of the closest day with rates, quoted against the pivot currency
+// @Tags Currency APIs
+// @Accept json
+// @Produce json
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} dto.RateListResponse "Exchange rates retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /currencies/rates [get]
+func (h *Handler) GetRates(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetRatesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.currencyService.GetRatesRequest(payload))
+}
+
+// RefreshRates godoc
+// @Summary Refresh exchange rates
+// @Description Load today's rates from the configured rate provider, the worker does this daily
+// @Tags Currency APIs
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.RateListResponse "Exchange rates retrieved successfully"
+// @Failure 502 {object} types.Response "Rate provider error"
+// @Router /currencies/rates/refresh [post]
+func (h *Handler) RefreshRates(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+
+	send(h.currencyService.RefreshRatesRequest())
+}
+
+// Convert godoc
+// @Summary Convert amount
+// @Description Convert an amount between currencies with the rates of the closest day
+// @Tags Currency APIs
+// @Accept json
+// @Produce json
+// @Param amount query number true "Amount"
+// @Param from query string true "Source currency (ISO 4217)"
+// @Param to query string true "Target currency (ISO 4217)"
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} dto.ConvertResponse "Amount converted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /currencies/convert [get]
+func (h *Handler) Convert(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.ConvertRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.currencyService.ConvertRequest(payload))
+}
+
+// UpdateBaseCurrency godoc
+// @Summary Update base currency
+// @Description Set the currency the user's summaries and analytics are converted into
+// @Tags Currency APIs
+// @Accept json
+// @Produce json
+// @Param currency body dto.UpdateBaseCurrencyRequest true "Base currency"
+// @Success 200 {object} dto.BaseCurrencyResponse "Base currency updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /currencies/base [put]
+func (h *Handler) UpdateBaseCurrency(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.UpdateBaseCurrencyRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.currencyService.UpdateBaseCurrencyRequest(payload))
+}
@@ -0,0 +1,11 @@
+package currency
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/currencies")
+	group.GET("/rates", h.GetRates)
+	group.POST("/rates/refresh", h.RefreshRates)
+	group.GET("/convert", h.Convert)
+	group.PUT("/base", h.UpdateBaseCurrency)
+}
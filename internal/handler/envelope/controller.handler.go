@@ -0,0 +1,238 @@
+package envelope
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	envelopeService "pannypal/internal/service/envelope"
+	"pannypal/internal/service/envelope/dto"
+)
+
+type Handler struct {
+	ctx             context.Context
+	rabbitmq        *rabbitmq.ConnectionManager
+	envelopeService envelopeService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	UpdateEnvelopeMode(c *gin.Context)
+	GetEnvelopes(c *gin.Context)
+	AssignEnvelope(c *gin.Context)
+	MoveEnvelope(c *gin.Context)
+	GetAllocations(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, envelopeService envelopeService.IService) IHandler {
+	return &Handler{
+		ctx:             ctx,
+		rabbitmq:        rabbitmq,
+		envelopeService: envelopeService,
+	}
+}
+
+// UpdateEnvelopeMode godoc
+// @Summary Turn envelope budgeting on or off
+// @Description Turn envelope budgeting on from a start date, the first day of the current month by default. Income from that day on is ready to assign and spending draws envelopes down
+// @Tags Envelope APIs
+// @Accept json
+// @Produce json
+// @Param mode body dto.UpdateEnvelopeModeRequest true "Envelope budgeting mode"
+// @Success 200 {object} dto.EnvelopeModeResponse "Envelope budgeting updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /envelopes/mode [put]
+func (h *Handler) UpdateEnvelopeMode(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.UpdateEnvelopeModeRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.envelopeService.UpdateEnvelopeModeRequest(payload))
+}
+
+// GetEnvelopes godoc
+// @Summary Get envelope balances
+// @Description Get the money ready to assign and every envelope with what was assigned and spent in the month, what is left and the category's budget as target
+// @Tags Envelope APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param month query int false "Month (1-12), defaults to the current month"
+// @Param year query int false "Year, required with month"
+// @Success 200 {object} dto.EnvelopeListResponse "Envelopes retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Envelope budgeting is not enabled"
+// @Router /envelopes [get]
+func (h *Handler) GetEnvelopes(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetEnvelopesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.envelopeService.GetEnvelopesRequest(payload))
+}
+
+// AssignEnvelope godoc
+// @Summary Assign money to an envelope
+// @Description Put money that is ready to assign into a top-level expense category
+// @Tags Envelope APIs
+// @Accept json
+// @Produce json
+// @Param allocation body dto.AssignEnvelopeRequest true "Allocation data"
+// @Success 201 {object} dto.AllocationResponse "Money moved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Not enough money ready to assign"
+// @Router /envelopes/assign [post]
+func (h *Handler) AssignEnvelope(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.AssignEnvelopeRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.envelopeService.AssignEnvelopeRequest(payload))
+}
+
+// MoveEnvelope godoc
+// @Summary Move money between envelopes
+// @Description Move money from one envelope to another. Without from_category_id the money comes from what is ready to assign, without to_category_id it is made ready to assign again
+// @Tags Envelope APIs
+// @Accept json
+// @Produce json
+// @Param allocation body dto.MoveEnvelopeRequest true "Allocation data"
+// @Success 201 {object} dto.AllocationResponse "Money moved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Not enough money in the envelope"
+// @Router /envelopes/move [post]
+func (h *Handler) MoveEnvelope(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.MoveEnvelopeRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.envelopeService.MoveEnvelopeRequest(payload))
+}
+
+// GetAllocations godoc
+// @Summary Get envelope allocations
+// @Description Get the money assigned and moved between envelopes in the month
+// @Tags Envelope APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param month query int false "Month (1-12), defaults to the current month"
+// @Param year query int false "Year, required with month"
+// @Success 200 {object} dto.AllocationListResponse "Allocations retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Envelope budgeting is not enabled"
+// @Router /envelopes/allocations [get]
+func (h *Handler) GetAllocations(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetAllocationsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.envelopeService.GetAllocationsRequest(payload))
+}
@@ -0,0 +1,12 @@
+package envelope
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/envelopes")
+	group.GET("", h.GetEnvelopes)
+	group.PUT("/mode", h.UpdateEnvelopeMode)
+	group.POST("/assign", h.AssignEnvelope)
+	group.POST("/move", h.MoveEnvelope)
+	group.GET("/allocations", h.GetAllocations)
+}
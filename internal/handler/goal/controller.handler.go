@@ -0,0 +1,374 @@
+package goal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	goalService "pannypal/internal/service/goal"
+	"pannypal/internal/service/goal/dto"
+)
+
+type Handler struct {
+	ctx         context.Context
+	rabbitmq    *rabbitmq.ConnectionManager
+	goalService goalService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateGoal(c *gin.Context)
+	GetGoals(c *gin.Context)
+	GetGoalByID(c *gin.Context)
+	UpdateGoal(c *gin.Context)
+	DeleteGoal(c *gin.Context)
+	AddContribution(c *gin.Context)
+	DeleteContribution(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, goalService goalService.IService) IHandler {
+	return &Handler{
+		ctx:         ctx,
+		rabbitmq:    rabbitmq,
+		goalService: goalService,
+	}
+}
+
+// CreateGoal godoc
+// @Summary Create savings goal
+// @Description Create a savings goal with a target amount and an optional deadline. Transactions with the linked tag, in the linked category or moving money in or out of the linked account count towards it
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param goal body dto.CreateGoalRequest true "Savings goal data"
+// @Success 201 {object} dto.GoalDetailResponse "Savings goal created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals [post]
+func (h *Handler) CreateGoal(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateGoalRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.goalService.CreateGoalRequest(payload))
+}
+
+// GetGoals godoc
+// @Summary Get savings goals
+// @Description Get the user's savings goals with their progress and projected completion date
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.GoalListResponse "Savings goals retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals [get]
+func (h *Handler) GetGoals(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetGoalsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.goalService.GetGoalsRequest(payload))
+}
+
+// GetGoalByID godoc
+// @Summary Get savings goal by ID
+// @Description Get a savings goal with its progress and latest contributions and linked transactions
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Savings goal ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.GoalDetailResponse "Savings goal retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals/{id} [get]
+func (h *Handler) GetGoalByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid savings goal ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.goalService.GetGoalByIDRequest(uint(goalID), phoneNumber))
+}
+
+// UpdateGoal godoc
+// @Summary Update savings goal
+// @Description Update a savings goal. All fields are optional, 0 for account_id or category_id and an empty tag unlink them
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Savings goal ID"
+// @Param phone_number query string true "User's phone number"
+// @Param goal body dto.UpdateGoalRequest true "Updated savings goal data"
+// @Success 200 {object} dto.GoalDetailResponse "Savings goal updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals/{id} [put]
+func (h *Handler) UpdateGoal(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid savings goal ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.goalService.UpdateGoalRequest(uint(goalID), payload, phoneNumber))
+}
+
+// DeleteGoal godoc
+// @Summary Delete savings goal
+// @Description Delete a savings goal, linked transactions are kept
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Savings goal ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Savings goal deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals/{id} [delete]
+func (h *Handler) DeleteGoal(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid savings goal ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.goalService.DeleteGoalRequest(uint(goalID), phoneNumber))
+}
+
+// AddContribution godoc
+// @Summary Add contribution
+// @Description Add money to a savings goal by hand, a negative amount takes money out
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Savings goal ID"
+// @Param phone_number query string true "User's phone number"
+// @Param contribution body dto.AddContributionRequest true "Contribution data"
+// @Success 201 {object} dto.GoalResponse "Contribution added successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals/{id}/contributions [post]
+func (h *Handler) AddContribution(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid savings goal ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.AddContributionRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.goalService.AddContributionRequest(uint(goalID), payload, phoneNumber))
+}
+
+// DeleteContribution godoc
+// @Summary Delete contribution
+// @Description Delete a contribution added by hand, linked transactions are unlinked by changing the transaction or the goal
+// @Tags Goal APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Savings goal ID"
+// @Param contributionId path int true "Contribution ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.GoalDetailResponse "Contribution deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /goals/{id}/contributions/{contributionId} [delete]
+func (h *Handler) DeleteContribution(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid savings goal ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	contributionID, err := strconv.ParseUint(c.Param("contributionId"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid contribution ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.goalService.DeleteContributionRequest(uint(goalID), uint(contributionID), phoneNumber))
+}
@@ -0,0 +1,14 @@
+package goal
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/goals")
+	group.POST("", h.CreateGoal)
+	group.GET("", h.GetGoals)
+	group.GET("/:id", h.GetGoalByID)
+	group.PUT("/:id", h.UpdateGoal)
+	group.DELETE("/:id", h.DeleteGoal)
+	group.POST("/:id/contributions", h.AddContribution)
+	group.DELETE("/:id/contributions/:contributionId", h.DeleteContribution)
+}
@@ -0,0 +1,166 @@
+package ledger
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	ledgerService "pannypal/internal/service/ledger"
+	"pannypal/internal/service/ledger/dto"
+)
+
+type Handler struct {
+	ctx           context.Context
+	rabbitmq      *rabbitmq.ConnectionManager
+	ledgerService ledgerService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	GetLedgers(c *gin.Context)
+	GetLedgerByID(c *gin.Context)
+	GetLedgerAnalytics(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, ledgerService ledgerService.IService) IHandler {
+	return &Handler{
+		ctx:           ctx,
+		rabbitmq:      rabbitmq,
+		ledgerService: ledgerService,
+	}
+}
+
+// GetLedgers godoc
+// @Summary Get ledgers
+// @Description Get the group ledgers the user is a member of
+// @Tags Ledger APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.LedgerListResponse "Ledgers retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /ledgers [get]
+func (h *Handler) GetLedgers(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetLedgersRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.ledgerService.GetLedgersRequest(payload))
+}
+
+// GetLedgerByID godoc
+// @Summary Get ledger by ID
+// @Description Get a group ledger with its members
+// @Tags Ledger APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Ledger ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.LedgerResponse "Ledger retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /ledgers/{id} [get]
+func (h *Handler) GetLedgerByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	ledgerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ledger ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.ledgerService.GetLedgerByIDRequest(uint(ledgerID), phoneNumber))
+}
+
+// GetLedgerAnalytics godoc
+// @Summary Get ledger analytics
+// @Description Get group totals, per-member spending and category breakdown
+// @Tags Ledger APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Ledger ID"
+// @Param phone_number query string true "User's phone number"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.LedgerAnalyticsResponse "Ledger analytics retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /ledgers/{id}/analytics [get]
+func (h *Handler) GetLedgerAnalytics(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+
+	ledgerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid ledger ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.LedgerAnalyticsRequest
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.ledgerService.GetLedgerAnalyticsRequest(uint(ledgerID), payload))
+}
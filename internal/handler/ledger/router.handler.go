@@ -0,0 +1,10 @@
+package ledger
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/ledgers")
+	group.GET("/", h.GetLedgers)
+	group.GET("/:id", h.GetLedgerByID)
+	group.GET("/:id/analytics", h.GetLedgerAnalytics)
+}
@@ -0,0 +1,389 @@
+package loan
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	loanService "pannypal/internal/service/loan"
+	"pannypal/internal/service/loan/dto"
+)
+
+type Handler struct {
+	ctx         context.Context
+	rabbitmq    *rabbitmq.ConnectionManager
+	loanService loanService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateLoan(c *gin.Context)
+	GetLoans(c *gin.Context)
+	GetLoanByID(c *gin.Context)
+	UpdateLoan(c *gin.Context)
+	DeleteLoan(c *gin.Context)
+	PayInstallment(c *gin.Context)
+	UnpayInstallment(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, loanService loanService.IService) IHandler {
+	return &Handler{
+		ctx:         ctx,
+		rabbitmq:    rabbitmq,
+		loanService: loanService,
+	}
+}
+
+// CreateLoan godoc
+// @Summary Create loan
+// @Description Create a loan such as a mortgage or motorbike credit and generate its monthly amortization schedule. ANNUITY loans pay equal installments with interest on the outstanding principal, FLAT loans pay interest on the original principal
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param loan body dto.CreateLoanRequest true "Loan data"
+// @Success 201 {object} dto.LoanDetailResponse "Loan created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /loans [post]
+func (h *Handler) CreateLoan(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateLoanRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.loanService.CreateLoanRequest(payload))
+}
+
+// GetLoans godoc
+// @Summary Get loans
+// @Description Get the user's loans with the outstanding principal and the next installment
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.LoanListResponse "Loans retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /loans [get]
+func (h *Handler) GetLoans(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetLoansRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.loanService.GetLoansRequest(payload))
+}
+
+// GetLoanByID godoc
+// @Summary Get loan by ID
+// @Description Get a loan with its full amortization schedule
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.LoanDetailResponse "Loan retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /loans/{id} [get]
+func (h *Handler) GetLoanByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid loan ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.loanService.GetLoanByIDRequest(uint(loanID), phoneNumber))
+}
+
+// UpdateLoan godoc
+// @Summary Update loan
+// @Description Update a loan. All fields are optional, changing the terms rebuilds the schedule and is refused once an installment is paid. 0 for account_id or category_id unlinks them
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param phone_number query string true "User's phone number"
+// @Param loan body dto.UpdateLoanRequest true "Updated loan data"
+// @Success 200 {object} dto.LoanDetailResponse "Loan updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Conflict"
+// @Router /loans/{id} [put]
+func (h *Handler) UpdateLoan(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid loan ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateLoanRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.loanService.UpdateLoanRequest(uint(loanID), payload, phoneNumber))
+}
+
+// DeleteLoan godoc
+// @Summary Delete loan
+// @Description Delete a loan with its schedule, the installment transactions are kept
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Loan deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /loans/{id} [delete]
+func (h *Handler) DeleteLoan(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid loan ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.loanService.DeleteLoanRequest(uint(loanID), phoneNumber))
+}
+
+// PayInstallment godoc
+// @Summary Pay installment
+// @Description Mark an installment paid by linking one of the user's expense transactions, without transaction_id an expense of the installment amount is recorded on the loan's account and category
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param number path int true "Installment number"
+// @Param phone_number query string true "User's phone number"
+// @Param payment body dto.PayInstallmentRequest true "Payment data"
+// @Success 200 {object} dto.LoanDetailResponse "Installment paid successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Conflict"
+// @Router /loans/{id}/installments/{number}/pay [post]
+func (h *Handler) PayInstallment(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid loan ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid installment number",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.PayInstallmentRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.loanService.PayInstallmentRequest(uint(loanID), number, payload, phoneNumber))
+}
+
+// UnpayInstallment godoc
+// @Summary Remove installment payment
+// @Description Unlink the transaction paying an installment, the transaction itself is kept
+// @Tags Loan APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param number path int true "Installment number"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.LoanDetailResponse "Installment payment removed successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 409 {object} types.Response "Conflict"
+// @Router /loans/{id}/installments/{number}/pay [delete]
+func (h *Handler) UnpayInstallment(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid loan ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid installment number",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.loanService.UnpayInstallmentRequest(uint(loanID), number, phoneNumber))
+}
@@ -0,0 +1,14 @@
+package loan
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/loans")
+	group.POST("", h.CreateLoan)
+	group.GET("", h.GetLoans)
+	group.GET("/:id", h.GetLoanByID)
+	group.PUT("/:id", h.UpdateLoan)
+	group.DELETE("/:id", h.DeleteLoan)
+	group.POST("/:id/installments/:number/pay", h.PayInstallment)
+	group.DELETE("/:id/installments/:number/pay", h.UnpayInstallment)
+}
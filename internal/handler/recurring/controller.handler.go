@@ -0,0 +1,297 @@
+package recurring
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	recurringService "pannypal/internal/service/recurring"
+	"pannypal/internal/service/recurring/dto"
+)
+
+type Handler struct {
+	ctx              context.Context
+	rabbitmq         *rabbitmq.ConnectionManager
+	recurringService recurringService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateRecurringRule(c *gin.Context)
+	GetRecurringRules(c *gin.Context)
+	GetRecurringRuleByID(c *gin.Context)
+	UpdateRecurringRule(c *gin.Context)
+	DeleteRecurringRule(c *gin.Context)
+	DetectSubscriptions(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, recurringService recurringService.IService) IHandler {
+	return &Handler{
+		ctx:              ctx,
+		rabbitmq:         rabbitmq,
+		recurringService: recurringService,
+	}
+}
+
+// CreateRecurringRule godoc
+// @Summary Create recurring rule
+// @Description Create a transaction that repeats weekly, monthly or yearly. AUTO rules are saved by the worker, DRAFT rules are sent to WhatsApp for confirmation
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param rule body dto.CreateRecurringRuleRequest true "Recurring rule data"
+// @Success 201 {object} dto.RecurringRuleResponse "Recurring rule created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /recurring [post]
+func (h *Handler) CreateRecurringRule(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateRecurringRuleRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.recurringService.CreateRecurringRuleRequest(payload))
+}
+
+// GetRecurringRules godoc
+// @Summary Get recurring rules
+// @Description Get the user's recurring rules with the monthly recurring income and expense
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param is_active query bool false "Filter by active state"
+// @Param is_subscription query bool false "Only subscriptions"
+// @Success 200 {object} dto.RecurringRuleListResponse "Recurring rules retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /recurring [get]
+func (h *Handler) GetRecurringRules(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetRecurringRulesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.recurringService.GetRecurringRulesRequest(payload))
+}
+
+// GetRecurringRuleByID godoc
+// @Summary Get recurring rule by ID
+// @Description Get a single recurring rule
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.RecurringRuleResponse "Recurring rule retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /recurring/{id} [get]
+func (h *Handler) GetRecurringRuleByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid recurring rule ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.recurringService.GetRecurringRuleByIDRequest(uint(ruleID), phoneNumber))
+}
+
+// UpdateRecurringRule godoc
+// @Summary Update recurring rule
+// @Description Update, pause or resume a recurring rule. All fields are optional, a changed schedule starts from today
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Param phone_number query string true "User's phone number"
+// @Param rule body dto.UpdateRecurringRuleRequest true "Updated recurring rule data"
+// @Success 200 {object} dto.RecurringRuleResponse "Recurring rule updated successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /recurring/{id} [put]
+func (h *Handler) UpdateRecurringRule(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid recurring rule ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	var payload dto.UpdateRecurringRuleRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.recurringService.UpdateRecurringRuleRequest(uint(ruleID), payload, phoneNumber))
+}
+
+// DeleteRecurringRule godoc
+// @Summary Delete recurring rule
+// @Description Delete a recurring rule, transactions it already created are kept
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Recurring rule ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Recurring rule deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /recurring/{id} [delete]
+func (h *Handler) DeleteRecurringRule(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid recurring rule ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.recurringService.DeleteRecurringRuleRequest(uint(ruleID), phoneNumber))
+}
+
+// DetectSubscriptions godoc
+// @Summary Detect subscriptions
+// @Description Find expenses that repeat with the same description and amount, candidates for recurring rules
+// @Tags Recurring APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param months query int false "Months of history to scan (default 6)"
+// @Success 200 {object} dto.SubscriptionCandidateListResponse "Subscription candidates retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /recurring/subscriptions [get]
+func (h *Handler) DetectSubscriptions(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.DetectSubscriptionsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.recurringService.DetectSubscriptionsRequest(payload))
+}
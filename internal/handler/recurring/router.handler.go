@@ -0,0 +1,13 @@
+package recurring
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/recurring")
+	group.POST("", h.CreateRecurringRule)
+	group.GET("", h.GetRecurringRules)
+	group.GET("/subscriptions", h.DetectSubscriptions)
+	group.GET("/:id", h.GetRecurringRuleByID)
+	group.PUT("/:id", h.UpdateRecurringRule)
+	group.DELETE("/:id", h.DeleteRecurringRule)
+}
@@ -0,0 +1,230 @@
+package split
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	splitService "pannypal/internal/service/split"
+	"pannypal/internal/service/split/dto"
+)
+
+type Handler struct {
+	ctx          context.Context
+	rabbitmq     *rabbitmq.ConnectionManager
+	splitService splitService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	CreateSplit(c *gin.Context)
+	GetSplits(c *gin.Context)
+	GetSplitByID(c *gin.Context)
+	GetBalances(c *gin.Context)
+	Settle(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, splitService splitService.IService) IHandler {
+	return &Handler{
+		ctx:          ctx,
+		rabbitmq:     rabbitmq,
+		splitService: splitService,
+	}
+}
+
+// CreateSplit godoc
+// @Summary Split transaction
+// @Description Split an expense equally, by percentage or by exact amounts, participants' shares become debts to the payer
+// @Tags Split APIs
+// @Accept json
+// @Produce json
+// @Param split body dto.CreateSplitRequest true "Split data"
+// @Success 201 {object} dto.SplitResponse "Split created successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Router /splits [post]
+func (h *Handler) CreateSplit(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.CreateSplitRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.splitService.CreateSplitRequest(payload))
+}
+
+// GetSplits godoc
+// @Summary Get splits
+// @Description Get splits the user paid or has a share in
+// @Tags Split APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} dto.SplitListResponse "Splits retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /splits [get]
+func (h *Handler) GetSplits(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetSplitsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.splitService.GetSplitsRequest(payload))
+}
+
+// GetSplitByID godoc
+// @Summary Get split by ID
+// @Description Get a split with its shares
+// @Tags Split APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Split ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.SplitResponse "Split retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Forbidden"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /splits/{id} [get]
+func (h *Handler) GetSplitByID(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	splitID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid split ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.splitService.GetSplitByIDRequest(uint(splitID), phoneNumber))
+}
+
+// GetBalances godoc
+// @Summary Get debt balances
+// @Description Get the net balance against every user, positive when they owe you
+// @Tags Split APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.BalanceListResponse "Balances retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /splits/balances [get]
+func (h *Handler) GetBalances(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetBalancesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.splitService.GetBalancesRequest(payload))
+}
+
+// Settle godoc
+// @Summary Settle up
+// @Description Record a repayment between two users, without an amount the whole outstanding balance is settled
+// @Tags Split APIs
+// @Accept json
+// @Produce json
+// @Param settlement body dto.SettleRequest true "Settlement data"
+// @Success 201 {object} dto.SettleResponse "Debt settled successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Router /splits/settle [post]
+func (h *Handler) Settle(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.SettleRequest
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.splitService.SettleRequest(payload))
+}
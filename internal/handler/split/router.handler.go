@@ -0,0 +1,12 @@
+package split
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/splits")
+	group.POST("/", h.CreateSplit)
+	group.GET("/", h.GetSplits)
+	group.GET("/balances", h.GetBalances)
+	group.POST("/settle", h.Settle)
+	group.GET("/:id", h.GetSplitByID)
+}
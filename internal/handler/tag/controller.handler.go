@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	tagService "pannypal/internal/service/tag"
+	"pannypal/internal/service/tag/dto"
+)
+
+type Handler struct {
+	ctx        context.Context
+	rabbitmq   *rabbitmq.ConnectionManager
+	tagService tagService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	GetTags(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, tagService tagService.IService) IHandler {
+	return &Handler{
+		ctx:        ctx,
+		rabbitmq:   rabbitmq,
+		tagService: tagService,
+	}
+}
+
+// GetTags godoc
+// @Summary Get tags
+// @Description Get the user's tags with how many transactions carry each, most used first
+// @Tags Tag APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} []dto.TagResponse "Tags retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /tags [get]
+func (h *Handler) GetTags(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetTagsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.tagService.GetTagsRequest(payload))
+}
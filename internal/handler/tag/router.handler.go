@@ -0,0 +1,8 @@
+package tag
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/tags")
+	group.GET("", h.GetTags)
+}
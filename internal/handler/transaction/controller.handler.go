@@ -27,7 +27,18 @@ type IHandler interface {
 	GetTransactionByID(c *gin.Context)
 	UpdateTransaction(c *gin.Context)
 	DeleteTransaction(c *gin.Context)
+	GetTransactionHistory(c *gin.Context)
 	GetTransactionsSummary(c *gin.Context)
+	SearchTransactions(c *gin.Context)
+	BulkCategorize(c *gin.Context)
+	BulkTag(c *gin.Context)
+	BulkDelete(c *gin.Context)
+	BulkRestore(c *gin.Context)
+	ScanDuplicates(c *gin.Context)
+	MergeDuplicates(c *gin.Context)
+	UploadAttachment(c *gin.Context)
+	GetAttachments(c *gin.Context)
+	DeleteAttachment(c *gin.Context)
 }
 
 func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, transactionService transactionService.IService) IHandler {
@@ -81,8 +92,10 @@ func (h *Handler) CreateTransaction(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param phone_number query string false "User's phone number"
-// @Param type query string false "Filter by type (INCOME/EXPENSE)"
+// @Param type query string false "Filter by type (INCOME/EXPENSE/TRANSFER)"
 // @Param category_id query int false "Filter by category ID"
+// @Param account_id query int false "Filter by account ID, transfers match on either side"
+// @Param tags query []string false "Filter by tags, a transaction must carry all of them (tags=kantor,reimburse)"
 // @Param start_date query string false "Filter transactions from this date (format: 2006-01-02)"
 // @Param end_date query string false "Filter transactions until this date (format: 2006-01-02)"
 // @Param page query int false "Page number (default: 1)"
@@ -299,3 +312,476 @@ func (h *Handler) GetTransactionsSummary(c *gin.Context) {
 
 	send(h.transactionService.GetTransactionsSummaryRequest(payload))
 }
+
+// SearchTransactions godoc
+// @Summary Search transactions
+// @Description Search description, merchant, notes and tags with typo tolerance, best matches first.
+// @Description The query may hold #tags and amount conditions in the base currency: >100rb, <=50rb, =25rb or 50rb-100rb
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param q query string true "Search query, e.g. kopi >20rb #kantor"
+// @Param type query string false "Filter by type (INCOME/EXPENSE/TRANSFER)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} dto.TransactionSearchResponse "Transactions retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /transactions/search [get]
+func (h *Handler) SearchTransactions(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.SearchTransactionsRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.SearchTransactionsRequest(payload))
+}
+
+// BulkCategorize godoc
+// @Summary Bulk re-categorize transactions
+// @Description Move many transactions into one category, transfers are skipped
+// @Description Pick transactions with ids or a filter, dry_run reports the per-item result without changing anything
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkCategorizeRequest true "Selection and change"
+// @Success 200 {object} dto.BulkResponse "Bulk operation completed successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/bulk/categorize [post]
+func (h *Handler) BulkCategorize(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.BulkCategorizeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.BulkCategorizeRequest(payload))
+}
+
+// BulkTag godoc
+// @Summary Bulk re-tag transactions
+// @Description Add and remove tags on many transactions
+// @Description Pick transactions with ids or a filter, dry_run reports the per-item result without changing anything
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkTagRequest true "Selection and change"
+// @Success 200 {object} dto.BulkResponse "Bulk operation completed successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/bulk/tags [post]
+func (h *Handler) BulkTag(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.BulkTagRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.BulkTagRequest(payload))
+}
+
+// BulkDelete godoc
+// @Summary Bulk delete transactions
+// @Description Delete many transactions together with their splits
+// @Description Pick transactions with ids or a filter, dry_run reports the per-item result without changing anything
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkRequest true "Selection and change"
+// @Success 200 {object} dto.BulkResponse "Bulk operation completed successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/bulk/delete [post]
+func (h *Handler) BulkDelete(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.BulkRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.BulkDeleteRequest(payload))
+}
+
+// BulkRestore godoc
+// @Summary Bulk restore transactions
+// @Description Restore deleted transactions, the selection matches deleted ones. Splits removed with them are not restored
+// @Description Pick transactions with ids or a filter, dry_run reports the per-item result without changing anything
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkRequest true "Selection and change"
+// @Success 200 {object} dto.BulkResponse "Bulk operation completed successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/bulk/restore [post]
+func (h *Handler) BulkRestore(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.BulkRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.BulkRestoreRequest(payload))
+}
+
+// ScanDuplicates godoc
+// @Summary Find duplicate transactions
+// @Description Group transactions that are likely recorded more than once, scored on amount, date, merchant or description and account
+// @Description Each group suggests the most complete transaction to keep, merge the rest into it with /transactions/duplicates/merge
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param start_date query string false "Start date (YYYY-MM-DD), default 90 days before the end date"
+// @Param end_date query string false "End date (YYYY-MM-DD), default today"
+// @Param min_score query number false "Lowest score between 0 and 1 to report (default: 0.7)"
+// @Success 200 {object} dto.DuplicateScanResponse "Duplicate transactions retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /transactions/duplicates [get]
+func (h *Handler) ScanDuplicates(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.ScanDuplicatesRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.ScanDuplicatesRequest(payload))
+}
+
+// MergeDuplicates godoc
+// @Summary Merge duplicate transactions
+// @Description Fold duplicates into the transaction kept: their tags and attachments move over, missing merchant, account,
+// @Description category and notes are filled in and the duplicates go to the trash
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param request body dto.MergeDuplicatesRequest true "Transaction to keep and its duplicates"
+// @Success 200 {object} dto.MergeDuplicatesResponse "Transactions merged successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/duplicates/merge [post]
+func (h *Handler) MergeDuplicates(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.MergeDuplicatesRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.transactionService.MergeDuplicatesRequest(payload))
+}
+
+// UploadAttachment godoc
+// @Summary Upload transaction attachment
+// @Description Attach a receipt image or PDF (max 10 MB) to a transaction
+// @Tags Transaction APIs
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param phone_number query string false "User's phone number"
+// @Param file formData file true "Receipt image or PDF"
+// @Success 201 {object} types.Response "Attachment uploaded successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Failure 503 {object} types.Response "File storage is not configured"
+// @Router /transactions/{id}/attachments [post]
+func (h *Handler) UploadAttachment(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transaction ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "File is required",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read file",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+	defer file.Close()
+
+	send(h.transactionService.UploadAttachmentRequest(uint(transactionID), phoneNumber, types.UploadFile{
+		File:   file,
+		Header: header,
+	}))
+}
+
+// GetAttachments godoc
+// @Summary Get transaction attachments
+// @Description Get the attachments of a transaction with their download URLs
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param phone_number query string false "User's phone number"
+// @Success 200 {object} types.Response "Attachments retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/{id}/attachments [get]
+func (h *Handler) GetAttachments(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transaction ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.transactionService.GetAttachmentsRequest(uint(transactionID), phoneNumber))
+}
+
+// DeleteAttachment godoc
+// @Summary Delete transaction attachment
+// @Description Delete an attachment and its stored file
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param phone_number query string false "User's phone number"
+// @Success 200 {object} types.Response "Attachment deleted successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/{id}/attachments/{attachment_id} [delete]
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transaction ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid attachment ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.transactionService.DeleteAttachmentRequest(uint(transactionID), uint(attachmentID), phoneNumber))
+}
+
+// GetTransactionHistory godoc
+// @Summary Get transaction history
+// @Description Get every change made to a transaction, oldest first, with who made it and the fields that changed.
+// @Description Deleted transactions keep their history until they are purged from the trash.
+// @Tags Transaction APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} dto.TransactionHistoryResponse "Transaction history retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Not Found"
+// @Router /transactions/{id}/history [get]
+func (h *Handler) GetTransactionHistory(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transaction ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.transactionService.GetTransactionHistoryRequest(uint(transactionID), phoneNumber))
+}
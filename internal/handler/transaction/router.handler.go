@@ -9,5 +9,16 @@ func (h *Handler) NewRoutes(e *gin.RouterGroup) {
 	group.GET("/:id", h.GetTransactionByID)
 	group.PUT("/:id", h.UpdateTransaction)
 	group.DELETE("/:id", h.DeleteTransaction)
+	group.GET("/:id/history", h.GetTransactionHistory)
 	group.GET("/summary", h.GetTransactionsSummary)
+	group.GET("/search", h.SearchTransactions)
+	group.POST("/bulk/categorize", h.BulkCategorize)
+	group.POST("/bulk/tags", h.BulkTag)
+	group.POST("/bulk/delete", h.BulkDelete)
+	group.POST("/bulk/restore", h.BulkRestore)
+	group.GET("/duplicates", h.ScanDuplicates)
+	group.POST("/duplicates/merge", h.MergeDuplicates)
+	group.POST("/:id/attachments", h.UploadAttachment)
+	group.GET("/:id/attachments", h.GetAttachments)
+	group.DELETE("/:id/attachments/:attachment_id", h.DeleteAttachment)
 }
@@ -0,0 +1,235 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/rabbitmq"
+	"pannypal/internal/pkg/validation"
+	trashService "pannypal/internal/service/trash"
+	"pannypal/internal/service/trash/dto"
+)
+
+type Handler struct {
+	ctx          context.Context
+	rabbitmq     *rabbitmq.ConnectionManager
+	trashService trashService.IService
+}
+
+type IHandler interface {
+	NewRoutes(e *gin.RouterGroup)
+	GetTrashTransactions(c *gin.Context)
+	GetTrashBudgets(c *gin.Context)
+	GetTrashCategories(c *gin.Context)
+	RestoreTransaction(c *gin.Context)
+	RestoreBudget(c *gin.Context)
+	RestoreCategory(c *gin.Context)
+}
+
+func NewHandler(ctx context.Context, rabbitmq *rabbitmq.ConnectionManager, trashService trashService.IService) IHandler {
+	return &Handler{
+		ctx:          ctx,
+		rabbitmq:     rabbitmq,
+		trashService: trashService,
+	}
+}
+
+// GetTrashTransactions godoc
+// @Summary Get deleted transactions
+// @Description Get the user's deleted transactions, most recently deleted first, with the time each is removed for good
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} dto.TrashTransactionListResponse "Deleted transactions retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /trash/transactions [get]
+func (h *Handler) GetTrashTransactions(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	var payload dto.GetTrashRequest
+
+	if err := c.ShouldBindQuery(&payload); err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	if err := validation.Validate(payload); err != nil {
+		send(helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    err.Error(),
+			Error:   err,
+		}))
+		return
+	}
+
+	send(h.trashService.GetTrashTransactionsRequest(payload))
+}
+
+// GetTrashBudgets godoc
+// @Summary Get deleted budgets
+// @Description Get the user's deleted budgets, most recently deleted first
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} []dto.TrashBudgetResponse "Deleted budgets retrieved successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 404 {object} types.Response "User not found"
+// @Router /trash/budgets [get]
+func (h *Handler) GetTrashBudgets(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	send(h.trashService.GetTrashBudgetsRequest(phoneNumber))
+}
+
+// GetTrashCategories godoc
+// @Summary Get deleted categories
+// @Description Get the user's deleted categories, most recently deleted first, or the template's when phone_number is left out
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param phone_number query string false "User's phone number"
+// @Success 200 {object} []dto.TrashCategoryResponse "Deleted categories retrieved successfully"
+// @Failure 404 {object} types.Response "User not found"
+// @Failure 500 {object} types.Response "Internal Server Error"
+// @Router /trash/categories [get]
+func (h *Handler) GetTrashCategories(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	send(h.trashService.GetTrashCategoriesRequest(c.Query("phone_number")))
+}
+
+// RestoreTransaction godoc
+// @Summary Restore deleted transaction
+// @Description Bring a deleted transaction back, it comes back without a category when its category is still deleted
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Transaction restored successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Transaction not found in trash"
+// @Router /trash/transactions/{id}/restore [post]
+func (h *Handler) RestoreTransaction(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid transaction ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.trashService.RestoreTransactionRequest(uint(transactionID), phoneNumber))
+}
+
+// RestoreBudget godoc
+// @Summary Restore deleted budget
+// @Description Bring a deleted budget back, its category has to be restored first when it is deleted too
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Param phone_number query string true "User's phone number"
+// @Success 200 {object} types.Response "Budget restored successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Budget not found in trash"
+// @Failure 409 {object} types.Response "Category is deleted"
+// @Router /trash/budgets/{id}/restore [post]
+func (h *Handler) RestoreBudget(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	phoneNumber := c.Query("phone_number")
+
+	if phoneNumber == "" {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	budgetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid budget ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.trashService.RestoreBudgetRequest(uint(budgetID), phoneNumber))
+}
+
+// RestoreCategory godoc
+// @Summary Restore deleted category
+// @Description Bring a deleted category back together with the budgets and transactions deleted with it,
+// @Description a subcategory needs its parent restored first
+// @Tags Trash APIs
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param phone_number query string false "User's phone number, left out for template categories"
+// @Success 200 {object} dto.RestoreCategoryResponse "Category restored successfully"
+// @Failure 400 {object} types.Response "Bad Request"
+// @Failure 403 {object} types.Response "Access denied"
+// @Failure 404 {object} types.Response "Category not found in trash"
+// @Failure 409 {object} types.Response "Parent category is deleted"
+// @Router /trash/categories/{id}/restore [post]
+func (h *Handler) RestoreCategory(c *gin.Context) {
+	send := c.MustGet("send").(func(r *types.Response))
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		send(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid category ID",
+			Data:    nil,
+			Error:   err,
+		})
+		return
+	}
+
+	send(h.trashService.RestoreCategoryRequest(uint(categoryID), c.Query("phone_number")))
+}
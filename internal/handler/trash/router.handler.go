@@ -0,0 +1,13 @@
+package trash
+
+import "github.com/gin-gonic/gin"
+
+func (h *Handler) NewRoutes(e *gin.RouterGroup) {
+	group := e.Group("/trash")
+	group.GET("/transactions", h.GetTrashTransactions)
+	group.GET("/budgets", h.GetTrashBudgets)
+	group.GET("/categories", h.GetTrashCategories)
+	group.POST("/transactions/:id/restore", h.RestoreTransaction)
+	group.POST("/budgets/:id/restore", h.RestoreBudget)
+	group.POST("/categories/:id/restore", h.RestoreCategory)
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+
+	"gorm.io/gorm"
+)
+
+// SeedUserCategories copies the category template to a user, subcategories stay under the copy of
+// their parent. It returns the ID of each copy by the ID of the template category it came from.
+func SeedUserCategories(tx *gorm.DB, userID uint) (map[uint]uint, error) {
+	var templates []models.Category
+	if err := tx.Where("user_id IS NULL").Order("parent_id NULLS FIRST, id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	copies := make(map[uint]uint, len(templates))
+	for _, template := range templates {
+		category := models.Category{
+			UserID: &userID,
+			Name:   template.Name,
+			Kind:   template.Kind,
+			Icon:   template.Icon,
+			Color:  template.Color,
+		}
+		// A subcategory whose parent was deleted from the template becomes a top-level category
+		if template.ParentID != nil {
+			if parentID, ok := copies[*template.ParentID]; ok {
+				category.ParentID = &parentID
+			}
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return nil, err
+		}
+		copies[template.ID] = category.ID
+	}
+	return copies, nil
+}
+
+// migrateUserCategories gives every user their own categories. Categories used to be one list shared
+// by all users, that list becomes the template and each user's transactions, budgets and recurring
+// rules move to the user's copies. Users who already have categories are left alone.
+func (db *Database) migrateUserCategories() error {
+	return db.Transaction(context.Background(), func(tx *Database) error {
+		var templates, userCategories int64
+		if err := tx.Model(&models.Category{}).Where("user_id IS NULL").Count(&templates).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Category{}).Where("user_id IS NOT NULL").Count(&userCategories).Error; err != nil {
+			return err
+		}
+
+		if templates == 0 {
+			if err := seedCategoryTemplate(tx.DB, nil, models.DefaultCategoryTemplate); err != nil {
+				return err
+			}
+		} else if userCategories == 0 {
+			// Shared categories came without a kind, read it from the transactions they hold
+			err := tx.Exec(`UPDATE categories c SET kind = CASE
+					WHEN u.income AND u.expense THEN 'BOTH' WHEN u.income THEN 'INCOME' ELSE 'EXPENSE' END
+				FROM (SELECT category_id, bool_or(type = 'INCOME') AS income, bool_or(type = 'EXPENSE') AS expense
+					FROM transactions WHERE category_id IS NOT NULL GROUP BY category_id) u
+				WHERE u.category_id = c.id AND c.user_id IS NULL`).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec(`UPDATE categories SET kind = 'BOTH' WHERE user_id IS NULL AND lower(name) IN ('lainnya', 'other')`).Error; err != nil {
+				return err
+			}
+		}
+
+		var userIDs []uint
+		if err := tx.Model(&models.User{}).
+			Where("NOT EXISTS (SELECT 1 FROM categories c WHERE c.user_id = users.id)").
+			Pluck("id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			copies, err := SeedUserCategories(tx.DB, userID)
+			if err != nil {
+				return err
+			}
+			// Deleted rows move too, so they come back from the trash in the user's own category
+			for templateID, categoryID := range copies {
+				for _, table := range []string{"transactions", "budgets", "recurring_rules"} {
+					if err := tx.Exec(`UPDATE `+table+` SET category_id = ? WHERE user_id = ? AND category_id = ?`,
+						categoryID, userID, templateID).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func seedCategoryTemplate(tx *gorm.DB, parentID *uint, templates []models.CategoryTemplate) error {
+	for _, template := range templates {
+		category := models.Category{
+			ParentID: parentID,
+			Name:     template.Name,
+			Kind:     template.Kind,
+			Icon:     template.Icon,
+			Color:    template.Color,
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		if err := seedCategoryTemplate(tx, &category.ID, template.Subcategories); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"pannypal/internal/common/models"
 	"pannypal/internal/pkg/logger"
@@ -22,13 +23,37 @@ func (db *Database) RunMigrations() error {
 		// Base tables first
 		&models.User{},
 		&models.Category{},
+		&models.Ledger{},
+		&models.LedgerMember{},
+		&models.Account{},
+		&models.RecurringRule{},
+		&models.ExchangeRate{},
+		&models.Tag{},
 
 		// Then transaction table
 		&models.Transaction{},
+		&models.Attachment{},
 		&models.Budget{},
+		&models.BudgetTemplate{},
+		&models.BudgetAlert{},
+		&models.AlertSetting{},
+		&models.EnvelopeAllocation{},
+		&models.SavingsGoal{},
+		&models.GoalContribution{},
+		&models.Loan{},
+		&models.LoanInstallment{},
+		&models.Asset{},
+		&models.NetWorthSnapshot{},
+		&models.Bill{},
+		&models.BillPayment{},
+		&models.AccountReconciliation{},
+		&models.Split{},
+		&models.SplitShare{},
+		&models.Debt{},
 		// Then ticketing related tables
 		&models.LogWaha{},
 		&models.LogPrompt{},
+		&models.TransactionRevision{},
 		&models.AccountBot{},
 		&models.MessageToReply{},
 		// Chatbot tables
@@ -43,6 +68,26 @@ func (db *Database) RunMigrations() error {
 		}
 	}
 
+	if err := db.createSearchIndexes(); err != nil {
+		return fmt.Errorf("failed to create search indexes: %w", err)
+	}
+
+	if err := db.migrateTransactionAudits(); err != nil {
+		return fmt.Errorf("failed to migrate transaction audits: %w", err)
+	}
+
+	if err := db.migrateUserCategories(); err != nil {
+		return fmt.Errorf("failed to migrate user categories: %w", err)
+	}
+
+	if err := db.migrateBudgetPeriods(); err != nil {
+		return fmt.Errorf("failed to migrate budget periods: %w", err)
+	}
+
+	if err := db.createBudgetIndexes(); err != nil {
+		return fmt.Errorf("failed to create budget indexes: %w", err)
+	}
+
 	// Create indexes after all tables are created
 	// if err := db.createIndexes(); err != nil {
 	// 	return fmt.Errorf("failed to create indexes: %w", err)
@@ -62,6 +107,53 @@ func (db *Database) createExtensions() error {
 	return db.Exec(query).Error
 }
 
+// TransactionSearchDocument is the text transaction search matches on, the full-text index is built
+// on this exact expression so queries must use it verbatim. The "simple" configuration does not stem,
+// there is no Indonesian one, typos and affixes are left to the trigram indexes.
+const TransactionSearchDocument = `to_tsvector('simple', coalesce(transactions.description, '') || ' ' || coalesce(transactions.merchant, '') || ' ' || coalesce(transactions.notes, ''))`
+
+// createSearchIndexes adds the full-text and trigram indexes used by transaction search
+func (db *Database) createSearchIndexes() error {
+	queries := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm;`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_search ON transactions USING GIN ((` + TransactionSearchDocument + `));`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops);`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_merchant_trgm ON transactions USING GIN (merchant gin_trgm_ops);`,
+		`CREATE INDEX IF NOT EXISTS idx_transactions_notes_trgm ON transactions USING GIN (notes gin_trgm_ops);`,
+		`CREATE INDEX IF NOT EXISTS idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops);`,
+	}
+
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			logger.Error.Printf("Error creating search index: %s, Error: %v", query, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateTransactionAudits moves the chat audits that came before transaction revisions into the
+// revision log and drops their table, it does nothing once the table is gone
+func (db *Database) migrateTransactionAudits() error {
+	if !db.Migrator().HasTable("transaction_audits") {
+		return nil
+	}
+
+	return db.Transaction(context.Background(), func(tx *Database) error {
+		err := tx.Exec(`INSERT INTO transaction_revisions
+			(created_at, transaction_id, user_id, action, actor_type, actor_id, message_id, source, before, after)
+			SELECT created_at, transaction_id, user_id,
+				CASE WHEN action = 'UNDO' THEN 'DELETE' WHEN before IS NULL THEN 'CREATE' ELSE 'UPDATE' END,
+				'BOT', user_id, message_id, COALESCE(NULLIF(source, ''), 'WHATSAPP'), before, after
+			FROM transaction_audits WHERE deleted_at IS NULL ORDER BY id`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`DROP TABLE transaction_audits`).Error
+	})
+}
+
 func (db *Database) createIndexes() error {
 	indexes := []string{
 		// Customer indexes
@@ -157,3 +249,36 @@ func (db *Database) createTriggers() error {
 
 	return nil
 }
+
+// migrateBudgetPeriods gives the budgets made before budget periods the calendar month they were set for
+func (db *Database) migrateBudgetPeriods() error {
+	return db.Exec(`UPDATE budgets SET
+			period_start = make_date(year, month, 1),
+			period_end = (make_date(year, month, 1) + interval '1 month' - interval '1 day')::date
+		WHERE period_start IS NULL`).Error
+}
+
+// createBudgetIndexes allows one budget per category and period, personal budgets per user and group
+// budgets per ledger. Duplicates made before the indexes existed go to the trash, the latest one stays.
+func (db *Database) createBudgetIndexes() error {
+	queries := []string{
+		`UPDATE budgets b SET deleted_at = now()
+			WHERE b.deleted_at IS NULL AND EXISTS (SELECT 1 FROM budgets d
+				WHERE d.deleted_at IS NULL AND d.id > b.id
+					AND d.category_id = b.category_id AND d.period = b.period AND d.period_start = b.period_start
+					AND ((d.ledger_id IS NULL AND b.ledger_id IS NULL AND d.user_id = b.user_id) OR d.ledger_id = b.ledger_id))`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_period ON budgets (user_id, category_id, period, period_start)
+			WHERE deleted_at IS NULL AND ledger_id IS NULL;`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_ledger_period ON budgets (ledger_id, category_id, period, period_start)
+			WHERE deleted_at IS NULL AND ledger_id IS NOT NULL;`,
+	}
+
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			logger.Error.Printf("Error creating budget index: %s, Error: %v", query, err)
+			return err
+		}
+	}
+
+	return nil
+}
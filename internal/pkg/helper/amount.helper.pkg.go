@@ -0,0 +1,97 @@
+package helper
+
+import (
+	"fmt"
+	types "pannypal/internal/common/type"
+	"strings"
+)
+
+var amountSuffixes = []struct {
+	suffix string
+	places int // Powers of ten the suffix multiplies by
+}{
+	{"ribu", 3},
+	{"rb", 3},
+	{"k", 3},
+	{"juta", 6},
+	{"jt", 6},
+}
+
+// ParseAmount parses chat style amounts like "300rb", "1,5jt", "50k", "Rp 100.000"
+func ParseAmount(payload string) (types.Money, error) {
+	value := strings.ToLower(strings.TrimSpace(payload))
+	value = strings.TrimPrefix(value, "rp.")
+	value = strings.TrimPrefix(value, "rp")
+	value = strings.TrimSpace(value)
+
+	places := 0
+	for _, s := range amountSuffixes {
+		if strings.HasSuffix(value, s.suffix) {
+			places = s.places
+			value = strings.TrimSpace(strings.TrimSuffix(value, s.suffix))
+			break
+		}
+	}
+
+	if places > 0 {
+		// With a unit both separators mean a decimal point, "1,5jt" or "1.5jt"
+		value = strings.ReplaceAll(value, ",", ".")
+	} else {
+		// Without a unit dots group thousands and a comma starts the decimals
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	if value == "" {
+		return 0, fmt.Errorf("invalid amount: %q", payload)
+	}
+
+	// The suffix moves the decimal point before parsing, so "1,255jt" is 1.255.000 and not the
+	// rounded 1,26 times a million
+	result, err := types.ParseMoney(shiftDecimal(value, places))
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("invalid amount: %q", payload)
+	}
+
+	return result, nil
+}
+
+// shiftDecimal moves the decimal point of a decimal string places digits to the right, malformed
+// values are returned as they are for the parser to reject
+func shiftDecimal(value string, places int) string {
+	if places == 0 || strings.Count(value, ".") > 1 {
+		return value
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) < places {
+		fraction += strings.Repeat("0", places-len(fraction))
+	}
+	whole += fraction[:places]
+	if fraction = fraction[places:]; fraction != "" {
+		return whole + "." + fraction
+	}
+	return whole
+}
+
+// FormatMoney formats an amount in its currency, "Rp. 1.250.000" for Rupiah and
+// "USD 12,50" for other currencies
+func FormatMoney(amount types.Money, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == "IDR" {
+		// Rupiah has no cents in practice, round to the whole unit
+		return sign + "Rp. " + FormatCurrency(int((amount + types.MoneyScale/2).Major()))
+	}
+
+	text := FormatCurrency(int(amount.Major()))
+	if cents := amount.Minor() % types.MoneyScale; cents != 0 {
+		text += fmt.Sprintf(",%02d", cents)
+	}
+	return sign + currency + " " + text
+}
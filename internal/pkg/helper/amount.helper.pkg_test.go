@@ -0,0 +1,65 @@
+package helper
+
+import (
+	types "pannypal/internal/common/type"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    types.Money
+		wantErr bool
+	}{
+		{payload: "50000", want: 5000000},
+		{payload: "Rp 100.000", want: 10000000},
+		{payload: "rp.25.000", want: 2500000},
+		{payload: "12.500,75", want: 1250075},
+		{payload: "300rb", want: 30000000},
+		{payload: "50k", want: 5000000},
+		{payload: "2 ribu", want: 200000},
+		{payload: "1,5jt", want: 150000000},
+		{payload: "1.5jt", want: 150000000},
+		{payload: "1,255jt", want: 125500000},
+		{payload: "2 juta", want: 200000000},
+		{payload: "", wantErr: true},
+		{payload: "rb", wantErr: true},
+		{payload: "-50k", wantErr: true},
+		{payload: "1.2.3jt", wantErr: true},
+		{payload: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.payload)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q) error = %v, wantErr %v", tt.payload, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, want %d", tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestShiftDecimal(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{value: "12", places: 0, want: "12"},
+		{value: "1.5", places: 0, want: "1.5"},
+		{value: "300", places: 3, want: "300000"},
+		{value: "1.5", places: 6, want: "1500000"},
+		{value: "1.255", places: 6, want: "1255000"},
+		{value: "1.2345", places: 3, want: "1234.5"},
+		{value: ".5", places: 3, want: "500"},
+		{value: "1.2.3", places: 3, want: "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		if got := shiftDecimal(tt.value, tt.places); got != tt.want {
+			t.Errorf("shiftDecimal(%q, %d) = %q, want %q", tt.value, tt.places, got, tt.want)
+		}
+	}
+}
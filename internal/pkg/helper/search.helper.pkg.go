@@ -0,0 +1,93 @@
+package helper
+
+import (
+	types "pannypal/internal/common/type"
+	"strings"
+)
+
+// SearchQuery is a free text search split into its parts
+type SearchQuery struct {
+	Text      string       // Words matched against description, merchant, notes and tags
+	Tags      []string     // "#kantor" words, transactions must carry all of them
+	MinAmount *types.Money // Inclusive
+	MaxAmount *types.Money // Inclusive
+}
+
+// ParseSearchQuery reads amount conditions and hashtags out of a search text:
+// ">100rb", ">=100rb", "<50rb", "<=50rb", "=25rb" and "100rb-200rb" filter the amount,
+// "#kantor" filters by tag and everything else is searched as text
+func ParseSearchQuery(query string) SearchQuery {
+	result := SearchQuery{}
+	words := []string{}
+
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(word, "#") {
+			if tag := NormalizeTag(word); tag != "" {
+				result.Tags = append(result.Tags, tag)
+				continue
+			}
+		}
+		if result.applyAmountCondition(word) {
+			continue
+		}
+		words = append(words, word)
+	}
+
+	result.Tags = NormalizeTags(result.Tags)
+	result.Text = strings.Join(words, " ")
+	return result
+}
+
+func (q *SearchQuery) applyAmountCondition(word string) bool {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(word, op) {
+			continue
+		}
+		amount, err := ParseAmount(strings.TrimPrefix(word, op))
+		if err != nil {
+			return false
+		}
+		// Amounts are exact minor units, "more than" starts one cent higher
+		switch op {
+		case ">=":
+			q.MinAmount = &amount
+		case ">":
+			low := amount + 1
+			q.MinAmount = &low
+		case "<=":
+			q.MaxAmount = &amount
+		case "<":
+			high := amount - 1
+			q.MaxAmount = &high
+		case "=":
+			high := amount
+			q.MinAmount = &amount
+			q.MaxAmount = &high
+		}
+		return true
+	}
+
+	// A range needs a digit on both sides so words like "liburan-bali" stay text
+	from, to, ok := strings.Cut(word, "-")
+	if !ok || !startsWithDigit(from) || !startsWithDigit(to) {
+		return false
+	}
+	low, err := ParseAmount(from)
+	if err != nil {
+		return false
+	}
+	high, err := ParseAmount(to)
+	if err != nil {
+		return false
+	}
+	if low > high {
+		low, high = high, low
+	}
+	q.MinAmount = &low
+	q.MaxAmount = &high
+	return true
+}
+
+func startsWithDigit(value string) bool {
+	return value != "" && value[0] >= '0' && value[0] <= '9'
+}
@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTagLength matches the tags.name column
+const maxTagLength = 50
+
+// NormalizeTag lowercases a tag and drops the leading #, anything besides letters, digits, "-" and "_"
+// is removed so "#Liburan-Bali!" and "liburan-bali" are the same tag
+func NormalizeTag(name string) string {
+	name = strings.TrimLeft(strings.TrimSpace(name), "#")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	tag := strings.Trim(b.String(), "-_")
+	if len([]rune(tag)) > maxTagLength {
+		tag = string([]rune(tag)[:maxTagLength])
+	}
+	return tag
+}
+
+// NormalizeTags normalizes a list of tags, dropping empty ones and duplicates while keeping the order
+func NormalizeTags(names []string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		// Query strings may carry several tags in one value, "kantor,reimburse"
+		for _, part := range strings.Split(name, ",") {
+			tag := NormalizeTag(part)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ExtractHashtags returns the hashtags in a text and the text without them,
+// words for which skip reports true (such as bot commands) stay in the text
+func ExtractHashtags(text string, skip func(word string) bool) ([]string, string) {
+	hashtags := []string{}
+	kept := []string{}
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, "#") && (skip == nil || !skip(word)) {
+			if tag := NormalizeTag(word); tag != "" {
+				hashtags = append(hashtags, tag)
+				continue
+			}
+		}
+		kept = append(kept, word)
+	}
+	return NormalizeTags(hashtags), strings.Join(kept, " ")
+}
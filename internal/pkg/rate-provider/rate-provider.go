@@ -0,0 +1,191 @@
+package rateprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderNone   = "none"
+	ProviderStatic = "static"
+	ProviderFile   = "file"
+	ProviderHTTP   = "http"
+)
+
+// IProvider supplies exchange rates, the price of one unit of base in every other currency
+type IProvider interface {
+	Name() string
+	GetRates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+type Config struct {
+	Provider string // static, file or http
+	FilePath string // JSON file for the file provider
+	URL      string // Endpoint for the http provider, "{base}" is replaced with the base currency
+}
+
+// RateTable is the JSON layout read by the file and http providers,
+// {"base": "USD", "rates": {"IDR": 16250, "SGD": 1.35}}
+type RateTable struct {
+	Base     string             `json:"base"`
+	BaseCode string             `json:"base_code"` // Alternative key used by some public rate APIs
+	Rates    map[string]float64 `json:"rates"`
+}
+
+// NewProvider builds the configured provider. The static table has to be asked for explicitly, without
+// a provider no rates are loaded and amounts in other currencies stay unconverted.
+func NewProvider(cfg Config) IProvider {
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderStatic:
+		return NewStaticProvider("USD", defaultRates)
+	case ProviderFile:
+		return NewFileProvider(cfg.FilePath)
+	case ProviderHTTP:
+		return NewHTTPProvider(cfg.URL)
+	default:
+		return &NoneProvider{}
+	}
+}
+
+// defaultRates keeps development and tests working without a rate source,
+// they are rough figures and should not be used in production
+var defaultRates = map[string]float64{
+	"USD": 1,
+	"IDR": 16000,
+	"SGD": 1.35,
+	"MYR": 4.7,
+	"EUR": 0.92,
+	"JPY": 150,
+	"AUD": 1.5,
+}
+
+// NoneProvider is used when no provider is configured, refreshing rates fails
+type NoneProvider struct{}
+
+func (p *NoneProvider) Name() string {
+	return ProviderNone
+}
+
+func (p *NoneProvider) GetRates(_ context.Context, _ string) (map[string]float64, error) {
+	return nil, fmt.Errorf("no exchange rate provider is configured")
+}
+
+type StaticProvider struct {
+	table RateTable
+}
+
+func NewStaticProvider(base string, rates map[string]float64) IProvider {
+	return &StaticProvider{table: RateTable{Base: base, Rates: rates}}
+}
+
+func (p *StaticProvider) Name() string {
+	return ProviderStatic
+}
+
+func (p *StaticProvider) GetRates(_ context.Context, base string) (map[string]float64, error) {
+	return p.table.rebase(base)
+}
+
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) IProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Name() string {
+	return ProviderFile
+}
+
+func (p *FileProvider) GetRates(_ context.Context, base string) (map[string]float64, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate file: %w", err)
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse rate file: %w", err)
+	}
+
+	return table.rebase(base)
+}
+
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPProvider(url string) IProvider {
+	return &HTTPProvider{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return ProviderHTTP
+}
+
+func (p *HTTPProvider) GetRates(ctx context.Context, base string) (map[string]float64, error) {
+	if p.url == "" {
+		return nil, fmt.Errorf("rate provider URL is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(p.url, "{base}", base), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch rates: status %d", resp.StatusCode)
+	}
+
+	var table RateTable
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return nil, fmt.Errorf("failed to parse rates: %w", err)
+	}
+
+	return table.rebase(base)
+}
+
+// rebase converts the table to rates against another base currency
+func (t RateTable) rebase(base string) (map[string]float64, error) {
+	tableBase := strings.ToUpper(t.Base)
+	if tableBase == "" {
+		tableBase = strings.ToUpper(t.BaseCode)
+	}
+	base = strings.ToUpper(base)
+
+	rates := make(map[string]float64, len(t.Rates)+1)
+	for currency, rate := range t.Rates {
+		rates[strings.ToUpper(currency)] = rate
+	}
+	rates[tableBase] = 1
+
+	baseRate, ok := rates[base]
+	if !ok || baseRate <= 0 {
+		return nil, fmt.Errorf("no rate for base currency %s", base)
+	}
+
+	result := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		if rate <= 0 {
+			continue
+		}
+		result[currency] = rate / baseRate
+	}
+	return result, nil
+}
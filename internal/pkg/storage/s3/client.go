@@ -34,6 +34,7 @@ type Is3 interface {
 	GetBucketName() string
 	UploadFile(fileName string, fileBytes []byte, contentType string) error
 	GetPresignedURL(key string) (string, error)
+	DeleteFile(key string) error
 }
 
 func newSession(cfg S3Config) (*session.Session, error) {
@@ -134,6 +135,21 @@ func (s *S3Client) UploadFile(fileName string, fileBytes []byte, contentType str
 
 	return nil
 }
+
+func (s *S3Client) DeleteFile(key string) error {
+	_, err := s.Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file from S3: %w", err)
+	}
+
+	// Drop the cached presigned URL so it is not handed out anymore
+	_ = s.redis.Del(fmt.Sprintf("s3:%s:%s", s.BucketName, key))
+	return nil
+}
+
 func (s *S3Client) GetPresignedURL(key string) (string, error) {
 	keyCahce := fmt.Sprintf("s3:%s:%s", s.BucketName, key)
 	cache, err := s.redis.Get(keyCahce)
@@ -0,0 +1,74 @@
+package s3aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalClient keeps files on the local filesystem with the same interface as S3, for development
+// without a bucket. Files are served by the API under BaseURL.
+type LocalClient struct {
+	BasePath string
+	BaseURL  string
+}
+
+func NewLocalClient(basePath, baseURL string) (*LocalClient, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalClient{
+		BasePath: basePath,
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+	}, nil
+}
+
+func (l *LocalClient) GetBucketName() string {
+	return l.BasePath
+}
+
+func (l *LocalClient) UploadFile(fileName string, fileBytes []byte, contentType string) error {
+	path, err := l.path(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	if err := os.WriteFile(path, fileBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// GetPresignedURL returns the URL the API serves the file at, local files do not expire
+func (l *LocalClient) GetPresignedURL(key string) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	return l.BaseURL + "/" + strings.TrimLeft(key, "/"), nil
+}
+
+func (l *LocalClient) DeleteFile(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// path resolves a key inside BasePath, keys escaping it with ".." are rejected
+func (l *LocalClient) path(key string) (string, error) {
+	path := filepath.Join(l.BasePath, filepath.FromSlash(key))
+	rel, err := filepath.Rel(l.BasePath, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid file key: %q", key)
+	}
+	return path, nil
+}
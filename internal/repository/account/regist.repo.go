@@ -0,0 +1,161 @@
+package account
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateAccount(model models.Account) (*models.Account, error)
+	UpdateAccount(model models.Account) (*models.Account, error)
+	GetAccountByID(id uint) (*models.Account, error)
+	GetAccountsByUserID(userID uint, includeArchived bool) ([]models.Account, error)
+	DeleteAccount(id uint) error
+	GetAccountBalances(userID uint, accountID *uint, asOf *time.Time) ([]AccountBalanceData, error)
+	CreateReconciliation(model models.AccountReconciliation) (*models.AccountReconciliation, error)
+	GetReconciliationsByAccountID(accountID uint, limit int) ([]models.AccountReconciliation, error)
+}
+
+// AccountBalanceData is an account with the transaction totals that make up its balance
+type AccountBalanceData struct {
+	AccountID      uint
+	Name           string
+	Type           models.AccountType
+	Currency       string
+	IsArchived     bool
+	OpeningBalance types.Money
+	TotalIncome    types.Money
+	TotalExpense   types.Money
+	TransfersIn    types.Money
+	TransfersOut   types.Money
+}
+
+// Balance is the opening balance moved by every income, expense and transfer
+func (d AccountBalanceData) Balance() types.Money {
+	return d.OpeningBalance + d.TotalIncome - d.TotalExpense + d.TransfersIn - d.TransfersOut
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateAccount(model models.Account) (*models.Account, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateAccount(model models.Account) (*models.Account, error) {
+	if err := r.db.WithContext(r.ctx).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetAccountByID(id uint) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.WithContext(r.ctx).Where("id = ?", id).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *Repository) GetAccountsByUserID(userID uint, includeArchived bool) ([]models.Account, error) {
+	var accounts []models.Account
+
+	query := r.db.WithContext(r.ctx).Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("is_archived = ?", false)
+	}
+
+	if err := query.Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *Repository) DeleteAccount(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.Account{}, id).Error
+}
+
+// GetAccountBalances totals the transactions of the user's accounts, optionally for one account
+// and only up to asOf
+func (r *Repository) GetAccountBalances(userID uint, accountID *uint, asOf *time.Time) ([]AccountBalanceData, error) {
+	var data []AccountBalanceData
+	// A transfer between accounts in different currencies lands converted
+	amountInAccount := exchangerate.ConvertSQL("t.amount", "t.currency", "a.currency", "t.transaction_date")
+
+	queryStr := `
+		SELECT 
+			a.id as account_id,
+			a.name,
+			a.type,
+			a.currency,
+			a.is_archived,
+			a.opening_balance,
+			COALESCE(SUM(CASE WHEN t.type = 'INCOME' AND t.account_id = a.id THEN ` + amountInAccount + ` ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN t.type = 'EXPENSE' AND t.account_id = a.id THEN ` + amountInAccount + ` ELSE 0 END), 0) as total_expense,
+			COALESCE(SUM(CASE WHEN t.type = 'TRANSFER' AND t.to_account_id = a.id THEN ` + amountInAccount + ` ELSE 0 END), 0) as transfers_in,
+			COALESCE(SUM(CASE WHEN t.type = 'TRANSFER' AND t.account_id = a.id THEN ` + amountInAccount + ` ELSE 0 END), 0) as transfers_out
+		FROM accounts a
+		LEFT JOIN transactions t ON (t.account_id = a.id OR t.to_account_id = a.id)
+			AND t.deleted_at IS NULL`
+
+	args := []interface{}{}
+	if asOf != nil {
+		queryStr += " AND t.transaction_date <= ?"
+		args = append(args, *asOf)
+	}
+
+	queryStr += `
+		WHERE a.deleted_at IS NULL AND a.user_id = ?`
+	args = append(args, userID)
+
+	if accountID != nil {
+		queryStr += " AND a.id = ?"
+		args = append(args, *accountID)
+	}
+
+	queryStr += `
+		GROUP BY a.id, a.name, a.type, a.currency, a.is_archived, a.opening_balance
+		ORDER BY a.name`
+
+	err := r.db.WithContext(r.ctx).Raw(queryStr, args...).Scan(&data).Error
+	return data, err
+}
+
+func (r *Repository) CreateReconciliation(model models.AccountReconciliation) (*models.AccountReconciliation, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetReconciliationsByAccountID(accountID uint, limit int) ([]models.AccountReconciliation, error) {
+	var reconciliations []models.AccountReconciliation
+	if err := r.db.WithContext(r.ctx).
+		Where("account_id = ?", accountID).
+		Order("as_of DESC").
+		Limit(limit).
+		Find(&reconciliations).Error; err != nil {
+		return nil, err
+	}
+	return reconciliations, nil
+}
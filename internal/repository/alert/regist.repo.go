@@ -0,0 +1,151 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+	"strconv"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revisionCursorKey keeps the last transaction revision the budget alerts were checked against
+const revisionCursorKey = "budget_alerts:revision_cursor"
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateAlertIfMissing(budgetID uint, threshold int) (bool, error)
+	GetPendingAlerts(limit int) ([]models.BudgetAlert, error)
+	GetPendingAlertsByUserID(userID uint) ([]models.BudgetAlert, error)
+	MarkAlertsSent(ids []uint, sentAt time.Time) error
+	DeleteBudgetAlerts(budgetID uint) error
+
+	GetSetting(userID uint) (*models.AlertSetting, error)
+	SaveSetting(model models.AlertSetting) (*models.AlertSetting, error)
+	GetDigestSettings(hour int, since time.Time) ([]models.AlertSetting, error)
+
+	GetRevisionCursor() (uint, bool, error)
+	SetRevisionCursor(id uint) error
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// CreateAlertIfMissing records that the budget crossed the threshold, false when it was recorded before
+func (r *Repository) CreateAlertIfMissing(budgetID uint, threshold int) (bool, error) {
+	result := r.db.WithContext(r.ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.BudgetAlert{BudgetID: budgetID, Threshold: threshold})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetPendingAlerts lists the unsent alerts, leaving out the personal budgets of users who get them in
+// the daily digest
+func (r *Repository) GetPendingAlerts(limit int) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.WithContext(r.ctx).
+		Preload("Budget.User").
+		Joins("JOIN budgets ON budgets.id = budget_alerts.budget_id").
+		Where("budget_alerts.sent_at IS NULL").
+		Where(`NOT EXISTS (SELECT 1 FROM alert_settings s
+			WHERE s.user_id = budgets.user_id AND s.daily_digest AND s.deleted_at IS NULL AND budgets.ledger_id IS NULL)`).
+		Order("budget_alerts.budget_id, budget_alerts.threshold").
+		Limit(limit).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// GetPendingAlertsByUserID lists the unsent alerts of the user's personal budgets
+func (r *Repository) GetPendingAlertsByUserID(userID uint) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.WithContext(r.ctx).
+		Joins("JOIN budgets ON budgets.id = budget_alerts.budget_id").
+		Where("budget_alerts.sent_at IS NULL AND budgets.user_id = ? AND budgets.ledger_id IS NULL", userID).
+		Order("budget_alerts.budget_id, budget_alerts.threshold").
+		Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *Repository) MarkAlertsSent(ids []uint, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(r.ctx).Model(&models.BudgetAlert{}).
+		Where("id IN ?", ids).
+		Update("sent_at", sentAt).Error
+}
+
+// DeleteBudgetAlerts forgets the thresholds the budget crossed so they are alerted again
+func (r *Repository) DeleteBudgetAlerts(budgetID uint) error {
+	return r.db.WithContext(r.ctx).Where("budget_id = ?", budgetID).Delete(&models.BudgetAlert{}).Error
+}
+
+// GetSetting returns the user's alert setting, an unsaved default when the user never set one
+func (r *Repository) GetSetting(userID uint) (*models.AlertSetting, error) {
+	var setting models.AlertSetting
+	err := r.db.WithContext(r.ctx).Where("user_id = ?", userID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.AlertSetting{UserID: userID, DigestHour: models.DefaultDigestHour}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *Repository) SaveSetting(model models.AlertSetting) (*models.AlertSetting, error) {
+	model.User = models.User{}
+	if err := r.db.WithContext(r.ctx).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// GetDigestSettings lists the daily digests due at the hour that were not sent since the given time
+func (r *Repository) GetDigestSettings(hour int, since time.Time) ([]models.AlertSetting, error) {
+	var settings []models.AlertSetting
+	err := r.db.WithContext(r.ctx).
+		Preload("User").
+		Where("daily_digest = ? AND digest_hour = ?", true, hour).
+		Where("last_digest_at IS NULL OR last_digest_at < ?", since).
+		Find(&settings).Error
+	return settings, err
+}
+
+// GetRevisionCursor returns the last checked revision, false when the alerts never ran
+func (r *Repository) GetRevisionCursor() (uint, bool, error) {
+	value, err := r.redis.Get(revisionCursorKey)
+	if err != nil {
+		return 0, false, err
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(id), true, nil
+}
+
+func (r *Repository) SetRevisionCursor(id uint) error {
+	return r.redis.Set(revisionCursorKey, id, 0)
+}
@@ -3,7 +3,10 @@ package analytics
 import (
 	"context"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"sort"
 	"time"
 
 	database "pannypal/internal/pkg/db"
@@ -16,50 +19,78 @@ type Repository struct {
 }
 
 type IRepository interface {
-	GetMonthlyAnalytics(userID *uint, year int) ([]MonthlyAnalyticsData, error)
-	GetYearlyAnalytics(userID *uint, startYear, endYear int) ([]YearlyAnalyticsData, error)
+	GetMonthlyAnalytics(userID, ledgerID *uint, year int) ([]MonthlyAnalyticsData, error)
+	GetYearlyAnalytics(userID, ledgerID *uint, startYear, endYear int) ([]YearlyAnalyticsData, error)
 	GetCategoryAnalytics(userID *uint, filters CategoryAnalyticsFilters) ([]CategoryAnalyticsData, error)
-	GetDashboardAnalytics(userID *uint, startDate, endDate time.Time) (*DashboardAnalyticsData, error)
+	GetTagAnalytics(userID *uint, filters CategoryAnalyticsFilters) ([]TagAnalyticsData, error)
+	GetDashboardAnalytics(userID, ledgerID *uint, startDate, endDate time.Time) (*DashboardAnalyticsData, error)
 }
 
 type MonthlyAnalyticsData struct {
 	Month            int
 	Year             int
-	TotalIncome      float64
-	TotalExpense     float64
+	TotalIncome      types.Money
+	TotalExpense     types.Money
 	TransactionCount int64
 }
 
 type YearlyAnalyticsData struct {
 	Year             int
-	TotalIncome      float64
-	TotalExpense     float64
+	TotalIncome      types.Money
+	TotalExpense     types.Money
 	TransactionCount int64
 }
 
+// CategoryAnalyticsData totals a category, the totals of a top-level category include its
+// subcategories, which are listed under it
 type CategoryAnalyticsData struct {
-	CategoryID   uint
-	CategoryName string
-	Type         models.TransactionType
-	TotalAmount  float64
-	Count        int64
+	CategoryID    uint
+	CategoryName  string
+	ParentID      *uint
+	Icon          string
+	Color         string
+	Type          models.TransactionType
+	TotalAmount   types.Money
+	Count         int64
+	Subcategories []CategoryAnalyticsData
+}
+
+type categoryAnalyticsRow struct {
+	CategoryAnalyticsData
+	ParentName  string
+	ParentIcon  string
+	ParentColor string
+}
+
+// TagAnalyticsData totals the transactions carrying a tag, a transaction with several tags counts
+// towards each of them
+type TagAnalyticsData struct {
+	TagID       uint
+	TagName     string
+	Type        models.TransactionType
+	TotalAmount types.Money
+	Count       int64
 }
 
 type CategoryAnalyticsFilters struct {
+	LedgerID  *uint
 	StartDate *time.Time
 	EndDate   *time.Time
 	Type      *models.TransactionType
 }
 
 type DashboardAnalyticsData struct {
-	CurrentIncome       float64
-	CurrentExpense      float64
-	PreviousIncome      float64
-	PreviousExpense     float64
-	TotalIncomeAllTime  float64
-	TotalExpenseAllTime float64
+	CurrentIncome       types.Money
+	CurrentExpense      types.Money
+	PreviousIncome      types.Money
+	PreviousExpense     types.Money
+	TotalIncomeAllTime  types.Money
+	TotalExpenseAllTime types.Money
 }
 
+// Totals are reported in the base currency of the transaction owner
+var amountInBase = exchangerate.AmountInBaseSQL("transactions")
+
 func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
 	return &Repository{
 		ctx:   ctx,
@@ -68,24 +99,27 @@ func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRe
 	}
 }
 
-func (r *Repository) GetMonthlyAnalytics(userID *uint, year int) ([]MonthlyAnalyticsData, error) {
+func (r *Repository) GetMonthlyAnalytics(userID, ledgerID *uint, year int) ([]MonthlyAnalyticsData, error) {
 	var data []MonthlyAnalyticsData
 
 	query := `
 		SELECT 
 			EXTRACT(MONTH FROM transaction_date) as month,
 			EXTRACT(YEAR FROM transaction_date) as year,
-			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN amount ELSE 0 END), 0) as total_expense,
+			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_expense,
 			COUNT(*) as transaction_count
 		FROM transactions`
 
 	args := []interface{}{}
+	query += ` WHERE deleted_at IS NULL AND type <> 'TRANSFER' AND`
 	if userID != nil {
-		query += ` WHERE user_id = ? AND`
+		query += ` user_id = ? AND`
 		args = append(args, *userID)
-	} else {
-		query += ` WHERE`
+	}
+	if ledgerID != nil {
+		query += ` ledger_id = ? AND`
+		args = append(args, *ledgerID)
 	}
 
 	query += ` EXTRACT(YEAR FROM transaction_date) = ?
@@ -103,25 +137,28 @@ func (r *Repository) GetMonthlyAnalytics(userID *uint, year int) ([]MonthlyAnaly
 	return data, err
 }
 
-func (r *Repository) GetYearlyAnalytics(userID *uint, startYear, endYear int) ([]YearlyAnalyticsData, error) {
+func (r *Repository) GetYearlyAnalytics(userID, ledgerID *uint, startYear, endYear int) ([]YearlyAnalyticsData, error) {
 	var data []YearlyAnalyticsData
 
 	query := `
 		SELECT 
 			EXTRACT(YEAR FROM transaction_date) as year,
-			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN amount ELSE 0 END), 0) as total_expense,
+			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_expense,
 			COUNT(*) as transaction_count
 		FROM transactions`
 
 	args := []interface{}{}
+	query += ` WHERE deleted_at IS NULL AND type <> 'TRANSFER'`
 	if userID != nil {
-		query += ` WHERE user_id = ?`
+		query += ` AND user_id = ?`
 		args = append(args, *userID)
-		query += ` AND EXTRACT(YEAR FROM transaction_date) BETWEEN ? AND ?`
-	} else {
-		query += ` WHERE EXTRACT(YEAR FROM transaction_date) BETWEEN ? AND ?`
 	}
+	if ledgerID != nil {
+		query += ` AND ledger_id = ?`
+		args = append(args, *ledgerID)
+	}
+	query += ` AND EXTRACT(YEAR FROM transaction_date) BETWEEN ? AND ?`
 	args = append(args, startYear, endYear)
 
 	query += ` GROUP BY EXTRACT(YEAR FROM transaction_date) ORDER BY year`
@@ -132,58 +169,156 @@ func (r *Repository) GetYearlyAnalytics(userID *uint, startYear, endYear int) ([
 }
 
 func (r *Repository) GetCategoryAnalytics(userID *uint, filters CategoryAnalyticsFilters) ([]CategoryAnalyticsData, error) {
-	var data []CategoryAnalyticsData
+	var rows []categoryAnalyticsRow
 
 	queryStr := `
 		SELECT 
 			t.category_id,
 			c.name as category_name,
+			c.parent_id,
+			c.icon,
+			c.color,
+			p.name as parent_name,
+			p.icon as parent_icon,
+			p.color as parent_color,
 			t.type,
-			COALESCE(SUM(t.amount), 0) as total_amount,
+			COALESCE(SUM(` + exchangerate.AmountInBaseSQL("t") + `), 0) as total_amount,
 			COUNT(*) as count
 		FROM transactions t
-		LEFT JOIN categories c ON t.category_id = c.id`
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN categories p ON c.parent_id = p.id
+		WHERE t.deleted_at IS NULL AND t.type <> 'TRANSFER'`
 
 	args := []interface{}{}
 	if userID != nil {
-		queryStr += ` WHERE t.user_id = ?`
+		queryStr += ` AND t.user_id = ?`
 		args = append(args, *userID)
 	}
 
-	hasWhere := userID != nil
+	if filters.LedgerID != nil {
+		queryStr += " AND t.ledger_id = ?"
+		args = append(args, *filters.LedgerID)
+	}
 
 	if filters.StartDate != nil {
-		if hasWhere {
-			queryStr += " AND t.transaction_date >= ?"
-		} else {
-			queryStr += " WHERE t.transaction_date >= ?"
-			hasWhere = true
-		}
+		queryStr += " AND t.transaction_date >= ?"
 		args = append(args, *filters.StartDate)
 	}
 
 	if filters.EndDate != nil {
-		if hasWhere {
-			queryStr += " AND t.transaction_date <= ?"
-		} else {
-			queryStr += " WHERE t.transaction_date <= ?"
-			hasWhere = true
-		}
+		queryStr += " AND t.transaction_date <= ?"
 		args = append(args, *filters.EndDate)
 	}
 
 	if filters.Type != nil {
-		if hasWhere {
-			queryStr += " AND t.type = ?"
-		} else {
-			queryStr += " WHERE t.type = ?"
-			hasWhere = true
+		queryStr += " AND t.type = ?"
+		args = append(args, *filters.Type)
+	}
+
+	queryStr += `
+		GROUP BY t.category_id, c.name, c.parent_id, c.icon, c.color, p.name, p.icon, p.color, t.type
+		ORDER BY total_amount DESC
+	`
+
+	if err := r.db.WithContext(r.ctx).Raw(queryStr, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rollUpCategories(rows), nil
+}
+
+// rollUpCategories adds each subcategory's totals to its parent and lists it under the parent,
+// a parent without transactions of its own still shows up through its subcategories
+func rollUpCategories(rows []categoryAnalyticsRow) []CategoryAnalyticsData {
+	type key struct {
+		id  uint
+		typ models.TransactionType
+	}
+
+	data := []CategoryAnalyticsData{}
+	index := map[key]int{}
+	top := func(k key, row CategoryAnalyticsData) *CategoryAnalyticsData {
+		i, ok := index[k]
+		if !ok {
+			i = len(data)
+			index[k] = i
+			data = append(data, row)
 		}
+		return &data[i]
+	}
+
+	for _, row := range rows {
+		if row.ParentID == nil {
+			parent := top(key{row.CategoryID, row.Type}, CategoryAnalyticsData{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+				Icon:         row.Icon,
+				Color:        row.Color,
+				Type:         row.Type,
+			})
+			parent.TotalAmount += row.TotalAmount
+			parent.Count += row.Count
+			continue
+		}
+
+		parent := top(key{*row.ParentID, row.Type}, CategoryAnalyticsData{
+			CategoryID:   *row.ParentID,
+			CategoryName: row.ParentName,
+			Icon:         row.ParentIcon,
+			Color:        row.ParentColor,
+			Type:         row.Type,
+		})
+		parent.TotalAmount += row.TotalAmount
+		parent.Count += row.Count
+		parent.Subcategories = append(parent.Subcategories, row.CategoryAnalyticsData)
+	}
+
+	sort.SliceStable(data, func(i, j int) bool { return data[i].TotalAmount > data[j].TotalAmount })
+	return data
+}
+
+func (r *Repository) GetTagAnalytics(userID *uint, filters CategoryAnalyticsFilters) ([]TagAnalyticsData, error) {
+	var data []TagAnalyticsData
+
+	queryStr := `
+		SELECT
+			tg.id as tag_id,
+			tg.name as tag_name,
+			t.type,
+			COALESCE(SUM(` + exchangerate.AmountInBaseSQL("t") + `), 0) as total_amount,
+			COUNT(*) as count
+		FROM transactions t
+		JOIN transaction_tags tt ON tt.transaction_id = t.id
+		JOIN tags tg ON tg.id = tt.tag_id AND tg.deleted_at IS NULL
+		WHERE t.deleted_at IS NULL AND t.type <> 'TRANSFER'`
+
+	args := []interface{}{}
+	if userID != nil {
+		queryStr += ` AND t.user_id = ?`
+		args = append(args, *userID)
+	}
+
+	if filters.LedgerID != nil {
+		queryStr += " AND t.ledger_id = ?"
+		args = append(args, *filters.LedgerID)
+	}
+
+	if filters.StartDate != nil {
+		queryStr += " AND t.transaction_date >= ?"
+		args = append(args, *filters.StartDate)
+	}
+
+	if filters.EndDate != nil {
+		queryStr += " AND t.transaction_date <= ?"
+		args = append(args, *filters.EndDate)
+	}
+
+	if filters.Type != nil {
+		queryStr += " AND t.type = ?"
 		args = append(args, *filters.Type)
 	}
 
 	queryStr += `
-		GROUP BY t.category_id, c.name, t.type
+		GROUP BY tg.id, tg.name, t.type
 		ORDER BY total_amount DESC
 	`
 
@@ -191,25 +326,29 @@ func (r *Repository) GetCategoryAnalytics(userID *uint, filters CategoryAnalytic
 	return data, err
 }
 
-func (r *Repository) GetDashboardAnalytics(userID *uint, startDate, endDate time.Time) (*DashboardAnalyticsData, error) {
+func (r *Repository) GetDashboardAnalytics(userID, ledgerID *uint, startDate, endDate time.Time) (*DashboardAnalyticsData, error) {
 	var result DashboardAnalyticsData
 
 	// Get current period data
 	currentQuery := `
 		SELECT 
-			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END), 0) as income,
-			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN amount ELSE 0 END), 0) as expense
+			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as income,
+			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as expense
 		FROM transactions
-		WHERE transaction_date >= ? AND transaction_date <= ?`
+		WHERE deleted_at IS NULL AND transaction_date >= ? AND transaction_date <= ?`
 
 	currentArgs := []interface{}{startDate, endDate}
 	if userID != nil {
 		currentQuery += " AND user_id = ?"
 		currentArgs = append(currentArgs, *userID)
 	}
+	if ledgerID != nil {
+		currentQuery += " AND ledger_id = ?"
+		currentArgs = append(currentArgs, *ledgerID)
+	}
 
 	row := r.db.WithContext(r.ctx).Raw(currentQuery, currentArgs...).Row()
-	var currentIncome, currentExpense float64
+	var currentIncome, currentExpense types.Money
 	if err := row.Scan(&currentIncome, &currentExpense); err != nil {
 		return nil, err
 	}
@@ -224,19 +363,23 @@ func (r *Repository) GetDashboardAnalytics(userID *uint, startDate, endDate time
 
 	previousQuery := `
 		SELECT 
-			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END), 0) as income,
-			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN amount ELSE 0 END), 0) as expense
+			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as income,
+			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as expense
 		FROM transactions
-		WHERE transaction_date >= ? AND transaction_date <= ?`
+		WHERE deleted_at IS NULL AND transaction_date >= ? AND transaction_date <= ?`
 
 	previousArgs := []interface{}{previousStartDate, previousEndDate}
 	if userID != nil {
 		previousQuery += " AND user_id = ?"
 		previousArgs = append(previousArgs, *userID)
 	}
+	if ledgerID != nil {
+		previousQuery += " AND ledger_id = ?"
+		previousArgs = append(previousArgs, *ledgerID)
+	}
 
 	row = r.db.WithContext(r.ctx).Raw(previousQuery, previousArgs...).Row()
-	var previousIncome, previousExpense float64
+	var previousIncome, previousExpense types.Money
 	if err := row.Scan(&previousIncome, &previousExpense); err != nil {
 		return nil, err
 	}
@@ -247,18 +390,23 @@ func (r *Repository) GetDashboardAnalytics(userID *uint, startDate, endDate time
 	// Get all time total
 	allTimeQuery := `
 		SELECT 
-			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END), 0) as income,
-			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN amount ELSE 0 END), 0) as expense
-		FROM transactions`
+			COALESCE(SUM(CASE WHEN type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as income,
+			COALESCE(SUM(CASE WHEN type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as expense
+		FROM transactions
+		WHERE deleted_at IS NULL`
 
 	allTimeArgs := []interface{}{}
 	if userID != nil {
-		allTimeQuery += " WHERE user_id = ?"
+		allTimeQuery += " AND user_id = ?"
 		allTimeArgs = append(allTimeArgs, *userID)
 	}
+	if ledgerID != nil {
+		allTimeQuery += " AND ledger_id = ?"
+		allTimeArgs = append(allTimeArgs, *ledgerID)
+	}
 
 	row = r.db.WithContext(r.ctx).Raw(allTimeQuery, allTimeArgs...).Row()
-	var totalIncome, totalExpense float64
+	var totalIncome, totalExpense types.Money
 	if err := row.Scan(&totalIncome, &totalExpense); err != nil {
 		return nil, err
 	}
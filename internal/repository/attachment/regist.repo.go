@@ -0,0 +1,58 @@
+package attachment
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+
+	database "pannypal/internal/pkg/db"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateAttachment(model models.Attachment) (*models.Attachment, error)
+	GetAttachmentByID(id uint) (*models.Attachment, error)
+	GetAttachmentsByTransactionID(transactionID uint) ([]models.Attachment, error)
+	DeleteAttachment(id uint) error
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateAttachment(model models.Attachment) (*models.Attachment, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetAttachmentByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.WithContext(r.ctx).Where("id = ?", id).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *Repository) GetAttachmentsByTransactionID(transactionID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(r.ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *Repository) DeleteAttachment(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.Attachment{}, id).Error
+}
@@ -0,0 +1,179 @@
+package bill
+
+import (
+	"context"
+	"database/sql"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateBill(model models.Bill) (*models.Bill, error)
+	UpdateBill(model models.Bill) (*models.Bill, error)
+	GetBillByID(id uint) (*models.Bill, error)
+	GetBillsByUserID(userID uint) ([]models.Bill, error)
+	GetActiveBills() ([]models.Bill, error)
+	DeleteBill(id uint) error
+
+	GetPayments(billID uint) ([]models.BillPayment, error)
+	GetPaymentByTransactionID(transactionID uint) (*models.BillPayment, error)
+	SavePayment(model models.BillPayment) (*models.BillPayment, error)
+	FindMatchingTransaction(bill models.Bill, after, until time.Time) (*models.Transaction, error)
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateBill(model models.Bill) (*models.Bill, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateBill(model models.Bill) (*models.Bill, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetBillByID(id uint) (*models.Bill, error) {
+	var bill models.Bill
+	if err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Where("id = ?", id).
+		First(&bill).Error; err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
+
+func (r *Repository) GetBillsByUserID(userID uint) ([]models.Bill, error) {
+	var bills []models.Bill
+	err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Order("is_active DESC, due_day, name").
+		Find(&bills).Error
+	return bills, err
+}
+
+// GetActiveBills returns the active bills of everyone with their owners, for the worker
+func (r *Repository) GetActiveBills() ([]models.Bill, error) {
+	var bills []models.Bill
+	err := r.db.WithContext(r.ctx).
+		Preload("User").
+		Where("is_active = ?", true).
+		Order("user_id, id").
+		Find(&bills).Error
+	return bills, err
+}
+
+// DeleteBill removes the bill with its payments, the paying transactions stay as plain expenses
+func (r *Repository) DeleteBill(id uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bill_id = ?", id).Delete(&models.BillPayment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Bill{}, id).Error
+	})
+}
+
+func (r *Repository) GetPayments(billID uint) ([]models.BillPayment, error) {
+	var payments []models.BillPayment
+	err := r.db.WithContext(r.ctx).
+		Where("bill_id = ?", billID).
+		Order("due_date ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *Repository) GetPaymentByTransactionID(transactionID uint) (*models.BillPayment, error) {
+	var payment models.BillPayment
+	if err := r.db.WithContext(r.ctx).
+		Where("transaction_id = ?", transactionID).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// SavePayment stores the state of a due date of the bill. A new one overwrites what was there for the
+// date, so two runs seeing the same due date keep one row.
+func (r *Repository) SavePayment(model models.BillPayment) (*models.BillPayment, error) {
+	if model.ID != 0 {
+		if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+			return nil, err
+		}
+		return &model, nil
+	}
+	err := r.db.WithContext(r.ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bill_id"}, {Name: "due_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"transaction_id", "amount", "paid_at", "reminded_at", "overdue_sent_at", "updated_at"}),
+	}).Create(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// ClearPayments sets the due dates paid by the given transactions back to unpaid, for when the payments
+// are deleted. transactionIDs is a list of IDs or a subquery selecting them.
+func ClearPayments(tx *gorm.DB, transactionIDs interface{}) error {
+	return tx.Model(&models.BillPayment{}).
+		Where("transaction_id IN (?)", transactionIDs).
+		Updates(map[string]interface{}{"transaction_id": nil, "paid_at": nil, "amount": 0}).Error
+}
+
+// FindMatchingTransaction returns the earliest expense dated after after and up to until that pays
+// the bill: it goes to the payee or falls in the bill's category, comes from the bill's account when
+// one is set, is within half of the expected amount either way and pays no other due date.
+func (r *Repository) FindMatchingTransaction(bill models.Bill, after, until time.Time) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(r.ctx).
+		Where("user_id = @user AND type = @type AND transaction_date > @after AND transaction_date <= @until",
+			sql.Named("user", bill.UserID),
+			sql.Named("type", models.TypeExpense),
+			sql.Named("after", after),
+			sql.Named("until", until),
+		).
+		Where("NOT EXISTS (SELECT 1 FROM bill_payments p WHERE p.transaction_id = transactions.id)").
+		Where(`((@payee <> '' AND (transactions.merchant ILIKE '%' || @payee || '%' OR transactions.description ILIKE '%' || @payee || '%'))
+			OR (CAST(@category AS bigint) IS NOT NULL AND transactions.category_id IN (SELECT c.id FROM categories c WHERE c.id = @category OR c.parent_id = @category)))`,
+			sql.Named("payee", bill.Payee),
+			sql.Named("category", bill.CategoryID),
+		).
+		Where("(CAST(@account AS bigint) IS NULL OR transactions.account_id = @account)", sql.Named("account", bill.AccountID)).
+		Where("(CAST(@expected AS numeric) = 0 OR "+exchangerate.ConvertSQL("transactions.amount", "transactions.currency", "CAST(@currency AS varchar)", "transactions.transaction_date")+
+			" BETWEEN CAST(@expected AS numeric) * 0.5 AND CAST(@expected AS numeric) * 1.5)",
+			sql.Named("expected", bill.ExpectedAmount),
+			sql.Named("currency", bill.Currency),
+		).
+		Order("transaction_date ASC, id ASC").
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
@@ -2,10 +2,18 @@ package budget
 
 import (
 	"context"
+	"database/sql"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"strings"
+	"time"
 
 	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
@@ -21,25 +29,53 @@ type IRepository interface {
 	GetBudgetByID(id uint) (*models.Budget, error)
 	GetBudgetsByUserID(userID *uint, filters BudgetFilters) ([]models.Budget, error)
 	GetBudgetStatus(userID *uint, filters BudgetStatusFilters) ([]BudgetStatusData, error)
+	GetBudgetStatusByID(id uint) (*BudgetStatusData, error)
+	GetBudgetForPeriod(model models.Budget) (*models.Budget, error)
+	CreateBudgetIfMissing(model models.Budget) (bool, error)
+	GetSpending(model models.Budget, from, to time.Time) (types.Money, error)
+
+	CreateTemplate(model models.BudgetTemplate) (*models.BudgetTemplate, error)
+	UpdateTemplate(model models.BudgetTemplate) (*models.BudgetTemplate, error)
+	DeleteTemplate(model models.BudgetTemplate) error
+	GetTemplateByID(id uint) (*models.BudgetTemplate, error)
+	GetTemplates(userID *uint, ledgerID *uint) ([]models.BudgetTemplate, error)
+	GetDueTemplates(now time.Time, limit int) ([]models.BudgetTemplate, error)
 }
 
+// LedgerID selects group budgets, personal budgets are returned when it is nil. Month and Year
+// are those the budget's period starts in.
 type BudgetFilters struct {
+	LedgerID   *uint
 	Month      *int
 	Year       *int
 	CategoryID *uint
+	Period     *models.BudgetPeriod
 }
 
+// LedgerID selects group budgets, personal budgets are returned when it is nil. Budgets whose period
+// overlaps From to To, both days included, are returned.
 type BudgetStatusFilters struct {
-	Month *int
-	Year  *int
+	LedgerID *uint
+	From     time.Time
+	To       time.Time
 }
 
+// BudgetStatusData is the spending in a budget's period, CarriedOver is what rolled over from the
+// previous periods and is negative when they were overspent
 type BudgetStatusData struct {
 	BudgetID     uint
+	UserID       uint
+	LedgerID     *uint
 	CategoryID   uint
 	CategoryName string
-	BudgetAmount float64
-	SpentAmount  float64
+	BudgetAmount types.Money
+	SpentAmount  types.Money
+	CarriedOver  types.Money
+	Period       models.BudgetPeriod
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	Rollover     bool
+	Alerts       *string
 	Month        int
 	Year         int
 }
@@ -87,6 +123,11 @@ func (r *Repository) GetBudgetsByUserID(userID *uint, filters BudgetFilters) ([]
 		query = query.Where("user_id = ?", *userID)
 	}
 
+	if filters.LedgerID != nil {
+		query = query.Where("ledger_id = ?", *filters.LedgerID)
+	} else {
+		query = query.Where("ledger_id IS NULL")
+	}
 	if filters.Month != nil {
 		query = query.Where("month = ?", *filters.Month)
 	}
@@ -96,6 +137,9 @@ func (r *Repository) GetBudgetsByUserID(userID *uint, filters BudgetFilters) ([]
 	if filters.CategoryID != nil {
 		query = query.Where("category_id = ?", *filters.CategoryID)
 	}
+	if filters.Period != nil {
+		query = query.Where("period = ?", *filters.Period)
+	}
 
 	if err := query.Preload("Category").Find(&budgets).Error; err != nil {
 		return nil, err
@@ -104,48 +148,252 @@ func (r *Repository) GetBudgetsByUserID(userID *uint, filters BudgetFilters) ([]
 }
 
 func (r *Repository) GetBudgetStatus(userID *uint, filters BudgetStatusFilters) ([]BudgetStatusData, error) {
-	var statusData []BudgetStatusData
-
-	whereClause := ""
-	var args []interface{}
+	// Conditions are written into the raw SQL, chained Where calls are
+	// ignored by gorm once Raw is used.
+	conditions := []string{"b.period_start <= ?", "b.period_end >= ?"}
+	args := []interface{}{filters.To, filters.From}
 
 	if userID != nil {
-		whereClause = "WHERE b.user_id = ?"
+		conditions = append(conditions, "b.user_id = ?")
 		args = append(args, *userID)
 	}
+	if filters.LedgerID != nil {
+		conditions = append(conditions, "b.ledger_id = ?")
+		args = append(args, *filters.LedgerID)
+	} else {
+		conditions = append(conditions, "b.ledger_id IS NULL")
+	}
+
+	statusData, err := r.budgetStatus(conditions, args, "b.period_start, c.name")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range statusData {
+		if !statusData[i].Rollover {
+			continue
+		}
+		if statusData[i].CarriedOver, err = r.carriedOver(statusData[i]); err != nil {
+			return nil, err
+		}
+	}
+	return statusData, nil
+}
+
+func (r *Repository) GetBudgetStatusByID(id uint) (*BudgetStatusData, error) {
+	statusData, err := r.budgetStatus([]string{"b.id = ?"}, []interface{}{id}, "b.id")
+	if err != nil {
+		return nil, err
+	}
+	if len(statusData) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	status := statusData[0]
+	if status.Rollover {
+		if status.CarriedOver, err = r.carriedOver(status); err != nil {
+			return nil, err
+		}
+	}
+	return &status, nil
+}
+
+// budgetSpendingSQL matches the transactions t that count towards budget b of category c. Group budgets
+// count the spending of every member, personal ones only the owner's. A budget covers its category's
+// subcategories, members have categories of their own so a group budget matches by name.
+const budgetSpendingSQL = `(
+	(b.ledger_id IS NULL AND b.user_id = t.user_id
+		AND t.category_id IN (SELECT sub.id FROM categories sub WHERE sub.id = b.category_id OR sub.parent_id = b.category_id))
+	OR (b.ledger_id IS NOT NULL AND b.ledger_id = t.ledger_id
+		AND t.category_id IN (SELECT sub.id FROM categories sub LEFT JOIN categories parent ON parent.id = sub.parent_id
+			WHERE lower(sub.name) = lower(c.name) OR lower(parent.name) = lower(c.name)))
+)`
+
+// budgetAmountSQL is the amount of transaction t in the currency of budget b, its owner's base currency.
+// A group budget counts the spending of members who may keep their books in other currencies.
+var budgetAmountSQL = exchangerate.AmountInUserBaseSQL("t", "b.user_id")
+
+// budgetStatus totals the spending of each budget over its period
+func (r *Repository) budgetStatus(conditions []string, args []interface{}, order string) ([]BudgetStatusData, error) {
+	var statusData []BudgetStatusData
 
 	query := r.db.WithContext(r.ctx).Raw(`
 		SELECT 
 			b.id as budget_id,
+			b.user_id,
+			b.ledger_id,
 			b.category_id,
 			c.name as category_name,
 			b.amount as budget_amount,
-			COALESCE(SUM(t.amount), 0) as spent_amount,
+			COALESCE(SUM(`+budgetAmountSQL+`), 0) as spent_amount,
+			b.period,
+			b.period_start,
+			b.period_end,
+			b.rollover,
+			b.alerts,
 			b.month,
 			b.year
 		FROM budgets b
 		LEFT JOIN categories c ON b.category_id = c.id
-		LEFT JOIN transactions t ON b.category_id = t.category_id 
-			AND b.user_id = t.user_id 
-			AND t.type = 'EXPENSE'
-			AND EXTRACT(MONTH FROM t.transaction_date) = b.month
-			AND EXTRACT(YEAR FROM t.transaction_date) = b.year
-		`+whereClause+`
-		GROUP BY b.id, b.category_id, c.name, b.amount, b.month, b.year
-	`, args...)
+		LEFT JOIN transactions t ON t.type = 'EXPENSE'
+			AND t.deleted_at IS NULL
+			AND t.transaction_date >= b.period_start
+			AND t.transaction_date < b.period_end + 1
+			AND `+budgetSpendingSQL+`
+		WHERE b.deleted_at IS NULL AND `+strings.Join(conditions, " AND ")+`
+		GROUP BY b.id, c.name
+		ORDER BY `+order, args...)
 
-	if filters.Month != nil {
-		query = query.Where("b.month = ?", *filters.Month)
+	if err := query.Scan(&statusData).Error; err != nil {
+		return nil, err
 	}
-	if filters.Year != nil {
-		query = query.Where("b.year = ?", *filters.Year)
+
+	return statusData, nil
+}
+
+// carriedOver walks back through the budget's earlier periods, the same category and period length
+// with no gap in between, adding up what each left over until one that does not roll over
+func (r *Repository) carriedOver(status BudgetStatusData) (types.Money, error) {
+	// A group budget carries over from the ledger's earlier budgets whoever set them
+	conditions := []string{"b.ledger_id IS NULL", "b.user_id = ?"}
+	args := []interface{}{status.UserID}
+	if status.LedgerID != nil {
+		conditions = []string{"b.ledger_id = ?"}
+		args = []interface{}{*status.LedgerID}
 	}
+	conditions = append(conditions, "b.category_id = ?", "b.period = ?", "b.period_end < ?")
+	args = append(args, status.CategoryID, status.Period, status.PeriodStart)
 
-	query = query.Group("b.id, b.category_id, c.name, b.amount, b.month, b.year")
+	previous, err := r.budgetStatus(conditions, args, "b.period_start DESC")
+	if err != nil {
+		return 0, err
+	}
 
-	if err := query.Scan(&statusData).Error; err != nil {
+	return carryOver(status.PeriodStart, previous), nil
+}
+
+// carryOver adds up the leftovers of the periods ending right before periodStart, previous is
+// ordered latest first
+func carryOver(periodStart time.Time, previous []BudgetStatusData) types.Money {
+	carried := types.Money(0)
+	expectedEnd := periodStart.AddDate(0, 0, -1)
+	for _, budget := range previous {
+		if !sameDay(budget.PeriodEnd, expectedEnd) {
+			break
+		}
+		carried += budget.BudgetAmount - budget.SpentAmount
+		if !budget.Rollover {
+			break
+		}
+		expectedEnd = budget.PeriodStart.AddDate(0, 0, -1)
+	}
+	return carried
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// GetBudgetForPeriod finds another budget in the same category and period, personal budgets are
+// matched by owner and group budgets by ledger
+func (r *Repository) GetBudgetForPeriod(model models.Budget) (*models.Budget, error) {
+	var budget models.Budget
+	query := r.db.WithContext(r.ctx).
+		Where("id <> ? AND category_id = ? AND period = ? AND period_start = ?",
+			model.ID, model.CategoryID, model.Period, model.PeriodStart)
+	if model.LedgerID != nil {
+		query = query.Where("ledger_id = ?", *model.LedgerID)
+	} else {
+		query = query.Where("ledger_id IS NULL AND user_id = ?", model.UserID)
+	}
+	if err := query.First(&budget).Error; err != nil {
 		return nil, err
 	}
+	return &budget, nil
+}
 
-	return statusData, nil
+// CreateBudgetIfMissing creates the budget unless the category already has one for the period, it
+// reports whether it was created
+func (r *Repository) CreateBudgetIfMissing(model models.Budget) (bool, error) {
+	result := r.db.WithContext(r.ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetSpending totals what would have counted towards the budget from from up to, not including, to
+func (r *Repository) GetSpending(model models.Budget, from, to time.Time) (types.Money, error) {
+	var spent types.Money
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT COALESCE(SUM(`+budgetAmountSQL+`), 0)
+		FROM (SELECT CAST(@user AS bigint) AS user_id, CAST(@ledger AS bigint) AS ledger_id, CAST(@category AS bigint) AS category_id) b
+		LEFT JOIN categories c ON b.category_id = c.id
+		JOIN transactions t ON t.type = 'EXPENSE'
+			AND t.deleted_at IS NULL
+			AND t.transaction_date >= @from
+			AND t.transaction_date < @to
+			AND `+budgetSpendingSQL,
+		sql.Named("user", model.UserID),
+		sql.Named("ledger", model.LedgerID),
+		sql.Named("category", model.CategoryID),
+		sql.Named("from", from),
+		sql.Named("to", to),
+	).Scan(&spent).Error
+	return spent, err
+}
+
+func (r *Repository) CreateTemplate(model models.BudgetTemplate) (*models.BudgetTemplate, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateTemplate(model models.BudgetTemplate) (*models.BudgetTemplate, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) DeleteTemplate(model models.BudgetTemplate) error {
+	return r.db.WithContext(r.ctx).Delete(&model).Error
+}
+
+func (r *Repository) GetTemplateByID(id uint) (*models.BudgetTemplate, error) {
+	var template models.BudgetTemplate
+	if err := r.db.WithContext(r.ctx).Preload("Category").Where("id = ?", id).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetTemplates returns the user's personal templates, or every template of the ledger when ledgerID is set
+func (r *Repository) GetTemplates(userID *uint, ledgerID *uint) ([]models.BudgetTemplate, error) {
+	var templates []models.BudgetTemplate
+	query := r.db.WithContext(r.ctx)
+	if ledgerID != nil {
+		query = query.Where("ledger_id = ?", *ledgerID)
+	} else {
+		query = query.Where("ledger_id IS NULL")
+		if userID != nil {
+			query = query.Where("user_id = ?", *userID)
+		}
+	}
+	if err := query.Preload("Category").Order("id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetDueTemplates returns active templates whose next period has started
+func (r *Repository) GetDueTemplates(now time.Time, limit int) ([]models.BudgetTemplate, error) {
+	var templates []models.BudgetTemplate
+	err := r.db.WithContext(r.ctx).
+		Where("is_active = ? AND next_period_start <= ?", true, now).
+		Order("next_period_start").
+		Limit(limit).
+		Find(&templates).Error
+	return templates, err
 }
@@ -0,0 +1,60 @@
+package budget
+
+import (
+	types "pannypal/internal/common/type"
+	"testing"
+	"time"
+)
+
+func TestCarryOver(t *testing.T) {
+	month := func(m time.Month, budget, spent types.Money, rollover bool) BudgetStatusData {
+		start := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+		return BudgetStatusData{
+			BudgetAmount: budget,
+			SpentAmount:  spent,
+			PeriodStart:  start,
+			PeriodEnd:    start.AddDate(0, 1, -1),
+			Rollover:     rollover,
+		}
+	}
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		previous []BudgetStatusData
+		want     types.Money
+	}{
+		{name: "no earlier budget", want: 0},
+		{
+			name:     "leftover of the last period",
+			previous: []BudgetStatusData{month(3, 1000, 400, false), month(2, 1000, 0, true)},
+			want:     600,
+		},
+		{
+			name:     "chain of rollovers",
+			previous: []BudgetStatusData{month(3, 1000, 400, true), month(2, 1000, 1200, true), month(1, 1000, 500, false)},
+			want:     600 - 200 + 500,
+		},
+		{
+			name:     "overspending carries as a negative",
+			previous: []BudgetStatusData{month(3, 1000, 1500, false)},
+			want:     -500,
+		},
+		{
+			name:     "gap before the period",
+			previous: []BudgetStatusData{month(2, 1000, 0, true)},
+			want:     0,
+		},
+		{
+			name:     "gap in the chain",
+			previous: []BudgetStatusData{month(3, 1000, 900, true), month(1, 1000, 0, true)},
+			want:     100,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := carryOver(april, tt.previous); got != tt.want {
+			t.Errorf("%s: carryOver = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
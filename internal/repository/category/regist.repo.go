@@ -2,10 +2,14 @@ package category
 
 import (
 	"context"
+	"errors"
 	"pannypal/internal/common/models"
 	"pannypal/internal/pkg/redis"
+	"time"
 
 	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
 )
 
 type Repository struct {
@@ -18,10 +22,33 @@ type IRepository interface {
 	CreateCategory(model models.Category) (*models.Category, error)
 	UpdateCategory(model models.Category) (*models.Category, error)
 	GetCategoryByID(id uint) (*models.Category, error)
-	GetAllCategories() ([]models.Category, error)
+	GetCategories(userID *uint) ([]models.Category, error)
+	GetCategoryByName(userID *uint, name string) (*models.Category, error)
+	GetFallbackCategory(userID uint) (*models.Category, error)
+	CountSubcategories(id uint) (int64, error)
 	DeleteCategory(model models.Category) error
+	MergeCategory(source, target models.Category, preview bool) (*MergeResult, error)
+}
+
+// MergeResult is what merging a category moved, Transactions holds the live transactions as they
+// were before the merge
+type MergeResult struct {
+	Transactions        []models.Transaction
+	TrashedTransactions int64
+	BudgetsMoved        int64
+	BudgetsCombined     int64
+	BudgetTemplates     int64
+	EnvelopeAllocations int64
+	SavingsGoals        int64
+	Loans               int64
+	Bills               int64
+	RecurringRules      int64
+	Subcategories       int64
 }
 
+// errMergePreview rolls back a merge that was only previewed
+var errMergePreview = errors.New("category merge preview")
+
 func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
 	return &Repository{
 		ctx:   ctx,
@@ -51,17 +78,205 @@ func (r *Repository) GetCategoryByID(id uint) (*models.Category, error) {
 	return &category, nil
 }
 
-func (r *Repository) GetAllCategories() ([]models.Category, error) {
+// GetCategories returns a user's categories, or the template when userID is nil, as a flat list
+// with top-level categories first
+func (r *Repository) GetCategories(userID *uint) ([]models.Category, error) {
 	var categories []models.Category
-	if err := r.db.WithContext(r.ctx).Find(&categories).Error; err != nil {
+	query := r.db.WithContext(r.ctx)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	} else {
+		query = query.Where("user_id IS NULL")
+	}
+	if err := query.Order("parent_id NULLS FIRST, name").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
+// GetCategoryByName finds a category by name ignoring case, a top-level one wins over a subcategory
+func (r *Repository) GetCategoryByName(userID *uint, name string) (*models.Category, error) {
+	var category models.Category
+	query := r.db.WithContext(r.ctx).Where("lower(name) = lower(?)", name)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	} else {
+		query = query.Where("user_id IS NULL")
+	}
+	if err := query.Order("parent_id NULLS FIRST, id").First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetFallbackCategory returns the user's top-level "Lainnya" category, it is created when the user has none
+func (r *Repository) GetFallbackCategory(userID uint) (*models.Category, error) {
+	category := models.Category{
+		UserID: &userID,
+		Name:   models.FallbackCategoryName,
+		Kind:   models.CategoryKindBoth,
+		Icon:   "📦",
+	}
+	err := r.db.WithContext(r.ctx).
+		Where("user_id = ? AND parent_id IS NULL AND lower(name) = lower(?)", userID, models.FallbackCategoryName).
+		Attrs(category).
+		FirstOrCreate(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *Repository) CountSubcategories(id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(r.ctx).Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
 func (r *Repository) DeleteCategory(model models.Category) error {
 	if err := r.db.WithContext(r.ctx).Delete(&model).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// MergeCategory moves everything from source to target in one transaction and moves source to the
+// trash. A source budget for a period the target already has a budget for is added to that budget.
+// With preview the same changes are made and rolled back, so the result is exactly what a merge would do.
+func (r *Repository) MergeCategory(source, target models.Category, preview bool) (*MergeResult, error) {
+	result := &MergeResult{}
+
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Tags").
+			Where("category_id = ?", source.ID).
+			Find(&result.Transactions).Error; err != nil {
+			return err
+		}
+
+		// Trashed transactions move too, so they come back from the trash in the target
+		if err := tx.Model(&models.Transaction{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID).Error; err != nil {
+			return err
+		}
+		trashed := tx.Unscoped().Model(&models.Transaction{}).
+			Where("category_id = ? AND deleted_at IS NOT NULL", source.ID).
+			Update("category_id", target.ID)
+		if trashed.Error != nil {
+			return trashed.Error
+		}
+		result.TrashedTransactions = trashed.RowsAffected
+
+		rules := tx.Unscoped().Model(&models.RecurringRule{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if rules.Error != nil {
+			return rules.Error
+		}
+		result.RecurringRules = rules.RowsAffected
+
+		subcategories := tx.Unscoped().Model(&models.Category{}).
+			Where("parent_id = ?", source.ID).
+			Update("parent_id", target.ID)
+		if subcategories.Error != nil {
+			return subcategories.Error
+		}
+		result.Subcategories = subcategories.RowsAffected
+
+		const samePeriod = `sb.category_id = ? AND tb.category_id = ?
+			AND sb.user_id = tb.user_id AND sb.ledger_id IS NOT DISTINCT FROM tb.ledger_id
+			AND sb.period = tb.period AND sb.period_start = tb.period_start
+			AND sb.deleted_at IS NULL AND tb.deleted_at IS NULL`
+		combined := tx.Exec(`UPDATE budgets tb SET amount = tb.amount + sb.amount, updated_at = ?
+			FROM budgets sb WHERE `+samePeriod, time.Now(), source.ID, target.ID)
+		if combined.Error != nil {
+			return combined.Error
+		}
+		result.BudgetsCombined = combined.RowsAffected
+
+		// A combined budget lives on in the target, restoring it with the source would count it twice
+		if err := tx.Exec(`DELETE FROM budgets sb WHERE EXISTS (SELECT 1 FROM budgets tb WHERE `+samePeriod+`)`,
+			source.ID, target.ID).Error; err != nil {
+			return err
+		}
+		budgets := tx.Model(&models.Budget{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if budgets.Error != nil {
+			return budgets.Error
+		}
+		result.BudgetsMoved = budgets.RowsAffected
+
+		// Templates go on setting the budget of future periods in the target
+		templates := tx.Unscoped().Model(&models.BudgetTemplate{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if templates.Error != nil {
+			return templates.Error
+		}
+		result.BudgetTemplates = templates.RowsAffected
+
+		// The money in the source envelope follows its spending into the target's envelope, which is the
+		// target's parent when the target is a subcategory
+		envelope := target.ID
+		if target.ParentID != nil {
+			envelope = *target.ParentID
+		}
+		// Moves between the source and that envelope would become moves from the envelope to itself,
+		// they cancel out in it and are dropped
+		if err := tx.Unscoped().
+			Where("(from_category_id = ? AND to_category_id = ?) OR (from_category_id = ? AND to_category_id = ?)",
+				source.ID, envelope, envelope, source.ID).
+			Delete(&models.EnvelopeAllocation{}).Error; err != nil {
+			return err
+		}
+		for _, column := range []string{"from_category_id", "to_category_id"} {
+			allocations := tx.Unscoped().Model(&models.EnvelopeAllocation{}).
+				Where(column+" = ?", source.ID).
+				Update(column, envelope)
+			if allocations.Error != nil {
+				return allocations.Error
+			}
+			result.EnvelopeAllocations += allocations.RowsAffected
+		}
+
+		// Goals count the transactions of the target from now on
+		goals := tx.Unscoped().Model(&models.SavingsGoal{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if goals.Error != nil {
+			return goals.Error
+		}
+		result.SavingsGoals = goals.RowsAffected
+
+		// Installments paid from now on are recorded in the target
+		loans := tx.Unscoped().Model(&models.Loan{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if loans.Error != nil {
+			return loans.Error
+		}
+		result.Loans = loans.RowsAffected
+
+		// Bills are paid by expenses in the target from now on
+		bills := tx.Unscoped().Model(&models.Bill{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if bills.Error != nil {
+			return bills.Error
+		}
+		result.Bills = bills.RowsAffected
+
+		if err := tx.Delete(&source).Error; err != nil {
+			return err
+		}
+		if preview {
+			return errMergePreview
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errMergePreview) {
+		return nil, err
+	}
+	return result, nil
+}
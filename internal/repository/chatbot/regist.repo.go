@@ -5,6 +5,8 @@ import (
 	"pannypal/internal/common/models"
 	database "pannypal/internal/pkg/db"
 	"pannypal/internal/pkg/redis"
+
+	"gorm.io/gorm"
 )
 
 type Repository struct {
@@ -17,6 +19,7 @@ type IRepository interface {
 	// Conversations
 	CreateConversation(conv models.ChatConversation) (*models.ChatConversation, error)
 	GetConversationBySession(sessionID string) (*models.ChatConversation, error)
+	GetActiveConversationByChatID(chatID string) (*models.ChatConversation, error)
 	GetAllConversations(limit int) ([]models.ChatConversation, error)
 	UpdateConversation(conv models.ChatConversation) error
 
@@ -51,6 +54,20 @@ func (r *Repository) GetConversationBySession(sessionID string) (*models.ChatCon
 	return &conv, nil
 }
 
+// GetActiveConversationByChatID retrieves the active conversation bound to a WhatsApp chat, nil if there is none
+func (r *Repository) GetActiveConversationByChatID(chatID string) (*models.ChatConversation, error) {
+	var conv models.ChatConversation
+	err := r.db.WithContext(r.ctx).Where("chat_id = ? AND is_active = ?", chatID, true).
+		Order("updated_at DESC").First(&conv).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &conv, nil
+}
+
 // GetAllConversations retrieves all conversations with limit
 func (r *Repository) GetAllConversations(limit int) ([]models.ChatConversation, error) {
 	var convs []models.ChatConversation
@@ -0,0 +1,161 @@
+package envelope
+
+import (
+	"context"
+	"database/sql"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateAllocation(model models.EnvelopeAllocation) (*models.EnvelopeAllocation, error)
+	GetAllocations(userID uint, from, to time.Time) ([]models.EnvelopeAllocation, error)
+	GetEnvelopes(userID uint, since, from, to time.Time) ([]EnvelopeData, error)
+	GetReadyToAssign(userID uint, since, to time.Time) (*ReadyToAssignData, error)
+}
+
+// EnvelopeData is an envelope over a period. Assigned and Activity only count the period, Allocated and
+// Spent everything up to its end, so Allocated minus Spent is what the envelope has left. Target is the
+// budget set on the category, or its subcategories, for the period.
+type EnvelopeData struct {
+	CategoryID   uint
+	CategoryName string
+	Icon         string
+	Color        string
+	Assigned     types.Money
+	Activity     types.Money
+	Allocated    types.Money
+	Spent        types.Money
+	Target       types.Money
+}
+
+// ReadyToAssignData is what makes up the money ready to assign: the income, less what went into
+// envelopes and the spending that has no envelope to draw from
+type ReadyToAssignData struct {
+	Income        types.Money
+	Assigned      types.Money
+	Uncategorized types.Money
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateAllocation(model models.EnvelopeAllocation) (*models.EnvelopeAllocation, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// GetAllocations lists the user's allocations dated from from through to, newest first
+func (r *Repository) GetAllocations(userID uint, from, to time.Time) ([]models.EnvelopeAllocation, error) {
+	var allocations []models.EnvelopeAllocation
+	err := r.db.WithContext(r.ctx).
+		Preload("FromCategory").
+		Preload("ToCategory").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, from, to.AddDate(0, 0, 1)).
+		Order("date DESC, id DESC").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// GetEnvelopes reports the envelopes that had money, spending or a budget by the end of the period from
+// through to. Spending from since on counts, a subcategory draws from its parent's envelope.
+func (r *Repository) GetEnvelopes(userID uint, since, from, to time.Time) ([]EnvelopeData, error) {
+	var envelopes []EnvelopeData
+	err := r.db.WithContext(r.ctx).Raw(`
+		WITH moves AS (
+			SELECT to_category_id AS category_id, amount, date
+			FROM envelope_allocations
+			WHERE user_id = @user AND deleted_at IS NULL AND to_category_id IS NOT NULL AND date < @until
+			UNION ALL
+			SELECT from_category_id, -amount, date
+			FROM envelope_allocations
+			WHERE user_id = @user AND deleted_at IS NULL AND from_category_id IS NOT NULL AND date < @until
+		), spending AS (
+			SELECT COALESCE(c.parent_id, c.id) AS category_id, `+exchangerate.AmountInBaseSQL("t")+` AS amount, t.transaction_date AS date
+			FROM transactions t
+			JOIN categories c ON c.id = t.category_id
+			WHERE t.user_id = @user AND t.type = 'EXPENSE' AND t.deleted_at IS NULL
+				AND t.transaction_date >= @since AND t.transaction_date < @until
+		), totals AS (
+			SELECT category_id, SUM(amount) FILTER (WHERE date >= @from) AS assigned, SUM(amount) AS allocated,
+				0 AS activity, 0 AS spent, 0 AS target
+			FROM moves GROUP BY category_id
+			UNION ALL
+			SELECT category_id, 0, 0, SUM(amount) FILTER (WHERE date >= @from), SUM(amount), 0
+			FROM spending GROUP BY category_id
+			UNION ALL
+			SELECT COALESCE(c.parent_id, c.id), 0, 0, 0, 0, SUM(b.amount)
+			FROM budgets b
+			JOIN categories c ON c.id = b.category_id
+			WHERE b.user_id = @user AND b.ledger_id IS NULL AND b.deleted_at IS NULL
+				AND b.period_start <= @to AND b.period_end >= @from
+			GROUP BY COALESCE(c.parent_id, c.id)
+		)
+		SELECT
+			c.id AS category_id,
+			c.name AS category_name,
+			c.icon,
+			c.color,
+			COALESCE(SUM(totals.assigned), 0) AS assigned,
+			COALESCE(SUM(totals.activity), 0) AS activity,
+			COALESCE(SUM(totals.allocated), 0) AS allocated,
+			COALESCE(SUM(totals.spent), 0) AS spent,
+			COALESCE(SUM(totals.target), 0) AS target
+		FROM totals
+		JOIN categories c ON c.id = totals.category_id AND c.deleted_at IS NULL
+		GROUP BY c.id, c.name, c.icon, c.color
+		ORDER BY c.name`,
+		sql.Named("user", userID),
+		sql.Named("since", since),
+		sql.Named("from", from),
+		sql.Named("to", to),
+		sql.Named("until", to.AddDate(0, 0, 1)),
+	).Scan(&envelopes).Error
+	return envelopes, err
+}
+
+// GetReadyToAssign totals what makes up the money ready to assign by the end of to, counting
+// transactions from since on
+func (r *Repository) GetReadyToAssign(userID uint, since, to time.Time) (*ReadyToAssignData, error) {
+	var data ReadyToAssignData
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT
+			(SELECT COALESCE(SUM(`+exchangerate.AmountInBaseSQL("t")+`), 0)
+				FROM transactions t
+				WHERE t.user_id = @user AND t.type = 'INCOME' AND t.deleted_at IS NULL
+					AND t.transaction_date >= @since AND t.transaction_date < @until) AS income,
+			(SELECT COALESCE(SUM(CASE WHEN from_category_id IS NULL THEN amount ELSE 0 END)
+					- SUM(CASE WHEN to_category_id IS NULL THEN amount ELSE 0 END), 0)
+				FROM envelope_allocations
+				WHERE user_id = @user AND deleted_at IS NULL AND date < @until) AS assigned,
+			(SELECT COALESCE(SUM(`+exchangerate.AmountInBaseSQL("t")+`), 0)
+				FROM transactions t
+				WHERE t.user_id = @user AND t.type = 'EXPENSE' AND t.deleted_at IS NULL AND t.category_id IS NULL
+					AND t.transaction_date >= @since AND t.transaction_date < @until) AS uncategorized`,
+		sql.Named("user", userID),
+		sql.Named("since", since),
+		sql.Named("until", to.AddDate(0, 0, 1)),
+	).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,149 @@
+package exchangerate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+	"strings"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	UpsertRates(rates []models.ExchangeRate) error
+	GetRatesOn(date time.Time) ([]models.ExchangeRate, error)
+	GetRate(from, to string, date time.Time) (*float64, error)
+	GetUnconvertedCurrencies(userID *uint) ([]string, error)
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// UpsertRates stores the rates of a day, a second refresh on the same day overwrites them
+func (r *Repository) UpsertRates(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.WithContext(r.ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(&rates).Error
+}
+
+// GetRatesOn returns the rates of the closest day with rates, preferring earlier days
+func (r *Repository) GetRatesOn(date time.Time) ([]models.ExchangeRate, error) {
+	var day models.ExchangeRate
+	err := r.db.WithContext(r.ctx).
+		Order(fmt.Sprintf("date > DATE '%[1]s', ABS(date - DATE '%[1]s')", date.Format("2006-01-02"))).
+		First(&day).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []models.ExchangeRate{}, nil
+		}
+		return nil, err
+	}
+
+	var rates []models.ExchangeRate
+	err = r.db.WithContext(r.ctx).
+		Where("date = ?", day.Date).
+		Order("currency ASC").
+		Find(&rates).Error
+	return rates, err
+}
+
+// GetRate returns how much one unit of from is worth in to, nil when either currency has no rate
+func (r *Repository) GetRate(from, to string, date time.Time) (*float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+	if from == to {
+		rate := float64(1)
+		return &rate, nil
+	}
+
+	var rates []float64
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT q.rate / f.rate
+		FROM exchange_rates f
+		JOIN exchange_rates q ON q.date = f.date AND q.currency = ? AND q.deleted_at IS NULL
+		WHERE f.currency = ? AND f.deleted_at IS NULL AND f.rate > 0
+		ORDER BY ABS(f.date - CAST(? AS date))
+		LIMIT 1`, to, from, date).Scan(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, nil
+	}
+	return &rates[0], nil
+}
+
+// GetUnconvertedCurrencies returns the currencies of transactions that have no rate to their owner's
+// base currency, of the user or of everyone when userID is nil. Totals leave those transactions out.
+func (r *Repository) GetUnconvertedCurrencies(userID *uint) ([]string, error) {
+	query := r.db.WithContext(r.ctx).
+		Table("transactions t").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Where("t.deleted_at IS NULL AND t.currency <> u.base_currency").
+		Where(`NOT EXISTS (
+			SELECT 1
+			FROM exchange_rates f
+			JOIN exchange_rates q ON q.date = f.date AND q.currency = u.base_currency AND q.deleted_at IS NULL
+			WHERE f.currency = t.currency AND f.deleted_at IS NULL AND f.rate > 0
+		)`)
+	if userID != nil {
+		query = query.Where("t.user_id = ?", *userID)
+	}
+
+	currencies := []string{}
+	err := query.Distinct("t.currency").Order("t.currency").Pluck("t.currency", &currencies).Error
+	return currencies, err
+}
+
+// ConvertSQL is a SQL expression converting an amount between currencies with the rates of the
+// closest day. Pairs without rates are NULL so sums leave them out rather than counting them at face
+// value, GetUnconvertedCurrencies reports them. Converted amounts are rounded to cents per row so sums
+// stay exact decimals.
+func ConvertSQL(amount, currency, target, date string) string {
+	return fmt.Sprintf(`(CASE WHEN %[2]s = %[3]s THEN %[1]s ELSE ROUND(%[1]s * (
+			SELECT q.rate / f.rate
+			FROM exchange_rates f
+			JOIN exchange_rates q ON q.date = f.date AND q.currency = %[3]s AND q.deleted_at IS NULL
+			WHERE f.currency = %[2]s AND f.deleted_at IS NULL AND f.rate > 0
+			ORDER BY ABS(f.date - CAST(%[4]s AS date))
+			LIMIT 1
+		), 2) END)`, amount, currency, target, date)
+}
+
+// AmountInBaseSQL converts the amount of a transactions row into the base currency of its owner,
+// table is the table name or alias of transactions in the query
+func AmountInBaseSQL(table string) string {
+	return AmountInUserBaseSQL(table, table+".user_id")
+}
+
+// AmountInUserBaseSQL converts the amount of a transactions row into the base currency of the user
+// userID, a SQL expression. Totals over several people's transactions use it to stay in one currency.
+func AmountInUserBaseSQL(table, userID string) string {
+	return ConvertSQL(
+		table+".amount",
+		table+".currency",
+		"(SELECT base_currency FROM users WHERE users.id = "+userID+")",
+		table+".transaction_date",
+	)
+}
@@ -0,0 +1,186 @@
+package goal
+
+import (
+	"context"
+	"database/sql"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateGoal(model models.SavingsGoal) (*models.SavingsGoal, error)
+	UpdateGoal(model models.SavingsGoal) (*models.SavingsGoal, error)
+	GetGoalByID(id uint) (*models.SavingsGoal, error)
+	GetGoalsByUserID(userID uint) ([]models.SavingsGoal, error)
+	DeleteGoal(id uint) error
+
+	CreateContribution(model models.GoalContribution) (*models.GoalContribution, error)
+	GetContributionByID(id uint) (*models.GoalContribution, error)
+	DeleteContribution(id uint) error
+
+	GetGoalProgress(goalIDs []uint, since time.Time) ([]GoalProgressData, error)
+	GetGoalEntries(goalID uint, limit int) ([]GoalEntryData, error)
+}
+
+// GoalProgressData is what a goal has saved, RecentSaved only counts from the given day on. CompletedAt
+// is the day the saved total reached the target and stayed there, nil while it is below the target.
+type GoalProgressData struct {
+	GoalID      uint
+	Saved       types.Money
+	RecentSaved types.Money
+	CompletedAt *time.Time
+}
+
+// GoalEntryData is a contribution added by hand or a linked transaction, one of the IDs is set
+type GoalEntryData struct {
+	ContributionID *uint
+	TransactionID  *uint
+	Amount         types.Money
+	Description    string
+	Date           time.Time
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateGoal(model models.SavingsGoal) (*models.SavingsGoal, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateGoal(model models.SavingsGoal) (*models.SavingsGoal, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetGoalByID(id uint) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+	if err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Preload("Tag").
+		Where("id = ?", id).
+		First(&goal).Error; err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *Repository) GetGoalsByUserID(userID uint) ([]models.SavingsGoal, error) {
+	var goals []models.SavingsGoal
+	err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Preload("Tag").
+		Where("user_id = ?", userID).
+		Order("deadline IS NULL, deadline, name").
+		Find(&goals).Error
+	return goals, err
+}
+
+func (r *Repository) DeleteGoal(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.SavingsGoal{}, id).Error
+}
+
+func (r *Repository) CreateContribution(model models.GoalContribution) (*models.GoalContribution, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetContributionByID(id uint) (*models.GoalContribution, error) {
+	var contribution models.GoalContribution
+	if err := r.db.WithContext(r.ctx).Where("id = ?", id).First(&contribution).Error; err != nil {
+		return nil, err
+	}
+	return &contribution, nil
+}
+
+func (r *Repository) DeleteContribution(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.GoalContribution{}, id).Error
+}
+
+// goalEntriesSQL lists the contributions and linked transactions of the goals in @goals. Money leaving
+// the goal's account counts against it.
+var goalEntriesSQL = `
+	SELECT gc.goal_id, gc.id AS contribution_id, CAST(NULL AS bigint) AS transaction_id,
+		gc.amount, gc.note AS description, gc.date
+	FROM goal_contributions gc
+	WHERE gc.goal_id IN @goals AND gc.deleted_at IS NULL
+	UNION ALL
+	SELECT g.id, NULL, t.id,
+		CASE WHEN g.account_id IS NOT NULL AND t.account_id = g.account_id AND t.type IN ('EXPENSE', 'TRANSFER')
+			THEN -1 ELSE 1 END * ` + exchangerate.AmountInBaseSQL("t") + `,
+		t.description, CAST(t.transaction_date AS date)
+	FROM savings_goals g
+	JOIN transactions t ON t.user_id = g.user_id AND t.deleted_at IS NULL AND t.transaction_date >= g.start_date
+	WHERE g.id IN @goals AND (
+		(g.tag_id IS NOT NULL AND EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag_id = g.tag_id))
+		OR (g.category_id IS NOT NULL AND t.category_id IN (SELECT c.id FROM categories c WHERE c.id = g.category_id OR c.parent_id = g.category_id))
+		OR (g.account_id IS NOT NULL AND (t.account_id = g.account_id OR (t.type = 'TRANSFER' AND t.to_account_id = g.account_id)))
+	)`
+
+func (r *Repository) GetGoalProgress(goalIDs []uint, since time.Time) ([]GoalProgressData, error) {
+	var progress []GoalProgressData
+	if len(goalIDs) == 0 {
+		return progress, nil
+	}
+	err := r.db.WithContext(r.ctx).Raw(`
+		WITH entries AS (`+goalEntriesSQL+`),
+		running AS (
+			SELECT daily.goal_id, daily.date,
+				SUM(daily.amount) OVER (PARTITION BY daily.goal_id ORDER BY daily.date) AS total
+			FROM (SELECT goal_id, date, SUM(amount) AS amount FROM entries GROUP BY goal_id, date) daily
+		)
+		SELECT entries.goal_id,
+			COALESCE(SUM(entries.amount), 0) AS saved,
+			COALESCE(SUM(entries.amount) FILTER (WHERE entries.date >= @since), 0) AS recent_saved,
+			(SELECT MIN(r.date) FROM running r JOIN savings_goals g ON g.id = r.goal_id
+				WHERE r.goal_id = entries.goal_id AND r.total >= g.target_amount
+				AND NOT EXISTS (SELECT 1 FROM running later
+					WHERE later.goal_id = r.goal_id AND later.date > r.date AND later.total < g.target_amount)
+			) AS completed_at
+		FROM entries
+		GROUP BY entries.goal_id`,
+		sql.Named("goals", goalIDs),
+		sql.Named("since", since),
+	).Scan(&progress).Error
+	return progress, err
+}
+
+// GetGoalEntries lists the goal's contributions and linked transactions, newest first
+func (r *Repository) GetGoalEntries(goalID uint, limit int) ([]GoalEntryData, error) {
+	var entries []GoalEntryData
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT entries.contribution_id, entries.transaction_id, entries.amount, entries.description, entries.date
+		FROM (`+goalEntriesSQL+`) entries
+		ORDER BY entries.date DESC, entries.contribution_id DESC NULLS LAST, entries.transaction_id DESC
+		LIMIT @limit`,
+		sql.Named("goals", []uint{goalID}),
+		sql.Named("limit", limit),
+	).Scan(&entries).Error
+	return entries, err
+}
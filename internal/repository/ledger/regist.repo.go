@@ -0,0 +1,156 @@
+package ledger
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"strconv"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateLedger(model models.Ledger) (*models.Ledger, error)
+	GetLedgerByID(id uint) (*models.Ledger, error)
+	GetLedgerByChatID(chatID string) (*models.Ledger, error)
+	GetLedgersByUserID(userID uint) ([]models.Ledger, error)
+	AddMember(ledgerID, userID uint) (*models.LedgerMember, error)
+	IsMember(ledgerID, userID uint) (bool, error)
+	GetMemberAnalytics(ledgerID, viewerID uint, startDate, endDate *time.Time) ([]MemberAnalyticsData, error)
+}
+
+type MemberAnalyticsData struct {
+	UserID           uint
+	PhoneNumber      string
+	Name             string
+	TotalIncome      types.Money
+	TotalExpense     types.Money
+	TransactionCount int64
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateLedger(model models.Ledger) (*models.Ledger, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetLedgerByID(id uint) (*models.Ledger, error) {
+	var ledger models.Ledger
+	if err := r.db.WithContext(r.ctx).Preload("Members.User").Where("id = ?", id).First(&ledger).Error; err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+func (r *Repository) GetLedgerByChatID(chatID string) (*models.Ledger, error) {
+	var ledger models.Ledger
+	err := r.db.WithContext(r.ctx).Where("chat_id = ?", chatID).First(&ledger).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+func (r *Repository) GetLedgersByUserID(userID uint) ([]models.Ledger, error) {
+	var ledgers []models.Ledger
+	err := r.db.WithContext(r.ctx).
+		Joins("JOIN ledger_members ON ledger_members.ledger_id = ledgers.id AND ledger_members.deleted_at IS NULL").
+		Where("ledger_members.user_id = ?", userID).
+		Preload("Members.User").
+		Order("ledgers.created_at DESC").
+		Find(&ledgers).Error
+	if err != nil {
+		return nil, err
+	}
+	return ledgers, nil
+}
+
+// AddMember registers the user in the ledger, it is a no-op for existing members
+func (r *Repository) AddMember(ledgerID, userID uint) (*models.LedgerMember, error) {
+	member := models.LedgerMember{
+		LedgerID: ledgerID,
+		UserID:   userID,
+	}
+	err := r.db.WithContext(r.ctx).
+		Where("ledger_id = ? AND user_id = ?", ledgerID, userID).
+		FirstOrCreate(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *Repository) IsMember(ledgerID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(r.ctx).Model(&models.LedgerMember{}).
+		Where("ledger_id = ? AND user_id = ?", ledgerID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetMemberAnalytics totals the ledger transactions per member who recorded them, in the base currency
+// of the viewer so the members can be compared
+func (r *Repository) GetMemberAnalytics(ledgerID, viewerID uint, startDate, endDate *time.Time) ([]MemberAnalyticsData, error) {
+	var data []MemberAnalyticsData
+	amountInBase := exchangerate.AmountInUserBaseSQL("t", strconv.FormatUint(uint64(viewerID), 10))
+
+	queryStr := `
+		SELECT 
+			u.id as user_id,
+			u.phone_number,
+			u.name,
+			COALESCE(SUM(CASE WHEN t.type = 'INCOME' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN t.type = 'EXPENSE' THEN ` + amountInBase + ` ELSE 0 END), 0) as total_expense,
+			COUNT(t.id) as transaction_count
+		FROM ledger_members m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN transactions t ON t.user_id = m.user_id
+			AND t.ledger_id = m.ledger_id
+			AND t.type <> 'TRANSFER'
+			AND t.deleted_at IS NULL`
+
+	args := []interface{}{}
+	if startDate != nil {
+		queryStr += " AND t.transaction_date >= ?"
+		args = append(args, *startDate)
+	}
+	if endDate != nil {
+		queryStr += " AND t.transaction_date <= ?"
+		args = append(args, *endDate)
+	}
+
+	queryStr += `
+		WHERE m.ledger_id = ? AND m.deleted_at IS NULL
+		GROUP BY u.id, u.phone_number, u.name
+		ORDER BY total_expense DESC`
+	args = append(args, ledgerID)
+
+	err := r.db.WithContext(r.ctx).Raw(queryStr, args...).Scan(&data).Error
+	return data, err
+}
@@ -0,0 +1,220 @@
+package loan
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateLoan(model models.Loan, installments []models.LoanInstallment) (*models.Loan, error)
+	UpdateLoan(model models.Loan) (*models.Loan, error)
+	ReplaceSchedule(model models.Loan, installments []models.LoanInstallment) (*models.Loan, error)
+	GetLoanByID(id uint) (*models.Loan, error)
+	GetLoansByUserID(userID uint) ([]models.Loan, error)
+	DeleteLoan(id uint) error
+
+	GetInstallment(loanID uint, number int) (*models.LoanInstallment, error)
+	GetInstallmentByTransactionID(transactionID uint) (*models.LoanInstallment, error)
+	UpdateInstallment(model models.LoanInstallment) (*models.LoanInstallment, error)
+
+	GetDebtTotals(userID *uint, startDate, endDate time.Time) (*DebtTotalsData, error)
+}
+
+// DebtTotalsData totals the loans in the base currency of their owners. InstallmentsDue are the
+// installments falling due between the dates, InstallmentsPaid the part of them already paid.
+type DebtTotalsData struct {
+	LoanCount        int64
+	Principal        types.Money
+	Outstanding      types.Money
+	InstallmentsDue  types.Money
+	InstallmentsPaid types.Money
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// CreateLoan stores the loan and its amortization schedule in one transaction
+func (r *Repository) CreateLoan(model models.Loan, installments []models.LoanInstallment) (*models.Loan, error) {
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&model).Error; err != nil {
+			return err
+		}
+		return createInstallments(tx, model.ID, installments)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateLoan(model models.Loan) (*models.Loan, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// ReplaceSchedule saves the loan with a new amortization schedule in place of the old one
+func (r *Repository) ReplaceSchedule(model models.Loan, installments []models.LoanInstallment) (*models.Loan, error) {
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&model).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("loan_id = ?", model.ID).Delete(&models.LoanInstallment{}).Error; err != nil {
+			return err
+		}
+		return createInstallments(tx, model.ID, installments)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func createInstallments(tx *gorm.DB, loanID uint, installments []models.LoanInstallment) error {
+	if len(installments) == 0 {
+		return nil
+	}
+	for i := range installments {
+		installments[i].LoanID = loanID
+	}
+	return tx.Omit(clause.Associations).Create(&installments).Error
+}
+
+func (r *Repository) GetLoanByID(id uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Preload("Installments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("number ASC")
+		}).
+		Where("id = ?", id).
+		First(&loan).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *Repository) GetLoansByUserID(userID uint) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := r.db.WithContext(r.ctx).
+		Preload("Account").
+		Preload("Category").
+		Preload("Installments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("number ASC")
+		}).
+		Where("user_id = ?", userID).
+		Order("paid_off_at IS NOT NULL, first_due_date, name").
+		Find(&loans).Error
+	return loans, err
+}
+
+// DeleteLoan removes the loan with its schedule, the installment transactions stay as plain expenses
+func (r *Repository) DeleteLoan(id uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("loan_id = ?", id).Delete(&models.LoanInstallment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Loan{}, id).Error
+	})
+}
+
+func (r *Repository) GetInstallment(loanID uint, number int) (*models.LoanInstallment, error) {
+	var installment models.LoanInstallment
+	if err := r.db.WithContext(r.ctx).
+		Where("loan_id = ? AND number = ?", loanID, number).
+		First(&installment).Error; err != nil {
+		return nil, err
+	}
+	return &installment, nil
+}
+
+func (r *Repository) GetInstallmentByTransactionID(transactionID uint) (*models.LoanInstallment, error) {
+	var installment models.LoanInstallment
+	if err := r.db.WithContext(r.ctx).
+		Where("transaction_id = ?", transactionID).
+		First(&installment).Error; err != nil {
+		return nil, err
+	}
+	return &installment, nil
+}
+
+func (r *Repository) UpdateInstallment(model models.LoanInstallment) (*models.LoanInstallment, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// UnpayInstallments sets the installments paid by the given transactions back to unpaid, for when the
+// payments are deleted. transactionIDs is a list of IDs or a subquery selecting them.
+func UnpayInstallments(tx *gorm.DB, transactionIDs interface{}) error {
+	loanIDs := tx.Model(&models.LoanInstallment{}).Select("loan_id").Where("transaction_id IN (?)", transactionIDs)
+	if err := tx.Unscoped().Model(&models.Loan{}).
+		Where("id IN (?) AND paid_off_at IS NOT NULL", loanIDs).
+		Update("paid_off_at", nil).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.LoanInstallment{}).
+		Where("transaction_id IN (?)", transactionIDs).
+		Updates(map[string]interface{}{"transaction_id": nil, "paid_at": nil}).Error
+}
+
+// GetDebtTotals totals the loans of the user, or of everyone when userID is nil
+func (r *Repository) GetDebtTotals(userID *uint, startDate, endDate time.Time) (*DebtTotalsData, error) {
+	inBase := func(amount string) string {
+		return exchangerate.ConvertSQL(amount, "l.currency", "(SELECT base_currency FROM users WHERE users.id = l.user_id)", "CURRENT_DATE")
+	}
+
+	queryStr := `
+		SELECT
+			COUNT(*) FILTER (WHERE l.paid_off_at IS NULL) AS loan_count,
+			COALESCE(SUM(` + inBase("l.principal") + `), 0) AS principal,
+			COALESCE(SUM(` + inBase("(l.principal - COALESCE(i.paid_principal, 0))") + `), 0) AS outstanding,
+			COALESCE(SUM(` + inBase("COALESCE(i.due, 0)") + `), 0) AS installments_due,
+			COALESCE(SUM(` + inBase("COALESCE(i.due_paid, 0)") + `), 0) AS installments_paid
+		FROM loans l
+		LEFT JOIN (
+			SELECT loan_id,
+				SUM(principal) FILTER (WHERE paid_at IS NOT NULL) AS paid_principal,
+				SUM(amount) FILTER (WHERE due_date BETWEEN CAST(? AS date) AND CAST(? AS date)) AS due,
+				SUM(amount) FILTER (WHERE paid_at IS NOT NULL AND due_date BETWEEN CAST(? AS date) AND CAST(? AS date)) AS due_paid
+			FROM loan_installments
+			GROUP BY loan_id
+		) i ON i.loan_id = l.id
+		WHERE l.deleted_at IS NULL`
+
+	args := []interface{}{startDate, endDate, startDate, endDate}
+	if userID != nil {
+		queryStr += " AND l.user_id = ?"
+		args = append(args, *userID)
+	}
+
+	var data DebtTotalsData
+	err := r.db.WithContext(r.ctx).Raw(queryStr, args...).Scan(&data).Error
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
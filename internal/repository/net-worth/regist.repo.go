@@ -0,0 +1,107 @@
+package networth
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateAsset(model models.Asset) (*models.Asset, error)
+	UpdateAsset(model models.Asset) (*models.Asset, error)
+	GetAssetByID(id uint) (*models.Asset, error)
+	GetAssetsByUserID(userID uint) ([]models.Asset, error)
+	DeleteAsset(id uint) error
+
+	SaveSnapshot(model models.NetWorthSnapshot) error
+	GetSnapshots(userID uint, from, to time.Time) ([]models.NetWorthSnapshot, error)
+	GetUsersWithoutSnapshot(date time.Time, limit int) ([]models.User, error)
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateAsset(model models.Asset) (*models.Asset, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateAsset(model models.Asset) (*models.Asset, error) {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetAssetByID(id uint) (*models.Asset, error) {
+	var asset models.Asset
+	if err := r.db.WithContext(r.ctx).Where("id = ?", id).First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *Repository) GetAssetsByUserID(userID uint) ([]models.Asset, error) {
+	var assets []models.Asset
+	err := r.db.WithContext(r.ctx).
+		Where("user_id = ?", userID).
+		Order("type, name").
+		Find(&assets).Error
+	return assets, err
+}
+
+func (r *Repository) DeleteAsset(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.Asset{}, id).Error
+}
+
+// SaveSnapshot stores the snapshot of a day, a second one on the same day overwrites it
+func (r *Repository) SaveSnapshot(model models.NetWorthSnapshot) error {
+	return r.db.WithContext(r.ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"currency", "accounts", "investments", "property", "vehicles", "other_assets",
+			"credit_cards", "loans", "other_liabilities", "total_assets", "total_liabilities", "net_worth", "updated_at",
+		}),
+	}).Create(&model).Error
+}
+
+func (r *Repository) GetSnapshots(userID uint, from, to time.Time) ([]models.NetWorthSnapshot, error) {
+	var snapshots []models.NetWorthSnapshot
+	err := r.db.WithContext(r.ctx).
+		Where("user_id = ? AND date BETWEEN CAST(? AS date) AND CAST(? AS date)", userID, from, to).
+		Order("date ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetUsersWithoutSnapshot returns users with accounts, assets or loans who have no snapshot of the day yet
+func (r *Repository) GetUsersWithoutSnapshot(date time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(r.ctx).
+		Where("NOT EXISTS (SELECT 1 FROM net_worth_snapshots s WHERE s.user_id = users.id AND s.date = CAST(? AS date))", date).
+		Where(`(EXISTS (SELECT 1 FROM accounts a WHERE a.user_id = users.id AND a.deleted_at IS NULL)
+			OR EXISTS (SELECT 1 FROM assets a WHERE a.user_id = users.id AND a.deleted_at IS NULL)
+			OR EXISTS (SELECT 1 FROM loans l WHERE l.user_id = users.id AND l.deleted_at IS NULL))`).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
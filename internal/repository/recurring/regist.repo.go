@@ -0,0 +1,151 @@
+package recurring
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateRule(model models.RecurringRule) (*models.RecurringRule, error)
+	UpdateRule(model models.RecurringRule) (*models.RecurringRule, error)
+	GetRuleByID(id uint) (*models.RecurringRule, error)
+	GetRulesByUserID(userID uint, filters RuleFilters) ([]models.RecurringRule, error)
+	DeleteRule(id uint) error
+	GetDueRules(now time.Time, limit int) ([]models.RecurringRule, error)
+	HasOccurrence(ruleID uint, date time.Time) (bool, error)
+	GetRepeatingTransactions(userID uint, since time.Time, minCount int) ([]RepeatingTransactionData, error)
+}
+
+type RuleFilters struct {
+	IsActive       *bool
+	IsSubscription *bool
+}
+
+// RepeatingTransactionData groups expenses with the same description and amount
+type RepeatingTransactionData struct {
+	Description string
+	Amount      types.Money
+	CategoryID  *uint
+	AccountID   *uint
+	Count       int64
+	FirstDate   time.Time
+	LastDate    time.Time
+	AvgGapDays  float64
+	LastDay     int
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) CreateRule(model models.RecurringRule) (*models.RecurringRule, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) UpdateRule(model models.RecurringRule) (*models.RecurringRule, error) {
+	if err := r.db.WithContext(r.ctx).Save(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetRuleByID(id uint) (*models.RecurringRule, error) {
+	var rule models.RecurringRule
+	if err := r.db.WithContext(r.ctx).Preload("Category").Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *Repository) GetRulesByUserID(userID uint, filters RuleFilters) ([]models.RecurringRule, error) {
+	var rules []models.RecurringRule
+
+	query := r.db.WithContext(r.ctx).Preload("Category").Where("user_id = ?", userID)
+	if filters.IsActive != nil {
+		query = query.Where("is_active = ?", *filters.IsActive)
+	}
+	if filters.IsSubscription != nil {
+		query = query.Where("is_subscription = ?", *filters.IsSubscription)
+	}
+
+	if err := query.Order("next_run_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *Repository) DeleteRule(id uint) error {
+	return r.db.WithContext(r.ctx).Delete(&models.RecurringRule{}, id).Error
+}
+
+// GetDueRules returns active rules whose next occurrence is not in the future
+func (r *Repository) GetDueRules(now time.Time, limit int) ([]models.RecurringRule, error) {
+	var rules []models.RecurringRule
+	if err := r.db.WithContext(r.ctx).
+		Preload("Category").
+		Preload("User").
+		Where("is_active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// HasOccurrence reports whether the rule already produced a transaction on that date
+func (r *Repository) HasOccurrence(ruleID uint, date time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(r.ctx).Model(&models.Transaction{}).
+		Where("recurring_rule_id = ? AND DATE(transaction_date) = DATE(?)", ruleID, date).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetRepeatingTransactions finds expenses that came back at least minCount times since the date,
+// transactions already produced by a rule are left out
+func (r *Repository) GetRepeatingTransactions(userID uint, since time.Time, minCount int) ([]RepeatingTransactionData, error) {
+	var data []RepeatingTransactionData
+
+	query := `
+		SELECT 
+			MIN(description) as description,
+			ROUND(amount, 0) as amount,
+			MIN(category_id) as category_id,
+			MIN(account_id) as account_id,
+			COUNT(*) as count,
+			MIN(transaction_date) as first_date,
+			MAX(transaction_date) as last_date,
+			EXTRACT(EPOCH FROM MAX(transaction_date) - MIN(transaction_date)) / 86400 / (COUNT(*) - 1) as avg_gap_days,
+			EXTRACT(DAY FROM MAX(transaction_date)) as last_day
+		FROM transactions
+		WHERE deleted_at IS NULL
+			AND user_id = ?
+			AND type = 'EXPENSE'
+			AND recurring_rule_id IS NULL
+			AND transaction_date >= ?
+		GROUP BY LOWER(TRIM(description)), ROUND(amount, 0)
+		HAVING COUNT(*) >= ?
+		ORDER BY count DESC`
+
+	err := r.db.WithContext(r.ctx).Raw(query, userID, since, minCount).Scan(&data).Error
+	return data, err
+}
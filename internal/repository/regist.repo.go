@@ -1,23 +1,108 @@
 package repository
 
 import (
+	"context"
+	database "pannypal/internal/pkg/db"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository/account"
+	"pannypal/internal/repository/alert"
 	"pannypal/internal/repository/analytics"
+	"pannypal/internal/repository/attachment"
+	"pannypal/internal/repository/bill"
 	"pannypal/internal/repository/bot"
 	"pannypal/internal/repository/budget"
 	"pannypal/internal/repository/category"
 	"pannypal/internal/repository/chatbot"
+	"pannypal/internal/repository/envelope"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"pannypal/internal/repository/goal"
+	"pannypal/internal/repository/ledger"
+	"pannypal/internal/repository/loan"
 	logdata "pannypal/internal/repository/log-data"
+	networth "pannypal/internal/repository/net-worth"
+	"pannypal/internal/repository/recurring"
+	"pannypal/internal/repository/revision"
+	"pannypal/internal/repository/split"
+	"pannypal/internal/repository/tag"
 	"pannypal/internal/repository/transaction"
+	"pannypal/internal/repository/trash"
 	"pannypal/internal/repository/user"
 )
 
 type IRepository struct {
-	User        user.IRepository
-	Category    category.IRepository
-	Budget      budget.IRepository
-	Transaction transaction.IRepository
-	Analytics   analytics.IRepository
-	LogData     logdata.IRepository
-	Bot         bot.IRepository
-	Chatbot     chatbot.IRepository
+	User         user.IRepository
+	Category     category.IRepository
+	Budget       budget.IRepository
+	Transaction  transaction.IRepository
+	Analytics    analytics.IRepository
+	LogData      logdata.IRepository
+	Bot          bot.IRepository
+	Chatbot      chatbot.IRepository
+	Ledger       ledger.IRepository
+	Split        split.IRepository
+	Account      account.IRepository
+	Recurring    recurring.IRepository
+	ExchangeRate exchangerate.IRepository
+	Tag          tag.IRepository
+	Attachment   attachment.IRepository
+	Trash        trash.IRepository
+	Revision     revision.IRepository
+	Alert        alert.IRepository
+	Envelope     envelope.IRepository
+	Goal         goal.IRepository
+	Loan         loan.IRepository
+	NetWorth     networth.IRepository
+	Bill         bill.IRepository
+
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+	inTx  bool
+}
+
+// New wires every repository on the database, shared by the API and the worker
+func New(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return IRepository{
+		Category:     category.NewRepo(ctx, redis, db),
+		Budget:       budget.NewRepo(ctx, redis, db),
+		Transaction:  transaction.NewRepo(ctx, redis, db),
+		User:         user.NewRepo(ctx, redis, db),
+		Analytics:    analytics.NewRepo(ctx, redis, db),
+		LogData:      logdata.NewRepo(ctx, redis, db),
+		Bot:          bot.NewRepo(ctx, redis, db),
+		Chatbot:      chatbot.NewRepo(ctx, redis, db),
+		Ledger:       ledger.NewRepo(ctx, redis, db),
+		Split:        split.NewRepo(ctx, redis, db),
+		Account:      account.NewRepo(ctx, redis, db),
+		Recurring:    recurring.NewRepo(ctx, redis, db),
+		ExchangeRate: exchangerate.NewRepo(ctx, redis, db),
+		Tag:          tag.NewRepo(ctx, redis, db),
+		Attachment:   attachment.NewRepo(ctx, redis, db),
+		Trash:        trash.NewRepo(ctx, redis, db),
+		Revision:     revision.NewRepo(ctx, redis, db),
+		Alert:        alert.NewRepo(ctx, redis, db),
+		Envelope:     envelope.NewRepo(ctx, redis, db),
+		Goal:         goal.NewRepo(ctx, redis, db),
+		Loan:         loan.NewRepo(ctx, redis, db),
+		NetWorth:     networth.NewRepo(ctx, redis, db),
+		Bill:         bill.NewRepo(ctx, redis, db),
+
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// Atomic runs fn with every repository bound to one database transaction, a write and the revision
+// describing it are committed together or not at all. Inside fn the given repositories must be used,
+// calling Atomic on them again joins the same transaction.
+func (rp IRepository) Atomic(fn func(rp IRepository) error) error {
+	if rp.inTx {
+		return fn(rp)
+	}
+	return rp.db.Transaction(rp.ctx, func(tx *database.Database) error {
+		txRepository := New(rp.ctx, rp.redis, tx)
+		txRepository.inTx = true
+		return fn(txRepository)
+	})
 }
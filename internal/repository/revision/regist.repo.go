@@ -0,0 +1,160 @@
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	Record(action models.RevisionAction, actor models.RevisionActor, before, after *models.Transaction) error
+	RecordChanges(action models.RevisionAction, actor models.RevisionActor, changes []Change) error
+	GetRevisionsByTransactionID(transactionID uint) ([]models.TransactionRevision, error)
+	GetLatestRevisionID() (uint, error)
+	GetChangesAfter(afterID uint, limit int) ([]ChangedScope, error)
+}
+
+// ChangedScope is whose spending a revision changed, LedgerID is the group the transaction is in
+// after the change
+type ChangedScope struct {
+	RevisionID uint
+	UserID     uint
+	LedgerID   *uint
+}
+
+// Change is one transaction before and after a write, Before is nil when it was created
+// and After is nil when it was deleted
+type Change struct {
+	Before *models.Transaction
+	After  *models.Transaction
+}
+
+// TransactionSnapshot is the state of a transaction kept in a revision, tags are part of it
+// so the transactions passed in must have them loaded
+type TransactionSnapshot struct {
+	Type            models.TransactionType `json:"type"`
+	Amount          types.Money            `json:"amount"`
+	Currency        string                 `json:"currency"`
+	CategoryID      *uint                  `json:"category_id"`
+	LedgerID        *uint                  `json:"ledger_id"`
+	AccountID       *uint                  `json:"account_id"`
+	ToAccountID     *uint                  `json:"to_account_id"`
+	Description     string                 `json:"description"`
+	Merchant        string                 `json:"merchant"`
+	Notes           string                 `json:"notes"`
+	Tags            []string               `json:"tags"`
+	TransactionDate time.Time              `json:"transaction_date"`
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+func (r *Repository) Record(action models.RevisionAction, actor models.RevisionActor, before, after *models.Transaction) error {
+	return r.RecordChanges(action, actor, []Change{{Before: before, After: after}})
+}
+
+// RecordChanges appends one revision per change in a single insert
+func (r *Repository) RecordChanges(action models.RevisionAction, actor models.RevisionActor, changes []Change) error {
+	revisions := make([]models.TransactionRevision, 0, len(changes))
+	for _, change := range changes {
+		current := change.After
+		if current == nil {
+			current = change.Before
+		}
+		if current == nil {
+			continue
+		}
+
+		revisions = append(revisions, models.TransactionRevision{
+			TransactionID: current.ID,
+			UserID:        current.UserID,
+			Action:        action,
+			RevisionActor: actor,
+			Before:        snapshot(change.Before),
+			After:         snapshot(change.After),
+		})
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(r.ctx).Create(&revisions).Error
+}
+
+// GetRevisionsByTransactionID returns the history of a transaction, oldest first
+func (r *Repository) GetRevisionsByTransactionID(transactionID uint) ([]models.TransactionRevision, error) {
+	var revisions []models.TransactionRevision
+	if err := r.db.WithContext(r.ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at ASC, id ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *Repository) GetLatestRevisionID() (uint, error) {
+	var id uint
+	err := r.db.WithContext(r.ctx).Model(&models.TransactionRevision{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// GetChangesAfter lists the revisions recorded after afterID, oldest first, so a job can follow the
+// log of every write to transactions
+func (r *Repository) GetChangesAfter(afterID uint, limit int) ([]ChangedScope, error) {
+	var scopes []ChangedScope
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT id AS revision_id, user_id, CAST(after->>'ledger_id' AS bigint) AS ledger_id
+		FROM transaction_revisions
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?`, afterID, limit).Scan(&scopes).Error
+	return scopes, err
+}
+
+func snapshot(tx *models.Transaction) *json.RawMessage {
+	if tx == nil {
+		return nil
+	}
+
+	tags := make([]string, len(tx.Tags))
+	for i, tag := range tx.Tags {
+		tags[i] = tag.Name
+	}
+
+	data, err := json.Marshal(TransactionSnapshot{
+		Type:            tx.Type,
+		Amount:          tx.Amount,
+		Currency:        tx.Currency,
+		CategoryID:      tx.CategoryID,
+		LedgerID:        tx.LedgerID,
+		AccountID:       tx.AccountID,
+		ToAccountID:     tx.ToAccountID,
+		Description:     tx.Description,
+		Merchant:        tx.Merchant,
+		Notes:           tx.Notes,
+		Tags:            tags,
+		TransactionDate: tx.TransactionDate,
+	})
+	if err != nil {
+		return nil
+	}
+	raw := json.RawMessage(data)
+	return &raw
+}
@@ -0,0 +1,212 @@
+package split
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	CreateSplit(model models.Split, debts []models.Debt) (*models.Split, error)
+	GetSplitByID(id uint) (*models.Split, error)
+	GetSplitByTransactionID(transactionID uint) (*models.Split, error)
+	GetSplitsByUserID(userID uint, filters SplitFilters) ([]models.Split, int64, error)
+	DeleteSplitByTransactionID(transactionID uint) error
+	CreateDebt(model models.Debt) (*models.Debt, error)
+	GetDebtsBetween(userID, counterpartyID uint, limit int) ([]models.Debt, error)
+	GetBalances(userID uint) ([]BalanceData, error)
+	GetBalanceBetween(userID, counterpartyID uint) (types.Money, error)
+}
+
+type SplitFilters struct {
+	Page  int
+	Limit int
+}
+
+// BalanceData is the net position against one counterparty,
+// positive when the counterparty owes the user
+type BalanceData struct {
+	CounterpartyID uint
+	PhoneNumber    string
+	Name           string
+	Balance        types.Money
+}
+
+// signedDebt counts shares as owed and settlements as paid back
+const signedDebt = "CASE WHEN type = 'SHARE' THEN amount ELSE -amount END"
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// CreateSplit stores the split, its shares and the debts it creates in one transaction
+func (r *Repository) CreateSplit(model models.Split, debts []models.Debt) (*models.Split, error) {
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+
+		for i := range debts {
+			debts[i].SplitID = &model.ID
+		}
+		if len(debts) > 0 {
+			if err := tx.Create(&debts).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetSplitByID(id uint) (*models.Split, error) {
+	var split models.Split
+	if err := r.db.WithContext(r.ctx).
+		Preload("Transaction.Category").
+		Preload("Payer").
+		Preload("Shares.User").
+		Where("id = ?", id).First(&split).Error; err != nil {
+		return nil, err
+	}
+	return &split, nil
+}
+
+func (r *Repository) GetSplitByTransactionID(transactionID uint) (*models.Split, error) {
+	var split models.Split
+	err := r.db.WithContext(r.ctx).Preload("Shares.User").Where("transaction_id = ?", transactionID).First(&split).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &split, nil
+}
+
+func (r *Repository) GetSplitsByUserID(userID uint, filters SplitFilters) ([]models.Split, int64, error) {
+	var splits []models.Split
+	var count int64
+
+	query := r.db.WithContext(r.ctx).Model(&models.Split{}).
+		Where("payer_id = ? OR id IN (?)", userID,
+			r.db.Model(&models.SplitShare{}).Select("split_id").Where("user_id = ?", userID))
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filters.Page - 1) * filters.Limit
+	if err := query.
+		Preload("Transaction.Category").
+		Preload("Payer").
+		Preload("Shares.User").
+		Order("created_at DESC").
+		Offset(offset).Limit(filters.Limit).
+		Find(&splits).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return splits, count, nil
+}
+
+// DeleteSplitByTransactionID removes the split of a deleted transaction together with its debts
+func (r *Repository) DeleteSplitByTransactionID(transactionID uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		var split models.Split
+		if err := tx.Where("transaction_id = ?", transactionID).First(&split).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Where("split_id = ?", split.ID).Delete(&models.Debt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("split_id = ?", split.ID).Delete(&models.SplitShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&split).Error
+	})
+}
+
+func (r *Repository) CreateDebt(model models.Debt) (*models.Debt, error) {
+	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *Repository) GetDebtsBetween(userID, counterpartyID uint, limit int) ([]models.Debt, error) {
+	var debts []models.Debt
+	if err := r.db.WithContext(r.ctx).
+		Preload("Debtor").
+		Preload("Creditor").
+		Where("(debtor_id = ? AND creditor_id = ?) OR (debtor_id = ? AND creditor_id = ?)",
+			userID, counterpartyID, counterpartyID, userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&debts).Error; err != nil {
+		return nil, err
+	}
+	return debts, nil
+}
+
+func (r *Repository) GetBalances(userID uint) ([]BalanceData, error) {
+	var data []BalanceData
+
+	query := `
+		SELECT 
+			b.counterparty_id,
+			u.phone_number,
+			u.name,
+			SUM(b.amount) as balance
+		FROM (
+			SELECT debtor_id as counterparty_id, ` + signedDebt + ` as amount
+			FROM debts WHERE creditor_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT creditor_id as counterparty_id, -(` + signedDebt + `) as amount
+			FROM debts WHERE debtor_id = ? AND deleted_at IS NULL
+		) b
+		JOIN users u ON u.id = b.counterparty_id
+		GROUP BY b.counterparty_id, u.phone_number, u.name
+		HAVING ROUND(SUM(b.amount), 2) <> 0
+		ORDER BY balance DESC`
+
+	err := r.db.WithContext(r.ctx).Raw(query, userID, userID).Scan(&data).Error
+	return data, err
+}
+
+func (r *Repository) GetBalanceBetween(userID, counterpartyID uint) (types.Money, error) {
+	var balance types.Money
+
+	query := `
+		SELECT COALESCE(SUM(amount), 0) FROM (
+			SELECT ` + signedDebt + ` as amount
+			FROM debts WHERE creditor_id = ? AND debtor_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT -(` + signedDebt + `) as amount
+			FROM debts WHERE debtor_id = ? AND creditor_id = ? AND deleted_at IS NULL
+		) b`
+
+	err := r.db.WithContext(r.ctx).Raw(query, userID, counterpartyID, userID, counterpartyID).Scan(&balance).Error
+	return balance, err
+}
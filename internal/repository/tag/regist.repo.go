@@ -0,0 +1,91 @@
+package tag
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/redis"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	GetOrCreateTags(userID uint, names []string) ([]models.Tag, error)
+	GetTagsByUserID(userID uint) ([]TagUsageData, error)
+	ReplaceTransactionTags(transactionID uint, tags []models.Tag) error
+	AddTransactionTags(transactionID uint, tags []models.Tag) error
+}
+
+// TagUsageData is a tag with the number of live transactions carrying it
+type TagUsageData struct {
+	ID               uint
+	Name             string
+	TransactionCount int64
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// GetOrCreateTags returns the user's tags with the given normalized names, creating missing ones
+func (r *Repository) GetOrCreateTags(userID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	missing := make([]models.Tag, len(names))
+	for i, name := range names {
+		missing[i] = models.Tag{UserID: userID, Name: name}
+	}
+	if err := r.db.WithContext(r.ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return nil, err
+	}
+
+	err := r.db.WithContext(r.ctx).
+		Where("user_id = ? AND name IN ?", userID, names).
+		Order("name ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *Repository) GetTagsByUserID(userID uint) ([]TagUsageData, error) {
+	var data []TagUsageData
+	err := r.db.WithContext(r.ctx).Raw(`
+		SELECT tg.id, tg.name, COUNT(t.id) as transaction_count
+		FROM tags tg
+		LEFT JOIN transaction_tags tt ON tt.tag_id = tg.id
+		LEFT JOIN transactions t ON t.id = tt.transaction_id AND t.deleted_at IS NULL
+		WHERE tg.user_id = ? AND tg.deleted_at IS NULL
+		GROUP BY tg.id, tg.name
+		ORDER BY transaction_count DESC, tg.name ASC`, userID).Scan(&data).Error
+	return data, err
+}
+
+// ReplaceTransactionTags sets exactly these tags on the transaction, an empty list clears them
+func (r *Repository) ReplaceTransactionTags(transactionID uint, tags []models.Tag) error {
+	transaction := models.Transaction{}
+	transaction.ID = transactionID
+	return r.db.WithContext(r.ctx).Model(&transaction).Association("Tags").Replace(tags)
+}
+
+// AddTransactionTags adds tags to the transaction, keeping the ones it already has
+func (r *Repository) AddTransactionTags(transactionID uint, tags []models.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	transaction := models.Transaction{}
+	transaction.ID = transactionID
+	return r.db.WithContext(r.ctx).Model(&transaction).Association("Tags").Append(tags)
+}
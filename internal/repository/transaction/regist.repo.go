@@ -2,11 +2,22 @@ package transaction
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository/bill"
+	exchangerate "pannypal/internal/repository/exchange-rate"
+	"pannypal/internal/repository/loan"
+	"strconv"
+	"strings"
 	"time"
 
 	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
@@ -19,21 +30,97 @@ type IRepository interface {
 	CreateTransaction(model models.Transaction) (*models.Transaction, error)
 	UpdateTransaction(model models.Transaction) (*models.Transaction, error)
 	GetTransactionByID(id uint) (*models.Transaction, error)
+	GetLatestTransactionByUserID(userID uint) (*models.Transaction, error)
 	GetTransactionsByUserID(userID *uint, filters TransactionFilters) ([]models.Transaction, int64, error)
+	SearchTransactions(userID *uint, filters SearchFilters) ([]models.Transaction, int64, error)
 	DeleteTransaction(id uint) error
 	GetTransactionsSummary(userID *uint, filters SummaryFilters) (*TransactionSummary, error)
+	GetTransactionsForBulk(userID uint, selection BulkSelection) ([]models.Transaction, error)
+	BulkUpdateCategory(ids []uint, categoryID uint) error
+	BulkUpdateTags(ids []uint, add []models.Tag, removeTagIDs []uint) error
+	BulkDeleteTransactions(ids []uint) error
+	BulkRestoreTransactions(ids []uint) error
+	FindDuplicates(userID uint, probe DuplicateProbe, limit int) ([]DuplicateMatch, error)
+	FindDuplicatePairs(userID uint, filters DuplicateScanFilters) ([]DuplicatePair, error)
+	MergeTransactions(keep models.Transaction, duplicateIDs []uint) error
 }
 
 type TransactionFilters struct {
 	Type       *models.TransactionType
 	CategoryID *uint
+	LedgerID   *uint
+	AccountID  *uint    // Matches both sides of a transfer
+	Tags       []string // Normalized tag names, a transaction must carry all of them
 	StartDate  *time.Time
 	EndDate    *time.Time
 	Page       int
 	Limit      int
 }
 
+type SearchFilters struct {
+	Query     string // Free text, matched with full-text search and trigram similarity
+	Type      *models.TransactionType
+	LedgerID  *uint
+	Tags      []string     // Normalized tag names, a transaction must carry all of them
+	MinAmount *types.Money // In the owner's base currency
+	MaxAmount *types.Money
+	Page      int
+	Limit     int
+}
+
+// BulkSelection picks the transactions of a bulk operation, by IDs or by filter
+type BulkSelection struct {
+	IDs     []uint
+	Filters *TransactionFilters // Used when IDs is empty, pagination fields are ignored
+	Deleted bool                // Select soft-deleted transactions instead of live ones, for restoring
+	Limit   int
+}
+
+// DuplicateProbe is a transaction, saved or still a draft, to look for duplicates of
+type DuplicateProbe struct {
+	ExcludeID       uint // The probe itself when it is saved
+	Type            models.TransactionType
+	Amount          types.Money
+	Currency        string
+	Text            string // Merchant and description
+	AccountID       *uint
+	TransactionDate time.Time
+	MinScore        float64
+}
+
+type DuplicateScanFilters struct {
+	StartDate time.Time
+	EndDate   time.Time
+	MinScore  float64
+	Limit     int
+}
+
+// DuplicateScore is how alike two transactions are, each part is between 0 and 1
+type DuplicateScore struct {
+	Score        float64
+	AmountScore  float64
+	DateScore    float64
+	TextScore    float64
+	AccountScore float64
+}
+
+type DuplicateMatch struct {
+	DuplicateScore
+	Transaction models.Transaction
+}
+
+// DuplicatePair is two saved transactions that are likely the same, FirstID is the lower ID
+type DuplicatePair struct {
+	DuplicateScore
+	FirstID  uint
+	SecondID uint
+}
+
+// SummaryFilters narrows a summary. A ledger summary holds the transactions of several members, with
+// ViewerID they are totalled in that user's base currency instead of each owner's.
 type SummaryFilters struct {
+	LedgerID  *uint
+	ViewerID  *uint
 	StartDate *time.Time
 	EndDate   *time.Time
 	Month     *int
@@ -41,8 +128,8 @@ type SummaryFilters struct {
 }
 
 type TransactionSummary struct {
-	TotalIncome      float64
-	TotalExpense     float64
+	TotalIncome      types.Money
+	TotalExpense     types.Money
 	TransactionCount int64
 	IncomeCount      int64
 	ExpenseCount     int64
@@ -53,7 +140,7 @@ type CategorySummaryData struct {
 	CategoryID   uint
 	CategoryName string
 	Type         models.TransactionType
-	TotalAmount  float64
+	TotalAmount  types.Money
 	Count        int64
 }
 
@@ -66,21 +153,59 @@ func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRe
 }
 
 func (r *Repository) CreateTransaction(model models.Transaction) (*models.Transaction, error) {
+	if model.Currency == "" {
+		currency, err := r.defaultCurrency(model)
+		if err != nil {
+			return nil, err
+		}
+		model.Currency = currency
+	}
+
 	if err := r.db.WithContext(r.ctx).Create(&model).Error; err != nil {
 		return nil, err
 	}
 	return &model, nil
 }
 
+// defaultCurrency is the currency of the transaction's account, otherwise the owner's base currency
+func (r *Repository) defaultCurrency(model models.Transaction) (string, error) {
+	var currencies []string
+	var err error
+	if model.AccountID != nil {
+		err = r.db.WithContext(r.ctx).Model(&models.Account{}).Where("id = ?", *model.AccountID).Pluck("currency", &currencies).Error
+	} else {
+		err = r.db.WithContext(r.ctx).Model(&models.User{}).Where("id = ?", model.UserID).Pluck("base_currency", &currencies).Error
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(currencies) == 0 || currencies[0] == "" {
+		return models.DefaultCurrency, nil
+	}
+	return currencies[0], nil
+}
+
+// UpdateTransaction saves the transaction columns only, tags and attachments have their own repositories
 func (r *Repository) UpdateTransaction(model models.Transaction) (*models.Transaction, error) {
-	if err := r.db.WithContext(r.ctx).Save(&model).Error; err != nil {
+	if err := r.db.WithContext(r.ctx).Omit(clause.Associations).Save(&model).Error; err != nil {
 		return nil, err
 	}
 	return &model, nil
 }
 func (r *Repository) GetTransactionByID(id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	if err := r.db.WithContext(r.ctx).Preload("Category").Where("id = ?", id).First(&transaction).Error; err != nil {
+	if err := r.db.WithContext(r.ctx).Preload("Category").Preload("Tags").Preload("Attachments").
+		Where("id = ?", id).First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *Repository) GetLatestTransactionByUserID(userID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.WithContext(r.ctx).Preload("Category").Preload("Tags").Where("user_id = ?", userID).
+		Order("created_at DESC").First(&transaction).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -95,29 +220,117 @@ func (r *Repository) GetTransactionsByUserID(userID *uint, filters TransactionFi
 	if userID != nil {
 		query = query.Where("user_id = ?", *userID)
 	}
+	query = applyTransactionFilters(query, filters)
 
-	// Apply filters
+	// Count total records
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination and preload
+	offset := (filters.Page - 1) * filters.Limit
+	if err := query.Preload("Category").Preload("Tags").Order("transaction_date DESC").
+		Offset(offset).Limit(filters.Limit).Find(&transactions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, count, nil
+}
+
+// applyTransactionFilters adds the conditions of the filters, pagination is left to the caller
+func applyTransactionFilters(query *gorm.DB, filters TransactionFilters) *gorm.DB {
 	if filters.Type != nil {
 		query = query.Where("type = ?", *filters.Type)
 	}
 	if filters.CategoryID != nil {
 		query = query.Where("category_id = ?", *filters.CategoryID)
 	}
+	if filters.LedgerID != nil {
+		query = query.Where("ledger_id = ?", *filters.LedgerID)
+	}
+	if filters.AccountID != nil {
+		query = query.Where("(account_id = ? OR to_account_id = ?)", *filters.AccountID, *filters.AccountID)
+	}
+	if len(filters.Tags) > 0 {
+		query = query.Where(`id IN (
+			SELECT tt.transaction_id FROM transaction_tags tt
+			JOIN tags tg ON tg.id = tt.tag_id AND tg.deleted_at IS NULL
+			WHERE tg.name IN ?
+			GROUP BY tt.transaction_id
+			HAVING COUNT(DISTINCT tg.name) = ?)`, filters.Tags, len(filters.Tags))
+	}
 	if filters.StartDate != nil {
 		query = query.Where("transaction_date >= ?", *filters.StartDate)
 	}
 	if filters.EndDate != nil {
 		query = query.Where("transaction_date <= ?", *filters.EndDate)
 	}
+	return query
+}
+
+// SearchTransactions finds transactions whose description, merchant, notes or tags match the query,
+// best matches first. Full-text hits rank above fuzzy ones, the trigram word similarity lets
+// "kopii" or "kenangn" still find "Kopi Kenangan".
+func (r *Repository) SearchTransactions(userID *uint, filters SearchFilters) ([]models.Transaction, int64, error) {
+	var transactions []models.Transaction
+	var count int64
+
+	query := r.db.WithContext(r.ctx).Model(&models.Transaction{})
+
+	if userID != nil {
+		query = query.Where("transactions.user_id = ?", *userID)
+	}
+	if filters.LedgerID != nil {
+		query = query.Where("transactions.ledger_id = ?", *filters.LedgerID)
+	}
+	if filters.Type != nil {
+		query = query.Where("transactions.type = ?", *filters.Type)
+	}
+	if len(filters.Tags) > 0 {
+		query = query.Where(`transactions.id IN (
+			SELECT tt.transaction_id FROM transaction_tags tt
+			JOIN tags tg ON tg.id = tt.tag_id AND tg.deleted_at IS NULL
+			WHERE tg.name IN ?
+			GROUP BY tt.transaction_id
+			HAVING COUNT(DISTINCT tg.name) = ?)`, filters.Tags, len(filters.Tags))
+	}
+	if filters.MinAmount != nil {
+		query = query.Where(exchangerate.AmountInBaseSQL("transactions")+" >= ?", *filters.MinAmount)
+	}
+	if filters.MaxAmount != nil {
+		query = query.Where(exchangerate.AmountInBaseSQL("transactions")+" <= ?", *filters.MaxAmount)
+	}
+
+	text := strings.ToLower(strings.TrimSpace(filters.Query))
+	if text != "" {
+		query = query.Where(`(`+database.TransactionSearchDocument+` @@ plainto_tsquery('simple', @q)
+			OR @q <% transactions.description
+			OR @q <% transactions.merchant
+			OR @q <% transactions.notes
+			OR EXISTS (
+				SELECT 1 FROM transaction_tags tt
+				JOIN tags tg ON tg.id = tt.tag_id AND tg.deleted_at IS NULL
+				WHERE tt.transaction_id = transactions.id AND @q <% tg.name))`, sql.Named("q", text))
+	}
 
-	// Count total records
 	if err := query.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination and preload
+	order := "transactions.transaction_date DESC"
+	if text != "" {
+		query = query.Select(`transactions.*, (
+			ts_rank(`+database.TransactionSearchDocument+`, plainto_tsquery('simple', @q)) * 2
+			+ GREATEST(
+				word_similarity(@q, coalesce(transactions.description, '')),
+				word_similarity(@q, coalesce(transactions.merchant, '')),
+				word_similarity(@q, coalesce(transactions.notes, '')) * 0.5)
+		) AS search_rank`, sql.Named("q", text))
+		order = "search_rank DESC, " + order
+	}
+
 	offset := (filters.Page - 1) * filters.Limit
-	if err := query.Preload("Category").Order("transaction_date DESC").
+	if err := query.Preload("Category").Preload("Tags").Order(order).
 		Offset(offset).Limit(filters.Limit).Find(&transactions).Error; err != nil {
 		return nil, 0, err
 	}
@@ -125,73 +338,96 @@ func (r *Repository) GetTransactionsByUserID(userID *uint, filters TransactionFi
 	return transactions, count, nil
 }
 
+// DeleteTransaction soft deletes the transaction, a loan installment or bill due date it paid is unpaid again
 func (r *Repository) DeleteTransaction(id uint) error {
-	if err := r.db.WithContext(r.ctx).Delete(&models.Transaction{}, id).Error; err != nil {
-		return err
-	}
-	return nil
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loan.UnpayInstallments(tx, []uint{id}); err != nil {
+			return err
+		}
+		if err := bill.ClearPayments(tx, []uint{id}); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Transaction{}, id).Error
+	})
 }
 
 func (r *Repository) GetTransactionsSummary(userID *uint, filters SummaryFilters) (*TransactionSummary, error) {
-	query := r.db.WithContext(r.ctx).Model(&models.Transaction{})
+	// Each aggregate needs its own statement, otherwise the type conditions
+	// pile up on the shared query and the later totals come back empty.
+	baseQuery := func() *gorm.DB {
+		// Transfers only move money between accounts, they are neither income nor expense
+		query := r.db.WithContext(r.ctx).Model(&models.Transaction{}).
+			Where("transactions.type <> ?", models.TypeTransfer)
 
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
-	}
+		if userID != nil {
+			query = query.Where("transactions.user_id = ?", *userID)
+		}
+		if filters.LedgerID != nil {
+			query = query.Where("transactions.ledger_id = ?", *filters.LedgerID)
+		}
 
-	// Apply date filters
-	if filters.StartDate != nil {
-		query = query.Where("transaction_date >= ?", *filters.StartDate)
+		// Apply date filters
+		if filters.StartDate != nil {
+			query = query.Where("transactions.transaction_date >= ?", *filters.StartDate)
+		}
+		if filters.EndDate != nil {
+			query = query.Where("transactions.transaction_date <= ?", *filters.EndDate)
+		}
+		if filters.Month != nil && filters.Year != nil {
+			query = query.Where("EXTRACT(MONTH FROM transactions.transaction_date) = ? AND EXTRACT(YEAR FROM transactions.transaction_date) = ?",
+				*filters.Month, *filters.Year)
+		}
+		return query
 	}
-	if filters.EndDate != nil {
-		query = query.Where("transaction_date <= ?", *filters.EndDate)
-	}
-	if filters.Month != nil && filters.Year != nil {
-		query = query.Where("EXTRACT(MONTH FROM transaction_date) = ? AND EXTRACT(YEAR FROM transaction_date) = ?",
-			*filters.Month, *filters.Year)
+
+	// Amounts in other currencies are converted into the owner's base currency, or the viewer's
+	amountInBase := exchangerate.AmountInBaseSQL("transactions")
+	if filters.ViewerID != nil {
+		amountInBase = exchangerate.AmountInUserBaseSQL("transactions", strconv.FormatUint(uint64(*filters.ViewerID), 10))
 	}
 
 	var summary TransactionSummary
 
 	// Get total income
-	var totalIncome float64
-	if err := query.Where("type = ?", models.TypeIncome).Select("COALESCE(SUM(amount), 0)").
+	var totalIncome types.Money
+	if err := baseQuery().Where("transactions.type = ?", models.TypeIncome).Select("COALESCE(SUM(" + amountInBase + "), 0)").
 		Scan(&totalIncome).Error; err != nil {
 		return nil, err
 	}
 	summary.TotalIncome = totalIncome
 
 	// Get total expense
-	var totalExpense float64
-	if err := query.Where("type = ?", models.TypeExpense).Select("COALESCE(SUM(amount), 0)").
+	var totalExpense types.Money
+	if err := baseQuery().Where("transactions.type = ?", models.TypeExpense).Select("COALESCE(SUM(" + amountInBase + "), 0)").
 		Scan(&totalExpense).Error; err != nil {
 		return nil, err
 	}
 	summary.TotalExpense = totalExpense
 
 	// Get counts
-	if err := query.Count(&summary.TransactionCount).Error; err != nil {
+	if err := baseQuery().Count(&summary.TransactionCount).Error; err != nil {
 		return nil, err
 	}
 
-	if err := query.Where("type = ?", models.TypeIncome).Count(&summary.IncomeCount).Error; err != nil {
+	if err := baseQuery().Where("transactions.type = ?", models.TypeIncome).Count(&summary.IncomeCount).Error; err != nil {
 		return nil, err
 	}
 
-	if err := query.Where("type = ?", models.TypeExpense).Count(&summary.ExpenseCount).Error; err != nil {
+	if err := baseQuery().Where("transactions.type = ?", models.TypeExpense).Count(&summary.ExpenseCount).Error; err != nil {
 		return nil, err
 	}
 
 	// Get category summary
 	var categorySummary []CategorySummaryData
-	if err := query.Select(`
-		t.category_id,
-		c.name as category_name,
-		t.type,
-		COALESCE(SUM(t.amount), 0) as total_amount,
+	if err := baseQuery().Select(`
+		transactions.category_id,
+		categories.name as category_name,
+		transactions.type,
+		COALESCE(SUM(` + amountInBase + `), 0) as total_amount,
 		COUNT(*) as count
-	`).Joins("JOIN categories c ON t.category_id = c.id").
-		Group("t.category_id, c.name, t.type").
+	`).Joins("JOIN categories ON transactions.category_id = categories.id").
+		Group("transactions.category_id, categories.name, transactions.type").
+		Order("total_amount DESC").
 		Scan(&categorySummary).Error; err != nil {
 		return nil, err
 	}
@@ -199,3 +435,297 @@ func (r *Repository) GetTransactionsSummary(userID *uint, filters SummaryFilters
 
 	return &summary, nil
 }
+
+// GetTransactionsForBulk loads the user's transactions picked by the selection, newest first
+func (r *Repository) GetTransactionsForBulk(userID uint, selection BulkSelection) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+
+	query := r.db.WithContext(r.ctx).Model(&models.Transaction{})
+	if selection.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	query = query.Where("user_id = ?", userID)
+
+	if len(selection.IDs) > 0 {
+		query = query.Where("id IN ?", selection.IDs)
+	} else if selection.Filters != nil {
+		query = applyTransactionFilters(query, *selection.Filters)
+	}
+	if selection.Limit > 0 {
+		query = query.Limit(selection.Limit)
+	}
+
+	err := query.Preload("Category").Preload("Tags").Order("transaction_date DESC").Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *Repository) BulkUpdateCategory(ids []uint, categoryID uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&models.Transaction{}).Where("id IN ?", ids).Update("category_id", categoryID).Error
+	})
+}
+
+// BulkUpdateTags adds and removes tags on all the transactions at once
+func (r *Repository) BulkUpdateTags(ids []uint, add []models.Tag, removeTagIDs []uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if len(removeTagIDs) > 0 {
+			if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN ? AND tag_id IN ?", ids, removeTagIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(add) > 0 {
+			rows := make([]map[string]interface{}, 0, len(ids)*len(add))
+			for _, id := range ids {
+				for _, tag := range add {
+					rows = append(rows, map[string]interface{}{"transaction_id": id, "tag_id": tag.ID})
+				}
+			}
+			if err := tx.Table("transaction_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Transaction{}).Where("id IN ?", ids).Update("updated_at", time.Now()).Error
+	})
+}
+
+// BulkDeleteTransactions soft deletes the transactions together with their splits and the debts they created,
+// loan installments and bill due dates they paid are unpaid again
+func (r *Repository) BulkDeleteTransactions(ids []uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loan.UnpayInstallments(tx, ids); err != nil {
+			return err
+		}
+		if err := bill.ClearPayments(tx, ids); err != nil {
+			return err
+		}
+		splitIDs := tx.Model(&models.Split{}).Select("id").Where("transaction_id IN ?", ids)
+
+		if err := tx.Where("split_id IN (?)", splitIDs).Delete(&models.Debt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("split_id IN (?)", splitIDs).Delete(&models.SplitShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id IN ?", ids).Delete(&models.Split{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.Transaction{}).Error
+	})
+}
+
+// BulkRestoreTransactions brings soft-deleted transactions back, splits removed with them stay removed
+// and transactions whose category is still deleted come back uncategorized
+func (r *Repository) BulkRestoreTransactions(ids []uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Transaction{}).Where("id IN ?", ids).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		deletedCategories := tx.Unscoped().Model(&models.Category{}).Select("id").Where("deleted_at IS NOT NULL")
+		return tx.Model(&models.Transaction{}).
+			Where("id IN ? AND category_id IN (?)", ids, deletedCategories).
+			Update("category_id", nil).Error
+	})
+}
+
+// Duplicates are looked for among transactions of the same type and currency whose amounts are
+// within duplicateAmountTolerance of each other and whose dates are at most duplicateWindow apart
+const (
+	duplicateAmountTolerance = 0.05
+	duplicateWindow          = "3 days"
+	duplicateWindowSeconds   = 3 * 24 * 60 * 60
+)
+
+// DefaultDuplicateScore is the score from which two transactions are reported as likely duplicates,
+// the same amount on the same day with nothing else in common just reaches it
+const DefaultDuplicateScore = 0.7
+
+// duplicatePartsSQL scores candidate c against the other transaction's amount, date, text and account.
+// OCR and bank statements rarely agree on the wording, so the amount and date weigh the most.
+func duplicatePartsSQL(amount, date, text, account string) string {
+	return `CASE WHEN c.amount = ` + amount + ` THEN 1
+			ELSE GREATEST(0, 1 - ABS(c.amount - ` + amount + `) / NULLIF(` + amount + ` * ` + fmt.Sprint(duplicateAmountTolerance) + `, 0)) END AS amount_score,
+		GREATEST(0, 1 - ABS(EXTRACT(EPOCH FROM (c.transaction_date - ` + date + `))) / ` + fmt.Sprint(duplicateWindowSeconds) + `) AS date_score,
+		similarity(lower(concat_ws(' ', NULLIF(c.merchant, ''), c.description)), lower(` + text + `)) AS text_score,
+		CASE WHEN c.account_id = ` + account + ` THEN 1
+			WHEN c.account_id IS NULL OR ` + account + ` IS NULL THEN 0.5 ELSE 0 END AS account_score`
+}
+
+const duplicateScoreSQL = `(amount_score * 0.4 + date_score * 0.25 + text_score * 0.25 + account_score * 0.1)`
+
+// FindDuplicates returns the user's saved transactions that look like the probe, most alike first
+func (r *Repository) FindDuplicates(userID uint, probe DuplicateProbe, limit int) ([]DuplicateMatch, error) {
+	var scores []struct {
+		ID uint
+		DuplicateScore
+	}
+
+	currency := probe.Currency
+	if currency == "" {
+		var currencies []string
+		if err := r.db.WithContext(r.ctx).Model(&models.User{}).Where("id = ?", userID).
+			Pluck("base_currency", &currencies).Error; err != nil {
+			return nil, err
+		}
+		currency = models.DefaultCurrency
+		if len(currencies) > 0 && currencies[0] != "" {
+			currency = currencies[0]
+		}
+	}
+
+	err := r.db.WithContext(r.ctx).Raw(`SELECT s.*, `+duplicateScoreSQL+` AS score FROM (
+			SELECT c.id, `+duplicatePartsSQL("CAST(@amount AS numeric)", "CAST(@date AS timestamptz)", "CAST(@text AS text)", "CAST(@account AS bigint)")+`
+			FROM transactions c
+			WHERE c.user_id = @user AND c.deleted_at IS NULL AND c.id <> @exclude
+				AND c.type = @type AND upper(c.currency) = upper(@currency)
+				AND c.amount BETWEEN CAST(@amount AS numeric) * @low AND CAST(@amount AS numeric) * @high
+				AND c.transaction_date BETWEEN CAST(@date AS timestamptz) - interval '`+duplicateWindow+`'
+					AND CAST(@date AS timestamptz) + interval '`+duplicateWindow+`'
+		) s
+		WHERE `+duplicateScoreSQL+` >= @min
+		ORDER BY score DESC
+		LIMIT @limit`,
+		sql.Named("user", userID),
+		sql.Named("exclude", probe.ExcludeID),
+		sql.Named("type", probe.Type),
+		sql.Named("currency", currency),
+		sql.Named("amount", probe.Amount),
+		sql.Named("low", 1-duplicateAmountTolerance),
+		sql.Named("high", 1+duplicateAmountTolerance),
+		sql.Named("date", probe.TransactionDate),
+		sql.Named("text", probe.Text),
+		sql.Named("account", probe.AccountID),
+		sql.Named("min", probe.MinScore),
+		sql.Named("limit", limit),
+	).Scan(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ID
+	}
+	var transactions []models.Transaction
+	if err := r.db.WithContext(r.ctx).Preload("Category").Preload("Tags").
+		Where("id IN ?", ids).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	byID := map[uint]models.Transaction{}
+	for _, tx := range transactions {
+		byID[tx.ID] = tx
+	}
+
+	matches := make([]DuplicateMatch, 0, len(scores))
+	for _, score := range scores {
+		if tx, ok := byID[score.ID]; ok {
+			matches = append(matches, DuplicateMatch{DuplicateScore: score.DuplicateScore, Transaction: tx})
+		}
+	}
+	return matches, nil
+}
+
+// FindDuplicatePairs scores every pair of the user's transactions dated within the range that
+// could be duplicates of each other, most alike first
+func (r *Repository) FindDuplicatePairs(userID uint, filters DuplicateScanFilters) ([]DuplicatePair, error) {
+	var pairs []DuplicatePair
+
+	err := r.db.WithContext(r.ctx).Raw(`SELECT s.*, `+duplicateScoreSQL+` AS score FROM (
+			SELECT o.id AS first_id, c.id AS second_id, `+duplicatePartsSQL("o.amount", "o.transaction_date", "concat_ws(' ', NULLIF(o.merchant, ''), o.description)", "o.account_id")+`
+			FROM transactions o
+			JOIN transactions c ON c.user_id = o.user_id AND c.id > o.id AND c.deleted_at IS NULL
+				AND c.type = o.type AND upper(c.currency) = upper(o.currency)
+				AND c.amount BETWEEN o.amount * @low AND o.amount * @high
+				AND c.transaction_date BETWEEN o.transaction_date - interval '`+duplicateWindow+`'
+					AND o.transaction_date + interval '`+duplicateWindow+`'
+			WHERE o.user_id = @user AND o.deleted_at IS NULL
+				AND o.transaction_date BETWEEN @start AND @end
+		) s
+		WHERE `+duplicateScoreSQL+` >= @min
+		ORDER BY score DESC
+		LIMIT @limit`,
+		sql.Named("user", userID),
+		sql.Named("low", 1-duplicateAmountTolerance),
+		sql.Named("high", 1+duplicateAmountTolerance),
+		sql.Named("start", filters.StartDate),
+		sql.Named("end", filters.EndDate),
+		sql.Named("min", filters.MinScore),
+		sql.Named("limit", filters.Limit),
+	).Scan(&pairs).Error
+	return pairs, err
+}
+
+// MergeTransactions folds duplicates into the kept transaction: their tags and attachments move over,
+// the kept transaction fills in the merchant, account, category and notes it is missing and the
+// duplicates are soft deleted together with their splits
+func (r *Repository) MergeTransactions(keep models.Transaction, duplicateIDs []uint) error {
+	return r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		var duplicates []models.Transaction
+		if err := tx.Where("id IN ?", duplicateIDs).Order("id").Find(&duplicates).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		notes := []string{}
+		if keep.Notes != "" {
+			notes = append(notes, keep.Notes)
+		}
+		for _, duplicate := range duplicates {
+			if keep.Merchant == "" && duplicate.Merchant != "" {
+				keep.Merchant = duplicate.Merchant
+				updates["merchant"] = duplicate.Merchant
+			}
+			if keep.AccountID == nil && duplicate.AccountID != nil {
+				keep.AccountID = duplicate.AccountID
+				updates["account_id"] = *duplicate.AccountID
+			}
+			if keep.CategoryID == nil && duplicate.CategoryID != nil {
+				keep.CategoryID = duplicate.CategoryID
+				updates["category_id"] = *duplicate.CategoryID
+			}
+			if duplicate.Notes != "" && !strings.Contains(strings.Join(notes, "\n"), duplicate.Notes) {
+				notes = append(notes, duplicate.Notes)
+			}
+		}
+		if merged := strings.Join(notes, "\n"); merged != keep.Notes {
+			updates["notes"] = merged
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", keep.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec(`INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT DISTINCT CAST(? AS bigint), tag_id FROM transaction_tags WHERE transaction_id IN ?
+			ON CONFLICT DO NOTHING`, keep.ID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Attachment{}).Where("transaction_id IN ?", duplicateIDs).
+			Update("transaction_id", keep.ID).Error; err != nil {
+			return err
+		}
+
+		splitIDs := tx.Model(&models.Split{}).Select("id").Where("transaction_id IN ?", duplicateIDs)
+		if err := tx.Where("split_id IN (?)", splitIDs).Delete(&models.Debt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("split_id IN (?)", splitIDs).Delete(&models.SplitShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id IN ?", duplicateIDs).Delete(&models.Split{}).Error; err != nil {
+			return err
+		}
+		if err := loan.UnpayInstallments(tx, duplicateIDs); err != nil {
+			return err
+		}
+		if err := bill.ClearPayments(tx, duplicateIDs); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", duplicateIDs).Delete(&models.Transaction{}).Error
+	})
+}
@@ -0,0 +1,508 @@
+package trash
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository/bill"
+	"pannypal/internal/repository/loan"
+	"time"
+
+	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchSize bounds how many transactions one purge statement removes
+const purgeBatchSize = 500
+
+type Repository struct {
+	ctx   context.Context
+	redis redis.IRedis
+	db    *database.Database
+}
+
+type IRepository interface {
+	GetDeletedTransactions(userID uint, page, limit int) ([]models.Transaction, int64, error)
+	GetDeletedBudgets(userID uint) ([]models.Budget, error)
+	GetDeletedCategories(userID *uint) ([]models.Category, error)
+	GetDeletedTransactionByID(id uint) (*models.Transaction, error)
+	GetDeletedBudgetByID(id uint) (*models.Budget, error)
+	GetDeletedCategoryByID(id uint) (*models.Category, error)
+	RestoreTransaction(id uint, categoryDeleted bool) error
+	RestoreBudget(id uint) error
+	RestoreCategory(category models.Category) (*RestoreResult, error)
+	DeleteCategory(category models.Category, rule CategoryDeleteRule) (*CategoryDeleteResult, error)
+	Purge(before time.Time) (*PurgeResult, error)
+}
+
+type CategoryTransactionAction string
+
+const (
+	CategoryUncategorize CategoryTransactionAction = "uncategorize" // Transactions stay, without a category
+	CategoryReassign     CategoryTransactionAction = "reassign"     // Transactions move to another category
+	CategoryDeleteAll    CategoryTransactionAction = "delete"       // Transactions go to the trash with the category
+)
+
+// CategoryDeleteRule says what happens to the transactions and recurring rules of a deleted category,
+// its budgets always go to the trash with it
+type CategoryDeleteRule struct {
+	Action     CategoryTransactionAction
+	ReassignTo *uint // Target category of CategoryReassign
+}
+
+// CategoryDeleteResult holds the category's transactions as they were before the delete.
+// EnvelopeReturned is the money the category's envelope held, given back to ready to assign.
+type CategoryDeleteResult struct {
+	Transactions     []models.Transaction
+	Budgets          int64
+	RecurringRules   int64
+	EnvelopeReturned types.Money
+}
+
+// RestoreResult holds the restored transactions as they are now
+type RestoreResult struct {
+	Transactions []models.Transaction
+	Budgets      int64
+}
+
+// PurgeResult counts what was removed for good, FileKeys are the attachment files left to delete from storage
+type PurgeResult struct {
+	Transactions int64
+	Budgets      int64
+	Categories   int64
+	FileKeys     []string
+}
+
+func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRepository {
+	return &Repository{
+		ctx:   ctx,
+		redis: redis,
+		db:    db,
+	}
+}
+
+// unscopedCategory loads the category even when it is in the trash too
+func unscopedCategory(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r *Repository) GetDeletedTransactions(userID uint, page, limit int) ([]models.Transaction, int64, error) {
+	var transactions []models.Transaction
+	var total int64
+
+	query := r.db.WithContext(r.ctx).Unscoped().Model(&models.Transaction{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page > 0 && limit > 0 {
+		query = query.Offset((page - 1) * limit).Limit(limit)
+	}
+
+	if err := query.Preload("Category", unscopedCategory).
+		Order("deleted_at DESC").
+		Find(&transactions).Error; err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+func (r *Repository) GetDeletedBudgets(userID uint) ([]models.Budget, error) {
+	var budgets []models.Budget
+	if err := r.db.WithContext(r.ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Preload("Category", unscopedCategory).
+		Order("deleted_at DESC").
+		Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// GetDeletedCategories returns a user's deleted categories, or the template's when userID is nil
+func (r *Repository) GetDeletedCategories(userID *uint) ([]models.Category, error) {
+	var categories []models.Category
+	query := r.db.WithContext(r.ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	} else {
+		query = query.Where("user_id IS NULL")
+	}
+	if err := query.
+		Preload("Parent", unscopedCategory).
+		Order("deleted_at DESC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *Repository) GetDeletedTransactionByID(id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.WithContext(r.ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Preload("Category", unscopedCategory).
+		First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *Repository) GetDeletedBudgetByID(id uint) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.db.WithContext(r.ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Preload("Category", unscopedCategory).
+		First(&budget).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *Repository) GetDeletedCategoryByID(id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(r.ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Preload("Parent", unscopedCategory).
+		First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// RestoreTransaction brings a transaction back, it loses its category when that category is still in the trash
+func (r *Repository) RestoreTransaction(id uint, categoryDeleted bool) error {
+	updates := map[string]interface{}{"deleted_at": nil}
+	if categoryDeleted {
+		updates["category_id"] = nil
+	}
+	return r.db.WithContext(r.ctx).Unscoped().Model(&models.Transaction{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *Repository) RestoreBudget(id uint) error {
+	return r.db.WithContext(r.ctx).Unscoped().Model(&models.Budget{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+// RestoreCategory brings the category back together with the budgets and transactions
+// that went to the trash with it, they share its deletion time
+func (r *Repository) RestoreCategory(category models.Category) (*RestoreResult, error) {
+	result := &RestoreResult{}
+	deletedAt := category.DeletedAt.Time
+
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Category{}).
+			Where("id = ?", category.ID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		budgets := tx.Unscoped().Model(&models.Budget{}).
+			Where("category_id = ? AND deleted_at = ?", category.ID, deletedAt).
+			Update("deleted_at", nil)
+		if budgets.Error != nil {
+			return budgets.Error
+		}
+		result.Budgets = budgets.RowsAffected
+
+		transactionIDs := tx.Unscoped().Model(&models.Transaction{}).Select("id").
+			Where("category_id = ? AND deleted_at = ?", category.ID, deletedAt)
+		splitIDs := tx.Unscoped().Model(&models.Split{}).Select("id").
+			Where("transaction_id IN (?) AND deleted_at = ?", transactionIDs, deletedAt)
+
+		if err := tx.Unscoped().Model(&models.Debt{}).
+			Where("split_id IN (?) AND deleted_at = ?", splitIDs, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.SplitShare{}).
+			Where("split_id IN (?) AND deleted_at = ?", splitIDs, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Split{}).
+			Where("transaction_id IN (?) AND deleted_at = ?", transactionIDs, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		var ids []uint
+		if err := tx.Unscoped().Model(&models.Transaction{}).
+			Where("category_id = ? AND deleted_at = ?", category.ID, deletedAt).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Unscoped().Model(&models.Transaction{}).
+			Where("id IN ?", ids).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Tags").Where("id IN ?", ids).Find(&result.Transactions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteCategory moves the category and its budgets to the trash and applies the rule to its transactions
+// and recurring rules. Everything trashed here shares one deletion time so RestoreCategory can find it.
+func (r *Repository) DeleteCategory(category models.Category, rule CategoryDeleteRule) (*CategoryDeleteResult, error) {
+	result := &CategoryDeleteResult{}
+	now := time.Now()
+
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Tags").
+			Where("category_id = ?", category.ID).
+			Find(&result.Transactions).Error; err != nil {
+			return err
+		}
+
+		switch rule.Action {
+		case CategoryReassign:
+			if err := tx.Model(&models.Transaction{}).
+				Where("category_id = ?", category.ID).
+				Update("category_id", *rule.ReassignTo).Error; err != nil {
+				return err
+			}
+
+			rules := tx.Model(&models.RecurringRule{}).
+				Where("category_id = ?", category.ID).
+				Update("category_id", *rule.ReassignTo)
+			if rules.Error != nil {
+				return rules.Error
+			}
+			result.RecurringRules = rules.RowsAffected
+
+		case CategoryDeleteAll:
+			transactionIDs := tx.Model(&models.Transaction{}).Select("id").Where("category_id = ?", category.ID)
+			splitIDs := tx.Model(&models.Split{}).Select("id").Where("transaction_id IN (?)", transactionIDs)
+
+			if err := tx.Model(&models.Debt{}).Where("split_id IN (?)", splitIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.SplitShare{}).Where("split_id IN (?)", splitIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Split{}).Where("transaction_id IN (?)", transactionIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+			if err := loan.UnpayInstallments(tx, transactionIDs); err != nil {
+				return err
+			}
+			if err := bill.ClearPayments(tx, transactionIDs); err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.Transaction{}).
+				Where("category_id = ?", category.ID).
+				Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+
+			// A rule would keep creating transactions for a category that is gone
+			rules := tx.Model(&models.RecurringRule{}).
+				Where("category_id = ?", category.ID).
+				Updates(map[string]interface{}{"category_id": nil, "is_active": false})
+			if rules.Error != nil {
+				return rules.Error
+			}
+			result.RecurringRules = rules.RowsAffected
+
+		default:
+			if err := tx.Model(&models.Transaction{}).
+				Where("category_id = ?", category.ID).
+				Update("category_id", nil).Error; err != nil {
+				return err
+			}
+
+			rules := tx.Model(&models.RecurringRule{}).
+				Where("category_id = ?", category.ID).
+				Update("category_id", nil)
+			if rules.Error != nil {
+				return rules.Error
+			}
+			result.RecurringRules = rules.RowsAffected
+		}
+
+		budgets := tx.Model(&models.Budget{}).
+			Where("category_id = ?", category.ID).
+			Update("deleted_at", now)
+		if budgets.Error != nil {
+			return budgets.Error
+		}
+		result.Budgets = budgets.RowsAffected
+
+		if err := returnEnvelope(tx, category, now, result); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Category{}).
+			Where("id = ?", category.ID).
+			Update("deleted_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// returnEnvelope gives the money allocated to the category's envelope back to ready to assign. None of
+// its spending stays in the envelope whatever the rule, so everything that went in comes out. The
+// allocations themselves are kept as history, restoring the category leaves the money ready to assign.
+func returnEnvelope(tx *gorm.DB, category models.Category, now time.Time, result *CategoryDeleteResult) error {
+	if category.UserID == nil {
+		return nil
+	}
+
+	var allocated types.Money
+	if err := tx.Model(&models.EnvelopeAllocation{}).
+		Select(`COALESCE(SUM(CASE WHEN to_category_id = ? THEN amount ELSE 0 END)
+			- SUM(CASE WHEN from_category_id = ? THEN amount ELSE 0 END), 0)`, category.ID, category.ID).
+		Where("user_id = ? AND (to_category_id = ? OR from_category_id = ?)", *category.UserID, category.ID, category.ID).
+		Scan(&allocated).Error; err != nil {
+		return err
+	}
+	if allocated == 0 {
+		return nil
+	}
+
+	allocation := models.EnvelopeAllocation{
+		UserID: *category.UserID,
+		Amount: allocated,
+		Note:   "Envelope " + category.Name + " deleted",
+		Date:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	if allocated > 0 {
+		allocation.FromCategoryID = &category.ID
+	} else {
+		// More was moved out of the envelope than went in, ready to assign makes up the difference
+		allocation.ToCategoryID = &category.ID
+		allocation.Amount = -allocated
+	}
+	if err := tx.Omit(clause.Associations).Create(&allocation).Error; err != nil {
+		return err
+	}
+	result.EnvelopeReturned = allocated
+	return nil
+}
+
+// Purge removes for good everything that has been in the trash since before the given time.
+// Transactions go first with everything that points at them, then budgets, then categories
+// nothing refers to anymore.
+func (r *Repository) Purge(before time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+
+	for {
+		var ids []uint
+		if err := r.db.WithContext(r.ctx).Unscoped().Model(&models.Transaction{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Limit(purgeBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		keys, err := r.purgeTransactions(ids)
+		if err != nil {
+			return nil, err
+		}
+		result.Transactions += int64(len(ids))
+		result.FileKeys = append(result.FileKeys, keys...)
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	budgets := r.db.WithContext(r.ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&models.Budget{})
+	if budgets.Error != nil {
+		return nil, budgets.Error
+	}
+	result.Budgets = budgets.RowsAffected
+
+	// Transactions uncategorized or restored later may still point at the category, those keep it
+	categories := r.db.WithContext(r.ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM transactions WHERE transactions.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM budgets WHERE budgets.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM recurring_rules WHERE recurring_rules.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM budget_templates WHERE budget_templates.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM envelope_allocations WHERE categories.id IN (envelope_allocations.from_category_id, envelope_allocations.to_category_id))").
+		Where("NOT EXISTS (SELECT 1 FROM savings_goals WHERE savings_goals.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM loans WHERE loans.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM bills WHERE bills.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM categories sub WHERE sub.parent_id = categories.id)").
+		Delete(&models.Category{})
+	if categories.Error != nil {
+		return nil, categories.Error
+	}
+	result.Categories = categories.RowsAffected
+
+	return result, nil
+}
+
+// purgeTransactions hard deletes the transactions and the rows that refer to them in one database transaction
+func (r *Repository) purgeTransactions(ids []uint) ([]string, error) {
+	var keys []string
+
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Attachment{}).
+			Where("transaction_id IN ?", ids).
+			Pluck("file_key", &keys).Error; err != nil {
+			return err
+		}
+
+		splitIDs := tx.Unscoped().Model(&models.Split{}).Select("id").Where("transaction_id IN ?", ids)
+
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("transaction_id IN ?", ids).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("split_id IN (?)", splitIDs).Delete(&models.Debt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("split_id IN (?)", splitIDs).Delete(&models.SplitShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("transaction_id IN ?", ids).Delete(&models.Split{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id IN ?", ids).Delete(&models.TransactionRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.AccountReconciliation{}).
+			Where("adjustment_transaction_id IN ?", ids).
+			Update("adjustment_transaction_id", nil).Error; err != nil {
+			return err
+		}
+		if err := loan.UnpayInstallments(tx, ids); err != nil {
+			return err
+		}
+		if err := bill.ClearPayments(tx, ids); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Transaction{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
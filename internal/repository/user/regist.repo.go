@@ -6,6 +6,8 @@ import (
 	"pannypal/internal/pkg/redis"
 
 	database "pannypal/internal/pkg/db"
+
+	"gorm.io/gorm"
 )
 
 type Repository struct {
@@ -28,8 +30,16 @@ func NewRepo(ctx context.Context, redis redis.IRedis, db *database.Database) IRe
 	}
 }
 
+// CreateUser also gives the user a copy of the category template
 func (r *Repository) CreateUser(d models.User) (*models.User, error) {
-	if err := r.db.WithContext(r.ctx).Create(&d).Error; err != nil {
+	err := r.db.WithContext(r.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&d).Error; err != nil {
+			return err
+		}
+		_, err := database.SeedUserCategories(tx, d.ID)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &d, nil
@@ -7,39 +7,59 @@ import (
 	"os"
 	"path/filepath"
 
+	accountHandler "pannypal/internal/handler/account"
 	aiHandler "pannypal/internal/handler/ai"
 	aicashflowHandler "pannypal/internal/handler/ai-cashflow"
+	alertHandler "pannypal/internal/handler/alert"
 	analyticsHandler "pannypal/internal/handler/analytics"
+	assetHandler "pannypal/internal/handler/asset"
+	billHandler "pannypal/internal/handler/bill"
 	budgetHandler "pannypal/internal/handler/budget"
 	categoryHandler "pannypal/internal/handler/category"
 	chatbotHandler "pannypal/internal/handler/chatbot"
+	currencyHandler "pannypal/internal/handler/currency"
+	envelopeHandler "pannypal/internal/handler/envelope"
+	goalHandler "pannypal/internal/handler/goal"
 	incomingHandler "pannypal/internal/handler/incoming"
+	ledgerHandler "pannypal/internal/handler/ledger"
+	loanHandler "pannypal/internal/handler/loan"
+	recurringHandler "pannypal/internal/handler/recurring"
+	splitHandler "pannypal/internal/handler/split"
+	tagHandler "pannypal/internal/handler/tag"
 	transactionHandler "pannypal/internal/handler/transaction"
+	trashHandler "pannypal/internal/handler/trash"
 	webhookHandler "pannypal/internal/handler/webhook"
 	ai "pannypal/internal/pkg/ai-connector"
 	database "pannypal/internal/pkg/db"
+	"pannypal/internal/pkg/helper"
 	"pannypal/internal/pkg/middleware"
 	"pannypal/internal/pkg/rabbitmq"
+	rateprovider "pannypal/internal/pkg/rate-provider"
 	"pannypal/internal/pkg/redis"
 	s3aws "pannypal/internal/pkg/storage/s3"
 	"pannypal/internal/repository"
-	"pannypal/internal/repository/analytics"
-	"pannypal/internal/repository/bot"
-	"pannypal/internal/repository/budget"
-	"pannypal/internal/repository/category"
-	"pannypal/internal/repository/chatbot"
-	logdata "pannypal/internal/repository/log-data"
-	"pannypal/internal/repository/transaction"
-	"pannypal/internal/repository/user"
+	accountService "pannypal/internal/service/account"
 	aiService "pannypal/internal/service/ai"
 	aicashflowService "pannypal/internal/service/ai-cashflow"
+	alertService "pannypal/internal/service/alert"
 	analyticsService "pannypal/internal/service/analytics"
+	assetService "pannypal/internal/service/asset"
+	billService "pannypal/internal/service/bill"
 	budgetService "pannypal/internal/service/budget"
 	categoryService "pannypal/internal/service/category"
 	chatbotService "pannypal/internal/service/chatbot"
+	currencyService "pannypal/internal/service/currency"
+	envelopeService "pannypal/internal/service/envelope"
+	goalService "pannypal/internal/service/goal"
 	incomingService "pannypal/internal/service/incoming"
+	ledgerService "pannypal/internal/service/ledger"
+	loanService "pannypal/internal/service/loan"
 	outgoingService "pannypal/internal/service/outgoing"
+	recurringService "pannypal/internal/service/recurring"
+	splitService "pannypal/internal/service/split"
+	tagService "pannypal/internal/service/tag"
 	transactionService "pannypal/internal/service/transaction"
+	trashService "pannypal/internal/service/trash"
 	webhookService "pannypal/internal/service/webhook"
 	"sync"
 
@@ -204,18 +224,11 @@ func InitRoutes(
 	ai *ai.AiClient) {
 
 	// init repo
-	rp := repository.IRepository{
-		Category:    category.NewRepo(ctx, redis, db),
-		Budget:      budget.NewRepo(ctx, redis, db),
-		Transaction: transaction.NewRepo(ctx, redis, db),
-		User:        user.NewRepo(ctx, redis, db),
-		Analytics:   analytics.NewRepo(ctx, redis, db),
-		LogData:     logdata.NewRepo(ctx, redis, db),
-		Bot:         bot.NewRepo(ctx, redis, db),
-		Chatbot:     chatbot.NewRepo(ctx, redis, db),
-	}
+	rp := repository.New(ctx, redis, db)
+	storage := newStorage(s3)
 	// init services
-	transactionSvc := transactionService.NewService(ctx, redis, rp)
+	transactionSvc := transactionService.NewService(ctx, redis, rp, storage)
+	tagSvc := tagService.NewService(ctx, redis, rp)
 	categorySvc := categoryService.NewService(ctx, redis, rp)
 	budgetSvc := budgetService.NewService(ctx, redis, rp)
 	analyticsSvc := analyticsService.NewService(ctx, redis, rp, db)
@@ -223,8 +236,20 @@ func InitRoutes(
 	aiSvc := aiService.NewService(ctx, redis, rp, ai, outgoingSvc)
 	aiCashflowSvc := aicashflowService.NewService(ctx, redis, rp, ai, outgoingSvc)
 	webhookSvc := webhookService.NewService(ctx, redis, rp, aiCashflowSvc)
-	incomingSvc := incomingService.NewService(ctx, redis, rp, aiSvc, outgoingSvc)
 	chatbotSvc := chatbotService.NewService(ctx, redis, rp, db, ai)
+	splitSvc := splitService.NewService(ctx, redis, rp)
+	goalSvc := goalService.NewService(ctx, redis, rp)
+	loanSvc := loanService.NewService(ctx, redis, rp)
+	assetSvc := assetService.NewService(ctx, redis, rp)
+	accountSvc := accountService.NewService(ctx, redis, rp)
+	incomingSvc := incomingService.NewService(ctx, redis, rp, aiSvc, outgoingSvc, chatbotSvc, splitSvc, goalSvc, storage)
+	ledgerSvc := ledgerService.NewService(ctx, redis, rp)
+	recurringSvc := recurringService.NewService(ctx, redis, rp, outgoingSvc)
+	currencySvc := currencyService.NewService(ctx, redis, rp, newRateProvider())
+	trashSvc := trashService.NewService(ctx, redis, rp, storage)
+	alertSvc := alertService.NewService(ctx, redis, rp, outgoingSvc)
+	envelopeSvc := envelopeService.NewService(ctx, redis, rp)
+	billSvc := billService.NewService(ctx, redis, rp, outgoingSvc)
 
 	// init handlers
 	transactionHandler := transactionHandler.NewHandler(ctx, rb, transactionSvc)
@@ -236,6 +261,19 @@ func InitRoutes(
 	webhookHandler := webhookHandler.NewHandler(ctx, rb, webhookSvc)
 	incomingHandler := incomingHandler.NewHandler(ctx, rb, incomingSvc)
 	chatbotHandler := chatbotHandler.NewHandler(ctx, chatbotSvc)
+	ledgerHandler := ledgerHandler.NewHandler(ctx, rb, ledgerSvc)
+	splitHandler := splitHandler.NewHandler(ctx, rb, splitSvc)
+	accountHandler := accountHandler.NewHandler(ctx, rb, accountSvc)
+	recurringHandler := recurringHandler.NewHandler(ctx, rb, recurringSvc)
+	currencyHandler := currencyHandler.NewHandler(ctx, rb, currencySvc)
+	tagHandler := tagHandler.NewHandler(ctx, rb, tagSvc)
+	trashHandler := trashHandler.NewHandler(ctx, rb, trashSvc)
+	alertHandler := alertHandler.NewHandler(ctx, rb, alertSvc)
+	envelopeHandler := envelopeHandler.NewHandler(ctx, rb, envelopeSvc)
+	goalHandler := goalHandler.NewHandler(ctx, rb, goalSvc)
+	loanHandler := loanHandler.NewHandler(ctx, rb, loanSvc)
+	assetHandler := assetHandler.NewHandler(ctx, rb, assetSvc)
+	billHandler := billHandler.NewHandler(ctx, rb, billSvc)
 
 	// init handler routes
 	transactionHandler.NewRoutes(e)
@@ -247,4 +285,39 @@ func InitRoutes(
 	webhookHandler.NewRoutes(e)
 	incomingHandler.NewRoutes(e)
 	chatbotHandler.NewRoutes(e)
+	ledgerHandler.NewRoutes(e)
+	splitHandler.NewRoutes(e)
+	accountHandler.NewRoutes(e)
+	recurringHandler.NewRoutes(e)
+	currencyHandler.NewRoutes(e)
+	tagHandler.NewRoutes(e)
+	trashHandler.NewRoutes(e)
+	alertHandler.NewRoutes(e)
+	envelopeHandler.NewRoutes(e)
+	goalHandler.NewRoutes(e)
+	loanHandler.NewRoutes(e)
+	assetHandler.NewRoutes(e)
+	billHandler.NewRoutes(e)
+
+	// Local storage has no bucket to hand out URLs, so the API serves the files itself
+	if local, ok := storage.(*s3aws.LocalClient); ok {
+		e.Static("/files", local.BasePath)
+	}
+}
+
+// newStorage unwraps the optional storage client, nil when none is configured
+func newStorage(s3 *s3aws.Is3) s3aws.Is3 {
+	if s3 == nil {
+		return nil
+	}
+	return *s3
+}
+
+// newRateProvider picks the exchange rate source from the environment
+func newRateProvider() rateprovider.IProvider {
+	return rateprovider.NewProvider(rateprovider.Config{
+		Provider: helper.GetEnv("EXCHANGE_RATE_PROVIDER"),
+		FilePath: helper.GetEnv("EXCHANGE_RATE_FILE"),
+		URL:      helper.GetEnv("EXCHANGE_RATE_URL"),
+	})
 }
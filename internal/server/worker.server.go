@@ -8,6 +8,15 @@ import (
 	"pannypal/internal/pkg/rabbitmq"
 	"pannypal/internal/pkg/redis"
 	s3aws "pannypal/internal/pkg/storage/s3"
+	"pannypal/internal/repository"
+	alertService "pannypal/internal/service/alert"
+	analyticsService "pannypal/internal/service/analytics"
+	billService "pannypal/internal/service/bill"
+	budgetService "pannypal/internal/service/budget"
+	currencyService "pannypal/internal/service/currency"
+	outgoingService "pannypal/internal/service/outgoing"
+	recurringService "pannypal/internal/service/recurring"
+	trashService "pannypal/internal/service/trash"
 
 	"time"
 
@@ -16,8 +25,16 @@ import (
 
 func InitWorker(ctx context.Context, redis redis.IRedis, db *database.Database, rb *rabbitmq.ConnectionManager, publisher *rabbitmq.Publisher, s3 *s3aws.Is3) {
 	// init repo
-	// rp := repository.IRepository{}
+	rp := repository.New(ctx, redis, db)
 	// init service
+	outgoingSvc := outgoingService.NewService(ctx, redis, rp)
+	recurringSvc := recurringService.NewService(ctx, redis, rp, outgoingSvc)
+	currencySvc := currencyService.NewService(ctx, redis, rp, newRateProvider())
+	trashSvc := trashService.NewService(ctx, redis, rp, newStorage(s3))
+	budgetSvc := budgetService.NewService(ctx, redis, rp)
+	alertSvc := alertService.NewService(ctx, redis, rp, outgoingSvc)
+	analyticsSvc := analyticsService.NewService(ctx, redis, rp, db)
+	billSvc := billService.NewService(ctx, redis, rp, outgoingSvc)
 	// init handlers
 	poolOpts := ants.Options{
 		ExpiryDuration: time.Hour,
@@ -32,7 +49,11 @@ func InitWorker(ctx context.Context, redis redis.IRedis, db *database.Database,
 	if err != nil {
 		panic(fmt.Errorf("failed to create worker pool: %w", err))
 	}
-	defer pool.Release()
+	// Scheduled jobs keep running until the app shuts down
+	go func() {
+		<-ctx.Done()
+		pool.Release()
+	}()
 
 	err = pool.Submit(func() {
 		// Initialize the RabbitMQ subscriber for kyb
@@ -44,4 +65,94 @@ func InitWorker(ctx context.Context, redis redis.IRedis, db *database.Database,
 	if err != nil {
 		panic(fmt.Errorf("failed to submit task to pool: %w", err))
 	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, time.Hour, "recurring rules", func() error {
+			return recurringSvc.ProcessDueRules(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, 24*time.Hour, "exchange rates", func() error {
+			return currencySvc.RefreshRates(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, time.Hour, "budget templates", func() error {
+			return budgetSvc.ApplyBudgetTemplates(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, time.Minute, "budget alerts", func() error {
+			return alertSvc.CheckBudgetAlerts(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, 10*time.Minute, "budget digests", func() error {
+			return alertSvc.SendBudgetDigests(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, time.Hour, "net worth snapshots", func() error {
+			return analyticsSvc.TakeNetWorthSnapshots(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, time.Hour, "bill reminders", func() error {
+			return billSvc.ProcessBills(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+
+	err = pool.Submit(func() {
+		runEvery(ctx, 24*time.Hour, "trash purge", func() error {
+			return trashSvc.PurgeExpired(time.Now())
+		})
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to submit task to pool: %w", err))
+	}
+}
+
+// runEvery runs the job right away and then on every tick until the context is done
+func runEvery(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(); err != nil {
+			logger.Error.Printf("Scheduled job %s failed: %v\n", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,458 @@
+package account
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/account"
+	"pannypal/internal/service/account/dto"
+	"strings"
+	"time"
+)
+
+const reconciliationLimit = 50
+
+func (s *Service) CreateAccountRequest(payload dto.CreateAccountRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	currency := strings.ToUpper(payload.Currency)
+	if currency == "" {
+		currency = user.BaseCurrency
+	}
+
+	created, err := s.rp.Account.CreateAccount(models.Account{
+		UserID:         user.ID,
+		Name:           payload.Name,
+		Type:           models.AccountType(payload.Type),
+		Currency:       currency,
+		OpeningBalance: payload.OpeningBalance,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create account",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Account created successfully",
+		Data:    toAccountResponse(*created),
+	})
+}
+
+func (s *Service) GetAccountsRequest(payload dto.GetAccountsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	accounts, err := s.rp.Account.GetAccountsByUserID(user.ID, payload.IncludeArchived)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get accounts",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	accountResponses := make([]dto.AccountResponse, len(accounts))
+	for i, a := range accounts {
+		accountResponses[i] = toAccountResponse(a)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Accounts retrieved successfully",
+		Data:    dto.AccountListResponse{Accounts: accountResponses},
+	})
+}
+
+func (s *Service) GetAccountByIDRequest(id uint, phoneNumber string) *types.Response {
+	account, resp := s.getUserAccount(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Account retrieved successfully",
+		Data:    toAccountResponse(*account),
+	})
+}
+
+func (s *Service) UpdateAccountRequest(id uint, payload dto.UpdateAccountRequest, phoneNumber string) *types.Response {
+	account, resp := s.getUserAccount(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	// Update fields if provided
+	if payload.Name != nil {
+		account.Name = *payload.Name
+	}
+	if payload.Type != nil {
+		account.Type = models.AccountType(*payload.Type)
+	}
+	if payload.Currency != nil {
+		account.Currency = strings.ToUpper(*payload.Currency)
+	}
+	if payload.OpeningBalance != nil {
+		account.OpeningBalance = *payload.OpeningBalance
+	}
+	if payload.IsArchived != nil {
+		account.IsArchived = *payload.IsArchived
+	}
+
+	updated, err := s.rp.Account.UpdateAccount(*account)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update account",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Account updated successfully",
+		Data:    toAccountResponse(*updated),
+	})
+}
+
+func (s *Service) DeleteAccountRequest(id uint, phoneNumber string) *types.Response {
+	account, resp := s.getUserAccount(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Account.DeleteAccount(account.ID); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete account",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Account deleted successfully",
+		Data:    nil,
+	})
+}
+
+func (s *Service) GetAccountBalancesRequest(payload dto.AccountBalanceRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	balances, err := s.rp.Account.GetAccountBalances(user.ID, nil, endOfDay(payload.AsOf))
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get account balances",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.AccountBalanceListResponse{
+		Accounts:     make([]dto.AccountBalanceResponse, len(balances)),
+		Totals:       map[string]types.Money{},
+		BaseCurrency: user.BaseCurrency,
+		AsOf:         payload.AsOf,
+	}
+	rateDate := time.Now()
+	if payload.AsOf != nil {
+		rateDate = *payload.AsOf
+	}
+	for i, b := range balances {
+		response.Accounts[i] = toAccountBalanceResponse(b)
+		response.Totals[b.Currency] += b.Balance()
+
+		rate, err := s.rp.ExchangeRate.GetRate(b.Currency, user.BaseCurrency, rateDate)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to get exchange rate",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		if rate == nil {
+			// Without a rate the balance counts at face value, like the analytics totals
+			one := float64(1)
+			rate = &one
+		}
+		response.TotalInBase += b.Balance().Mul(*rate)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Account balances retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetAccountBalanceRequest(id uint, payload dto.AccountBalanceRequest) *types.Response {
+	account, resp := s.getUserAccount(id, payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	balance, err := s.accountBalance(*account, endOfDay(payload.AsOf))
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get account balance",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Account balance retrieved successfully",
+		Data:    toAccountBalanceResponse(*balance),
+	})
+}
+
+// ReconcileAccountRequest compares the balance the user sees at the bank with the computed one,
+// with adjust the difference is booked so both agree from now on
+func (s *Service) ReconcileAccountRequest(id uint, payload dto.ReconcileAccountRequest) *types.Response {
+	account, resp := s.getUserAccount(id, payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	asOf := time.Now()
+	if payload.AsOf != nil {
+		asOf = *payload.AsOf
+	}
+
+	balance, err := s.accountBalance(*account, &asOf)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get account balance",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	computed := balance.Balance()
+	difference := payload.StatedBalance - computed
+
+	reconciliation := models.AccountReconciliation{
+		AccountID:       account.ID,
+		StatedBalance:   payload.StatedBalance,
+		ComputedBalance: computed,
+		Difference:      difference,
+		AsOf:            asOf,
+		Note:            payload.Note,
+	}
+
+	var created *models.AccountReconciliation
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if payload.Adjust && difference != 0 {
+			adjustment := models.Transaction{
+				UserID:          account.UserID,
+				AccountID:       &account.ID,
+				Type:            models.TypeIncome,
+				Amount:          difference,
+				Description:     "Penyesuaian saldo " + account.Name,
+				TransactionDate: asOf,
+			}
+			if difference < 0 {
+				adjustment.Type = models.TypeExpense
+				adjustment.Amount = -difference
+			}
+
+			transaction, err := rp.Transaction.CreateTransaction(adjustment)
+			if err != nil {
+				return err
+			}
+			reconciliation.AdjustmentTransactionID = &transaction.ID
+
+			actor := models.RevisionActor{
+				ActorType: models.RevisionActorUser,
+				ActorID:   &account.UserID,
+				Source:    models.RevisionSourceReconciliation,
+			}
+			if err := rp.Revision.Record(models.RevisionActionCreate, actor, nil, transaction); err != nil {
+				return err
+			}
+		}
+
+		saved, err := rp.Account.CreateReconciliation(reconciliation)
+		if err != nil {
+			return err
+		}
+		created = saved
+		return nil
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to reconcile account",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Account reconciled successfully",
+		Data:    toReconciliationResponse(*created),
+	})
+}
+
+func (s *Service) GetReconciliationsRequest(id uint, phoneNumber string) *types.Response {
+	account, resp := s.getUserAccount(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	reconciliations, err := s.rp.Account.GetReconciliationsByAccountID(account.ID, reconciliationLimit)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get reconciliations",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.ReconciliationResponse, len(reconciliations))
+	for i, r := range reconciliations {
+		responses[i] = toReconciliationResponse(r)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Reconciliations retrieved successfully",
+		Data:    dto.ReconciliationListResponse{Reconciliations: responses},
+	})
+}
+
+// getUserAccount loads an account and checks it belongs to the user with the phone number
+func (s *Service) getUserAccount(id uint, phoneNumber string) (*models.Account, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	account, err := s.rp.Account.GetAccountByID(id)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Account not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if account.UserID != user.ID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	return account, nil
+}
+
+func (s *Service) accountBalance(acc models.Account, asOf *time.Time) (*account.AccountBalanceData, error) {
+	balances, err := s.rp.Account.GetAccountBalances(acc.UserID, &acc.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		return &account.AccountBalanceData{AccountID: acc.ID}, nil
+	}
+	return &balances[0], nil
+}
+
+// endOfDay makes a date filter include the whole day
+func endOfDay(date *time.Time) *time.Time {
+	if date == nil {
+		return nil
+	}
+	end := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	return &end
+}
+
+func toAccountResponse(a models.Account) dto.AccountResponse {
+	return dto.AccountResponse{
+		ID:             a.ID,
+		Name:           a.Name,
+		Type:           a.Type,
+		Currency:       a.Currency,
+		OpeningBalance: a.OpeningBalance,
+		IsArchived:     a.IsArchived,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+func toAccountBalanceResponse(b account.AccountBalanceData) dto.AccountBalanceResponse {
+	return dto.AccountBalanceResponse{
+		AccountID:      b.AccountID,
+		Name:           b.Name,
+		Type:           b.Type,
+		Currency:       b.Currency,
+		IsArchived:     b.IsArchived,
+		OpeningBalance: b.OpeningBalance,
+		TotalIncome:    b.TotalIncome,
+		TotalExpense:   b.TotalExpense,
+		TransfersIn:    b.TransfersIn,
+		TransfersOut:   b.TransfersOut,
+		Balance:        b.Balance(),
+	}
+}
+
+func toReconciliationResponse(r models.AccountReconciliation) dto.ReconciliationResponse {
+	return dto.ReconciliationResponse{
+		ID:                      r.ID,
+		AccountID:               r.AccountID,
+		StatedBalance:           r.StatedBalance,
+		ComputedBalance:         r.ComputedBalance,
+		Difference:              r.Difference,
+		AsOf:                    r.AsOf,
+		AdjustmentTransactionID: r.AdjustmentTransactionID,
+		Note:                    r.Note,
+		CreatedAt:               r.CreatedAt,
+	}
+}
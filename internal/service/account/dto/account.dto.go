@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type CreateAccountRequest struct {
+	PhoneNumber    string      `json:"phone_number" validate:"required"`
+	Name           string      `json:"name" validate:"required,max=100"`
+	Type           string      `json:"type" validate:"required,oneof=CASH BANK EWALLET CREDIT_CARD OTHER"`
+	Currency       string      `json:"currency" validate:"omitempty,len=3"` // Defaults to the user's base currency
+	OpeningBalance types.Money `json:"opening_balance" validate:"omitempty"`
+}
+
+type UpdateAccountRequest struct {
+	Name           *string      `json:"name" validate:"omitempty,max=100"`
+	Type           *string      `json:"type" validate:"omitempty,oneof=CASH BANK EWALLET CREDIT_CARD OTHER"`
+	Currency       *string      `json:"currency" validate:"omitempty,len=3"`
+	OpeningBalance *types.Money `json:"opening_balance" validate:"omitempty"`
+	IsArchived     *bool        `json:"is_archived" validate:"omitempty"`
+}
+
+type GetAccountsRequest struct {
+	PhoneNumber     string `form:"phone_number" validate:"required"`
+	IncludeArchived bool   `form:"include_archived" validate:"omitempty"`
+}
+
+type AccountBalanceRequest struct {
+	PhoneNumber string     `form:"phone_number" validate:"required"`
+	AsOf        *time.Time `form:"as_of" validate:"omitempty" time_format:"2006-01-02"`
+}
+
+type ReconcileAccountRequest struct {
+	PhoneNumber   string      `json:"phone_number" validate:"required"`
+	StatedBalance types.Money `json:"stated_balance" validate:"omitempty"`
+	AsOf          *time.Time  `json:"as_of" validate:"omitempty"`  // Defaults to now
+	Adjust        bool        `json:"adjust" validate:"omitempty"` // Record the difference as an adjustment transaction
+	Note          string      `json:"note" validate:"omitempty"`
+}
+
+type AccountResponse struct {
+	ID             uint               `json:"id"`
+	Name           string             `json:"name"`
+	Type           models.AccountType `json:"type"`
+	Currency       string             `json:"currency"`
+	OpeningBalance types.Money        `json:"opening_balance"`
+	IsArchived     bool               `json:"is_archived"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type AccountListResponse struct {
+	Accounts []AccountResponse `json:"accounts"`
+}
+
+type AccountBalanceResponse struct {
+	AccountID      uint               `json:"account_id"`
+	Name           string             `json:"name"`
+	Type           models.AccountType `json:"type"`
+	Currency       string             `json:"currency"`
+	IsArchived     bool               `json:"is_archived"`
+	OpeningBalance types.Money        `json:"opening_balance"`
+	TotalIncome    types.Money        `json:"total_income"`
+	TotalExpense   types.Money        `json:"total_expense"`
+	TransfersIn    types.Money        `json:"transfers_in"`
+	TransfersOut   types.Money        `json:"transfers_out"`
+	Balance        types.Money        `json:"balance"`
+}
+
+type AccountBalanceListResponse struct {
+	Accounts []AccountBalanceResponse `json:"accounts"`
+	Totals   map[string]types.Money   `json:"totals"` // Total balance per currency
+	// Every balance converted into the user's base currency
+	BaseCurrency string      `json:"base_currency"`
+	TotalInBase  types.Money `json:"total_in_base"`
+	AsOf         *time.Time  `json:"as_of"`
+}
+
+type ReconciliationResponse struct {
+	ID                      uint        `json:"id"`
+	AccountID               uint        `json:"account_id"`
+	StatedBalance           types.Money `json:"stated_balance"`
+	ComputedBalance         types.Money `json:"computed_balance"`
+	Difference              types.Money `json:"difference"`
+	AsOf                    time.Time   `json:"as_of"`
+	AdjustmentTransactionID *uint       `json:"adjustment_transaction_id"`
+	Note                    string      `json:"note"`
+	CreatedAt               time.Time   `json:"created_at"`
+}
+
+type ReconciliationListResponse struct {
+	Reconciliations []ReconciliationResponse `json:"reconciliations"`
+}
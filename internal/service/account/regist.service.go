@@ -0,0 +1,35 @@
+package account
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/account/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	CreateAccountRequest(payload dto.CreateAccountRequest) *types.Response
+	GetAccountsRequest(payload dto.GetAccountsRequest) *types.Response
+	GetAccountByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateAccountRequest(id uint, payload dto.UpdateAccountRequest, phoneNumber string) *types.Response
+	DeleteAccountRequest(id uint, phoneNumber string) *types.Response
+	GetAccountBalancesRequest(payload dto.AccountBalanceRequest) *types.Response
+	GetAccountBalanceRequest(id uint, payload dto.AccountBalanceRequest) *types.Response
+	ReconcileAccountRequest(id uint, payload dto.ReconcileAccountRequest) *types.Response
+	GetReconciliationsRequest(id uint, phoneNumber string) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"pannypal/internal/common/models"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
 	"pannypal/internal/service/ai-cashflow/dto"
 	dtoOutgoingMessage "pannypal/internal/service/outgoing/dto"
+	"strings"
 	"time"
 )
 
@@ -40,7 +42,7 @@ func (s *Service) InputTransaction(payload dto.InputTransaction) *types.Response
 		},
 	}
 
-	prompt, err := s.promptUserTransactionInputEdit(payload.Message, req)
+	prompt, err := PromptUserTransactionInputEdit(payload.Message, req)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -50,7 +52,7 @@ func (s *Service) InputTransaction(payload dto.InputTransaction) *types.Response
 		})
 	}
 
-	schema, err := s.getTransactionSchema()
+	schema, err := s.getTransactionSchema(payload.PhoneNumber)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -168,7 +170,7 @@ func (s *Service) PannyPalBotCashflowText(payload dto.PayloadAICashflow) {
 		return
 	}
 
-	schema, err := s.getTransactionSchema()
+	schema, err := s.getTransactionSchema(payload.To)
 	if err != nil {
 		OutgiingMessage.Message = "Maaf, terjadi kesalahan saat memproses permintaan Anda."
 		s.outgoingService.HandleWebhookEventWaha(OutgiingMessage)
@@ -288,7 +290,7 @@ func (s *Service) PannyPalBotCashflowImage(payload dto.PayloadAICashflow) {
 	}
 
 	// Perform OCR on image
-	ocrResponse, err := s.performOCROnImage(base64Image)
+	ocrResponse, err := s.performOCROnImage(base64Image, payload.To)
 	if err != nil {
 		OutgiingMessage.Message = "Maaf, terjadi kesalahan saat memproses gambar."
 		s.outgoingService.HandleWebhookEventWaha(OutgiingMessage)
@@ -407,7 +409,7 @@ func (s *Service) SaveTransaction(payload dto.PayloadAICashflow, messageToReply
 		return
 	}
 	for _, tx := range *dataTransaction {
-		validCategoryID, err := s.validateOrCreateCategory(tx.CategoryId)
+		validCategoryID, err := s.validateOrCreateCategory(user.ID, tx.CategoryId, tx.Type)
 		if err != nil {
 			fmt.Println("Failed to validate category:", err)
 			return
@@ -417,16 +419,30 @@ func (s *Service) SaveTransaction(payload dto.PayloadAICashflow, messageToReply
 			UserID:          user.ID,
 			Type:            models.TransactionType(tx.Type),
 			Amount:          tx.Amount,
+			Currency:        strings.ToUpper(tx.Currency),
 			CategoryID:      validCategoryID,
 			Description:     tx.Description,
 			TransactionDate: time.Now(),
 		}
-		_, err = s.rp.Transaction.CreateTransaction(model)
+		err = s.rp.Atomic(func(rp repository.IRepository) error {
+			created, err := rp.Transaction.CreateTransaction(model)
+			if err != nil {
+				return err
+			}
+
+			actor := models.RevisionActor{
+				ActorType: models.RevisionActorBot,
+				ActorID:   &user.ID,
+				Channel:   payload.From,
+				MessageID: payload.MessageId,
+				Source:    models.RevisionSourceWhatsapp,
+			}
+			return rp.Revision.Record(models.RevisionActionCreate, actor, nil, created)
+		})
 		if err != nil {
 			fmt.Println("Failed to create transaction:", err)
 			return
 		}
-
 	}
 
 	payloadBot := dtoOutgoingMessage.PayloadOutgoing{
@@ -460,14 +476,14 @@ func (s *Service) EditTransaction(payload dto.PayloadAICashflow, messageToReply
 		To:             payload.To,
 	}
 
-	prompt, err := s.promptUserTransactionInputEdit(payload.Message, messageToReply.Additional)
+	prompt, err := PromptUserTransactionInputEdit(payload.Message, messageToReply.Additional)
 	if err != nil {
 		OutgiingMessage.Message = "Maaf, terjadi kesalahan saat memproses permintaan Anda."
 		s.outgoingService.HandleWebhookEventWaha(OutgiingMessage)
 		return
 	}
 
-	schema, err := s.getTransactionSchema()
+	schema, err := s.getTransactionSchema(payload.To)
 	if err != nil {
 		OutgiingMessage.Message = "Maaf, terjadi kesalahan saat memproses permintaan Anda."
 		s.outgoingService.HandleWebhookEventWaha(OutgiingMessage)
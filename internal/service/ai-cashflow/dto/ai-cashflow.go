@@ -1,6 +1,9 @@
 package dto
 
-import "pannypal/internal/common/enum"
+import (
+	"pannypal/internal/common/enum"
+	types "pannypal/internal/common/type"
+)
 
 type InputTransaction struct {
 	PhoneNumber string `json:"phone_number" binding:"required"`
@@ -13,10 +16,11 @@ type TransactionResponseAi struct {
 }
 
 type TransactionPayload struct {
-	Type        string  `json:"type"`
-	Amount      float64 `json:"amount"`
-	CategoryId  int     `json:"category_id"`
-	Description string  `json:"description"`
+	Type        string      `json:"type"`
+	Amount      types.Money `json:"amount"`
+	Currency    string      `json:"currency,omitempty"`
+	CategoryId  int         `json:"category_id"`
+	Description string      `json:"description"`
 }
 type PayloadAICashflow struct {
 	TypeBot   enum.BotType  `json:"type_bot"`
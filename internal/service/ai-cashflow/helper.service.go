@@ -6,8 +6,8 @@ import (
 	"io"
 	"net/http"
 	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
 	"pannypal/internal/service/ai-cashflow/dto"
-	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
@@ -40,56 +40,30 @@ func (s *Service) GetUser(phoneNumber string) (*models.User, error) {
 	return user, nil
 }
 
-func (s *Service) validateOrCreateCategory(categoryID int) (*uint, error) {
-	if categoryID <= 0 {
-		// If no category or invalid ID, get the first available category or create default
-		categories, err := s.rp.Category.GetAllCategories()
-		if err != nil {
-			return nil, err
+// validateOrCreateCategory keeps the category the AI picked when it is the user's own and fits the
+// transaction type, anything else goes to the user's fallback category
+func (s *Service) validateOrCreateCategory(userID uint, categoryID int, transactionType string) (*uint, error) {
+	txType := models.TransactionType(transactionType)
+	if categoryID > 0 {
+		category, err := s.rp.Category.GetCategoryByID(uint(categoryID))
+		// The AI is shown the template when the sender has no account yet, take the user's copy of it
+		if err == nil && category.UserID == nil {
+			category, err = s.rp.Category.GetCategoryByName(&userID, category.Name)
 		}
-
-		if len(categories) == 0 {
-			// Create default category if none exists
-			defaultCategory, err := s.rp.Category.CreateCategory(models.Category{
-				Name: "Other",
-			})
-			if err != nil {
-				return nil, err
-			}
-			return &defaultCategory.ID, nil
+		if err == nil && category.UserID != nil && *category.UserID == userID && category.Kind.Allows(txType) {
+			return &category.ID, nil
 		}
-
-		// Use first available category
-		return &categories[0].ID, nil
 	}
 
-	// Check if category exists
-	_, err := s.rp.Category.GetCategoryByID(uint(categoryID))
+	fallback, err := s.rp.Category.GetFallbackCategory(userID)
 	if err != nil {
-		// Category doesn't exist, get first available category or create default
-		categories, err := s.rp.Category.GetAllCategories()
-		if err != nil {
-			return nil, err
-		}
-
-		if len(categories) == 0 {
-			// Create default category if none exists
-			defaultCategory, err := s.rp.Category.CreateCategory(models.Category{
-				Name: "Lainnya",
-			})
-			if err != nil {
-				return nil, err
-			}
-			return &defaultCategory.ID, nil
-		}
-
-		// Use first available category
-		return &categories[0].ID, nil
+		return nil, err
 	}
-
-	// Category exists, return as pointer
-	validID := uint(categoryID)
-	return &validID, nil
+	// The user narrowed the fallback category down, the transaction stays uncategorized
+	if !fallback.Kind.Allows(txType) {
+		return nil, nil
+	}
+	return &fallback.ID, nil
 }
 
 // cleanAIResponse removes markdown formatting and backticks from AI response
@@ -161,7 +135,7 @@ func (s *Service) generateTransactionSummary(transactions []dto.TransactionPaylo
 		}
 
 		// Format amount with dots
-		amountStr := s.formatCurrency(int(tx.Amount))
+		amountStr := helper.FormatMoney(tx.Amount, tx.Currency)
 
 		if tx.Type == "EXPENSE" {
 			summary += "✅ Tercatat pengeluaran\n"
@@ -170,33 +144,13 @@ func (s *Service) generateTransactionSummary(transactions []dto.TransactionPaylo
 		}
 
 		summary += " n: " + tx.Description + "\n"
-		summary += " a: Rp. " + amountStr + "\n"
+		summary += " a: " + amountStr + "\n"
 		summary += " c: " + categoryName + "\n\n"
 	}
 
 	return summary
 }
 
-func (s *Service) formatCurrency(amount int) string {
-	if amount < 1000 {
-		return strconv.Itoa(amount)
-	}
-
-	// Convert to string
-	str := strconv.Itoa(amount)
-
-	// Add dots from right to left
-	result := ""
-	for i, char := range str {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result += "."
-		}
-		result += string(char)
-	}
-
-	return result
-}
-
 // downloadImageAndEncodeBase64 downloads image from media URL with authentication
 func (s *Service) downloadImageAndEncodeBase64(mediaURL string, accountBot *models.AccountBot) (string, error) {
 	// Replace localhost with base URL if needed
@@ -10,20 +10,12 @@ import (
 )
 
 // getTransactionSchema builds JSON Schema for structured output with dynamic categories from database
-func (s *Service) getTransactionSchema() (*genai.Schema, error) {
-	// Get categories from database
-	categoryList, err := s.rp.Category.GetAllCategories()
+func (s *Service) getTransactionSchema(phoneNumber string) (*genai.Schema, error) {
+	categoryDescription, err := s.categoryDescription(phoneNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
 	}
 
-	// Build category description from database
-	var categoryDescParts []string
-	for _, cat := range categoryList {
-		categoryDescParts = append(categoryDescParts, fmt.Sprintf("%d=%s", cat.ID, cat.Name))
-	}
-	categoryDescription := "Category ID: " + strings.Join(categoryDescParts, ", ")
-
 	return &genai.Schema{
 		Type: genai.TypeObject,
 		Properties: map[string]*genai.Schema{
@@ -39,8 +31,12 @@ func (s *Service) getTransactionSchema() (*genai.Schema, error) {
 							Enum:        []string{"EXPENSE", "INCOME"},
 						},
 						"amount": {
-							Type:        genai.TypeInteger,
-							Description: "Transaction amount in integer",
+							Type:        genai.TypeNumber,
+							Description: "Transaction amount in major units of the currency, up to two decimals (12.50 for 12 dollars 50 cents)",
+						},
+						"currency": {
+							Type:        genai.TypeString,
+							Description: "ISO 4217 currency code when the input names a currency or symbol (USD for $ or dollar, SGD, JPY for yen), empty otherwise",
 						},
 						"category_id": {
 							Type:        genai.TypeInteger,
@@ -70,8 +66,9 @@ Extract with JSON schema.`, input)
 	return prompt, nil
 }
 
-// promptUserTransactionInputEdit generates prompt for editing existing transactions
-func (s *Service) promptUserTransactionInputEdit(input string, existJson interface{}) (string, error) {
+// PromptUserTransactionInputEdit generates prompt for editing existing transactions, the AI service
+// uses it for edits to saved transactions as well
+func PromptUserTransactionInputEdit(input string, existJson interface{}) (string, error) {
 	existingData, err := json.Marshal(existJson)
 	if err != nil {
 		return "", err
@@ -94,14 +91,53 @@ Extract with JSON schema.`, string(existingData), input)
 	return prompt, nil
 }
 
+// categoryDescription lists the categories the AI may pick from as "12=Makanan & Minuman > Kopi",
+// the sender's own or the template when the sender is unknown
+func (s *Service) categoryDescription(phoneNumber string) (string, error) {
+	var userID *uint
+	if phoneNumber != "" {
+		if user, err := s.rp.User.GetUserByPhone(phoneNumber); err == nil {
+			userID = &user.ID
+		}
+	}
+
+	categoryList, err := s.rp.Category.GetCategories(userID)
+	if err != nil {
+		return "", err
+	}
+
+	parents := map[uint]string{}
+	for _, cat := range categoryList {
+		if cat.ParentID == nil {
+			parents[cat.ID] = cat.Name
+		}
+	}
+
+	var categoryDescParts []string
+	for _, cat := range categoryList {
+		name := cat.Name
+		if cat.ParentID != nil {
+			if parent, ok := parents[*cat.ParentID]; ok {
+				name = parent + " > " + name
+			}
+		}
+		if cat.Kind != models.CategoryKindExpense {
+			name += " (" + string(cat.Kind) + ")"
+		}
+		categoryDescParts = append(categoryDescParts, fmt.Sprintf("%d=%s", cat.ID, name))
+	}
+	return "Category ID, the most specific one that fits; categories are for EXPENSE unless marked INCOME or BOTH: " +
+		strings.Join(categoryDescParts, ", "), nil
+}
+
 // performOCROnImage performs OCR on image using Gemini vision with structured output
-func (s *Service) performOCROnImage(base64Image string) (string, error) {
+func (s *Service) performOCROnImage(base64Image string, phoneNumber string) (string, error) {
 	prompt := `Extract financial transactions from this image (receipt, invoice, bank statement, shopping list, etc).
 
 Extract with JSON schema.`
 
 	// Get transaction schema for structured output (with dynamic categories)
-	schema, err := s.getTransactionSchema()
+	schema, err := s.getTransactionSchema(phoneNumber)
 	if err != nil {
 		return "", fmt.Errorf("failed to get transaction schema: %w", err)
 	}
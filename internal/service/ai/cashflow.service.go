@@ -3,7 +3,9 @@ package AI
 import (
 	"encoding/json"
 	"fmt"
+	"pannypal/internal/common/models"
 	"pannypal/internal/pkg/helper"
+	aicashflow "pannypal/internal/service/ai-cashflow"
 	"pannypal/internal/service/ai/dto"
 	"strings"
 
@@ -20,20 +22,12 @@ Extract with JSON schema.`, input)
 	return prompt, nil
 }
 
-func (s *Service) getTransactionSchema() (*genai.Schema, error) {
-	// Get categories from database
-	categoryList, err := s.rp.Category.GetAllCategories()
+func (s *Service) getTransactionSchema(phoneNumber string) (*genai.Schema, error) {
+	categoryDescription, err := s.categoryDescription(phoneNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
 	}
 
-	// Build category description from database
-	var categoryDescParts []string
-	for _, cat := range categoryList {
-		categoryDescParts = append(categoryDescParts, fmt.Sprintf("%d=%s", cat.ID, cat.Name))
-	}
-	categoryDescription := "Category ID: " + strings.Join(categoryDescParts, ", ")
-
 	return &genai.Schema{
 		Type: genai.TypeObject,
 		Properties: map[string]*genai.Schema{
@@ -49,8 +43,12 @@ func (s *Service) getTransactionSchema() (*genai.Schema, error) {
 							Enum:        []string{"EXPENSE", "INCOME"},
 						},
 						"amount": {
-							Type:        genai.TypeInteger,
-							Description: "Transaction amount in integer",
+							Type:        genai.TypeNumber,
+							Description: "Transaction amount in major units of the currency, up to two decimals (12.50 for 12 dollars 50 cents)",
+						},
+						"currency": {
+							Type:        genai.TypeString,
+							Description: "ISO 4217 currency code when the input names a currency or symbol (USD for $ or dollar, SGD, JPY for yen), empty otherwise",
 						},
 						"category_id": {
 							Type:        genai.TypeInteger,
@@ -69,6 +67,45 @@ func (s *Service) getTransactionSchema() (*genai.Schema, error) {
 	}, nil
 }
 
+// categoryDescription lists the categories the AI may pick from as "12=Makanan & Minuman > Kopi",
+// the sender's own or the template when the sender is unknown
+func (s *Service) categoryDescription(phoneNumber string) (string, error) {
+	var userID *uint
+	if phoneNumber != "" {
+		if user, err := s.rp.User.GetUserByPhone(phoneNumber); err == nil {
+			userID = &user.ID
+		}
+	}
+
+	categoryList, err := s.rp.Category.GetCategories(userID)
+	if err != nil {
+		return "", err
+	}
+
+	parents := map[uint]string{}
+	for _, cat := range categoryList {
+		if cat.ParentID == nil {
+			parents[cat.ID] = cat.Name
+		}
+	}
+
+	var categoryDescParts []string
+	for _, cat := range categoryList {
+		name := cat.Name
+		if cat.ParentID != nil {
+			if parent, ok := parents[*cat.ParentID]; ok {
+				name = parent + " > " + name
+			}
+		}
+		if cat.Kind != models.CategoryKindExpense {
+			name += " (" + string(cat.Kind) + ")"
+		}
+		categoryDescParts = append(categoryDescParts, fmt.Sprintf("%d=%s", cat.ID, name))
+	}
+	return "Category ID, the most specific one that fits; categories are for EXPENSE unless marked INCOME or BOTH: " +
+		strings.Join(categoryDescParts, ", "), nil
+}
+
 func (s *Service) generateTransactionSummary(transactions []dto.TransactionPayload) string {
 	if len(transactions) == 0 {
 		return "Tidak ada transaksi yang terdeteksi."
@@ -85,7 +122,7 @@ func (s *Service) generateTransactionSummary(transactions []dto.TransactionPaylo
 		}
 
 		// Format amount with dots
-		amountStr := helper.FormatCurrency(int(tx.Amount))
+		amountStr := helper.FormatMoney(tx.Amount, tx.Currency)
 
 		if tx.Type == "EXPENSE" {
 			summary += "✅ Tercatat pengeluaran\n"
@@ -94,7 +131,7 @@ func (s *Service) generateTransactionSummary(transactions []dto.TransactionPaylo
 		}
 
 		summary += " n: " + tx.Description + "\n"
-		summary += " a: Rp. " + amountStr + "\n"
+		summary += " a: " + amountStr + "\n"
 		summary += " c: " + categoryName + "\n\n"
 	}
 
@@ -107,7 +144,24 @@ func (s *Service) InputTextCashflow(payload dto.InputTextCashflow) (*dto.Transac
 		return nil, "", err
 	}
 
-	schema, err := s.getTransactionSchema()
+	return s.promptCashflow(prompt, payload.PhoneNumber)
+}
+
+// EditTextCashflow merges the user's change into existing transactions
+func (s *Service) EditTextCashflow(payload dto.EditTextCashflow) (*dto.TransactionResponseAi, string, error) {
+	prompt, err := aicashflow.PromptUserTransactionInputEdit(payload.Message, payload.Existing)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.promptCashflow(prompt, payload.PhoneNumber)
+}
+
+// promptCashflow runs a transaction prompt and renders the draft summary, the categories offered
+// are the sender's
+func (s *Service) promptCashflow(prompt string, phoneNumber string) (*dto.TransactionResponseAi, string, error) {
+
+	schema, err := s.getTransactionSchema(phoneNumber)
 	if err != nil {
 		return nil, "", err
 	}
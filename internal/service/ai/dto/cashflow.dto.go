@@ -1,7 +1,19 @@
 package dto
 
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
 type InputTextCashflow struct {
-	Message string `json:"message"`
+	Message     string `json:"message"`
+	PhoneNumber string `json:"phone_number,omitempty"` // Sender, the AI picks from their categories
+}
+
+type EditTextCashflow struct {
+	Message     string               `json:"message"`
+	Existing    []TransactionPayload `json:"existing"`
+	PhoneNumber string               `json:"phone_number,omitempty"`
 }
 
 type TransactionResponseAi struct {
@@ -9,8 +21,15 @@ type TransactionResponseAi struct {
 }
 
 type TransactionPayload struct {
-	Type        string `json:"type"`
-	Amount      int    `json:"amount"`
-	CategoryId  int    `json:"category_id"`
-	Description string `json:"description"`
+	Type        string      `json:"type"`
+	Amount      types.Money `json:"amount"`
+	Currency    string      `json:"currency,omitempty"` // Empty means the account or user default
+	CategoryId  int         `json:"category_id"`
+	Description string      `json:"description"`
+
+	// Not part of the AI schema, set when the draft comes from a recurring rule
+	AccountID       *uint      `json:"account_id,omitempty"`
+	RecurringRuleID *uint      `json:"recurring_rule_id,omitempty"`
+	TransactionDate *time.Time `json:"transaction_date,omitempty"`
+	Tags            []string   `json:"tags,omitempty"` // Hashtags from the message
 }
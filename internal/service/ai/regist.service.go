@@ -20,6 +20,7 @@ type Service struct {
 
 type IService interface {
 	InputTextCashflow(payload dto.InputTextCashflow) (*dto.TransactionResponseAi, string, error)
+	EditTextCashflow(payload dto.EditTextCashflow) (*dto.TransactionResponseAi, string, error)
 }
 
 func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, aiClient *ai.AiClient, outgoingService outgoingService.IService) IService {
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type GetAlertSettingRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+// UpdateAlertSettingRequest replaces the user's alert setting, leave both quiet hours out to have none
+type UpdateAlertSettingRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required"`
+	QuietStart  *int   `json:"quiet_start" validate:"required_with=QuietEnd,omitempty,min=0,max=23"` // Hour alerts stop being sent
+	QuietEnd    *int   `json:"quiet_end" validate:"required_with=QuietStart,omitempty,min=0,max=23"` // Hour alerts are sent again
+	DailyDigest bool   `json:"daily_digest" validate:"omitempty"`                                    // Personal budget alerts wait for one daily message
+	DigestHour  *int   `json:"digest_hour" validate:"omitempty,min=0,max=23"`                        // Defaults to 20
+}
+
+type AlertSettingResponse struct {
+	QuietStart   *int       `json:"quiet_start"`
+	QuietEnd     *int       `json:"quiet_end"`
+	DailyDigest  bool       `json:"daily_digest"`
+	DigestHour   int        `json:"digest_hour"`
+	LastDigestAt *time.Time `json:"last_digest_at"`
+}
@@ -0,0 +1,35 @@
+package alert
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/alert/dto"
+	"pannypal/internal/service/outgoing"
+	"time"
+)
+
+type Service struct {
+	ctx      context.Context
+	redis    redis.IRedis
+	rp       repository.IRepository
+	outgoing outgoing.IService
+}
+
+type IService interface {
+	CheckBudgetAlerts(now time.Time) error
+	SendBudgetDigests(now time.Time) error
+
+	GetAlertSettingRequest(payload dto.GetAlertSettingRequest) *types.Response
+	UpdateAlertSettingRequest(payload dto.UpdateAlertSettingRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, outgoing outgoing.IService) IService {
+	return &Service{
+		ctx:      ctx,
+		redis:    redis,
+		rp:       repository,
+		outgoing: outgoing,
+	}
+}
@@ -0,0 +1,93 @@
+package alert
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/alert/dto"
+)
+
+func (s *Service) GetAlertSettingRequest(payload dto.GetAlertSettingRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	setting, err := s.rp.Alert.GetSetting(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get alert settings",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Alert settings retrieved successfully",
+		Data:    toSettingResponse(*setting),
+	})
+}
+
+func (s *Service) UpdateAlertSettingRequest(payload dto.UpdateAlertSettingRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	setting, err := s.rp.Alert.GetSetting(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get alert settings",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	setting.QuietStart = payload.QuietStart
+	setting.QuietEnd = payload.QuietEnd
+	setting.DailyDigest = payload.DailyDigest
+	setting.DigestHour = models.DefaultDigestHour
+	if payload.DigestHour != nil {
+		setting.DigestHour = *payload.DigestHour
+	}
+
+	updated, err := s.rp.Alert.SaveSetting(*setting)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update alert settings",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Alert settings updated successfully",
+		Data:    toSettingResponse(*updated),
+	})
+}
+
+func toSettingResponse(setting models.AlertSetting) dto.AlertSettingResponse {
+	return dto.AlertSettingResponse{
+		QuietStart:   setting.QuietStart,
+		QuietEnd:     setting.QuietEnd,
+		DailyDigest:  setting.DailyDigest,
+		DigestHour:   setting.DigestHour,
+		LastDigestAt: setting.LastDigestAt,
+	}
+}
@@ -0,0 +1,300 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository/budget"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	revisionBatchSize     = 500
+	pendingAlertBatchSize = 100
+)
+
+// CheckBudgetAlerts follows the transaction revision log, so every way a transaction is saved is
+// covered, and records the thresholds the changed budgets crossed. Alerts are then sent unless the
+// owner is in quiet hours or gets them in the daily digest.
+func (s *Service) CheckBudgetAlerts(now time.Time) error {
+	if err := s.recordCrossings(now); err != nil {
+		return err
+	}
+	return s.sendPendingAlerts(now)
+}
+
+func (s *Service) recordCrossings(now time.Time) error {
+	cursor, ok, err := s.rp.Alert.GetRevisionCursor()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// The first run starts from now, older transactions are not alerted on
+		latest, err := s.rp.Revision.GetLatestRevisionID()
+		if err != nil {
+			return err
+		}
+		return s.rp.Alert.SetRevisionCursor(latest)
+	}
+
+	changes, err := s.rp.Revision.GetChangesAfter(cursor, revisionBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	users := map[uint]bool{}
+	ledgers := map[uint]bool{}
+	for _, change := range changes {
+		users[change.UserID] = true
+		if change.LedgerID != nil {
+			ledgers[*change.LedgerID] = true
+		}
+	}
+
+	today := startOfDay(now)
+	for userID := range users {
+		statuses, err := s.rp.Budget.GetBudgetStatus(&userID, budget.BudgetStatusFilters{From: today, To: today})
+		if err != nil {
+			logger.Error.Printf("Error checking budget alerts of user %d: %v", userID, err)
+			continue
+		}
+		s.recordStatusCrossings(statuses)
+	}
+	for ledgerID := range ledgers {
+		statuses, err := s.rp.Budget.GetBudgetStatus(nil, budget.BudgetStatusFilters{LedgerID: &ledgerID, From: today, To: today})
+		if err != nil {
+			logger.Error.Printf("Error checking budget alerts of ledger %d: %v", ledgerID, err)
+			continue
+		}
+		s.recordStatusCrossings(statuses)
+	}
+
+	return s.rp.Alert.SetRevisionCursor(changes[len(changes)-1].RevisionID)
+}
+
+func (s *Service) recordStatusCrossings(statuses []budget.BudgetStatusData) {
+	for _, status := range statuses {
+		for _, threshold := range models.ParseAlertThresholds(status.Alerts) {
+			if !crossed(status, threshold) {
+				break
+			}
+			if _, err := s.rp.Alert.CreateAlertIfMissing(status.BudgetID, threshold); err != nil {
+				logger.Error.Printf("Error recording alert of budget %d at %d%%: %v", status.BudgetID, threshold, err)
+			}
+		}
+	}
+}
+
+// crossed reports whether the spending reached threshold percent of what the budget has for the period
+func crossed(status budget.BudgetStatusData, threshold int) bool {
+	if status.SpentAmount <= 0 {
+		return false
+	}
+	available := status.BudgetAmount + status.CarriedOver
+	if available <= 0 {
+		return true
+	}
+	return int64(status.SpentAmount)*100 >= int64(threshold)*int64(available)
+}
+
+// sendPendingAlerts sends one message per budget for the highest threshold it crossed
+func (s *Service) sendPendingAlerts(now time.Time) error {
+	alerts, err := s.rp.Alert.GetPendingAlerts(pendingAlertBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var budgetIDs []uint
+	byBudget := map[uint][]models.BudgetAlert{}
+	for _, alert := range alerts {
+		if _, ok := byBudget[alert.BudgetID]; !ok {
+			budgetIDs = append(budgetIDs, alert.BudgetID)
+		}
+		byBudget[alert.BudgetID] = append(byBudget[alert.BudgetID], alert)
+	}
+
+	settings := map[uint]*models.AlertSetting{}
+	for _, budgetID := range budgetIDs {
+		if err := s.sendBudgetAlert(byBudget[budgetID], settings, now); err != nil {
+			logger.Error.Printf("Error sending alert of budget %d: %v", budgetID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) sendBudgetAlert(alerts []models.BudgetAlert, settings map[uint]*models.AlertSetting, now time.Time) error {
+	ids := make([]uint, len(alerts))
+	for i, alert := range alerts {
+		ids[i] = alert.ID
+	}
+
+	// Deleted budgets and periods that are over are not worth a message anymore
+	owned := alerts[0].Budget
+	if owned.ID == 0 {
+		return s.rp.Alert.MarkAlertsSent(ids, now)
+	}
+	status, err := s.rp.Budget.GetBudgetStatusByID(owned.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.rp.Alert.MarkAlertsSent(ids, now)
+	}
+	if err != nil {
+		return err
+	}
+	if daysLeft(status.PeriodEnd, now) < 1 {
+		return s.rp.Alert.MarkAlertsSent(ids, now)
+	}
+
+	setting, ok := settings[owned.UserID]
+	if !ok {
+		if setting, err = s.rp.Alert.GetSetting(owned.UserID); err != nil {
+			return err
+		}
+		settings[owned.UserID] = setting
+	}
+	if setting.IsQuiet(now) || (owned.LedgerID == nil && setting.DailyDigest) {
+		return nil
+	}
+
+	chatID := owned.User.WhatsappChatID
+	if owned.LedgerID != nil {
+		ledger, err := s.rp.Ledger.GetLedgerByID(*owned.LedgerID)
+		if err != nil {
+			return err
+		}
+		chatID = ledger.ChatID
+	}
+	if chatID == "" || owned.User.BotAccountID == "" {
+		// Nowhere to send it, the alert is dropped rather than retried forever
+		if err := s.rp.Alert.MarkAlertsSent(ids, now); err != nil {
+			return err
+		}
+		return fmt.Errorf("user %d has no WhatsApp chat with the bot", owned.UserID)
+	}
+
+	text := alertText(*status, alerts[len(alerts)-1].Threshold, owned.User.BaseCurrency, now)
+	if _, err := s.outgoing.SendText(owned.User.BotAccountID, chatID, text); err != nil {
+		return err
+	}
+	return s.rp.Alert.MarkAlertsSent(ids, now)
+}
+
+func alertText(status budget.BudgetStatusData, threshold int, currency string, now time.Time) string {
+	available := status.BudgetAmount + status.CarriedOver
+	remaining := available - status.SpentAmount
+
+	text := fmt.Sprintf("⚠️ *Budget %s sudah %d%%*\n\n", status.CategoryName, threshold)
+	if threshold >= 100 {
+		text = fmt.Sprintf("🚨 *Budget %s sudah habis*\n\n", status.CategoryName)
+	}
+	text += fmt.Sprintf("Terpakai: %s dari %s (%d%%)\n", helper.FormatMoney(status.SpentAmount, currency),
+		helper.FormatMoney(available, currency), percentage(status.SpentAmount, available))
+	if remaining >= 0 {
+		text += "Sisa: " + helper.FormatMoney(remaining, currency) + "\n"
+	} else {
+		text += "Lebih: " + helper.FormatMoney(-remaining, currency) + "\n"
+	}
+	text += fmt.Sprintf("Sisa waktu: %d hari (s/d %s)", daysLeft(status.PeriodEnd, now), status.PeriodEnd.Format("02-01-2006"))
+	return text
+}
+
+// SendBudgetDigests sends the daily digest to the users whose digest hour it is, listing their
+// personal budgets and the thresholds crossed since the last digest
+func (s *Service) SendBudgetDigests(now time.Time) error {
+	settings, err := s.rp.Alert.GetDigestSettings(now.Hour(), startOfDay(now))
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range settings {
+		if err := s.sendDigest(setting, now); err != nil {
+			logger.Error.Printf("Error sending budget digest of user %d: %v", setting.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) sendDigest(setting models.AlertSetting, now time.Time) error {
+	user := setting.User
+	today := startOfDay(now)
+	statuses, err := s.rp.Budget.GetBudgetStatus(&user.ID, budget.BudgetStatusFilters{From: today, To: today})
+	if err != nil {
+		return err
+	}
+	pending, err := s.rp.Alert.GetPendingAlertsByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) > 0 || len(pending) > 0 {
+		if user.WhatsappChatID == "" || user.BotAccountID == "" {
+			return fmt.Errorf("user %d has no WhatsApp chat with the bot", user.ID)
+		}
+		if _, err := s.outgoing.SendText(user.BotAccountID, user.WhatsappChatID, digestText(statuses, pending, user.BaseCurrency, now)); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]uint, len(pending))
+	for i, alert := range pending {
+		ids[i] = alert.ID
+	}
+	if err := s.rp.Alert.MarkAlertsSent(ids, now); err != nil {
+		return err
+	}
+
+	setting.LastDigestAt = &now
+	_, err = s.rp.Alert.SaveSetting(setting)
+	return err
+}
+
+func digestText(statuses []budget.BudgetStatusData, pending []models.BudgetAlert, currency string, now time.Time) string {
+	crossedAt := map[uint]int{}
+	for _, alert := range pending {
+		if alert.Threshold > crossedAt[alert.BudgetID] {
+			crossedAt[alert.BudgetID] = alert.Threshold
+		}
+	}
+
+	text := "📊 *Ringkasan budget hari ini*\n"
+	for _, status := range statuses {
+		available := status.BudgetAmount + status.CarriedOver
+		text += fmt.Sprintf("\n• *%s*: %s dari %s (%d%%)", status.CategoryName,
+			helper.FormatMoney(status.SpentAmount, currency), helper.FormatMoney(available, currency),
+			percentage(status.SpentAmount, available))
+		if threshold, ok := crossedAt[status.BudgetID]; ok {
+			text += fmt.Sprintf(" ⚠️ lewat %d%%", threshold)
+		}
+		text += fmt.Sprintf("\n  Sisa %s, %d hari lagi", helper.FormatMoney(available-status.SpentAmount, currency),
+			daysLeft(status.PeriodEnd, now))
+	}
+	if len(statuses) == 0 {
+		text += "\nTidak ada budget yang berjalan hari ini."
+	}
+	return text
+}
+
+func percentage(spent, available types.Money) int {
+	if available <= 0 {
+		return 100
+	}
+	return int(int64(spent) * 100 / int64(available))
+}
+
+// daysLeft counts the days from now through the period's last day, today included
+func daysLeft(periodEnd, now time.Time) int {
+	end := time.Date(periodEnd.Year(), periodEnd.Month(), periodEnd.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(today).Hours()/24) + 1
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -12,6 +12,7 @@ import (
 
 func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest) *types.Response {
 	var userID *uint
+	currency := models.DefaultCurrency
 
 	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
 		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
@@ -24,6 +25,7 @@ func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest
 			})
 		}
 		userID = &user.ID
+		currency = user.BaseCurrency
 	}
 
 	year := time.Now().Year()
@@ -31,7 +33,7 @@ func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest
 		year = *payload.Year
 	}
 
-	data, err := s.analyticsRepo.GetMonthlyAnalytics(userID, year)
+	data, err := s.analyticsRepo.GetMonthlyAnalytics(userID, nil, year)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -45,8 +47,8 @@ func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest
 		"July", "August", "September", "October", "November", "December"}
 
 	monthlyData := make([]dto.MonthlyDataPoint, len(data))
-	totalIncome := float64(0)
-	totalExpense := float64(0)
+	totalIncome := types.Money(0)
+	totalExpense := types.Money(0)
 	var bestMonth, worstMonth *dto.MonthlyDataPoint
 
 	for i, d := range data {
@@ -73,14 +75,26 @@ func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest
 		}
 	}
 
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
 	response := dto.MonthlyAnalyticsResponse{
-		Data:         monthlyData,
-		Year:         year,
-		TotalIncome:  totalIncome,
-		TotalExpense: totalExpense,
-		TotalBalance: totalIncome - totalExpense,
-		BestMonth:    bestMonth,
-		WorstMonth:   worstMonth,
+		Currency:              currency,
+		UnconvertedCurrencies: unconverted,
+		Data:                  monthlyData,
+		Year:                  year,
+		TotalIncome:           totalIncome,
+		TotalExpense:          totalExpense,
+		TotalBalance:          totalIncome - totalExpense,
+		BestMonth:             bestMonth,
+		WorstMonth:            worstMonth,
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -92,6 +106,7 @@ func (s *Service) GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest
 
 func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest) *types.Response {
 	var userID *uint
+	currency := models.DefaultCurrency
 
 	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
 		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
@@ -104,6 +119,7 @@ func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest)
 			})
 		}
 		userID = &user.ID
+		currency = user.BaseCurrency
 	}
 
 	currentYear := time.Now().Year()
@@ -117,7 +133,7 @@ func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest)
 		endYear = *payload.EndYear
 	}
 
-	data, err := s.analyticsRepo.GetYearlyAnalytics(userID, startYear, endYear)
+	data, err := s.analyticsRepo.GetYearlyAnalytics(userID, nil, startYear, endYear)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -128,8 +144,8 @@ func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest)
 	}
 
 	yearlyData := make([]dto.YearlyDataPoint, len(data))
-	totalIncome := float64(0)
-	totalExpense := float64(0)
+	totalIncome := types.Money(0)
+	totalExpense := types.Money(0)
 	var bestYear, worstYear *dto.YearlyDataPoint
 
 	for i, d := range data {
@@ -154,15 +170,27 @@ func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest)
 		}
 	}
 
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
 	response := dto.YearlyAnalyticsResponse{
-		Data:         yearlyData,
-		StartYear:    startYear,
-		EndYear:      endYear,
-		TotalIncome:  totalIncome,
-		TotalExpense: totalExpense,
-		TotalBalance: totalIncome - totalExpense,
-		BestYear:     bestYear,
-		WorstYear:    worstYear,
+		Currency:              currency,
+		UnconvertedCurrencies: unconverted,
+		Data:                  yearlyData,
+		StartYear:             startYear,
+		EndYear:               endYear,
+		TotalIncome:           totalIncome,
+		TotalExpense:          totalExpense,
+		TotalBalance:          totalIncome - totalExpense,
+		BestYear:              bestYear,
+		WorstYear:             worstYear,
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -174,6 +202,7 @@ func (s *Service) GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest)
 
 func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsRequest) *types.Response {
 	var userID *uint
+	currency := models.DefaultCurrency
 
 	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
 		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
@@ -186,6 +215,7 @@ func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsReque
 			})
 		}
 		userID = &user.ID
+		currency = user.BaseCurrency
 	}
 
 	filters := analytics.CategoryAnalyticsFilters{
@@ -207,7 +237,7 @@ func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsReque
 		})
 	}
 
-	totalAmount := float64(0)
+	totalAmount := types.Money(0)
 	totalCount := int64(0)
 
 	// Calculate totals first
@@ -220,25 +250,7 @@ func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsReque
 	var topCategory *dto.CategoryDataPoint
 
 	for i, d := range data {
-		percentage := float64(0)
-		if totalAmount > 0 {
-			percentage = (d.TotalAmount / totalAmount) * 100
-		}
-
-		averageAmount := float64(0)
-		if d.Count > 0 {
-			averageAmount = d.TotalAmount / float64(d.Count)
-		}
-
-		categoryData[i] = dto.CategoryDataPoint{
-			CategoryID:    d.CategoryID,
-			CategoryName:  d.CategoryName,
-			Type:          d.Type,
-			TotalAmount:   d.TotalAmount,
-			Count:         d.Count,
-			Percentage:    percentage,
-			AverageAmount: averageAmount,
-		}
+		categoryData[i] = toCategoryDataPoint(d, totalAmount)
 
 		// Find top category
 		if topCategory == nil || d.TotalAmount > topCategory.TotalAmount {
@@ -246,10 +258,22 @@ func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsReque
 		}
 	}
 
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
 	response := dto.CategoryAnalyticsResponse{
-		Data:             categoryData,
-		TotalAmount:      totalAmount,
-		TransactionCount: totalCount,
+		Currency:              currency,
+		UnconvertedCurrencies: unconverted,
+		Data:                  categoryData,
+		TotalAmount:           totalAmount,
+		TransactionCount:      totalCount,
 		Period: dto.PeriodInfo{
 			StartDate: payload.StartDate,
 			EndDate:   payload.EndDate,
@@ -264,8 +288,134 @@ func (s *Service) GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsReque
 	})
 }
 
+// toCategoryDataPoint maps a category and its subcategories, shares are of the total across all
+// categories so a subcategory reads as part of the whole
+func toCategoryDataPoint(d analytics.CategoryAnalyticsData, totalAmount types.Money) dto.CategoryDataPoint {
+	percentage := float64(0)
+	if totalAmount > 0 {
+		percentage = (d.TotalAmount.Float64() / totalAmount.Float64()) * 100
+	}
+
+	averageAmount := types.Money(0)
+	if d.Count > 0 {
+		averageAmount = types.MoneyFromFloat(d.TotalAmount.Float64() / float64(d.Count))
+	}
+
+	point := dto.CategoryDataPoint{
+		CategoryID:    d.CategoryID,
+		CategoryName:  d.CategoryName,
+		ParentID:      d.ParentID,
+		Icon:          d.Icon,
+		Color:         d.Color,
+		Type:          d.Type,
+		TotalAmount:   d.TotalAmount,
+		Count:         d.Count,
+		Percentage:    percentage,
+		AverageAmount: averageAmount,
+	}
+	for _, sub := range d.Subcategories {
+		point.Subcategories = append(point.Subcategories, toCategoryDataPoint(sub, totalAmount))
+	}
+	return point
+}
+
+func (s *Service) GetTagAnalyticsRequest(payload dto.TagAnalyticsRequest) *types.Response {
+	var userID *uint
+	currency := models.DefaultCurrency
+
+	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
+		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		userID = &user.ID
+		currency = user.BaseCurrency
+	}
+
+	filters := analytics.CategoryAnalyticsFilters{
+		StartDate: payload.StartDate,
+		EndDate:   payload.EndDate,
+	}
+	if payload.Type != nil {
+		transactionType := models.TransactionType(*payload.Type)
+		filters.Type = &transactionType
+	}
+
+	data, err := s.analyticsRepo.GetTagAnalytics(userID, filters)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get tag analytics",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Tags overlap, so shares are taken against every transaction of the type rather than the tag sum
+	categories, err := s.analyticsRepo.GetCategoryAnalytics(userID, filters)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get tag analytics",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	totals := map[models.TransactionType]types.Money{}
+	for _, c := range categories {
+		totals[c.Type] += c.TotalAmount
+	}
+
+	tagData := make([]dto.TagDataPoint, len(data))
+	for i, d := range data {
+		percentage := float64(0)
+		if totals[d.Type] > 0 {
+			percentage = (d.TotalAmount.Float64() / totals[d.Type].Float64()) * 100
+		}
+
+		tagData[i] = dto.TagDataPoint{
+			TagID:       d.TagID,
+			TagName:     d.TagName,
+			Type:        d.Type,
+			TotalAmount: d.TotalAmount,
+			Count:       d.Count,
+			Percentage:  percentage,
+		}
+	}
+
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Tag analytics retrieved successfully",
+		Data: dto.TagAnalyticsResponse{
+			Currency:              currency,
+			UnconvertedCurrencies: unconverted,
+			Data:                  tagData,
+			Period: dto.PeriodInfo{
+				StartDate: payload.StartDate,
+				EndDate:   payload.EndDate,
+			},
+		},
+	})
+}
+
 func (s *Service) GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsRequest) *types.Response {
 	var userID *uint
+	currency := models.DefaultCurrency
 
 	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
 		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
@@ -278,6 +428,7 @@ func (s *Service) GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsReq
 			})
 		}
 		userID = &user.ID
+		currency = user.BaseCurrency
 	}
 
 	// Default to current month if not provided
@@ -290,7 +441,7 @@ func (s *Service) GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsReq
 		endDate = *payload.EndDate
 	}
 
-	data, err := s.analyticsRepo.GetDashboardAnalytics(userID, startDate, endDate)
+	data, err := s.analyticsRepo.GetDashboardAnalytics(userID, nil, startDate, endDate)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -303,14 +454,14 @@ func (s *Service) GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsReq
 	// Calculate percentage changes
 	incomeChange := float64(0)
 	if data.PreviousIncome > 0 {
-		incomeChange = ((data.CurrentIncome - data.PreviousIncome) / data.PreviousIncome) * 100
+		incomeChange = ((data.CurrentIncome - data.PreviousIncome).Float64() / data.PreviousIncome.Float64()) * 100
 	} else if data.CurrentIncome > 0 {
 		incomeChange = 100
 	}
 
 	expenseChange := float64(0)
 	if data.PreviousExpense > 0 {
-		expenseChange = ((data.CurrentExpense - data.PreviousExpense) / data.PreviousExpense) * 100
+		expenseChange = ((data.CurrentExpense - data.PreviousExpense).Float64() / data.PreviousExpense.Float64()) * 100
 	} else if data.CurrentExpense > 0 {
 		expenseChange = 100
 	}
@@ -320,18 +471,45 @@ func (s *Service) GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsReq
 	previousEndDate := startDate.Add(-time.Second)
 	previousStartDate := previousEndDate.Add(-duration)
 
+	debt, err := s.rp.Loan.GetDebtTotals(userID, startDate, endDate)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get dashboard analytics",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
 	totalBalance := data.TotalIncomeAllTime - data.TotalExpenseAllTime
 
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
 	response := dto.DashboardAnalyticsResponse{
-		TotalBalance:      totalBalance,
-		Income:            data.CurrentIncome,
-		Expense:           data.CurrentExpense,
-		IncomeChange:      incomeChange,
-		ExpenseChange:     expenseChange,
-		StartDate:         &startDate,
-		EndDate:           &endDate,
-		PreviousStartDate: &previousStartDate,
-		PreviousEndDate:   &previousEndDate,
+		Currency:              currency,
+		UnconvertedCurrencies: unconverted,
+		TotalBalance:          totalBalance,
+		Income:                data.CurrentIncome,
+		Expense:               data.CurrentExpense,
+		IncomeChange:          incomeChange,
+		ExpenseChange:         expenseChange,
+		StartDate:             &startDate,
+		EndDate:               &endDate,
+		PreviousStartDate:     &previousStartDate,
+		PreviousEndDate:       &previousEndDate,
+		TotalDebt:             debt.Outstanding,
+		InstallmentsDue:       debt.InstallmentsDue,
+		InstallmentsPaid:      debt.InstallmentsPaid,
+		ActiveLoans:           debt.LoanCount,
+		NetWorth:              totalBalance - debt.Outstanding,
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"time"
 )
 
@@ -23,61 +24,95 @@ type CategoryAnalyticsRequest struct {
 	Type        *string    `form:"type" validate:"omitempty,oneof=INCOME EXPENSE"`
 }
 
+type TagAnalyticsRequest struct {
+	PhoneNumber *string    `form:"phone_number,omitempty" validate:"omitempty"`
+	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"`
+	EndDate     *time.Time `form:"end_date" validate:"omitempty" time_format:"2006-01-02"`
+	Type        *string    `form:"type" validate:"omitempty,oneof=INCOME EXPENSE"`
+}
+
 type MonthlyDataPoint struct {
-	Month            int     `json:"month"`
-	MonthName        string  `json:"month_name"`
-	Year             int     `json:"year"`
-	TotalIncome      float64 `json:"total_income"`
-	TotalExpense     float64 `json:"total_expense"`
-	Balance          float64 `json:"balance"`
-	TransactionCount int64   `json:"transaction_count"`
+	Month            int         `json:"month"`
+	MonthName        string      `json:"month_name"`
+	Year             int         `json:"year"`
+	TotalIncome      types.Money `json:"total_income"`
+	TotalExpense     types.Money `json:"total_expense"`
+	Balance          types.Money `json:"balance"`
+	TransactionCount int64       `json:"transaction_count"`
 }
 
 type YearlyDataPoint struct {
-	Year             int     `json:"year"`
-	TotalIncome      float64 `json:"total_income"`
-	TotalExpense     float64 `json:"total_expense"`
-	Balance          float64 `json:"balance"`
-	TransactionCount int64   `json:"transaction_count"`
+	Year             int         `json:"year"`
+	TotalIncome      types.Money `json:"total_income"`
+	TotalExpense     types.Money `json:"total_expense"`
+	Balance          types.Money `json:"balance"`
+	TransactionCount int64       `json:"transaction_count"`
 }
 
+// CategoryDataPoint totals of a top-level category include its subcategories
 type CategoryDataPoint struct {
 	CategoryID    uint                   `json:"category_id"`
 	CategoryName  string                 `json:"category_name"`
+	ParentID      *uint                  `json:"parent_id,omitempty"`
+	Icon          string                 `json:"icon,omitempty"`
+	Color         string                 `json:"color,omitempty"`
 	Type          models.TransactionType `json:"type"`
-	TotalAmount   float64                `json:"total_amount"`
+	TotalAmount   types.Money            `json:"total_amount"`
 	Count         int64                  `json:"count"`
 	Percentage    float64                `json:"percentage"`
-	AverageAmount float64                `json:"average_amount"`
+	AverageAmount types.Money            `json:"average_amount"`
+	Subcategories []CategoryDataPoint    `json:"subcategories,omitempty"`
 }
 
 type MonthlyAnalyticsResponse struct {
-	Data         []MonthlyDataPoint `json:"data"`
-	Year         int                `json:"year"`
-	TotalIncome  float64            `json:"total_income"`
-	TotalExpense float64            `json:"total_expense"`
-	TotalBalance float64            `json:"total_balance"`
-	BestMonth    *MonthlyDataPoint  `json:"best_month"`
-	WorstMonth   *MonthlyDataPoint  `json:"worst_month"`
+	Currency              string             `json:"currency"`               // Amounts are converted into the user's base currency
+	UnconvertedCurrencies []string           `json:"unconverted_currencies"` // Currencies without a rate, their transactions are left out of the totals
+	Data                  []MonthlyDataPoint `json:"data"`
+	Year                  int                `json:"year"`
+	TotalIncome           types.Money        `json:"total_income"`
+	TotalExpense          types.Money        `json:"total_expense"`
+	TotalBalance          types.Money        `json:"total_balance"`
+	BestMonth             *MonthlyDataPoint  `json:"best_month"`
+	WorstMonth            *MonthlyDataPoint  `json:"worst_month"`
 }
 
 type YearlyAnalyticsResponse struct {
-	Data         []YearlyDataPoint `json:"data"`
-	StartYear    int               `json:"start_year"`
-	EndYear      int               `json:"end_year"`
-	TotalIncome  float64           `json:"total_income"`
-	TotalExpense float64           `json:"total_expense"`
-	TotalBalance float64           `json:"total_balance"`
-	BestYear     *YearlyDataPoint  `json:"best_year"`
-	WorstYear    *YearlyDataPoint  `json:"worst_year"`
+	Currency              string            `json:"currency"`               // Amounts are converted into the user's base currency
+	UnconvertedCurrencies []string          `json:"unconverted_currencies"` // Currencies without a rate, their transactions are left out of the totals
+	Data                  []YearlyDataPoint `json:"data"`
+	StartYear             int               `json:"start_year"`
+	EndYear               int               `json:"end_year"`
+	TotalIncome           types.Money       `json:"total_income"`
+	TotalExpense          types.Money       `json:"total_expense"`
+	TotalBalance          types.Money       `json:"total_balance"`
+	BestYear              *YearlyDataPoint  `json:"best_year"`
+	WorstYear             *YearlyDataPoint  `json:"worst_year"`
 }
 
 type CategoryAnalyticsResponse struct {
-	Data             []CategoryDataPoint `json:"data"`
-	TotalAmount      float64             `json:"total_amount"`
-	TransactionCount int64               `json:"transaction_count"`
-	Period           PeriodInfo          `json:"period"`
-	TopCategory      *CategoryDataPoint  `json:"top_category"`
+	Currency              string              `json:"currency"`               // Amounts are converted into the user's base currency
+	UnconvertedCurrencies []string            `json:"unconverted_currencies"` // Currencies without a rate, their transactions are left out of the totals
+	Data                  []CategoryDataPoint `json:"data"`
+	TotalAmount           types.Money         `json:"total_amount"`
+	TransactionCount      int64               `json:"transaction_count"`
+	Period                PeriodInfo          `json:"period"`
+	TopCategory           *CategoryDataPoint  `json:"top_category"`
+}
+
+type TagDataPoint struct {
+	TagID       uint                   `json:"tag_id"`
+	TagName     string                 `json:"tag_name"`
+	Type        models.TransactionType `json:"type"`
+	TotalAmount types.Money            `json:"total_amount"`
+	Count       int64                  `json:"count"`
+	Percentage  float64                `json:"percentage"` // Share of all transactions of the type in the period
+}
+
+type TagAnalyticsResponse struct {
+	Currency              string         `json:"currency"`               // Amounts are converted into the user's base currency
+	UnconvertedCurrencies []string       `json:"unconverted_currencies"` // Currencies without a rate, their transactions are left out of the totals
+	Data                  []TagDataPoint `json:"data"`
+	Period                PeriodInfo     `json:"period"`
 }
 
 type PeriodInfo struct {
@@ -93,13 +128,67 @@ type DashboardAnalyticsRequest struct {
 }
 
 type DashboardAnalyticsResponse struct {
-	TotalBalance      float64    `json:"total_balance"`          // Total income - expense all time
-	Income            float64    `json:"monthly_income"`         // Income in date range
-	Expense           float64    `json:"monthly_expense"`        // Expense in date range
-	IncomeChange      float64    `json:"monthly_income_change"`  // Percentage change from previous period
-	ExpenseChange     float64    `json:"monthly_expense_change"` // Percentage change from previous period
-	StartDate         *time.Time `json:"start_date"`
-	EndDate           *time.Time `json:"end_date"`
-	PreviousStartDate *time.Time `json:"previous_start_date"`
-	PreviousEndDate   *time.Time `json:"previous_end_date"`
+	Currency              string      `json:"currency"`               // Amounts are converted into the user's base currency
+	UnconvertedCurrencies []string    `json:"unconverted_currencies"` // Currencies without a rate, their transactions are left out of the totals
+	TotalBalance          types.Money `json:"total_balance"`          // Total income - expense all time
+	Income                types.Money `json:"monthly_income"`         // Income in date range
+	Expense               types.Money `json:"monthly_expense"`        // Expense in date range
+	IncomeChange          float64     `json:"monthly_income_change"`  // Percentage change from previous period
+	ExpenseChange         float64     `json:"monthly_expense_change"` // Percentage change from previous period
+	StartDate             *time.Time  `json:"start_date"`
+	EndDate               *time.Time  `json:"end_date"`
+	PreviousStartDate     *time.Time  `json:"previous_start_date"`
+	PreviousEndDate       *time.Time  `json:"previous_end_date"`
+	TotalDebt             types.Money `json:"total_debt"`        // Outstanding principal of all loans
+	InstallmentsDue       types.Money `json:"installments_due"`  // Loan installments falling due in date range
+	InstallmentsPaid      types.Money `json:"installments_paid"` // Part of the installments due already paid
+	ActiveLoans           int64       `json:"active_loans"`
+	NetWorth              types.Money `json:"net_worth"` // Total balance minus total debt
+}
+
+// NetWorthRequest defaults to the last twelve months in MONTHLY points
+type NetWorthRequest struct {
+	PhoneNumber string     `form:"phone_number" validate:"required"`
+	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"`
+	EndDate     *time.Time `form:"end_date" validate:"omitempty" time_format:"2006-01-02"`
+	Interval    *string    `form:"interval" validate:"omitempty,oneof=DAILY WEEKLY MONTHLY"`
+}
+
+// NetWorthPoint is the net worth on a day split by where the money sits
+type NetWorthPoint struct {
+	Date             time.Time   `json:"date"`
+	Accounts         types.Money `json:"accounts"`
+	Investments      types.Money `json:"investments"`
+	Property         types.Money `json:"property"`
+	Vehicles         types.Money `json:"vehicles"`
+	OtherAssets      types.Money `json:"other_assets"`
+	CreditCards      types.Money `json:"credit_cards"`
+	Loans            types.Money `json:"loans"`
+	OtherLiabilities types.Money `json:"other_liabilities"`
+	TotalAssets      types.Money `json:"total_assets"`
+	TotalLiabilities types.Money `json:"total_liabilities"`
+	NetWorth         types.Money `json:"net_worth"`
+}
+
+// NetWorthItem is an account, an asset entered by hand or a loan counted in the net worth
+type NetWorthItem struct {
+	Source      string      `json:"source"` // ACCOUNT, ASSET or LOAN
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Value       types.Money `json:"value"` // In the item's currency, liabilities are positive
+	Currency    string      `json:"currency"`
+	ValueInBase types.Money `json:"value_in_base"`
+}
+
+type NetWorthResponse struct {
+	Currency         string          `json:"currency"` // Amounts are converted into the user's base currency
+	Current          NetWorthPoint   `json:"current"`
+	Assets           []NetWorthItem  `json:"assets"`
+	Liabilities      []NetWorthItem  `json:"liabilities"`
+	Change           types.Money     `json:"change"`            // Current net worth against the first point
+	ChangePercentage float64         `json:"change_percentage"` // Relative to the first point
+	Interval         string          `json:"interval"`
+	Series           []NetWorthPoint `json:"series"` // Last snapshot of every interval, today's point is live
+	Period           PeriodInfo      `json:"period"`
 }
@@ -0,0 +1,327 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/service/analytics/dto"
+	"time"
+)
+
+// snapshotBatchSize is how many users a run of the snapshot job handles, the rest wait for the next run
+const snapshotBatchSize = 500
+
+const (
+	netWorthDaily   = "DAILY"
+	netWorthWeekly  = "WEEKLY"
+	netWorthMonthly = "MONTHLY"
+)
+
+// TakeNetWorthSnapshots records today's net worth of the users who have none yet
+func (s *Service) TakeNetWorthSnapshots(now time.Time) error {
+	today := startOfDay(now)
+	users, err := s.rp.NetWorth.GetUsersWithoutSnapshot(today, snapshotBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		point, _, _, err := s.computeNetWorth(user, now)
+		if err != nil {
+			logger.Error.Printf("Error computing net worth of user %d: %v", user.ID, err)
+			continue
+		}
+		if err := s.rp.NetWorth.SaveSnapshot(toNetWorthSnapshot(user, *point)); err != nil {
+			logger.Error.Printf("Error saving net worth snapshot of user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetNetWorthRequest(payload dto.NetWorthRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	now := time.Now()
+	today := startOfDay(now)
+	endDate := today
+	if payload.EndDate != nil {
+		endDate = startOfDay(*payload.EndDate)
+	}
+	startDate := endDate.AddDate(-1, 0, 0)
+	if payload.StartDate != nil {
+		startDate = startOfDay(*payload.StartDate)
+	}
+	interval := netWorthMonthly
+	if payload.Interval != nil {
+		interval = *payload.Interval
+	}
+
+	current, assets, liabilities, err := s.computeNetWorth(*user, now)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get net worth",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	snapshots, err := s.rp.NetWorth.GetSnapshots(user.ID, startDate, endDate)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get net worth",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	points := make([]dto.NetWorthPoint, 0, len(snapshots)+1)
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Format("2006-01-02") != today.Format("2006-01-02") {
+			points = append(points, toNetWorthPoint(snapshot))
+		}
+	}
+	// Today's point is always the live figure, the snapshot may be hours old
+	if !today.Before(startDate) && !today.After(endDate) {
+		points = append(points, *current)
+	}
+	series := bucketNetWorth(points, interval)
+
+	response := dto.NetWorthResponse{
+		Currency:    user.BaseCurrency,
+		Current:     *current,
+		Assets:      assets,
+		Liabilities: liabilities,
+		Interval:    interval,
+		Series:      series,
+		Period: dto.PeriodInfo{
+			StartDate: &startDate,
+			EndDate:   &endDate,
+		},
+	}
+	if len(series) > 0 {
+		first := series[0].NetWorth
+		response.Change = current.NetWorth - first
+		if first != 0 {
+			response.ChangePercentage = math.Round(response.Change.Float64()/math.Abs(first.Float64())*10000) / 100
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Net worth retrieved successfully",
+		Data:    response,
+	})
+}
+
+// computeNetWorth adds up the user's accounts, assets entered by hand and loans in the base currency.
+// Accounts in the red count as liabilities, credit cards apart from the other accounts.
+func (s *Service) computeNetWorth(user models.User, now time.Time) (*dto.NetWorthPoint, []dto.NetWorthItem, []dto.NetWorthItem, error) {
+	rates := map[string]float64{}
+	inBase := func(amount types.Money, currency string) (types.Money, error) {
+		rate, ok := rates[currency]
+		if !ok {
+			found, err := s.rp.ExchangeRate.GetRate(currency, user.BaseCurrency, now)
+			if err != nil {
+				return 0, err
+			}
+			// Without a rate the amount counts at face value, like the analytics totals
+			rate = 1
+			if found != nil {
+				rate = *found
+			}
+			rates[currency] = rate
+		}
+		return amount.Mul(rate), nil
+	}
+
+	point := dto.NetWorthPoint{Date: startOfDay(now)}
+	assets := []dto.NetWorthItem{}
+	liabilities := []dto.NetWorthItem{}
+
+	balances, err := s.rp.Account.GetAccountBalances(user.ID, nil, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, b := range balances {
+		balance := b.Balance()
+		if balance == 0 {
+			continue
+		}
+		value, err := inBase(balance.Abs(), b.Currency)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		item := dto.NetWorthItem{
+			Source:      "ACCOUNT",
+			ID:          b.AccountID,
+			Name:        b.Name,
+			Type:        string(b.Type),
+			Value:       balance.Abs(),
+			Currency:    b.Currency,
+			ValueInBase: value,
+		}
+		switch {
+		case balance > 0:
+			point.Accounts += value
+			assets = append(assets, item)
+		case b.Type == models.AccountTypeCreditCard:
+			point.CreditCards += value
+			liabilities = append(liabilities, item)
+		default:
+			point.OtherLiabilities += value
+			liabilities = append(liabilities, item)
+		}
+	}
+
+	manual, err := s.rp.NetWorth.GetAssetsByUserID(user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, a := range manual {
+		value, err := inBase(a.Value, a.Currency)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		item := dto.NetWorthItem{
+			Source:      "ASSET",
+			ID:          a.ID,
+			Name:        a.Name,
+			Type:        string(a.Type),
+			Value:       a.Value,
+			Currency:    a.Currency,
+			ValueInBase: value,
+		}
+		switch a.Type {
+		case models.AssetTypeLiability:
+			point.OtherLiabilities += value
+			liabilities = append(liabilities, item)
+			continue
+		case models.AssetTypeInvestment:
+			point.Investments += value
+		case models.AssetTypeProperty:
+			point.Property += value
+		case models.AssetTypeVehicle:
+			point.Vehicles += value
+		default:
+			point.OtherAssets += value
+		}
+		assets = append(assets, item)
+	}
+
+	loans, err := s.rp.Loan.GetLoansByUserID(user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, l := range loans {
+		outstanding := l.Principal
+		for _, installment := range l.Installments {
+			if installment.PaidAt != nil {
+				outstanding -= installment.Principal
+			}
+		}
+		if outstanding <= 0 {
+			continue
+		}
+		value, err := inBase(outstanding, l.Currency)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		point.Loans += value
+		liabilities = append(liabilities, dto.NetWorthItem{
+			Source:      "LOAN",
+			ID:          l.ID,
+			Name:        l.Name,
+			Type:        string(l.InterestType),
+			Value:       outstanding,
+			Currency:    l.Currency,
+			ValueInBase: value,
+		})
+	}
+
+	point.TotalAssets = point.Accounts + point.Investments + point.Property + point.Vehicles + point.OtherAssets
+	point.TotalLiabilities = point.CreditCards + point.Loans + point.OtherLiabilities
+	point.NetWorth = point.TotalAssets - point.TotalLiabilities
+	return &point, assets, liabilities, nil
+}
+
+// bucketNetWorth keeps the last point of every day, week or month, the points are in date order
+func bucketNetWorth(points []dto.NetWorthPoint, interval string) []dto.NetWorthPoint {
+	key := func(date time.Time) string {
+		switch interval {
+		case netWorthDaily:
+			return date.Format("2006-01-02")
+		case netWorthWeekly:
+			year, week := date.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		default:
+			return date.Format("2006-01")
+		}
+	}
+
+	series := []dto.NetWorthPoint{}
+	lastKey := ""
+	for _, point := range points {
+		k := key(point.Date)
+		if len(series) > 0 && k == lastKey {
+			series[len(series)-1] = point
+			continue
+		}
+		series = append(series, point)
+		lastKey = k
+	}
+	return series
+}
+
+func toNetWorthPoint(snapshot models.NetWorthSnapshot) dto.NetWorthPoint {
+	return dto.NetWorthPoint{
+		Date:             snapshot.Date,
+		Accounts:         snapshot.Accounts,
+		Investments:      snapshot.Investments,
+		Property:         snapshot.Property,
+		Vehicles:         snapshot.Vehicles,
+		OtherAssets:      snapshot.OtherAssets,
+		CreditCards:      snapshot.CreditCards,
+		Loans:            snapshot.Loans,
+		OtherLiabilities: snapshot.OtherLiabilities,
+		TotalAssets:      snapshot.TotalAssets,
+		TotalLiabilities: snapshot.TotalLiabilities,
+		NetWorth:         snapshot.NetWorth,
+	}
+}
+
+func toNetWorthSnapshot(user models.User, point dto.NetWorthPoint) models.NetWorthSnapshot {
+	return models.NetWorthSnapshot{
+		UserID:           user.ID,
+		Date:             point.Date,
+		Currency:         user.BaseCurrency,
+		Accounts:         point.Accounts,
+		Investments:      point.Investments,
+		Property:         point.Property,
+		Vehicles:         point.Vehicles,
+		OtherAssets:      point.OtherAssets,
+		CreditCards:      point.CreditCards,
+		Loans:            point.Loans,
+		OtherLiabilities: point.OtherLiabilities,
+		TotalAssets:      point.TotalAssets,
+		TotalLiabilities: point.TotalLiabilities,
+		NetWorth:         point.NetWorth,
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,54 @@
+package analytics
+
+import (
+	types "pannypal/internal/common/type"
+	"pannypal/internal/service/analytics/dto"
+	"testing"
+	"time"
+)
+
+func TestBucketNetWorth(t *testing.T) {
+	point := func(year int, month time.Month, day int, netWorth types.Money) dto.NetWorthPoint {
+		return dto.NetWorthPoint{Date: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), NetWorth: netWorth}
+	}
+	points := []dto.NetWorthPoint{
+		point(2024, 12, 27, 100), // Friday of ISO week 52
+		point(2024, 12, 28, 110),
+		point(2024, 12, 30, 120), // Monday of ISO week 1 of 2025
+		point(2024, 12, 31, 130),
+		point(2025, 1, 1, 140),
+		point(2025, 1, 6, 150),
+		point(2025, 2, 3, 160),
+	}
+
+	tests := []struct {
+		interval string
+		want     []types.Money
+	}{
+		{interval: netWorthDaily, want: []types.Money{100, 110, 120, 130, 140, 150, 160}},
+		{interval: netWorthWeekly, want: []types.Money{110, 140, 150, 160}},
+		{interval: netWorthMonthly, want: []types.Money{130, 150, 160}},
+	}
+
+	for _, tt := range tests {
+		series := bucketNetWorth(points, tt.interval)
+		got := make([]types.Money, len(series))
+		for i, p := range series {
+			got[i] = p.NetWorth
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("bucketNetWorth(%s) = %v, want %v", tt.interval, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("bucketNetWorth(%s) = %v, want %v", tt.interval, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if series := bucketNetWorth(nil, netWorthMonthly); len(series) != 0 {
+		t.Errorf("bucketNetWorth(nil) = %v, want an empty series", series)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"pannypal/internal/repository"
 	"pannypal/internal/repository/analytics"
 	"pannypal/internal/service/analytics/dto"
+	"time"
 )
 
 type Service struct {
@@ -21,7 +22,10 @@ type IService interface {
 	GetMonthlyAnalyticsRequest(payload dto.MonthlyAnalyticsRequest) *types.Response
 	GetYearlyAnalyticsRequest(payload dto.YearlyAnalyticsRequest) *types.Response
 	GetCategoryAnalyticsRequest(payload dto.CategoryAnalyticsRequest) *types.Response
+	GetTagAnalyticsRequest(payload dto.TagAnalyticsRequest) *types.Response
 	GetDashboardAnalyticsRequest(payload dto.DashboardAnalyticsRequest) *types.Response
+	GetNetWorthRequest(payload dto.NetWorthRequest) *types.Response
+	TakeNetWorthSnapshots(now time.Time) error
 }
 
 func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, db *database.Database) IService {
@@ -0,0 +1,222 @@
+package asset
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/asset/dto"
+	"strings"
+	"time"
+)
+
+func (s *Service) CreateAssetRequest(payload dto.CreateAssetRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	currency := strings.ToUpper(payload.Currency)
+	if currency == "" {
+		currency = user.BaseCurrency
+	}
+	valuedAt := time.Now()
+	if payload.ValuedAt != nil {
+		valuedAt = *payload.ValuedAt
+	}
+
+	created, err := s.rp.NetWorth.CreateAsset(models.Asset{
+		UserID:   user.ID,
+		Name:     payload.Name,
+		Type:     models.AssetType(payload.Type),
+		Value:    payload.Value,
+		Currency: currency,
+		ValuedAt: valuedAt,
+		Note:     payload.Note,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create asset",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Asset created successfully",
+		Data:    toAssetResponse(*created),
+	})
+}
+
+func (s *Service) GetAssetsRequest(payload dto.GetAssetsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	assets, err := s.rp.NetWorth.GetAssetsByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get assets",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.AssetListResponse{
+		Assets:           make([]dto.AssetResponse, len(assets)),
+		TotalAssets:      map[string]types.Money{},
+		TotalLiabilities: map[string]types.Money{},
+	}
+	for i, asset := range assets {
+		response.Assets[i] = toAssetResponse(asset)
+		if asset.Type.IsLiability() {
+			response.TotalLiabilities[asset.Currency] += asset.Value
+		} else {
+			response.TotalAssets[asset.Currency] += asset.Value
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Assets retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetAssetByIDRequest(id uint, phoneNumber string) *types.Response {
+	asset, resp := s.getUserAsset(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Asset retrieved successfully",
+		Data:    toAssetResponse(*asset),
+	})
+}
+
+func (s *Service) UpdateAssetRequest(id uint, payload dto.UpdateAssetRequest, phoneNumber string) *types.Response {
+	asset, resp := s.getUserAsset(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.Name != nil {
+		asset.Name = *payload.Name
+	}
+	if payload.Type != nil {
+		asset.Type = models.AssetType(*payload.Type)
+	}
+	if payload.Value != nil {
+		asset.Value = *payload.Value
+		asset.ValuedAt = time.Now()
+	}
+	if payload.Currency != nil {
+		asset.Currency = strings.ToUpper(*payload.Currency)
+	}
+	if payload.ValuedAt != nil {
+		asset.ValuedAt = *payload.ValuedAt
+	}
+	if payload.Note != nil {
+		asset.Note = *payload.Note
+	}
+
+	updated, err := s.rp.NetWorth.UpdateAsset(*asset)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update asset",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Asset updated successfully",
+		Data:    toAssetResponse(*updated),
+	})
+}
+
+func (s *Service) DeleteAssetRequest(id uint, phoneNumber string) *types.Response {
+	_, resp := s.getUserAsset(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.NetWorth.DeleteAsset(id); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete asset",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Asset deleted successfully",
+		Data:    nil,
+	})
+}
+
+// getUserAsset loads the asset, which must belong to the user with the phone number
+func (s *Service) getUserAsset(id uint, phoneNumber string) (*models.Asset, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	asset, err := s.rp.NetWorth.GetAssetByID(id)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Asset not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if asset.UserID != user.ID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return asset, nil
+}
+
+func toAssetResponse(a models.Asset) dto.AssetResponse {
+	return dto.AssetResponse{
+		ID:          a.ID,
+		Name:        a.Name,
+		Type:        a.Type,
+		IsLiability: a.Type.IsLiability(),
+		Value:       a.Value,
+		Currency:    a.Currency,
+		ValuedAt:    a.ValuedAt,
+		Note:        a.Note,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+// CreateAssetRequest adds an investment, property, vehicle or other asset entered by hand, LIABILITY is
+// money owed that is neither a loan nor a credit card
+type CreateAssetRequest struct {
+	PhoneNumber string      `json:"phone_number" validate:"required"`
+	Name        string      `json:"name" validate:"required,max=100"`
+	Type        string      `json:"type" validate:"required,oneof=INVESTMENT PROPERTY VEHICLE OTHER LIABILITY"`
+	Value       types.Money `json:"value" validate:"gte=0"`
+	Currency    string      `json:"currency" validate:"omitempty,iso4217"` // Defaults to the user's base currency
+	ValuedAt    *time.Time  `json:"valued_at" validate:"omitempty"`        // Defaults to today
+	Note        string      `json:"note" validate:"omitempty"`
+}
+
+// UpdateAssetRequest changes the asset, a new value without valued_at is dated today
+type UpdateAssetRequest struct {
+	Name     *string      `json:"name" validate:"omitempty,max=100"`
+	Type     *string      `json:"type" validate:"omitempty,oneof=INVESTMENT PROPERTY VEHICLE OTHER LIABILITY"`
+	Value    *types.Money `json:"value" validate:"omitempty,gte=0"`
+	Currency *string      `json:"currency" validate:"omitempty,iso4217"`
+	ValuedAt *time.Time   `json:"valued_at" validate:"omitempty"`
+	Note     *string      `json:"note" validate:"omitempty"`
+}
+
+type GetAssetsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+type AssetResponse struct {
+	ID          uint             `json:"id"`
+	Name        string           `json:"name"`
+	Type        models.AssetType `json:"type"`
+	IsLiability bool             `json:"is_liability"`
+	Value       types.Money      `json:"value"`
+	Currency    string           `json:"currency"`
+	ValuedAt    time.Time        `json:"valued_at"`
+	Note        string           `json:"note"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+type AssetListResponse struct {
+	Assets           []AssetResponse        `json:"assets"`
+	TotalAssets      map[string]types.Money `json:"total_assets"`      // Per currency
+	TotalLiabilities map[string]types.Money `json:"total_liabilities"` // Per currency
+}
@@ -0,0 +1,31 @@
+package asset
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/asset/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	CreateAssetRequest(payload dto.CreateAssetRequest) *types.Response
+	GetAssetsRequest(payload dto.GetAssetsRequest) *types.Response
+	GetAssetByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateAssetRequest(id uint, payload dto.UpdateAssetRequest, phoneNumber string) *types.Response
+	DeleteAssetRequest(id uint, phoneNumber string) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
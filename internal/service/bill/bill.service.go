@@ -0,0 +1,534 @@
+package bill
+
+import (
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/bill/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *Service) CreateBillRequest(payload dto.CreateBillRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	bill := models.Bill{
+		UserID:           user.ID,
+		Name:             payload.Name,
+		Payee:            strings.TrimSpace(payload.Payee),
+		DueDay:           payload.DueDay,
+		ExpectedAmount:   payload.ExpectedAmount,
+		Currency:         strings.ToUpper(payload.Currency),
+		RemindDaysBefore: 3,
+		StartDate:        startOfDay(time.Now()),
+		IsActive:         true,
+	}
+	if payload.RemindDaysBefore != nil {
+		bill.RemindDaysBefore = *payload.RemindDaysBefore
+	}
+	if payload.StartDate != nil {
+		bill.StartDate = startOfDay(*payload.StartDate)
+	}
+	if resp := s.linkBill(&bill, payload.AccountID, payload.CategoryID); resp != nil {
+		return resp
+	}
+	if bill.Currency == "" {
+		bill.Currency = user.BaseCurrency
+		if bill.Account != nil {
+			bill.Currency = bill.Account.Currency
+		}
+	}
+
+	created, err := s.rp.Bill.CreateBill(bill)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.billResponse(http.StatusCreated, "Bill created successfully", created.ID)
+}
+
+func (s *Service) GetBillsRequest(payload dto.GetBillsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	bills, err := s.rp.Bill.GetBillsByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get bills",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	today := startOfDay(time.Now())
+	response := dto.BillListResponse{Bills: make([]dto.BillResponse, len(bills))}
+	for i, bill := range bills {
+		cycles, err := s.loadCycles(bill, today)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to get bills",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		response.Bills[i] = toBillResponse(bill, cycles, today)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Bills retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetBillByIDRequest(id uint, phoneNumber string) *types.Response {
+	_, resp := s.getUserBill(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return s.billResponse(http.StatusOK, "Bill retrieved successfully", id)
+}
+
+func (s *Service) UpdateBillRequest(id uint, payload dto.UpdateBillRequest, phoneNumber string) *types.Response {
+	bill, resp := s.getUserBill(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.Name != nil {
+		bill.Name = *payload.Name
+	}
+	if payload.Payee != nil {
+		bill.Payee = strings.TrimSpace(*payload.Payee)
+	}
+	if payload.DueDay != nil {
+		bill.DueDay = *payload.DueDay
+	}
+	if payload.ExpectedAmount != nil {
+		bill.ExpectedAmount = *payload.ExpectedAmount
+	}
+	if payload.Currency != nil {
+		bill.Currency = strings.ToUpper(*payload.Currency)
+	}
+	if payload.RemindDaysBefore != nil {
+		bill.RemindDaysBefore = *payload.RemindDaysBefore
+	}
+	if payload.StartDate != nil {
+		bill.StartDate = startOfDay(*payload.StartDate)
+	}
+	if payload.IsActive != nil {
+		bill.IsActive = *payload.IsActive
+	}
+	if resp := s.linkBill(bill, payload.AccountID, payload.CategoryID); resp != nil {
+		return resp
+	}
+
+	if _, err := s.rp.Bill.UpdateBill(*bill); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.billResponse(http.StatusOK, "Bill updated successfully", id)
+}
+
+func (s *Service) DeleteBillRequest(id uint, phoneNumber string) *types.Response {
+	_, resp := s.getUserBill(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Bill.DeleteBill(id); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Bill deleted successfully",
+		Data:    nil,
+	})
+}
+
+func (s *Service) PayBillRequest(id uint, payload dto.PayBillRequest, phoneNumber string) *types.Response {
+	bill, resp := s.getUserBill(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	today := startOfDay(time.Now())
+	cycles, err := s.loadCycles(*bill, today)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to pay bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	var cycle *models.BillPayment
+	for i := range cycles {
+		if payload.DueDate != nil {
+			if cycles[i].DueDate.Format("2006-01-02") == payload.DueDate.Format("2006-01-02") {
+				cycle = &cycles[i]
+				break
+			}
+		} else if cycles[i].PaidAt == nil {
+			cycle = &cycles[i]
+			break
+		}
+	}
+	if cycle == nil && payload.DueDate != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Due date not found",
+			Data:    nil,
+		})
+	}
+	if cycle == nil || cycle.PaidAt != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Bill is already paid",
+			Data:    nil,
+		})
+	}
+
+	var payment *models.Transaction
+	amount := bill.ExpectedAmount
+	if payload.TransactionID != nil {
+		payment, resp = s.billTransaction(*payload.TransactionID, bill.UserID)
+		if resp != nil {
+			return resp
+		}
+	} else {
+		if payload.Amount != nil {
+			amount = *payload.Amount
+		}
+		if amount <= 0 {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Amount is required when the bill has no expected amount",
+				Data:    nil,
+			})
+		}
+	}
+
+	// A created payment and the due date it pays are saved together, a failure leaves neither behind
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if payment == nil {
+			date := time.Now()
+			if payload.Date != nil {
+				date = *payload.Date
+			}
+			created, err := rp.Transaction.CreateTransaction(models.Transaction{
+				UserID:          bill.UserID,
+				AccountID:       bill.AccountID,
+				CategoryID:      bill.CategoryID,
+				Type:            models.TypeExpense,
+				Amount:          amount,
+				Currency:        bill.Currency,
+				Description:     fmt.Sprintf("Tagihan %s %s", bill.Name, cycle.DueDate.Format("01-2006")),
+				Merchant:        bill.Payee,
+				TransactionDate: date,
+			})
+			if err != nil {
+				return err
+			}
+			payment = created
+
+			actor := models.RevisionActor{
+				ActorType: models.RevisionActorUser,
+				ActorID:   &bill.UserID,
+				Source:    models.RevisionSourceBill,
+			}
+			if err := rp.Revision.Record(models.RevisionActionCreate, actor, nil, created); err != nil {
+				return err
+			}
+		}
+
+		cycle.TransactionID = &payment.ID
+		cycle.Amount = payment.Amount
+		cycle.PaidAt = &payment.TransactionDate
+		_, err := rp.Bill.SavePayment(*cycle)
+		return err
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to pay bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.billResponse(http.StatusOK, "Bill paid successfully", id)
+}
+
+// GetOverdueBillsRequest lists the unpaid due dates of the user's active bills that have passed,
+// the longest overdue first
+func (s *Service) GetOverdueBillsRequest(payload dto.GetBillsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	bills, err := s.rp.Bill.GetBillsByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get overdue bills",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	today := startOfDay(time.Now())
+	response := dto.OverdueReportResponse{
+		Bills: []dto.OverdueBillResponse{},
+		Total: map[string]types.Money{},
+	}
+	for _, bill := range bills {
+		if !bill.IsActive {
+			continue
+		}
+		cycles, err := s.loadCycles(bill, today)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to get overdue bills",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		for _, cycle := range overdueCycles(cycles, today) {
+			response.Bills = append(response.Bills, dto.OverdueBillResponse{
+				BillID:         bill.ID,
+				Name:           bill.Name,
+				Payee:          bill.Payee,
+				DueDate:        cycle.DueDate,
+				DaysOverdue:    daysBetween(cycle.DueDate, today),
+				ExpectedAmount: bill.ExpectedAmount,
+				Currency:       bill.Currency,
+			})
+			response.Total[bill.Currency] += bill.ExpectedAmount
+		}
+	}
+	sort.SliceStable(response.Bills, func(i, j int) bool {
+		return response.Bills[i].DueDate.Before(response.Bills[j].DueDate)
+	})
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Overdue bills retrieved successfully",
+		Data:    response,
+	})
+}
+
+// overdueCycles keeps the unpaid due dates before today
+func overdueCycles(cycles []models.BillPayment, today time.Time) []models.BillPayment {
+	var overdue []models.BillPayment
+	for _, cycle := range cycles {
+		if cycle.PaidAt == nil && cycle.DueDate.Before(today) {
+			overdue = append(overdue, cycle)
+		}
+	}
+	return overdue
+}
+
+// getUserBill loads the bill, which must belong to the user with the phone number
+func (s *Service) getUserBill(id uint, phoneNumber string) (*models.Bill, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	bill, err := s.rp.Bill.GetBillByID(id)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Bill not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if bill.UserID != user.ID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return bill, nil
+}
+
+// linkBill links the bill to the user's account and expense category, 0 unlinks them
+func (s *Service) linkBill(bill *models.Bill, accountID, categoryID *uint) *types.Response {
+	if accountID != nil {
+		bill.AccountID = nil
+		bill.Account = nil
+		if *accountID != 0 {
+			account, err := s.rp.Account.GetAccountByID(*accountID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Account not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if account.UserID != bill.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			bill.AccountID = &account.ID
+			bill.Account = account
+		}
+	}
+
+	if categoryID != nil {
+		bill.CategoryID = nil
+		bill.Category = nil
+		if *categoryID != 0 {
+			category, err := s.rp.Category.GetCategoryByID(*categoryID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Category not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if category.UserID == nil || *category.UserID != bill.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			if !category.Kind.Allows(models.TypeExpense) {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusBadRequest,
+					Message: "Bills need an expense category",
+					Data:    nil,
+				})
+			}
+			bill.CategoryID = &category.ID
+			bill.Category = category
+		}
+	}
+	return nil
+}
+
+// billTransaction checks the transaction can pay the bill, it has to be one of the user's expenses
+// that pays no other due date yet
+func (s *Service) billTransaction(transactionID, userID uint) (*models.Transaction, *types.Response) {
+	transaction, err := s.rp.Transaction.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if transaction.UserID != userID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	if transaction.Type != models.TypeExpense {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Bills are paid by expense transactions",
+			Data:    nil,
+		})
+	}
+	if linked, err := s.rp.Bill.GetPaymentByTransactionID(transactionID); err == nil && linked != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Transaction already pays a bill",
+			Data:    nil,
+		})
+	}
+	return transaction, nil
+}
+
+// billResponse loads the bill again with its due dates
+func (s *Service) billResponse(code int, message string, id uint) *types.Response {
+	bill, err := s.rp.Bill.GetBillByID(id)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	today := startOfDay(time.Now())
+	cycles, err := s.loadCycles(*bill, today)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get bill",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    code,
+		Message: message,
+		Data:    toBillDetailResponse(*bill, cycles, today),
+	})
+}
@@ -0,0 +1,105 @@
+package dto
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+// CreateBillRequest adds a monthly bill due on DueDay, clamped to the month length. Expenses to the payee
+// or in the category pay it automatically, an ExpectedAmount of 0 matches them whatever their amount.
+type CreateBillRequest struct {
+	PhoneNumber      string      `json:"phone_number" validate:"required"`
+	Name             string      `json:"name" validate:"required,max=100"`
+	Payee            string      `json:"payee" validate:"omitempty,max=150"`
+	DueDay           int         `json:"due_day" validate:"required,min=1,max=31"`
+	ExpectedAmount   types.Money `json:"expected_amount" validate:"gte=0"`
+	Currency         string      `json:"currency" validate:"omitempty,iso4217"` // Defaults to the account's or the user's currency
+	CategoryID       *uint       `json:"category_id" validate:"omitempty"`
+	AccountID        *uint       `json:"account_id" validate:"omitempty"`
+	RemindDaysBefore *int        `json:"remind_days_before" validate:"omitempty,min=0,max=28"` // Defaults to 3
+	StartDate        *time.Time  `json:"start_date" validate:"omitempty"`                      // First due date counted, defaults to today
+}
+
+// UpdateBillRequest changes the bill, 0 for account_id or category_id unlinks them
+type UpdateBillRequest struct {
+	Name             *string      `json:"name" validate:"omitempty,max=100"`
+	Payee            *string      `json:"payee" validate:"omitempty,max=150"`
+	DueDay           *int         `json:"due_day" validate:"omitempty,min=1,max=31"`
+	ExpectedAmount   *types.Money `json:"expected_amount" validate:"omitempty,gte=0"`
+	Currency         *string      `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID       *uint        `json:"category_id" validate:"omitempty"`
+	AccountID        *uint        `json:"account_id" validate:"omitempty"`
+	RemindDaysBefore *int         `json:"remind_days_before" validate:"omitempty,min=0,max=28"`
+	StartDate        *time.Time   `json:"start_date" validate:"omitempty"`
+	IsActive         *bool        `json:"is_active" validate:"omitempty"`
+}
+
+type GetBillsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+// PayBillRequest marks a due date of the bill paid, the earliest unpaid one unless due_date is given. It
+// links the expense transaction_id, or records an expense of amount on the bill's account and category.
+type PayBillRequest struct {
+	DueDate       *time.Time   `json:"due_date" validate:"omitempty"`
+	TransactionID *uint        `json:"transaction_id" validate:"omitempty"`
+	Amount        *types.Money `json:"amount" validate:"omitempty,gt=0"` // Defaults to the expected amount
+	Date          *time.Time   `json:"date" validate:"omitempty"`        // Date of the recorded expense, defaults to today
+}
+
+type BillCycleResponse struct {
+	DueDate       time.Time   `json:"due_date"`
+	TransactionID *uint       `json:"transaction_id"`
+	Amount        types.Money `json:"amount"`
+	PaidAt        *time.Time  `json:"paid_at"`
+	RemindedAt    *time.Time  `json:"reminded_at"`
+	IsPaid        bool        `json:"is_paid"`
+	IsOverdue     bool        `json:"is_overdue"`
+	DaysOverdue   int         `json:"days_overdue"`
+}
+
+type BillResponse struct {
+	ID               uint        `json:"id"`
+	Name             string      `json:"name"`
+	Payee            string      `json:"payee"`
+	DueDay           int         `json:"due_day"`
+	ExpectedAmount   types.Money `json:"expected_amount"`
+	Currency         string      `json:"currency"`
+	CategoryID       *uint       `json:"category_id"`
+	CategoryName     string      `json:"category_name,omitempty"`
+	AccountID        *uint       `json:"account_id"`
+	AccountName      string      `json:"account_name,omitempty"`
+	RemindDaysBefore int         `json:"remind_days_before"`
+	StartDate        time.Time   `json:"start_date"`
+	IsActive         bool        `json:"is_active"`
+	NextDueDate      time.Time   `json:"next_due_date"`
+	IsNextPaid       bool        `json:"is_next_paid"` // The next due date is already paid
+	OverdueCount     int         `json:"overdue_count"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// BillDetailResponse adds the due dates of the last year up to the next one
+type BillDetailResponse struct {
+	BillResponse
+	Cycles []BillCycleResponse `json:"cycles"`
+}
+
+type BillListResponse struct {
+	Bills []BillResponse `json:"bills"`
+}
+
+type OverdueBillResponse struct {
+	BillID         uint        `json:"bill_id"`
+	Name           string      `json:"name"`
+	Payee          string      `json:"payee"`
+	DueDate        time.Time   `json:"due_date"`
+	DaysOverdue    int         `json:"days_overdue"`
+	ExpectedAmount types.Money `json:"expected_amount"`
+	Currency       string      `json:"currency"`
+}
+
+type OverdueReportResponse struct {
+	Bills []OverdueBillResponse  `json:"bills"`
+	Total map[string]types.Money `json:"total"` // Expected amount per currency
+}
@@ -0,0 +1,39 @@
+package bill
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/bill/dto"
+	"pannypal/internal/service/outgoing"
+	"time"
+)
+
+type Service struct {
+	ctx      context.Context
+	redis    redis.IRedis
+	rp       repository.IRepository
+	outgoing outgoing.IService
+}
+
+type IService interface {
+	ProcessBills(now time.Time) error
+
+	CreateBillRequest(payload dto.CreateBillRequest) *types.Response
+	GetBillsRequest(payload dto.GetBillsRequest) *types.Response
+	GetBillByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateBillRequest(id uint, payload dto.UpdateBillRequest, phoneNumber string) *types.Response
+	DeleteBillRequest(id uint, phoneNumber string) *types.Response
+	PayBillRequest(id uint, payload dto.PayBillRequest, phoneNumber string) *types.Response
+	GetOverdueBillsRequest(payload dto.GetBillsRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, outgoing outgoing.IService) IService {
+	return &Service{
+		ctx:      ctx,
+		redis:    redis,
+		rp:       repository,
+		outgoing: outgoing,
+	}
+}
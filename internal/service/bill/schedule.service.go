@@ -0,0 +1,145 @@
+package bill
+
+import (
+	"pannypal/internal/common/models"
+	"pannypal/internal/service/bill/dto"
+	"time"
+)
+
+// cycleMonths is how far back due dates are tracked, older unpaid ones are neither matched nor reported
+const cycleMonths = 12
+
+// loadCycles returns the due dates of the bill from cycleMonths ago up to the next one with what
+// happened to them. Due dates nothing happened to yet have no ID.
+func (s *Service) loadCycles(bill models.Bill, today time.Time) ([]models.BillPayment, error) {
+	payments, err := s.rp.Bill.GetPayments(bill.ID)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]models.BillPayment, len(payments))
+	for _, payment := range payments {
+		byDate[payment.DueDate.Format("2006-01-02")] = payment
+	}
+
+	from := today.AddDate(0, -cycleMonths, 0)
+	if start := localDate(bill.StartDate, today.Location()); start.After(from) {
+		from = start
+	}
+	next := nextDueDate(bill, today)
+
+	var cycles []models.BillPayment
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, today.Location()); !month.After(next); month = month.AddDate(0, 1, 0) {
+		date := dueDateIn(month, bill.DueDay)
+		if date.Before(from) || date.After(next) {
+			continue
+		}
+		cycle, ok := byDate[date.Format("2006-01-02")]
+		if !ok {
+			cycle = models.BillPayment{BillID: bill.ID}
+		}
+		cycle.DueDate = date
+		cycles = append(cycles, cycle)
+	}
+	return cycles, nil
+}
+
+// nextDueDate is the first due date of the bill from today on, not before its start date
+func nextDueDate(bill models.Bill, today time.Time) time.Time {
+	from := today
+	if start := localDate(bill.StartDate, today.Location()); start.After(from) {
+		from = start
+	}
+	date := dueDateIn(from, bill.DueDay)
+	if date.Before(from) {
+		date = dueDateIn(time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location()), bill.DueDay)
+	}
+	return date
+}
+
+// previousDueDate is the due date of the month before, payments made after it count for date
+func previousDueDate(bill models.Bill, date time.Time) time.Time {
+	return dueDateIn(time.Date(date.Year(), date.Month()-1, 1, 0, 0, 0, 0, date.Location()), bill.DueDay)
+}
+
+// dueDateIn is the day of the month of date, clamped to the month length so a bill due on the 31st
+// falls on the 28th or 29th in February
+func dueDateIn(date time.Time, day int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, date.Location())
+}
+
+// localDate moves a date column, read back at midnight UTC, to the same day in loc
+func localDate(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween counts the calendar days from from to to, both at the start of a day
+func daysBetween(from, to time.Time) int {
+	return int((to.Sub(from).Hours() + 12) / 24)
+}
+
+func toBillResponse(bill models.Bill, cycles []models.BillPayment, today time.Time) dto.BillResponse {
+	response := dto.BillResponse{
+		ID:               bill.ID,
+		Name:             bill.Name,
+		Payee:            bill.Payee,
+		DueDay:           bill.DueDay,
+		ExpectedAmount:   bill.ExpectedAmount,
+		Currency:         bill.Currency,
+		CategoryID:       bill.CategoryID,
+		AccountID:        bill.AccountID,
+		RemindDaysBefore: bill.RemindDaysBefore,
+		StartDate:        bill.StartDate,
+		IsActive:         bill.IsActive,
+		CreatedAt:        bill.CreatedAt,
+		UpdatedAt:        bill.UpdatedAt,
+	}
+	if bill.Category != nil {
+		response.CategoryName = bill.Category.Name
+	}
+	if bill.Account != nil {
+		response.AccountName = bill.Account.Name
+	}
+	// The cycles always end with the next due date
+	if len(cycles) > 0 {
+		next := cycles[len(cycles)-1]
+		response.NextDueDate = next.DueDate
+		response.IsNextPaid = next.PaidAt != nil
+	}
+	response.OverdueCount = len(overdueCycles(cycles, today))
+	return response
+}
+
+func toBillDetailResponse(bill models.Bill, cycles []models.BillPayment, today time.Time) dto.BillDetailResponse {
+	response := dto.BillDetailResponse{
+		BillResponse: toBillResponse(bill, cycles, today),
+		Cycles:       make([]dto.BillCycleResponse, len(cycles)),
+	}
+	for i, cycle := range cycles {
+		response.Cycles[i] = toBillCycleResponse(cycle, today)
+	}
+	return response
+}
+
+func toBillCycleResponse(cycle models.BillPayment, today time.Time) dto.BillCycleResponse {
+	response := dto.BillCycleResponse{
+		DueDate:       cycle.DueDate,
+		TransactionID: cycle.TransactionID,
+		Amount:        cycle.Amount,
+		PaidAt:        cycle.PaidAt,
+		RemindedAt:    cycle.RemindedAt,
+		IsPaid:        cycle.PaidAt != nil,
+		IsOverdue:     cycle.PaidAt == nil && cycle.DueDate.Before(today),
+	}
+	if response.IsOverdue {
+		response.DaysOverdue = daysBetween(cycle.DueDate, today)
+	}
+	return response
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,75 @@
+package bill
+
+import (
+	"pannypal/internal/common/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextDueDate(t *testing.T) {
+	tests := []struct {
+		dueDay int
+		start  time.Time
+		today  time.Time
+		want   time.Time
+	}{
+		{15, date(2024, 1, 1), date(2024, 3, 10), date(2024, 3, 15)},
+		{15, date(2024, 1, 1), date(2024, 3, 15), date(2024, 3, 15)},
+		{15, date(2024, 1, 1), date(2024, 3, 16), date(2024, 4, 15)},
+		{31, date(2024, 1, 1), date(2024, 2, 10), date(2024, 2, 29)},
+		{31, date(2024, 1, 1), date(2024, 4, 30), date(2024, 4, 30)},
+		{31, date(2024, 1, 1), date(2024, 12, 31), date(2024, 12, 31)},
+		{10, date(2024, 1, 1), date(2024, 12, 11), date(2025, 1, 10)},
+		{5, date(2024, 6, 10), date(2024, 3, 1), date(2024, 7, 5)},
+		{15, date(2024, 6, 10), date(2024, 3, 1), date(2024, 6, 15)},
+	}
+
+	for _, tt := range tests {
+		bill := models.Bill{DueDay: tt.dueDay, StartDate: tt.start}
+		if got := nextDueDate(bill, tt.today); !got.Equal(tt.want) {
+			t.Errorf("nextDueDate(day %d, start %s, today %s) = %s, want %s", tt.dueDay, tt.start.Format(time.DateOnly),
+				tt.today.Format(time.DateOnly), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestPreviousDueDate(t *testing.T) {
+	tests := []struct {
+		dueDay int
+		date   time.Time
+		want   time.Time
+	}{
+		{15, date(2024, 3, 15), date(2024, 2, 15)},
+		{15, date(2024, 1, 15), date(2023, 12, 15)},
+		{31, date(2024, 3, 31), date(2024, 2, 29)},
+		{31, date(2024, 5, 31), date(2024, 4, 30)},
+		{30, date(2024, 3, 30), date(2024, 2, 29)},
+	}
+
+	for _, tt := range tests {
+		bill := models.Bill{DueDay: tt.dueDay}
+		if got := previousDueDate(bill, tt.date); !got.Equal(tt.want) {
+			t.Errorf("previousDueDate(day %d, %s) = %s, want %s", tt.dueDay, tt.date.Format(time.DateOnly),
+				got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestOverdueCycles(t *testing.T) {
+	paidAt := date(2024, 2, 14)
+	cycles := []models.BillPayment{
+		{DueDate: date(2024, 1, 15)},
+		{DueDate: date(2024, 2, 15), PaidAt: &paidAt},
+		{DueDate: date(2024, 3, 15)},
+		{DueDate: date(2024, 4, 15)},
+	}
+
+	overdue := overdueCycles(cycles, date(2024, 4, 15))
+	if len(overdue) != 2 || !overdue[0].DueDate.Equal(date(2024, 1, 15)) || !overdue[1].DueDate.Equal(date(2024, 3, 15)) {
+		t.Errorf("overdueCycles = %+v, want the unpaid January and March cycles", overdue)
+	}
+}
@@ -0,0 +1,158 @@
+package bill
+
+import (
+	"errors"
+	"fmt"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProcessBills marks the due dates of the active bills paid when a matching expense was recorded, then
+// reminds the owners of the next due date and tells them about the ones they missed. Messages wait
+// while the owner is in quiet hours.
+func (s *Service) ProcessBills(now time.Time) error {
+	bills, err := s.rp.Bill.GetActiveBills()
+	if err != nil {
+		return err
+	}
+
+	settings := map[uint]*models.AlertSetting{}
+	for _, bill := range bills {
+		if err := s.processBill(bill, settings, now); err != nil {
+			logger.Error.Printf("Error processing bill %d: %v", bill.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) processBill(bill models.Bill, settings map[uint]*models.AlertSetting, now time.Time) error {
+	today := startOfDay(now)
+	cycles, err := s.loadCycles(bill, today)
+	if err != nil {
+		return err
+	}
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	// The oldest unpaid due date takes the first payment, a transaction pays a single due date
+	for i := range cycles {
+		cycle := &cycles[i]
+		if cycle.PaidAt != nil {
+			continue
+		}
+		transaction, err := s.rp.Bill.FindMatchingTransaction(bill, previousDueDate(bill, cycle.DueDate), now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cycle.TransactionID = &transaction.ID
+		cycle.Amount = transaction.Amount
+		cycle.PaidAt = &transaction.TransactionDate
+		if _, err := s.rp.Bill.SavePayment(*cycle); err != nil {
+			return err
+		}
+	}
+
+	next := cycles[len(cycles)-1]
+	remind := next.PaidAt == nil && next.RemindedAt == nil &&
+		!today.Before(next.DueDate.AddDate(0, 0, -bill.RemindDaysBefore))
+	var overdue []models.BillPayment
+	for _, cycle := range overdueCycles(cycles, today) {
+		if cycle.OverdueSentAt == nil {
+			overdue = append(overdue, cycle)
+		}
+	}
+	if !remind && len(overdue) == 0 {
+		return nil
+	}
+
+	setting, ok := settings[bill.UserID]
+	if !ok {
+		if setting, err = s.rp.Alert.GetSetting(bill.UserID); err != nil {
+			return err
+		}
+		settings[bill.UserID] = setting
+	}
+	if setting.IsQuiet(now) {
+		return nil
+	}
+
+	user := bill.User
+	if user.WhatsappChatID == "" || user.BotAccountID == "" {
+		// Nowhere to send it, the messages are dropped rather than retried every run
+		if err := s.markSent(next, remind, overdue, now); err != nil {
+			return err
+		}
+		return fmt.Errorf("user %d has no WhatsApp chat with the bot", bill.UserID)
+	}
+
+	if remind {
+		if _, err := s.outgoing.SendText(user.BotAccountID, user.WhatsappChatID, reminderText(bill, next.DueDate, today)); err != nil {
+			return err
+		}
+	}
+	if len(overdue) > 0 {
+		if _, err := s.outgoing.SendText(user.BotAccountID, user.WhatsappChatID, overdueText(bill, overdue, today)); err != nil {
+			return err
+		}
+	}
+	return s.markSent(next, remind, overdue, now)
+}
+
+// markSent records the reminder of the next due date and the overdue notices as sent
+func (s *Service) markSent(next models.BillPayment, remind bool, overdue []models.BillPayment, now time.Time) error {
+	if remind {
+		next.RemindedAt = &now
+		if _, err := s.rp.Bill.SavePayment(next); err != nil {
+			return err
+		}
+	}
+	for _, cycle := range overdue {
+		cycle.OverdueSentAt = &now
+		if _, err := s.rp.Bill.SavePayment(cycle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reminderText(bill models.Bill, dueDate, today time.Time) string {
+	days := daysBetween(today, dueDate)
+	text := fmt.Sprintf("🔔 *Tagihan %s jatuh tempo %d hari lagi*\n\n", bill.Name, days)
+	if days == 0 {
+		text = fmt.Sprintf("🔔 *Tagihan %s jatuh tempo hari ini*\n\n", bill.Name)
+	}
+	text += "Jatuh tempo: " + dueDate.Format("02-01-2006") + "\n"
+	if bill.ExpectedAmount > 0 {
+		text += "Perkiraan: " + helper.FormatMoney(bill.ExpectedAmount, bill.Currency) + "\n"
+	}
+	if bill.Payee != "" {
+		text += "Bayar ke: " + bill.Payee + "\n"
+	}
+	text += "\nTagihan otomatis tercatat lunas begitu pembayarannya dicatat."
+	return text
+}
+
+func overdueText(bill models.Bill, overdue []models.BillPayment, today time.Time) string {
+	text := fmt.Sprintf("⚠️ *Tagihan %s belum dibayar*\n", bill.Name)
+	for _, cycle := range overdue {
+		text += fmt.Sprintf("\n• Jatuh tempo %s, terlambat %d hari", cycle.DueDate.Format("02-01-2006"), daysBetween(cycle.DueDate, today))
+	}
+	if bill.ExpectedAmount > 0 {
+		text += "\n\nPerkiraan: " + helper.FormatMoney(bill.ExpectedAmount, bill.Currency)
+		if len(overdue) > 1 {
+			text += " per bulan"
+		}
+	}
+	if bill.Payee != "" {
+		text += "\nBayar ke: " + bill.Payee
+	}
+	return text
+}
@@ -1,12 +1,15 @@
 package budget
 
 import (
+	"errors"
 	"net/http"
 	"pannypal/internal/common/models"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
 	"pannypal/internal/repository/budget"
 	"pannypal/internal/service/budget/dto"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -49,12 +52,47 @@ func (s *Service) CreateBudgetRequest(payload dto.CreateBudgetRequest) *types.Re
 		})
 	}
 
+	if payload.LedgerID != nil {
+		if resp := s.checkLedgerMember(*payload.LedgerID, user.ID); resp != nil {
+			return resp
+		}
+	}
+
+	if resp := s.checkBudgetCategory(uint(payload.CategoryID), user.ID); resp != nil {
+		return resp
+	}
+
 	budgetModel := models.Budget{
 		UserID:     user.ID,
+		LedgerID:   payload.LedgerID,
 		CategoryID: uint(payload.CategoryID),
 		Amount:     payload.Amount,
-		Month:      payload.Month,
-		Year:       payload.Year,
+		Period:     models.BudgetPeriodMonthly,
+		StartDay:   1,
+		Rollover:   payload.Rollover,
+	}
+	if payload.Alerts != nil {
+		budgetModel.Alerts = models.FormatAlertThresholds(payload.Alerts)
+	}
+	if payload.Period != "" {
+		budgetModel.Period = models.BudgetPeriod(payload.Period)
+	}
+	if payload.StartDay != nil {
+		budgetModel.StartDay = *payload.StartDay
+	}
+	if resp := checkStartDay(budgetModel.Period, budgetModel.StartDay); resp != nil {
+		return resp
+	}
+
+	date := time.Now()
+	if payload.Date != nil {
+		date = *payload.Date
+	} else if payload.Month != 0 {
+		date = periodStartIn(budgetModel, payload.Year, time.Month(payload.Month))
+	}
+	budgetModel.SetPeriod(date)
+	if resp := s.checkBudgetPeriod(budgetModel); resp != nil {
+		return resp
 	}
 
 	createdBudget, err := s.rp.Budget.CreateBudget(budgetModel)
@@ -78,17 +116,7 @@ func (s *Service) CreateBudgetRequest(payload dto.CreateBudgetRequest) *types.Re
 		})
 	}
 
-	response := dto.BudgetResponse{
-		ID:         budgetWithCategory.ID,
-		UserID:     budgetWithCategory.UserID,
-		CategoryID: budgetWithCategory.CategoryID,
-		Category:   budgetWithCategory.Category,
-		Amount:     budgetWithCategory.Amount,
-		Month:      budgetWithCategory.Month,
-		Year:       budgetWithCategory.Year,
-		CreatedAt:  budgetWithCategory.CreatedAt,
-		UpdatedAt:  budgetWithCategory.UpdatedAt,
-	}
+	response := toBudgetResponse(*budgetWithCategory)
 
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusCreated,
@@ -113,9 +141,29 @@ func (s *Service) GetBudgetsRequest(payload dto.GetBudgetsRequest) *types.Respon
 		userID = &user.ID
 	}
 
+	// Group budgets are shared by every member, so they are not filtered by owner
+	if payload.LedgerID != nil {
+		if userID == nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Phone number is required for ledger budgets",
+				Data:    nil,
+			})
+		}
+		if resp := s.checkLedgerMember(*payload.LedgerID, *userID); resp != nil {
+			return resp
+		}
+		userID = nil
+	}
+
 	filters := budget.BudgetFilters{
-		Month: payload.Month,
-		Year:  payload.Year,
+		LedgerID: payload.LedgerID,
+		Month:    payload.Month,
+		Year:     payload.Year,
+	}
+	if payload.Period != nil {
+		period := models.BudgetPeriod(*payload.Period)
+		filters.Period = &period
 	}
 	if payload.CategoryID != nil {
 		categoryID := uint(*payload.CategoryID)
@@ -134,17 +182,7 @@ func (s *Service) GetBudgetsRequest(payload dto.GetBudgetsRequest) *types.Respon
 
 	budgetResponses := make([]dto.BudgetResponse, len(budgets))
 	for i, b := range budgets {
-		budgetResponses[i] = dto.BudgetResponse{
-			ID:         b.ID,
-			UserID:     b.UserID,
-			CategoryID: b.CategoryID,
-			Category:   b.Category,
-			Amount:     b.Amount,
-			Month:      b.Month,
-			Year:       b.Year,
-			CreatedAt:  b.CreatedAt,
-			UpdatedAt:  b.UpdatedAt,
-		}
+		budgetResponses[i] = toBudgetResponse(b)
 	}
 
 	response := dto.BudgetListResponse{
@@ -180,7 +218,7 @@ func (s *Service) GetBudgetByIDRequest(id uint, phoneNumber string) *types.Respo
 	}
 
 	// Check if budget belongs to user
-	if budgetModel.UserID != user.ID {
+	if !s.canAccess(budgetModel.UserID, budgetModel.LedgerID, user.ID) {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusForbidden,
 			Message: "Access denied",
@@ -188,17 +226,7 @@ func (s *Service) GetBudgetByIDRequest(id uint, phoneNumber string) *types.Respo
 		})
 	}
 
-	response := dto.BudgetResponse{
-		ID:         budgetModel.ID,
-		UserID:     budgetModel.UserID,
-		CategoryID: budgetModel.CategoryID,
-		Category:   budgetModel.Category,
-		Amount:     budgetModel.Amount,
-		Month:      budgetModel.Month,
-		Year:       budgetModel.Year,
-		CreatedAt:  budgetModel.CreatedAt,
-		UpdatedAt:  budgetModel.UpdatedAt,
-	}
+	response := toBudgetResponse(*budgetModel)
 
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusOK,
@@ -229,7 +257,7 @@ func (s *Service) UpdateBudgetRequest(id uint, payload dto.UpdateBudgetRequest,
 	}
 
 	// Check if budget belongs to user
-	if budgetModel.UserID != user.ID {
+	if !s.canAccess(budgetModel.UserID, budgetModel.LedgerID, user.ID) {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusForbidden,
 			Message: "Access denied",
@@ -237,18 +265,54 @@ func (s *Service) UpdateBudgetRequest(id uint, payload dto.UpdateBudgetRequest,
 		})
 	}
 
+	before := *budgetModel
+
 	// Update fields if provided
 	if payload.CategoryID != nil {
+		if resp := s.checkBudgetCategory(uint(*payload.CategoryID), user.ID); resp != nil {
+			return resp
+		}
 		budgetModel.CategoryID = uint(*payload.CategoryID)
 	}
 	if payload.Amount != nil {
 		budgetModel.Amount = *payload.Amount
 	}
-	if payload.Month != nil {
-		budgetModel.Month = *payload.Month
+	if payload.Rollover != nil {
+		budgetModel.Rollover = *payload.Rollover
 	}
-	if payload.Year != nil {
-		budgetModel.Year = *payload.Year
+	if payload.Alerts != nil {
+		budgetModel.Alerts = models.FormatAlertThresholds(payload.Alerts)
+	}
+
+	// The budget keeps its period unless it is moved or its period settings change
+	if payload.Period != nil || payload.StartDay != nil || payload.Date != nil || payload.Month != nil || payload.Year != nil {
+		if payload.Period != nil {
+			budgetModel.Period = models.BudgetPeriod(*payload.Period)
+		}
+		if payload.StartDay != nil {
+			budgetModel.StartDay = *payload.StartDay
+		}
+		if resp := checkStartDay(budgetModel.Period, budgetModel.StartDay); resp != nil {
+			return resp
+		}
+
+		date := budgetModel.PeriodStart
+		if payload.Date != nil {
+			date = *payload.Date
+		} else if payload.Month != nil || payload.Year != nil {
+			month, year := budgetModel.Month, budgetModel.Year
+			if payload.Month != nil {
+				month = *payload.Month
+			}
+			if payload.Year != nil {
+				year = *payload.Year
+			}
+			date = periodStartIn(*budgetModel, year, time.Month(month))
+		}
+		budgetModel.SetPeriod(date)
+	}
+	if resp := s.checkBudgetPeriod(*budgetModel); resp != nil {
+		return resp
 	}
 
 	updatedBudget, err := s.rp.Budget.UpdateBudget(*budgetModel)
@@ -261,6 +325,13 @@ func (s *Service) UpdateBudgetRequest(id uint, payload dto.UpdateBudgetRequest,
 		})
 	}
 
+	// A budget that changes what it allows is alerted again on the thresholds it crosses anew
+	if budgetAlertsChanged(before, *updatedBudget) {
+		if err := s.rp.Alert.DeleteBudgetAlerts(updatedBudget.ID); err != nil {
+			logger.Error.Printf("Error resetting alerts of budget %d: %v", updatedBudget.ID, err)
+		}
+	}
+
 	// Get updated budget with category
 	budgetWithCategory, err := s.rp.Budget.GetBudgetByID(updatedBudget.ID)
 	if err != nil {
@@ -272,17 +343,7 @@ func (s *Service) UpdateBudgetRequest(id uint, payload dto.UpdateBudgetRequest,
 		})
 	}
 
-	response := dto.BudgetResponse{
-		ID:         budgetWithCategory.ID,
-		UserID:     budgetWithCategory.UserID,
-		CategoryID: budgetWithCategory.CategoryID,
-		Category:   budgetWithCategory.Category,
-		Amount:     budgetWithCategory.Amount,
-		Month:      budgetWithCategory.Month,
-		Year:       budgetWithCategory.Year,
-		CreatedAt:  budgetWithCategory.CreatedAt,
-		UpdatedAt:  budgetWithCategory.UpdatedAt,
-	}
+	response := toBudgetResponse(*budgetWithCategory)
 
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusOK,
@@ -313,7 +374,7 @@ func (s *Service) DeleteBudgetRequest(id uint, phoneNumber string) *types.Respon
 	}
 
 	// Check if budget belongs to user
-	if budgetModel.UserID != user.ID {
+	if !s.canAccess(budgetModel.UserID, budgetModel.LedgerID, user.ID) {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusForbidden,
 			Message: "Access denied",
@@ -353,22 +414,39 @@ func (s *Service) GetBudgetStatusRequest(payload dto.BudgetStatusRequest) *types
 		userID = &user.ID
 	}
 
-	// Use current month/year if not provided
-	now := time.Now()
-	month := payload.Month
-	year := payload.Year
-	if month == nil {
-		currentMonth := int(now.Month())
-		month = &currentMonth
+	if payload.LedgerID != nil {
+		if userID == nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Phone number is required for ledger budgets",
+				Data:    nil,
+			})
+		}
+		if resp := s.checkLedgerMember(*payload.LedgerID, *userID); resp != nil {
+			return resp
+		}
+		userID = nil
 	}
-	if year == nil {
-		currentYear := now.Year()
-		year = &currentYear
+
+	// Budgets active today unless a day, month or year is asked for
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := from
+	if payload.Date != nil {
+		from, to = *payload.Date, *payload.Date
+	} else if payload.Year != nil {
+		from = time.Date(*payload.Year, time.January, 1, 0, 0, 0, 0, now.Location())
+		to = from.AddDate(1, 0, -1)
+		if payload.Month != nil {
+			from = time.Date(*payload.Year, time.Month(*payload.Month), 1, 0, 0, 0, 0, now.Location())
+			to = from.AddDate(0, 1, -1)
+		}
 	}
 
 	filters := budget.BudgetStatusFilters{
-		Month: month,
-		Year:  year,
+		LedgerID: payload.LedgerID,
+		From:     from,
+		To:       to,
 	}
 
 	statusData, err := s.rp.Budget.GetBudgetStatus(userID, filters)
@@ -382,31 +460,39 @@ func (s *Service) GetBudgetStatusRequest(payload dto.BudgetStatusRequest) *types
 	}
 
 	budgetStatuses := make([]dto.BudgetStatusResponse, len(statusData))
-	totalBudget := float64(0)
-	totalSpent := float64(0)
+	totalBudget := types.Money(0)
+	totalSpent := types.Money(0)
 
+	// Totals are of what could be spent, rollover included
 	for i, status := range statusData {
-		remainingAmount := status.BudgetAmount - status.SpentAmount
+		availableAmount := status.BudgetAmount + status.CarriedOver
+		remainingAmount := availableAmount - status.SpentAmount
 		percentageUsed := float64(0)
-		if status.BudgetAmount > 0 {
-			percentageUsed = (status.SpentAmount / status.BudgetAmount) * 100
+		if availableAmount > 0 {
+			percentageUsed = (status.SpentAmount.Float64() / availableAmount.Float64()) * 100
 		}
-		isOverBudget := status.SpentAmount > status.BudgetAmount
+		isOverBudget := status.SpentAmount > availableAmount
 
 		budgetStatuses[i] = dto.BudgetStatusResponse{
 			BudgetID:        status.BudgetID,
 			CategoryID:      status.CategoryID,
 			CategoryName:    status.CategoryName,
 			BudgetAmount:    status.BudgetAmount,
+			CarriedOver:     status.CarriedOver,
+			AvailableAmount: availableAmount,
 			SpentAmount:     status.SpentAmount,
 			RemainingAmount: remainingAmount,
 			PercentageUsed:  percentageUsed,
 			IsOverBudget:    isOverBudget,
+			Period:          status.Period,
+			PeriodStart:     status.PeriodStart,
+			PeriodEnd:       status.PeriodEnd,
+			Rollover:        status.Rollover,
 			Month:           status.Month,
 			Year:            status.Year,
 		}
 
-		totalBudget += status.BudgetAmount
+		totalBudget += availableAmount
 		totalSpent += status.SpentAmount
 	}
 
@@ -415,8 +501,10 @@ func (s *Service) GetBudgetStatusRequest(payload dto.BudgetStatusRequest) *types
 		TotalBudget:    totalBudget,
 		TotalSpent:     totalSpent,
 		TotalRemaining: totalBudget - totalSpent,
-		Month:          *month,
-		Year:           *year,
+		StartDate:      from,
+		EndDate:        to,
+		Month:          int(from.Month()),
+		Year:           from.Year(),
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -425,3 +513,138 @@ func (s *Service) GetBudgetStatusRequest(payload dto.BudgetStatusRequest) *types
 		Data:    response,
 	})
 }
+
+// canAccess allows the owner of a budget or template, and every member for group ones
+func (s *Service) canAccess(ownerID uint, ledgerID *uint, userID uint) bool {
+	if ownerID == userID {
+		return true
+	}
+	if ledgerID == nil {
+		return false
+	}
+	isMember, err := s.rp.Ledger.IsMember(*ledgerID, userID)
+	return err == nil && isMember
+}
+
+// checkBudgetPeriod returns a conflict when the category already has a budget for the period
+func (s *Service) checkBudgetPeriod(budgetModel models.Budget) *types.Response {
+	_, err := s.rp.Budget.GetBudgetForPeriod(budgetModel)
+	if err == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The category already has a budget for this period",
+			Data:    nil,
+		})
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check existing budgets",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	return nil
+}
+
+// checkBudgetCategory checks the category is one of the user's and holds expenses, a budget on a
+// top-level category covers its subcategories too
+func (s *Service) checkBudgetCategory(categoryID, userID uint) *types.Response {
+	category, err := s.rp.Category.GetCategoryByID(categoryID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if category.UserID == nil || *category.UserID != userID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	if !category.Kind.Allows(models.TypeExpense) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Budgets need an expense category",
+			Data:    nil,
+		})
+	}
+	return nil
+}
+
+// checkLedgerMember returns an error response when the user is not a member of the ledger
+func (s *Service) checkLedgerMember(ledgerID, userID uint) *types.Response {
+	isMember, err := s.rp.Ledger.IsMember(ledgerID, userID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to check ledger membership",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if !isMember {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return nil
+}
+
+func checkStartDay(period models.BudgetPeriod, startDay int) *types.Response {
+	if period.Valid(startDay) {
+		return nil
+	}
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusBadRequest,
+		Message: "Start day must be a weekday (0-6) for weekly budgets and a day of the month (1-31) otherwise",
+		Data:    nil,
+	})
+}
+
+// periodStartIn returns a day of the period that starts in the month, for weekly budgets the first
+// of the month picks the week holding it
+func periodStartIn(budgetModel models.Budget, year int, month time.Month) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Now().Location())
+	if budgetModel.Period == models.BudgetPeriodWeekly {
+		return firstOfMonth
+	}
+	day := budgetModel.StartDay
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func budgetAlertsChanged(before, after models.Budget) bool {
+	return before.Amount != after.Amount || before.CategoryID != after.CategoryID || before.Rollover != after.Rollover ||
+		!before.PeriodStart.Equal(after.PeriodStart) || !before.PeriodEnd.Equal(after.PeriodEnd) ||
+		!slices.Equal(before.AlertThresholds(), after.AlertThresholds())
+}
+
+func toBudgetResponse(b models.Budget) dto.BudgetResponse {
+	return dto.BudgetResponse{
+		ID:          b.ID,
+		UserID:      b.UserID,
+		LedgerID:    b.LedgerID,
+		CategoryID:  b.CategoryID,
+		Category:    b.Category,
+		Amount:      b.Amount,
+		Period:      b.Period,
+		StartDay:    b.StartDay,
+		PeriodStart: b.PeriodStart,
+		PeriodEnd:   b.PeriodEnd,
+		Rollover:    b.Rollover,
+		Alerts:      b.AlertThresholds(),
+		Month:       b.Month,
+		Year:        b.Year,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
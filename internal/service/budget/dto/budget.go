@@ -2,47 +2,75 @@ package dto
 
 import (
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"time"
 )
 
+// CreateBudgetRequest sets a budget for the period holding Date, or the one starting in Month/Year,
+// or the current one
 type CreateBudgetRequest struct {
-	PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty"`
-	CategoryID  int     `json:"category_id" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Month       int     `json:"month" validate:"required,min=1,max=12"`
-	Year        int     `json:"year" validate:"required,min=2020"`
+	PhoneNumber *string     `json:"phone_number,omitempty" validate:"omitempty"`
+	LedgerID    *uint       `json:"ledger_id,omitempty" validate:"omitempty"` // Creates a group budget, caller must be a member
+	CategoryID  int         `json:"category_id" validate:"required"`
+	Amount      types.Money `json:"amount" validate:"required,gt=0"`
+	Period      string      `json:"period" validate:"omitempty,oneof=WEEKLY MONTHLY QUARTERLY YEARLY"` // Defaults to MONTHLY
+	StartDay    *int        `json:"start_day" validate:"omitempty,min=0,max=31"`                       // Day of the month, or weekday (0 = Sunday) for WEEKLY, defaults to 1
+	Rollover    bool        `json:"rollover"`
+	Alerts      []int       `json:"alert_thresholds" validate:"omitempty,max=5,dive,min=1,max=200"` // Percentages alerted on WhatsApp, defaults to 80 and 100, empty turns alerts off
+	Date        *time.Time  `json:"date" validate:"omitempty"`
+	Month       int         `json:"month" validate:"required_with=Year,omitempty,min=1,max=12"`
+	Year        int         `json:"year" validate:"required_with=Month,omitempty,min=2020"`
 }
 
+// UpdateBudgetRequest moves the budget to the period holding Date, or starting in Month/Year, when
+// either or the period settings change
 type UpdateBudgetRequest struct {
-	CategoryID *int     `json:"category_id" validate:"omitempty"`
-	Amount     *float64 `json:"amount" validate:"omitempty,gt=0"`
-	Month      *int     `json:"month" validate:"omitempty,min=1,max=12"`
-	Year       *int     `json:"year" validate:"omitempty,min=2020"`
+	CategoryID *int         `json:"category_id" validate:"omitempty"`
+	Amount     *types.Money `json:"amount" validate:"omitempty,gt=0"`
+	Period     *string      `json:"period" validate:"omitempty,oneof=WEEKLY MONTHLY QUARTERLY YEARLY"`
+	StartDay   *int         `json:"start_day" validate:"omitempty,min=0,max=31"`
+	Rollover   *bool        `json:"rollover" validate:"omitempty"`
+	Alerts     []int        `json:"alert_thresholds" validate:"omitempty,max=5,dive,min=1,max=200"` // Empty turns alerts off
+	Date       *time.Time   `json:"date" validate:"omitempty"`
+	Month      *int         `json:"month" validate:"omitempty,min=1,max=12"`
+	Year       *int         `json:"year" validate:"omitempty,min=2020"`
 }
 
 type GetBudgetsRequest struct {
 	PhoneNumber *string `form:"phone_number,omitempty" validate:"omitempty"`
+	LedgerID    *uint   `form:"ledger_id" validate:"omitempty"`
 	Month       *int    `form:"month" validate:"omitempty,min=1,max=12"`
 	Year        *int    `form:"year" validate:"omitempty,min=2020"`
 	CategoryID  *int    `form:"category_id" validate:"omitempty"`
+	Period      *string `form:"period" validate:"omitempty,oneof=WEEKLY MONTHLY QUARTERLY YEARLY"`
 }
 
+// BudgetStatusRequest selects the budgets active on Date, today by default, or overlapping Month/Year
 type BudgetStatusRequest struct {
-	PhoneNumber *string `form:"phone_number,omitempty" validate:"omitempty"`
-	Month       *int    `form:"month" validate:"omitempty,min=1,max=12"`
-	Year        *int    `form:"year" validate:"omitempty,min=2020"`
+	PhoneNumber *string    `form:"phone_number,omitempty" validate:"omitempty"`
+	LedgerID    *uint      `form:"ledger_id" validate:"omitempty"`
+	Date        *time.Time `form:"date" validate:"omitempty" time_format:"2006-01-02"`
+	Month       *int       `form:"month" validate:"omitempty,min=1,max=12"`
+	Year        *int       `form:"year" validate:"required_with=Month,omitempty,min=2020"`
 }
 
 type BudgetResponse struct {
-	ID         uint            `json:"id"`
-	UserID     uint            `json:"user_id"`
-	CategoryID uint            `json:"category_id"`
-	Category   models.Category `json:"category"`
-	Amount     float64         `json:"amount"`
-	Month      int             `json:"month"`
-	Year       int             `json:"year"`
-	CreatedAt  time.Time       `json:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at"`
+	ID          uint                `json:"id"`
+	UserID      uint                `json:"user_id"`
+	LedgerID    *uint               `json:"ledger_id"`
+	CategoryID  uint                `json:"category_id"`
+	Category    models.Category     `json:"category"`
+	Amount      types.Money         `json:"amount"`
+	Period      models.BudgetPeriod `json:"period"`
+	StartDay    int                 `json:"start_day"`
+	PeriodStart time.Time           `json:"period_start"`
+	PeriodEnd   time.Time           `json:"period_end"`
+	Rollover    bool                `json:"rollover"`
+	Alerts      []int               `json:"alert_thresholds"`
+	Month       int                 `json:"month"`
+	Year        int                 `json:"year"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
 }
 
 type BudgetListResponse struct {
@@ -50,23 +78,83 @@ type BudgetListResponse struct {
 }
 
 type BudgetStatusResponse struct {
-	BudgetID        uint    `json:"budget_id"`
-	CategoryID      uint    `json:"category_id"`
-	CategoryName    string  `json:"category_name"`
-	BudgetAmount    float64 `json:"budget_amount"`
-	SpentAmount     float64 `json:"spent_amount"`
-	RemainingAmount float64 `json:"remaining_amount"`
-	PercentageUsed  float64 `json:"percentage_used"`
-	IsOverBudget    bool    `json:"is_over_budget"`
-	Month           int     `json:"month"`
-	Year            int     `json:"year"`
+	BudgetID        uint                `json:"budget_id"`
+	CategoryID      uint                `json:"category_id"`
+	CategoryName    string              `json:"category_name"`
+	BudgetAmount    types.Money         `json:"budget_amount"`
+	CarriedOver     types.Money         `json:"carried_over"`     // Rolled over from earlier periods, negative when they were overspent
+	AvailableAmount types.Money         `json:"available_amount"` // BudgetAmount plus CarriedOver
+	SpentAmount     types.Money         `json:"spent_amount"`
+	RemainingAmount types.Money         `json:"remaining_amount"`
+	PercentageUsed  float64             `json:"percentage_used"`
+	IsOverBudget    bool                `json:"is_over_budget"`
+	Period          models.BudgetPeriod `json:"period"`
+	PeriodStart     time.Time           `json:"period_start"`
+	PeriodEnd       time.Time           `json:"period_end"`
+	Rollover        bool                `json:"rollover"`
+	Month           int                 `json:"month"`
+	Year            int                 `json:"year"`
 }
 
 type BudgetStatusListResponse struct {
 	BudgetStatuses []BudgetStatusResponse `json:"budget_statuses"`
-	TotalBudget    float64                `json:"total_budget"`
-	TotalSpent     float64                `json:"total_spent"`
-	TotalRemaining float64                `json:"total_remaining"`
+	TotalBudget    types.Money            `json:"total_budget"`
+	TotalSpent     types.Money            `json:"total_spent"`
+	TotalRemaining types.Money            `json:"total_remaining"`
+	StartDate      time.Time              `json:"start_date"` // Budgets overlapping StartDate to EndDate are listed
+	EndDate        time.Time              `json:"end_date"`
 	Month          int                    `json:"month"`
 	Year           int                    `json:"year"`
 }
+
+// CreateBudgetTemplateRequest sets a budget for the current period and every period after it
+type CreateBudgetTemplateRequest struct {
+	PhoneNumber     *string     `json:"phone_number,omitempty" validate:"omitempty"`
+	LedgerID        *uint       `json:"ledger_id,omitempty" validate:"omitempty"` // Creates group budgets, caller must be a member
+	CategoryID      int         `json:"category_id" validate:"required"`
+	Amount          types.Money `json:"amount" validate:"required,gt=0"` // Used as is, or when AdjustToAverage finds no spending
+	Period          string      `json:"period" validate:"omitempty,oneof=WEEKLY MONTHLY QUARTERLY YEARLY"`
+	StartDay        *int        `json:"start_day" validate:"omitempty,min=0,max=31"`
+	Rollover        bool        `json:"rollover"`
+	AdjustToAverage bool        `json:"adjust_to_average"` // Budget the average spending of the three months before each period
+	Alerts          []int       `json:"alert_thresholds" validate:"omitempty,max=5,dive,min=1,max=200"`
+}
+
+type UpdateBudgetTemplateRequest struct {
+	CategoryID      *int         `json:"category_id" validate:"omitempty"`
+	Amount          *types.Money `json:"amount" validate:"omitempty,gt=0"`
+	Period          *string      `json:"period" validate:"omitempty,oneof=WEEKLY MONTHLY QUARTERLY YEARLY"`
+	StartDay        *int         `json:"start_day" validate:"omitempty,min=0,max=31"`
+	Rollover        *bool        `json:"rollover" validate:"omitempty"`
+	AdjustToAverage *bool        `json:"adjust_to_average" validate:"omitempty"`
+	Alerts          []int        `json:"alert_thresholds" validate:"omitempty,max=5,dive,min=1,max=200"`
+	IsActive        *bool        `json:"is_active" validate:"omitempty"`
+}
+
+type GetBudgetTemplatesRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	LedgerID    *uint  `form:"ledger_id" validate:"omitempty"`
+}
+
+type BudgetTemplateResponse struct {
+	ID              uint                `json:"id"`
+	UserID          uint                `json:"user_id"`
+	LedgerID        *uint               `json:"ledger_id"`
+	CategoryID      uint                `json:"category_id"`
+	Category        models.Category     `json:"category"`
+	Amount          types.Money         `json:"amount"`
+	Period          models.BudgetPeriod `json:"period"`
+	StartDay        int                 `json:"start_day"`
+	Rollover        bool                `json:"rollover"`
+	AdjustToAverage bool                `json:"adjust_to_average"`
+	Alerts          []int               `json:"alert_thresholds"`
+	NextPeriodStart time.Time           `json:"next_period_start"`
+	IsActive        bool                `json:"is_active"`
+	CategoryDeleted bool                `json:"category_deleted"` // The category is deleted, periods are skipped until it is restored or the template points at another one
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+type BudgetTemplateListResponse struct {
+	Templates []BudgetTemplateResponse `json:"templates"`
+}
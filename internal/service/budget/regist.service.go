@@ -6,6 +6,7 @@ import (
 	"pannypal/internal/pkg/redis"
 	"pannypal/internal/repository"
 	"pannypal/internal/service/budget/dto"
+	"time"
 )
 
 type Service struct {
@@ -21,6 +22,12 @@ type IService interface {
 	UpdateBudgetRequest(id uint, payload dto.UpdateBudgetRequest, phoneNumber string) *types.Response
 	DeleteBudgetRequest(id uint, phoneNumber string) *types.Response
 	GetBudgetStatusRequest(payload dto.BudgetStatusRequest) *types.Response
+
+	CreateBudgetTemplateRequest(payload dto.CreateBudgetTemplateRequest) *types.Response
+	GetBudgetTemplatesRequest(payload dto.GetBudgetTemplatesRequest) *types.Response
+	UpdateBudgetTemplateRequest(id uint, payload dto.UpdateBudgetTemplateRequest, phoneNumber string) *types.Response
+	DeleteBudgetTemplateRequest(id uint, phoneNumber string) *types.Response
+	ApplyBudgetTemplates(now time.Time) error
 }
 
 func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
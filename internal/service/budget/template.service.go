@@ -0,0 +1,368 @@
+package budget
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/service/budget/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dueTemplatesBatchSize = 100
+	// Months of spending an adjusted template averages over
+	averageMonths = 3
+)
+
+func (s *Service) CreateBudgetTemplateRequest(payload dto.CreateBudgetTemplateRequest) *types.Response {
+	if payload.PhoneNumber == nil || *payload.PhoneNumber == "" {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Phone number is required for creating budget template",
+			Data:    nil,
+		})
+	}
+	user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if payload.LedgerID != nil {
+		if resp := s.checkLedgerMember(*payload.LedgerID, user.ID); resp != nil {
+			return resp
+		}
+	}
+	if resp := s.checkBudgetCategory(uint(payload.CategoryID), user.ID); resp != nil {
+		return resp
+	}
+
+	template := models.BudgetTemplate{
+		UserID:          user.ID,
+		LedgerID:        payload.LedgerID,
+		CategoryID:      uint(payload.CategoryID),
+		Amount:          payload.Amount,
+		Period:          models.BudgetPeriodMonthly,
+		StartDay:        1,
+		Rollover:        payload.Rollover,
+		AdjustToAverage: payload.AdjustToAverage,
+		IsActive:        true,
+	}
+	if payload.Alerts != nil {
+		template.Alerts = models.FormatAlertThresholds(payload.Alerts)
+	}
+	if payload.Period != "" {
+		template.Period = models.BudgetPeriod(payload.Period)
+	}
+	if payload.StartDay != nil {
+		template.StartDay = *payload.StartDay
+	}
+	if resp := checkStartDay(template.Period, template.StartDay); resp != nil {
+		return resp
+	}
+
+	// The current period gets its budget right away rather than on the worker's next run
+	now := time.Now()
+	template.NextPeriodStart, _ = template.Period.Window(template.StartDay, now)
+
+	created, err := s.rp.Budget.CreateTemplate(template)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create budget template",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if err := s.applyTemplate(*created, now); err != nil {
+		logger.Error.Printf("Error applying budget template %d: %v", created.ID, err)
+	}
+
+	templateWithCategory, err := s.rp.Budget.GetTemplateByID(created.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get created budget template",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Budget template created successfully",
+		Data:    toTemplateResponse(*templateWithCategory),
+	})
+}
+
+func (s *Service) GetBudgetTemplatesRequest(payload dto.GetBudgetTemplatesRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if payload.LedgerID != nil {
+		if resp := s.checkLedgerMember(*payload.LedgerID, user.ID); resp != nil {
+			return resp
+		}
+	}
+
+	templates, err := s.rp.Budget.GetTemplates(&user.ID, payload.LedgerID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get budget templates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.BudgetTemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = toTemplateResponse(template)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Budget templates retrieved successfully",
+		Data:    dto.BudgetTemplateListResponse{Templates: responses},
+	})
+}
+
+func (s *Service) UpdateBudgetTemplateRequest(id uint, payload dto.UpdateBudgetTemplateRequest, phoneNumber string) *types.Response {
+	user, template, resp := s.getTemplate(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.CategoryID != nil {
+		if resp := s.checkBudgetCategory(uint(*payload.CategoryID), user.ID); resp != nil {
+			return resp
+		}
+		template.CategoryID = uint(*payload.CategoryID)
+	}
+	if payload.Amount != nil {
+		template.Amount = *payload.Amount
+	}
+	if payload.Rollover != nil {
+		template.Rollover = *payload.Rollover
+	}
+	if payload.AdjustToAverage != nil {
+		template.AdjustToAverage = *payload.AdjustToAverage
+	}
+	if payload.Alerts != nil {
+		template.Alerts = models.FormatAlertThresholds(payload.Alerts)
+	}
+	if payload.IsActive != nil {
+		template.IsActive = *payload.IsActive
+	}
+
+	// A new period length starts with the period holding today, its budget is created on the next run
+	if payload.Period != nil || payload.StartDay != nil {
+		if payload.Period != nil {
+			template.Period = models.BudgetPeriod(*payload.Period)
+		}
+		if payload.StartDay != nil {
+			template.StartDay = *payload.StartDay
+		}
+		if resp := checkStartDay(template.Period, template.StartDay); resp != nil {
+			return resp
+		}
+		template.NextPeriodStart, _ = template.Period.Window(template.StartDay, time.Now())
+	}
+
+	template.Category = models.Category{}
+	if _, err := s.rp.Budget.UpdateTemplate(*template); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update budget template",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	updated, err := s.rp.Budget.GetTemplateByID(template.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get updated budget template",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Budget template updated successfully",
+		Data:    toTemplateResponse(*updated),
+	})
+}
+
+// DeleteBudgetTemplateRequest stops the template, budgets it already created stay
+func (s *Service) DeleteBudgetTemplateRequest(id uint, phoneNumber string) *types.Response {
+	_, template, resp := s.getTemplate(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Budget.DeleteTemplate(*template); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete budget template",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Budget template deleted successfully",
+		Data:    nil,
+	})
+}
+
+// ApplyBudgetTemplates creates the budget of every template whose next period has started, run by
+// the worker. A template that was paused for long only gets the budget of the current period.
+func (s *Service) ApplyBudgetTemplates(now time.Time) error {
+	templates, err := s.rp.Budget.GetDueTemplates(now, dueTemplatesBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		if err := s.applyTemplate(template, now); err != nil {
+			logger.Error.Printf("Error applying budget template %d: %v", template.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// applyTemplate creates the budget for the period holding now, a budget the category already has for
+// the period is left as it is
+func (s *Service) applyTemplate(template models.BudgetTemplate, now time.Time) error {
+	template.Category = models.Category{}
+
+	budgetModel := models.Budget{
+		UserID:     template.UserID,
+		LedgerID:   template.LedgerID,
+		CategoryID: template.CategoryID,
+		Amount:     template.Amount,
+		Period:     template.Period,
+		StartDay:   template.StartDay,
+		Rollover:   template.Rollover,
+		Alerts:     template.Alerts,
+	}
+	budgetModel.SetPeriod(now)
+
+	if _, err := s.rp.Category.GetCategoryByID(template.CategoryID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// The category is in the trash, this period is skipped and the template picks up again once the
+		// category is restored. The template list reports it with category_deleted.
+		logger.Warning.Printf("Budget template %d skipped, its category %d is deleted", template.ID, template.CategoryID)
+		template.NextPeriodStart = budgetModel.PeriodEnd.AddDate(0, 0, 1)
+		_, err := s.rp.Budget.UpdateTemplate(template)
+		return err
+	}
+
+	if template.AdjustToAverage {
+		average, err := s.averageSpending(budgetModel)
+		if err != nil {
+			return err
+		}
+		if average > 0 {
+			budgetModel.Amount = average
+		}
+	}
+
+	if _, err := s.rp.Budget.CreateBudgetIfMissing(budgetModel); err != nil {
+		return err
+	}
+
+	template.NextPeriodStart = budgetModel.PeriodEnd.AddDate(0, 0, 1)
+	_, err := s.rp.Budget.UpdateTemplate(template)
+	return err
+}
+
+// averageSpending scales what was spent in the months before the budget's period to the length of
+// the period, rounded to whole units
+func (s *Service) averageSpending(budgetModel models.Budget) (types.Money, error) {
+	from := budgetModel.PeriodStart.AddDate(0, -averageMonths, 0)
+	spent, err := s.rp.Budget.GetSpending(budgetModel, from, budgetModel.PeriodStart)
+	if err != nil {
+		return 0, err
+	}
+
+	windowDays := budgetModel.PeriodStart.Sub(from).Hours() / 24
+	periodDays := budgetModel.PeriodEnd.Sub(budgetModel.PeriodStart).Hours()/24 + 1
+	return types.NewMoney(int64(math.Round(spent.Float64() * periodDays / windowDays))), nil
+}
+
+func (s *Service) getTemplate(id uint, phoneNumber string) (*models.User, *models.BudgetTemplate, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	template, err := s.rp.Budget.GetTemplateByID(id)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Budget template not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if !s.canAccess(template.UserID, template.LedgerID, user.ID) {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return user, template, nil
+}
+
+func toTemplateResponse(t models.BudgetTemplate) dto.BudgetTemplateResponse {
+	return dto.BudgetTemplateResponse{
+		ID:              t.ID,
+		UserID:          t.UserID,
+		LedgerID:        t.LedgerID,
+		CategoryID:      t.CategoryID,
+		Category:        t.Category,
+		Amount:          t.Amount,
+		Period:          t.Period,
+		StartDay:        t.StartDay,
+		Rollover:        t.Rollover,
+		AdjustToAverage: t.AdjustToAverage,
+		Alerts:          models.ParseAlertThresholds(t.Alerts),
+		NextPeriodStart: t.NextPeriodStart,
+		IsActive:        t.IsActive,
+		CategoryDeleted: t.Category.ID == 0,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+}
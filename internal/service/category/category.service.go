@@ -5,14 +5,45 @@ import (
 	"pannypal/internal/common/models"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/revision"
+	"pannypal/internal/repository/trash"
 	"pannypal/internal/service/category/dto"
-
-	"gorm.io/gorm"
+	"strings"
 )
 
 func (s *Service) CreateCategoryRequest(payload dto.CreateCategoryRequest) *types.Response {
+	phoneNumber := ""
+	if payload.PhoneNumber != nil {
+		phoneNumber = *payload.PhoneNumber
+	}
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+
 	category := models.Category{
-		Name: payload.Name,
+		UserID:   owner,
+		ParentID: payload.ParentID,
+		Name:     payload.Name,
+		Kind:     models.CategoryKind(payload.Kind),
+		Icon:     payload.Icon,
+		Color:    strings.ToUpper(payload.Color),
+	}
+	if payload.ParentID != nil {
+		parent, failed := s.getOwnedCategory(*payload.ParentID, owner)
+		if failed != nil {
+			return failed
+		}
+		if category.Kind == "" {
+			category.Kind = parent.Kind
+		}
+		if failed := checkParent(category, *parent); failed != nil {
+			return failed
+		}
+	}
+	if category.Kind == "" {
+		category.Kind = models.CategoryKindExpense
 	}
 
 	createdCategory, err := s.rp.Category.CreateCategory(category)
@@ -25,22 +56,24 @@ func (s *Service) CreateCategoryRequest(payload dto.CreateCategoryRequest) *type
 		})
 	}
 
-	response := dto.CategoryResponse{
-		ID:        createdCategory.ID,
-		Name:      createdCategory.Name,
-		CreatedAt: createdCategory.CreatedAt,
-		UpdatedAt: createdCategory.UpdatedAt,
-	}
-
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusCreated,
 		Message: "Category created successfully",
-		Data:    response,
+		Data:    toCategoryResponse(*createdCategory),
 	})
 }
 
-func (s *Service) GetCategoriesRequest() *types.Response {
-	categories, err := s.rp.Category.GetAllCategories()
+func (s *Service) GetCategoriesRequest(payload dto.GetCategoriesRequest) *types.Response {
+	phoneNumber := ""
+	if payload.PhoneNumber != nil {
+		phoneNumber = *payload.PhoneNumber
+	}
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+
+	categories, err := s.rp.Category.GetCategories(owner)
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -50,18 +83,19 @@ func (s *Service) GetCategoriesRequest() *types.Response {
 		})
 	}
 
-	categoryResponses := make([]dto.CategoryResponse, len(categories))
-	for i, c := range categories {
-		categoryResponses[i] = dto.CategoryResponse{
-			ID:        c.ID,
-			Name:      c.Name,
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
+	if payload.Type != nil {
+		transactionType := models.TransactionType(*payload.Type)
+		applicable := []models.Category{}
+		for _, c := range categories {
+			if c.Kind.Allows(transactionType) {
+				applicable = append(applicable, c)
+			}
 		}
+		categories = applicable
 	}
 
 	response := dto.CategoryListResponse{
-		Categories: categoryResponses,
+		Categories: categoryTree(categories),
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -71,30 +105,32 @@ func (s *Service) GetCategoriesRequest() *types.Response {
 	})
 }
 
-func (s *Service) GetCategoryByIDRequest(id uint) *types.Response {
-	category, err := s.rp.Category.GetCategoryByID(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+func (s *Service) GetCategoryByIDRequest(id uint, phoneNumber string) *types.Response {
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+	category, failed := s.getOwnedCategory(id, owner)
+	if failed != nil {
+		return failed
+	}
+
+	response := toCategoryResponse(*category)
+	if category.ParentID == nil {
+		categories, err := s.rp.Category.GetCategories(owner)
+		if err != nil {
 			return helper.ParseResponse(&types.Response{
-				Code:    http.StatusNotFound,
-				Message: "Category not found",
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to get category",
 				Data:    nil,
 				Error:   err,
 			})
 		}
-		return helper.ParseResponse(&types.Response{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to get category",
-			Data:    nil,
-			Error:   err,
-		})
-	}
-
-	response := dto.CategoryResponse{
-		ID:        category.ID,
-		Name:      category.Name,
-		CreatedAt: category.CreatedAt,
-		UpdatedAt: category.UpdatedAt,
+		for _, c := range categories {
+			if c.ParentID != nil && *c.ParentID == category.ID {
+				response.Subcategories = append(response.Subcategories, toCategoryResponse(c))
+			}
+		}
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -104,28 +140,79 @@ func (s *Service) GetCategoryByIDRequest(id uint) *types.Response {
 	})
 }
 
-func (s *Service) UpdateCategoryRequest(id uint, payload dto.UpdateCategoryRequest) *types.Response {
-	category, err := s.rp.Category.GetCategoryByID(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return helper.ParseResponse(&types.Response{
-				Code:    http.StatusNotFound,
-				Message: "Category not found",
-				Data:    nil,
-				Error:   err,
-			})
+func (s *Service) UpdateCategoryRequest(id uint, payload dto.UpdateCategoryRequest, phoneNumber string) *types.Response {
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+	category, failed := s.getOwnedCategory(id, owner)
+	if failed != nil {
+		return failed
+	}
+
+	// Update fields if provided
+	if payload.Name != nil {
+		category.Name = *payload.Name
+	}
+	if payload.Kind != nil {
+		category.Kind = models.CategoryKind(*payload.Kind)
+	}
+	if payload.Icon != nil {
+		category.Icon = *payload.Icon
+	}
+	if payload.Color != nil {
+		category.Color = strings.ToUpper(*payload.Color)
+	}
+	if payload.ParentID != nil {
+		category.ParentID = nil
+		if *payload.ParentID != 0 {
+			category.ParentID = payload.ParentID
 		}
+	}
+
+	siblings, err := s.rp.Category.GetCategories(owner)
+	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
-			Message: "Failed to get category",
+			Message: "Failed to get categories",
 			Data:    nil,
 			Error:   err,
 		})
 	}
+	subcategories := []models.Category{}
+	for _, c := range siblings {
+		if c.ParentID != nil && *c.ParentID == category.ID {
+			subcategories = append(subcategories, c)
+		}
+	}
 
-	// Update fields if provided
-	if payload.Name != nil {
-		category.Name = *payload.Name
+	if category.ParentID != nil {
+		if *category.ParentID == category.ID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "A category cannot be its own parent",
+				Data:    nil,
+			})
+		}
+		if len(subcategories) > 0 {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "A category with subcategories cannot become a subcategory",
+				Data:    nil,
+			})
+		}
+		parent, failed := s.getOwnedCategory(*category.ParentID, owner)
+		if failed != nil {
+			return failed
+		}
+		if failed := checkParent(*category, *parent); failed != nil {
+			return failed
+		}
+	}
+	for _, sub := range subcategories {
+		if failed := checkParent(sub, *category); failed != nil {
+			return failed
+		}
 	}
 
 	updatedCategory, err := s.rp.Category.UpdateCategory(*category)
@@ -138,40 +225,75 @@ func (s *Service) UpdateCategoryRequest(id uint, payload dto.UpdateCategoryReque
 		})
 	}
 
-	response := dto.CategoryResponse{
-		ID:        updatedCategory.ID,
-		Name:      updatedCategory.Name,
-		CreatedAt: updatedCategory.CreatedAt,
-		UpdatedAt: updatedCategory.UpdatedAt,
-	}
-
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusOK,
 		Message: "Category updated successfully",
-		Data:    response,
+		Data:    toCategoryResponse(*updatedCategory),
 	})
 }
 
-func (s *Service) DeleteCategoryRequest(id uint) *types.Response {
-	category, err := s.rp.Category.GetCategoryByID(id)
+func (s *Service) DeleteCategoryRequest(id uint, payload dto.DeleteCategoryRequest) *types.Response {
+	owner, failed := s.categoryOwner(payload.PhoneNumber)
+	if failed != nil {
+		return failed
+	}
+	category, failed := s.getOwnedCategory(id, owner)
+	if failed != nil {
+		return failed
+	}
+
+	// Subcategories are not cascaded, they would come back as top-level ones on their own
+	subcategories, err := s.rp.Category.CountSubcategories(category.ID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get subcategories",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if subcategories > 0 {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The category has subcategories, move or delete them first",
+			Data:    nil,
+		})
+	}
+
+	rule := trash.CategoryDeleteRule{Action: trash.CategoryUncategorize}
+	if payload.OnTransactions != "" {
+		rule.Action = trash.CategoryTransactionAction(payload.OnTransactions)
+	}
+	if rule.Action == trash.CategoryReassign {
+		if *payload.ReassignTo == category.ID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Cannot reassign transactions to the category being deleted",
+				Data:    nil,
+			})
+		}
+		target, err := s.rp.Category.GetCategoryByID(*payload.ReassignTo)
+		if err != nil || !sameOwner(target.UserID, owner) {
 			return helper.ParseResponse(&types.Response{
 				Code:    http.StatusNotFound,
-				Message: "Category not found",
+				Message: "Target category not found",
 				Data:    nil,
 				Error:   err,
 			})
 		}
-		return helper.ParseResponse(&types.Response{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to get category",
-			Data:    nil,
-			Error:   err,
-		})
+		rule.ReassignTo = payload.ReassignTo
 	}
 
-	if err := s.rp.Category.DeleteCategory(*category); err != nil {
+	var result *trash.CategoryDeleteResult
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		deleted, err := rp.Trash.DeleteCategory(*category, rule)
+		if err != nil {
+			return err
+		}
+		result = deleted
+		return recordCascadeRevisions(rp, owner, rule, deleted.Transactions)
+	})
+	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to delete category",
@@ -183,6 +305,41 @@ func (s *Service) DeleteCategoryRequest(id uint) *types.Response {
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusOK,
 		Message: "Category deleted successfully",
-		Data:    nil,
+		Data: dto.DeleteCategoryResponse{
+			ID:                    category.ID,
+			OnTransactions:        string(rule.Action),
+			ReassignTo:            rule.ReassignTo,
+			TransactionsAffected:  int64(len(result.Transactions)),
+			BudgetsDeleted:        result.Budgets,
+			RecurringRulesChanged: result.RecurringRules,
+			EnvelopeReturned:      result.EnvelopeReturned,
+		},
 	})
 }
+
+// recordCascadeRevisions adds the category change to the history of each affected transaction,
+// the actor is left empty for a template category
+func recordCascadeRevisions(rp repository.IRepository, owner *uint, rule trash.CategoryDeleteRule, transactions []models.Transaction) error {
+	action := models.RevisionActionUpdate
+	if rule.Action == trash.CategoryDeleteAll {
+		action = models.RevisionActionDelete
+	}
+
+	changes := make([]revision.Change, len(transactions))
+	for i := range transactions {
+		changes[i].Before = &transactions[i]
+		if action == models.RevisionActionDelete {
+			continue
+		}
+		after := transactions[i]
+		after.CategoryID = rule.ReassignTo
+		changes[i].After = &after
+	}
+
+	actor := models.RevisionActor{
+		ActorType: models.RevisionActorUser,
+		ActorID:   owner,
+		Source:    models.RevisionSourceCategory,
+	}
+	return rp.Revision.RecordChanges(action, actor, changes)
+}
@@ -1,24 +1,90 @@
 package dto
 
 import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"time"
 )
 
+// Without a phone number the category requests work on the template new users get a copy of
+
 type CreateCategoryRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=100"`
+	PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty"`
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	ParentID    *uint   `json:"parent_id" validate:"omitempty"`                      // Makes it a subcategory of a top-level category
+	Kind        string  `json:"kind" validate:"omitempty,oneof=EXPENSE INCOME BOTH"` // Defaults to the parent's kind, then EXPENSE
+	Icon        string  `json:"icon" validate:"omitempty,max=50"`
+	Color       string  `json:"color" validate:"omitempty,hexcolor,max=7"`
 }
 
 type UpdateCategoryRequest struct {
-	Name *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Name     *string `json:"name" validate:"omitempty,min=1,max=100"`
+	ParentID *uint   `json:"parent_id" validate:"omitempty"` // 0 makes it a top-level category
+	Kind     *string `json:"kind" validate:"omitempty,oneof=EXPENSE INCOME BOTH"`
+	Icon     *string `json:"icon" validate:"omitempty,max=50"`
+	Color    *string `json:"color" validate:"omitempty,hexcolor,max=7"`
+}
+
+type GetCategoriesRequest struct {
+	PhoneNumber *string `form:"phone_number" validate:"omitempty"`
+	Type        *string `form:"type" validate:"omitempty,oneof=INCOME EXPENSE"` // Only categories that can hold this type
 }
 
 type CategoryResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint                `json:"id"`
+	UserID        *uint               `json:"user_id"`
+	ParentID      *uint               `json:"parent_id"`
+	Name          string              `json:"name"`
+	Kind          models.CategoryKind `json:"kind"`
+	Icon          string              `json:"icon"`
+	Color         string              `json:"color"`
+	Subcategories []CategoryResponse  `json:"subcategories,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
 }
 
+// CategoryListResponse holds the top-level categories, each with its subcategories
 type CategoryListResponse struct {
 	Categories []CategoryResponse `json:"categories"`
 }
+
+// DeleteCategoryRequest says what happens to the category's transactions, they lose their category by default
+type DeleteCategoryRequest struct {
+	PhoneNumber    string `form:"phone_number" validate:"omitempty"`
+	OnTransactions string `form:"on_transactions" validate:"omitempty,oneof=uncategorize reassign delete"`
+	ReassignTo     *uint  `form:"reassign_to" validate:"required_if=OnTransactions reassign"`
+}
+
+type DeleteCategoryResponse struct {
+	ID                    uint        `json:"id"`
+	OnTransactions        string      `json:"on_transactions"`
+	ReassignTo            *uint       `json:"reassign_to,omitempty"`
+	TransactionsAffected  int64       `json:"transactions_affected"`
+	BudgetsDeleted        int64       `json:"budgets_deleted"`
+	RecurringRulesChanged int64       `json:"recurring_rules_changed"`
+	EnvelopeReturned      types.Money `json:"envelope_returned"` // Money the envelope held, back in ready to assign
+}
+
+// MergeCategoryRequest folds a category into the target, with preview nothing is changed
+type MergeCategoryRequest struct {
+	PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty"`
+	TargetID    uint    `json:"target_id" validate:"required"`
+	Preview     bool    `json:"preview"`
+}
+
+type MergeCategoryResponse struct {
+	SourceID                 uint  `json:"source_id"`
+	TargetID                 uint  `json:"target_id"`
+	Preview                  bool  `json:"preview"`
+	TransactionsMoved        int64 `json:"transactions_moved"`
+	TrashedTransactionsMoved int64 `json:"trashed_transactions_moved"`
+	BudgetsMoved             int64 `json:"budgets_moved"`
+	BudgetsCombined          int64 `json:"budgets_combined"` // Added to the target's budget for the same period
+	BudgetTemplatesMoved     int64 `json:"budget_templates_moved"`
+	EnvelopeAllocationsMoved int64 `json:"envelope_allocations_moved"` // Money moved into or out of the envelope
+	SavingsGoalsMoved        int64 `json:"savings_goals_moved"`
+	LoansMoved               int64 `json:"loans_moved"`
+	BillsMoved               int64 `json:"bills_moved"`
+	RecurringRulesMoved      int64 `json:"recurring_rules_moved"`
+	SubcategoriesMoved       int64 `json:"subcategories_moved"`
+}
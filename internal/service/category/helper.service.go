@@ -0,0 +1,125 @@
+package category
+
+import (
+	"errors"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/category/dto"
+
+	"gorm.io/gorm"
+)
+
+// categoryOwner resolves whose categories a request works on, nil is the template and is used
+// when no phone number is given
+func (s *Service) categoryOwner(phoneNumber string) (*uint, *types.Response) {
+	if phoneNumber == "" {
+		return nil, nil
+	}
+
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	return &user.ID, nil
+}
+
+func (s *Service) getOwnedCategory(id uint, owner *uint) (*models.Category, *types.Response) {
+	category, err := s.rp.Category.GetCategoryByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "Category not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get category",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if !sameOwner(category.UserID, owner) {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return category, nil
+}
+
+func sameOwner(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// checkParent enforces the two levels of categories and that a subcategory holds a subset of
+// the transaction types its parent does
+func checkParent(category, parent models.Category) *types.Response {
+	if parent.ParentID != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "A subcategory cannot have subcategories",
+			Data:    nil,
+		})
+	}
+	if parent.Kind != models.CategoryKindBoth && category.Kind != parent.Kind {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "A " + string(parent.Kind) + " category can only have " + string(parent.Kind) + " subcategories",
+			Data:    nil,
+		})
+	}
+	return nil
+}
+
+func toCategoryResponse(c models.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:        c.ID,
+		UserID:    c.UserID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		Kind:      c.Kind,
+		Icon:      c.Icon,
+		Color:     c.Color,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// categoryTree nests subcategories under their parents, a subcategory whose parent is not in
+// the list is shown at the top level
+func categoryTree(categories []models.Category) []dto.CategoryResponse {
+	index := map[uint]int{}
+	tree := []dto.CategoryResponse{}
+	for _, c := range categories {
+		if c.ParentID == nil {
+			index[c.ID] = len(tree)
+			tree = append(tree, toCategoryResponse(c))
+		}
+	}
+	for _, c := range categories {
+		if c.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*c.ParentID]; ok {
+			tree[i].Subcategories = append(tree[i].Subcategories, toCategoryResponse(c))
+		} else {
+			tree = append(tree, toCategoryResponse(c))
+		}
+	}
+	return tree
+}
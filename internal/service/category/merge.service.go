@@ -0,0 +1,120 @@
+package category
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/category"
+	"pannypal/internal/repository/trash"
+	"pannypal/internal/service/category/dto"
+)
+
+func (s *Service) MergeCategoryRequest(id uint, payload dto.MergeCategoryRequest) *types.Response {
+	phoneNumber := ""
+	if payload.PhoneNumber != nil {
+		phoneNumber = *payload.PhoneNumber
+	}
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+	source, failed := s.getOwnedCategory(id, owner)
+	if failed != nil {
+		return failed
+	}
+
+	if payload.TargetID == source.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "A category cannot be merged into itself",
+			Data:    nil,
+		})
+	}
+	target, err := s.rp.Category.GetCategoryByID(payload.TargetID)
+	if err != nil || !sameOwner(target.UserID, owner) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Target category not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Every transaction of the source has to fit the target
+	if target.Kind != models.CategoryKindBoth && target.Kind != source.Kind {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "A " + string(target.Kind) + " category cannot take the transactions of a " + string(source.Kind) + " category",
+			Data:    nil,
+		})
+	}
+
+	subcategories, err := s.rp.Category.CountSubcategories(source.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get subcategories",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if subcategories > 0 && target.ParentID != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The category has subcategories, it can only be merged into a top-level category",
+			Data:    nil,
+		})
+	}
+
+	var result *category.MergeResult
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		merged, err := rp.Category.MergeCategory(*source, *target, payload.Preview)
+		if err != nil {
+			return err
+		}
+		result = merged
+		if payload.Preview {
+			return nil
+		}
+		return recordCascadeRevisions(rp, owner, trash.CategoryDeleteRule{
+			Action:     trash.CategoryReassign,
+			ReassignTo: &target.ID,
+		}, merged.Transactions)
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to merge category",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	message := "Category merged successfully"
+	if payload.Preview {
+		message = "Category merge previewed successfully"
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: message,
+		Data: dto.MergeCategoryResponse{
+			SourceID:                 source.ID,
+			TargetID:                 target.ID,
+			Preview:                  payload.Preview,
+			TransactionsMoved:        int64(len(result.Transactions)),
+			TrashedTransactionsMoved: result.TrashedTransactions,
+			BudgetsMoved:             result.BudgetsMoved,
+			BudgetsCombined:          result.BudgetsCombined,
+			BudgetTemplatesMoved:     result.BudgetTemplates,
+			EnvelopeAllocationsMoved: result.EnvelopeAllocations,
+			SavingsGoalsMoved:        result.SavingsGoals,
+			LoansMoved:               result.Loans,
+			BillsMoved:               result.Bills,
+			RecurringRulesMoved:      result.RecurringRules,
+			SubcategoriesMoved:       result.Subcategories,
+		},
+	})
+}
@@ -17,10 +17,11 @@ type Service struct {
 
 type IService interface {
 	CreateCategoryRequest(payload dto.CreateCategoryRequest) *types.Response
-	GetCategoriesRequest() *types.Response
-	GetCategoryByIDRequest(id uint) *types.Response
-	UpdateCategoryRequest(id uint, payload dto.UpdateCategoryRequest) *types.Response
-	DeleteCategoryRequest(id uint) *types.Response
+	GetCategoriesRequest(payload dto.GetCategoriesRequest) *types.Response
+	GetCategoryByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateCategoryRequest(id uint, payload dto.UpdateCategoryRequest, phoneNumber string) *types.Response
+	DeleteCategoryRequest(id uint, payload dto.DeleteCategoryRequest) *types.Response
+	MergeCategoryRequest(id uint, payload dto.MergeCategoryRequest) *types.Response
 }
 
 func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
@@ -8,6 +8,7 @@ import (
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
 	"pannypal/internal/service/chatbot/dto"
+	"pannypal/internal/service/chatbot/engine"
 	"strings"
 	"time"
 
@@ -65,24 +66,52 @@ func (s *Service) SendMessage(payload dto.SendMessageRequest) *types.Response {
 		}
 	}
 
+	// The dashboard chat answers over all data, like the dashboard analytics without a phone number
+	savedMessage, metadata, err := s.converse(conversation, engine.Scope{}, payload.Message)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to process message",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Build response
+	response := dto.ChatMessageResponse{
+		SessionID:    conversation.SessionID,
+		Role:         savedMessage.Role,
+		Content:      savedMessage.Content,
+		Metadata:     metadata,
+		TokenUsed:    savedMessage.TokenUsed,
+		ResponseTime: int(time.Since(startTime).Milliseconds()),
+		CreatedAt:    savedMessage.CreatedAt,
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    response,
+		Error:   nil,
+	})
+}
+
+// converse saves the user message, analyzes it against the conversation history
+// and the data of the scope, and saves the assistant answer
+func (s *Service) converse(conversation *models.ChatConversation, scope engine.Scope, message string) (*models.ChatMessage, *dto.MessageMetadata, error) {
 	// Save user message
 	userMessage := models.ChatMessage{
 		ConversationID: conversation.ID,
 		Role:           "user",
-		Content:        payload.Message,
+		Content:        message,
 		Metadata:       "",
 		TokenUsed:      0,
 		ResponseTime:   0,
 	}
 
-	_, err = s.rp.Chatbot.CreateMessage(userMessage)
+	_, err := s.rp.Chatbot.CreateMessage(userMessage)
 	if err != nil {
-		return helper.ParseResponse(&types.Response{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to save user message",
-			Data:    nil,
-			Error:   err,
-		})
+		return nil, nil, fmt.Errorf("failed to save user message: %w", err)
 	}
 
 	// Get conversation history (last 10 messages)
@@ -93,16 +122,12 @@ func (s *Service) SendMessage(payload dto.SendMessageRequest) *types.Response {
 
 	// Analyze query using engine
 	metadata, textResponse, tokenUsed, responseTime, err := s.analysisEngine.AnalyzeQuery(
-		payload.Message,
+		scope,
+		message,
 		history,
 	)
 	if err != nil {
-		return helper.ParseResponse(&types.Response{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to analyze query",
-			Data:    nil,
-			Error:   err,
-		})
+		return nil, nil, fmt.Errorf("failed to analyze query: %w", err)
 	}
 
 	// Save assistant message
@@ -118,31 +143,10 @@ func (s *Service) SendMessage(payload dto.SendMessageRequest) *types.Response {
 
 	savedMessage, err := s.rp.Chatbot.CreateMessage(assistantMessage)
 	if err != nil {
-		return helper.ParseResponse(&types.Response{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to save assistant message",
-			Data:    nil,
-			Error:   err,
-		})
+		return nil, nil, fmt.Errorf("failed to save assistant message: %w", err)
 	}
 
-	// Build response
-	response := dto.ChatMessageResponse{
-		SessionID:    conversation.SessionID,
-		Role:         savedMessage.Role,
-		Content:      savedMessage.Content,
-		Metadata:     metadata,
-		TokenUsed:    savedMessage.TokenUsed,
-		ResponseTime: int(time.Since(startTime).Milliseconds()),
-		CreatedAt:    savedMessage.CreatedAt,
-	}
-
-	return helper.ParseResponse(&types.Response{
-		Code:    http.StatusOK,
-		Message: "Success",
-		Data:    response,
-		Error:   nil,
-	})
+	return savedMessage, metadata, nil
 }
 
 // GetConversations retrieves all conversations
@@ -8,6 +8,22 @@ type SendMessageRequest struct {
 	Message   string `json:"message" validate:"required"`
 }
 
+// ChatReplyRequest represents a question asked from a WhatsApp chat
+type ChatReplyRequest struct {
+	ChatID   string `json:"chat_id" validate:"required"`
+	Message  string `json:"message" validate:"required"`
+	UserID   *uint  `json:"user_id"`   // Sender of a private chat
+	LedgerID *uint  `json:"ledger_id"` // Ledger of a group chat
+}
+
+// ChatReply represents the chatbot answer rendered for WhatsApp
+type ChatReply struct {
+	SessionID  string  `json:"session_id"`
+	Text       string  `json:"text"`
+	ChartURL   *string `json:"chart_url,omitempty"`   // Rendered chart image, nil when no renderer is configured
+	ChartTable string  `json:"chart_table,omitempty"` // Text fallback of the chart
+}
+
 // ChatMessageResponse represents a chat message response
 type ChatMessageResponse struct {
 	SessionID    string      `json:"session_id"`
@@ -55,6 +55,7 @@ func NewAnalysisEngine(
 
 // AnalyzeQuery analyzes user query and generates response
 func (e *AnalysisEngine) AnalyzeQuery(
+	scope Scope,
 	userQuery string,
 	conversationHistory []models.ChatMessage,
 ) (*dto.MessageMetadata, string, int, int, error) {
@@ -62,7 +63,7 @@ func (e *AnalysisEngine) AnalyzeQuery(
 	intent := e.detectIntent(userQuery)
 
 	// 2. Fetch relevant data based on intent
-	dataContext, err := e.fetchRelevantData(intent, userQuery)
+	dataContext, err := e.fetchRelevantData(scope, intent, userQuery)
 	if err != nil {
 		return nil, "", 0, 0, fmt.Errorf("failed to fetch data: %w", err)
 	}
@@ -157,39 +158,39 @@ func (e *AnalysisEngine) detectIntent(query string) IntentType {
 }
 
 // fetchRelevantData fetches data based on intent
-func (e *AnalysisEngine) fetchRelevantData(intent IntentType, query string) (string, error) {
+func (e *AnalysisEngine) fetchRelevantData(scope Scope, intent IntentType, query string) (string, error) {
 	now := time.Now()
 
 	switch intent {
 	case IntentStatistical, IntentSummary, IntentGeneral:
 		// Fetch current month data by default
-		return e.dataFetcher.FetchCurrentMonthData()
+		return e.dataFetcher.FetchCurrentMonthData(scope)
 
 	case IntentTrend:
 		// Fetch last 6 months trend
-		return e.dataFetcher.FetchMonthlyTrend(now.Year())
+		return e.dataFetcher.FetchMonthlyTrend(scope, now.Year())
 
 	case IntentCategory:
 		// Fetch category breakdown for current month
 		startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 		endOfMonth := startOfMonth.AddDate(0, 1, -1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-		return e.dataFetcher.FetchAllCategoriesComparison(&startOfMonth, &endOfMonth)
+		return e.dataFetcher.FetchAllCategoriesComparison(scope, &startOfMonth, &endOfMonth)
 
 	case IntentBudget:
 		// Fetch current month data (budget comparison will be done by AI)
-		return e.dataFetcher.FetchCurrentMonthData()
+		return e.dataFetcher.FetchCurrentMonthData(scope)
 
 	case IntentRecommendation, IntentPrediction:
 		// Fetch last 3 months for better recommendations
-		return e.dataFetcher.FetchLastNMonths(3)
+		return e.dataFetcher.FetchLastNMonths(scope, 3)
 
 	case IntentComparison:
 		// Fetch last 6 months for comparison
-		return e.dataFetcher.FetchLastNMonths(6)
+		return e.dataFetcher.FetchLastNMonths(scope, 6)
 
 	default:
 		// Default to current month
-		return e.dataFetcher.FetchCurrentMonthData()
+		return e.dataFetcher.FetchCurrentMonthData(scope)
 	}
 }
 
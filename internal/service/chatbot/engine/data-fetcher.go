@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"pannypal/internal/repository/analytics"
 	"time"
 )
@@ -13,6 +14,14 @@ type DataFetcher struct {
 	analyticsRepo analytics.IRepository
 }
 
+// Scope limits the data the chatbot answers from. A WhatsApp chat sees the sender's transactions, or
+// the ledger's in a group chat. The dashboard chat leaves both nil and sees all data, like the other
+// dashboard analytics.
+type Scope struct {
+	UserID   *uint
+	LedgerID *uint
+}
+
 // NewDataFetcher creates a new DataFetcher instance
 func NewDataFetcher(analyticsRepo analytics.IRepository) *DataFetcher {
 	return &DataFetcher{
@@ -21,9 +30,8 @@ func NewDataFetcher(analyticsRepo analytics.IRepository) *DataFetcher {
 }
 
 // FetchTransactionSummary fetches transaction summary for a date range
-func (d *DataFetcher) FetchTransactionSummary(startDate, endDate time.Time) (string, error) {
-	// Since this is personal use, userID is nil to get all data
-	data, err := d.analyticsRepo.GetDashboardAnalytics(nil, startDate, endDate)
+func (d *DataFetcher) FetchTransactionSummary(scope Scope, startDate, endDate time.Time) (string, error) {
+	data, err := d.analyticsRepo.GetDashboardAnalytics(scope.UserID, scope.LedgerID, startDate, endDate)
 	if err != nil {
 		return "", err
 	}
@@ -33,17 +41,17 @@ func (d *DataFetcher) FetchTransactionSummary(startDate, endDate time.Time) (str
 			"start": startDate.Format("2006-01-02"),
 			"end":   endDate.Format("2006-01-02"),
 		},
-		"current": map[string]float64{
+		"current": map[string]types.Money{
 			"income":  data.CurrentIncome,
 			"expense": data.CurrentExpense,
 			"net":     data.CurrentIncome - data.CurrentExpense,
 		},
-		"previous": map[string]float64{
+		"previous": map[string]types.Money{
 			"income":  data.PreviousIncome,
 			"expense": data.PreviousExpense,
 			"net":     data.PreviousIncome - data.PreviousExpense,
 		},
-		"all_time": map[string]float64{
+		"all_time": map[string]types.Money{
 			"total_income":  data.TotalIncomeAllTime,
 			"total_expense": data.TotalExpenseAllTime,
 			"net":           data.TotalIncomeAllTime - data.TotalExpenseAllTime,
@@ -59,19 +67,20 @@ func (d *DataFetcher) FetchTransactionSummary(startDate, endDate time.Time) (str
 }
 
 // FetchCategoryBreakdown fetches category breakdown for a specific type and period
-func (d *DataFetcher) FetchCategoryBreakdown(txType models.TransactionType, startDate, endDate *time.Time) (string, error) {
+func (d *DataFetcher) FetchCategoryBreakdown(scope Scope, txType models.TransactionType, startDate, endDate *time.Time) (string, error) {
 	filters := analytics.CategoryAnalyticsFilters{
+		LedgerID:  scope.LedgerID,
 		StartDate: startDate,
 		EndDate:   endDate,
 		Type:      &txType,
 	}
 
-	data, err := d.analyticsRepo.GetCategoryAnalytics(nil, filters)
+	data, err := d.analyticsRepo.GetCategoryAnalytics(scope.UserID, filters)
 	if err != nil {
 		return "", err
 	}
 
-	var total float64
+	var total types.Money
 	categories := make([]map[string]interface{}, 0)
 	for _, cat := range data {
 		total += cat.TotalAmount
@@ -87,7 +96,7 @@ func (d *DataFetcher) FetchCategoryBreakdown(txType models.TransactionType, star
 	// Add percentage
 	for i := range categories {
 		if total > 0 {
-			categories[i]["percentage"] = (categories[i]["amount"].(float64) / total) * 100
+			categories[i]["percentage"] = (categories[i]["amount"].(types.Money).Float64() / total.Float64()) * 100
 		} else {
 			categories[i]["percentage"] = 0.0
 		}
@@ -112,8 +121,8 @@ func (d *DataFetcher) FetchCategoryBreakdown(txType models.TransactionType, star
 }
 
 // FetchMonthlyTrend fetches monthly trend for a specific year
-func (d *DataFetcher) FetchMonthlyTrend(year int) (string, error) {
-	data, err := d.analyticsRepo.GetMonthlyAnalytics(nil, year)
+func (d *DataFetcher) FetchMonthlyTrend(scope Scope, year int) (string, error) {
+	data, err := d.analyticsRepo.GetMonthlyAnalytics(scope.UserID, scope.LedgerID, year)
 	if err != nil {
 		return "", err
 	}
@@ -144,8 +153,8 @@ func (d *DataFetcher) FetchMonthlyTrend(year int) (string, error) {
 }
 
 // FetchYearlyTrend fetches yearly trend for a range of years
-func (d *DataFetcher) FetchYearlyTrend(startYear, endYear int) (string, error) {
-	data, err := d.analyticsRepo.GetYearlyAnalytics(nil, startYear, endYear)
+func (d *DataFetcher) FetchYearlyTrend(scope Scope, startYear, endYear int) (string, error) {
+	data, err := d.analyticsRepo.GetYearlyAnalytics(scope.UserID, scope.LedgerID, startYear, endYear)
 	if err != nil {
 		return "", err
 	}
@@ -178,16 +187,16 @@ func (d *DataFetcher) FetchYearlyTrend(startYear, endYear int) (string, error) {
 }
 
 // FetchCurrentMonthData fetches data for current month
-func (d *DataFetcher) FetchCurrentMonthData() (string, error) {
+func (d *DataFetcher) FetchCurrentMonthData(scope Scope) (string, error) {
 	now := time.Now()
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	endOfMonth := startOfMonth.AddDate(0, 1, -1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-	return d.FetchTransactionSummary(startOfMonth, endOfMonth)
+	return d.FetchTransactionSummary(scope, startOfMonth, endOfMonth)
 }
 
 // FetchLastNMonths fetches data for last N months
-func (d *DataFetcher) FetchLastNMonths(n int) (string, error) {
+func (d *DataFetcher) FetchLastNMonths(scope Scope, n int) (string, error) {
 	now := time.Now()
 	endDate := now
 	startDate := now.AddDate(0, -n, 0)
@@ -199,7 +208,7 @@ func (d *DataFetcher) FetchLastNMonths(n int) (string, error) {
 		monthStart = time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, monthStart.Location())
 		monthEnd := monthStart.AddDate(0, 1, -1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-		summary, err := d.FetchTransactionSummary(monthStart, monthEnd)
+		summary, err := d.FetchTransactionSummary(scope, monthStart, monthEnd)
 		if err != nil {
 			return "", err
 		}
@@ -229,17 +238,17 @@ func (d *DataFetcher) FetchLastNMonths(n int) (string, error) {
 }
 
 // FetchAllCategoriesComparison fetches comparison of all categories
-func (d *DataFetcher) FetchAllCategoriesComparison(startDate, endDate *time.Time) (string, error) {
+func (d *DataFetcher) FetchAllCategoriesComparison(scope Scope, startDate, endDate *time.Time) (string, error) {
 	// Fetch expenses
 	expenseType := models.TypeExpense
-	expenseData, err := d.FetchCategoryBreakdown(expenseType, startDate, endDate)
+	expenseData, err := d.FetchCategoryBreakdown(scope, expenseType, startDate, endDate)
 	if err != nil {
 		return "", err
 	}
 
 	// Fetch income
 	incomeType := models.TypeIncome
-	incomeData, err := d.FetchCategoryBreakdown(incomeType, startDate, endDate)
+	incomeData, err := d.FetchCategoryBreakdown(scope, incomeType, startDate, endDate)
 	if err != nil {
 		return "", err
 	}
@@ -274,33 +283,33 @@ func formatTimePtr(t *time.Time) string {
 }
 
 // BuildContextString builds a context string from data for AI prompt
-func (d *DataFetcher) BuildContextString(dataType string, params map[string]interface{}) (string, error) {
+func (d *DataFetcher) BuildContextString(scope Scope, dataType string, params map[string]interface{}) (string, error) {
 	switch dataType {
 	case "current_month":
-		return d.FetchCurrentMonthData()
+		return d.FetchCurrentMonthData(scope)
 	case "transaction_summary":
 		startDate := params["start_date"].(time.Time)
 		endDate := params["end_date"].(time.Time)
-		return d.FetchTransactionSummary(startDate, endDate)
+		return d.FetchTransactionSummary(scope, startDate, endDate)
 	case "category_breakdown":
 		txType := params["type"].(models.TransactionType)
 		startDate, _ := params["start_date"].(*time.Time)
 		endDate, _ := params["end_date"].(*time.Time)
-		return d.FetchCategoryBreakdown(txType, startDate, endDate)
+		return d.FetchCategoryBreakdown(scope, txType, startDate, endDate)
 	case "monthly_trend":
 		year := params["year"].(int)
-		return d.FetchMonthlyTrend(year)
+		return d.FetchMonthlyTrend(scope, year)
 	case "yearly_trend":
 		startYear := params["start_year"].(int)
 		endYear := params["end_year"].(int)
-		return d.FetchYearlyTrend(startYear, endYear)
+		return d.FetchYearlyTrend(scope, startYear, endYear)
 	case "last_n_months":
 		n := params["n"].(int)
-		return d.FetchLastNMonths(n)
+		return d.FetchLastNMonths(scope, n)
 	case "all_categories":
 		startDate, _ := params["start_date"].(*time.Time)
 		endDate, _ := params["end_date"].(*time.Time)
-		return d.FetchAllCategoriesComparison(startDate, endDate)
+		return d.FetchAllCategoriesComparison(scope, startDate, endDate)
 	default:
 		return "", fmt.Errorf("unknown data type: %s", dataType)
 	}
@@ -2,7 +2,11 @@ package engine
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"pannypal/internal/pkg/helper"
 	"pannypal/internal/service/chatbot/dto"
+	"strings"
 )
 
 // Visualizer generates visualization data from raw data
@@ -280,3 +284,73 @@ func (v *Visualizer) ParseAndVisualize(aiResponse string) (*dto.VisualizationDat
 
 	return v.GenerateChartData(vizType, data)
 }
+
+// ChartImageURL renders chart visualization as an image URL on a Chart.js
+// compatible render service (e.g. https://quickchart.io/chart)
+func (v *Visualizer) ChartImageURL(renderURL string, viz *dto.VisualizationData) (string, error) {
+	chartData, ok := viz.Data.(dto.ChartData)
+	if !ok || len(chartData.Labels) == 0 {
+		return "", fmt.Errorf("visualization has no chart data")
+	}
+
+	chartType := viz.Type
+	switch chartType {
+	case "bar", "line", "pie":
+	case "donut":
+		chartType = "doughnut"
+	default:
+		return "", fmt.Errorf("visualization type %s cannot be rendered as image", viz.Type)
+	}
+
+	colors := chartData.Colors
+	if len(colors) == 0 {
+		colors = generateColors(len(chartData.Labels))
+	}
+
+	config := map[string]interface{}{
+		"type": chartType,
+		"data": map[string]interface{}{
+			"labels": chartData.Labels,
+			"datasets": []map[string]interface{}{
+				{
+					"label":           "Jumlah (Rp)",
+					"data":            chartData.Values,
+					"backgroundColor": colors,
+				},
+			},
+		},
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return renderURL + "?width=600&height=400&format=png&c=" + url.QueryEscape(string(configJSON)), nil
+}
+
+// ChartTextTable renders chart visualization as a monospace text table,
+// used where images cannot be sent
+func (v *Visualizer) ChartTextTable(viz *dto.VisualizationData) string {
+	chartData, ok := viz.Data.(dto.ChartData)
+	if !ok || len(chartData.Labels) == 0 {
+		return ""
+	}
+
+	labelWidth := 0
+	for _, label := range chartData.Labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	lines := []string{}
+	for i, label := range chartData.Labels {
+		if i >= len(chartData.Values) {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  Rp. %s", labelWidth, label, helper.FormatCurrency(int(chartData.Values[i]))))
+	}
+
+	return "```\n" + strings.Join(lines, "\n") + "\n```"
+}
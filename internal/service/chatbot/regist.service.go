@@ -18,6 +18,7 @@ type Service struct {
 	rp             repository.IRepository
 	aiClient       *ai.AiClient
 	analysisEngine *engine.AnalysisEngine
+	visualizer     *engine.Visualizer
 }
 
 type IService interface {
@@ -25,6 +26,8 @@ type IService interface {
 	GetConversations(limit int) *types.Response
 	GetConversation(sessionID string, limit int) *types.Response
 	ClearConversation(sessionID string) *types.Response
+	ReplyToChat(payload dto.ChatReplyRequest) (*dto.ChatReply, error)
+	ResetChat(chatID string) error
 }
 
 func NewService(
@@ -46,5 +49,6 @@ func NewService(
 		rp:             repository,
 		aiClient:       aiClient,
 		analysisEngine: analysisEngine,
+		visualizer:     engine.NewVisualizer(),
 	}
 }
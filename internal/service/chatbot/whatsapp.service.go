@@ -0,0 +1,150 @@
+package chatbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/chatbot/dto"
+	"pannypal/internal/service/chatbot/engine"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ReplyToChat answers a question asked from a WhatsApp chat from the sender's data, or the ledger's
+// in a group chat. Each chat keeps one active conversation so follow-up questions share the same history.
+func (s *Service) ReplyToChat(payload dto.ChatReplyRequest) (*dto.ChatReply, error) {
+	if strings.TrimSpace(payload.Message) == "" {
+		return nil, fmt.Errorf("empty message")
+	}
+	// Without a sender the engine would answer over everyone's data
+	if payload.UserID == nil && payload.LedgerID == nil {
+		return nil, fmt.Errorf("chat sender is unknown")
+	}
+
+	conversation, err := s.rp.Chatbot.GetActiveConversationByChatID(payload.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversation == nil {
+		chatID := payload.ChatID
+		conversation, err = s.rp.Chatbot.CreateConversation(models.ChatConversation{
+			SessionID: uuid.New().String(),
+			Title:     s.generateTitle(payload.Message),
+			IsActive:  true,
+			ChatID:    &chatID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scope := engine.Scope{UserID: payload.UserID, LedgerID: payload.LedgerID}
+	savedMessage, metadata, err := s.converse(conversation, scope, payload.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &dto.ChatReply{
+		SessionID: conversation.SessionID,
+		Text:      renderChatText(savedMessage.Content),
+	}
+
+	if metadata != nil && metadata.Visualization != nil {
+		reply.ChartTable = s.visualizer.ChartTextTable(metadata.Visualization)
+
+		renderURL := helper.GetEnv("CHART_RENDER_URL")
+		if renderURL != "" {
+			chartURL, err := s.visualizer.ChartImageURL(renderURL, metadata.Visualization)
+			if err == nil {
+				reply.ChartURL = &chartURL
+			}
+		}
+	}
+
+	return reply, nil
+}
+
+// ResetChat closes the active conversation of a WhatsApp chat, the next
+// question starts a fresh one
+func (s *Service) ResetChat(chatID string) error {
+	conversation, err := s.rp.Chatbot.GetActiveConversationByChatID(chatID)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		return nil
+	}
+
+	conversation.IsActive = false
+	return s.rp.Chatbot.UpdateConversation(*conversation)
+}
+
+// renderChatText turns the AI answer (intro text plus JSON block) into plain
+// WhatsApp text. Answers that are not JSON are returned as is.
+func renderChatText(content string) string {
+	var parsed struct {
+		Answer          string            `json:"answer"`
+		Insights        []string          `json:"insights"`
+		Recommendations []json.RawMessage `json:"recommendations"`
+	}
+
+	intro := content
+	if idx := strings.Index(content, "```"); idx >= 0 {
+		intro = content[:idx]
+	}
+	intro = strings.TrimSpace(intro)
+
+	if err := json.Unmarshal([]byte(helper.CleanAIResponse(content)), &parsed); err != nil {
+		if intro != "" {
+			return intro
+		}
+		return strings.TrimSpace(content)
+	}
+
+	parts := []string{}
+	if intro != "" && !strings.HasPrefix(intro, "{") {
+		parts = append(parts, intro)
+	}
+	if parsed.Answer != "" {
+		parts = append(parts, parsed.Answer)
+	}
+
+	if len(parsed.Insights) > 0 {
+		text := "*Insight:*"
+		for _, insight := range parsed.Insights {
+			text += "\n• " + insight
+		}
+		parts = append(parts, text)
+	}
+
+	if len(parsed.Recommendations) > 0 {
+		text := "*Rekomendasi:*"
+		for i, raw := range parsed.Recommendations {
+			text += fmt.Sprintf("\n%d. %s", i+1, recommendationText(raw))
+		}
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// recommendationText reads a recommendation that may be a plain string or a
+// {title, description} object
+func recommendationText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var rec dto.RecommendationData
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return string(raw)
+	}
+	if rec.Description != "" {
+		return rec.Title + " - " + rec.Description
+	}
+	return rec.Title
+}
@@ -0,0 +1,170 @@
+package currency
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/service/currency/dto"
+	"strings"
+	"time"
+)
+
+// RefreshRates loads the provider's rates against the pivot currency for the given day
+func (s *Service) RefreshRates(date time.Time) error {
+	rates, err := s.provider.GetRates(s.ctx, models.ExchangeRatePivot)
+	if err != nil {
+		return err
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	records := make([]models.ExchangeRate, 0, len(rates))
+	for currency, rate := range rates {
+		if len(currency) != 3 || rate <= 0 {
+			continue
+		}
+		records = append(records, models.ExchangeRate{
+			Currency: currency,
+			Date:     day,
+			Rate:     rate,
+			Source:   s.provider.Name(),
+		})
+	}
+
+	if err := s.rp.ExchangeRate.UpsertRates(records); err != nil {
+		return err
+	}
+
+	// Transactions in these currencies are left out of every total until the provider covers them
+	unconverted, err := s.rp.ExchangeRate.GetUnconvertedCurrencies(nil)
+	if err != nil {
+		return err
+	}
+	if len(unconverted) > 0 {
+		logger.Warning.Printf("No exchange rate for %s, their transactions are left out of the totals", strings.Join(unconverted, ", "))
+	}
+	return nil
+}
+
+func (s *Service) GetRatesRequest(payload dto.GetRatesRequest) *types.Response {
+	date := time.Now()
+	if payload.Date != nil {
+		date = *payload.Date
+	}
+
+	rates, err := s.rp.ExchangeRate.GetRatesOn(date)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.RateListResponse{
+		Pivot: models.ExchangeRatePivot,
+		Rates: make([]dto.RateResponse, len(rates)),
+	}
+	for i, rate := range rates {
+		response.Rates[i] = dto.RateResponse{
+			Currency: rate.Currency,
+			Rate:     rate.Rate,
+		}
+	}
+	if len(rates) > 0 {
+		response.Date = &rates[0].Date
+		response.Source = rates[0].Source
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Exchange rates retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) RefreshRatesRequest() *types.Response {
+	if err := s.RefreshRates(time.Now()); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadGateway,
+			Message: "Failed to refresh exchange rates",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.GetRatesRequest(dto.GetRatesRequest{})
+}
+
+func (s *Service) ConvertRequest(payload dto.ConvertRequest) *types.Response {
+	date := time.Now()
+	if payload.Date != nil {
+		date = *payload.Date
+	}
+	from := strings.ToUpper(payload.From)
+	to := strings.ToUpper(payload.To)
+
+	rate, err := s.rp.ExchangeRate.GetRate(from, to, date)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get exchange rate",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if rate == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Exchange rate not found",
+			Data:    nil,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Amount converted successfully",
+		Data: dto.ConvertResponse{
+			Amount:          payload.Amount,
+			From:            from,
+			To:              to,
+			Rate:            *rate,
+			ConvertedAmount: payload.Amount.Mul(*rate),
+			Date:            date,
+		},
+	})
+}
+
+func (s *Service) UpdateBaseCurrencyRequest(payload dto.UpdateBaseCurrencyRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	user.BaseCurrency = strings.ToUpper(payload.BaseCurrency)
+	updated, err := s.rp.User.UpdateUser(*user)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update base currency",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Base currency updated successfully",
+		Data: dto.BaseCurrencyResponse{
+			PhoneNumber:  updated.PhoneNumber,
+			BaseCurrency: updated.BaseCurrency,
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type GetRatesRequest struct {
+	Date *time.Time `form:"date" validate:"omitempty" time_format:"2006-01-02"` // Defaults to today
+}
+
+type ConvertRequest struct {
+	Amount types.Money `form:"amount" validate:"required,gt=0"`
+	From   string      `form:"from" validate:"required,iso4217"`
+	To     string      `form:"to" validate:"required,iso4217"`
+	Date   *time.Time  `form:"date" validate:"omitempty" time_format:"2006-01-02"`
+}
+
+type UpdateBaseCurrencyRequest struct {
+	PhoneNumber  string `json:"phone_number" validate:"required"`
+	BaseCurrency string `json:"base_currency" validate:"required,iso4217"`
+}
+
+type RateResponse struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"` // Units of the currency for one unit of the pivot
+}
+
+type RateListResponse struct {
+	Pivot  string         `json:"pivot"`
+	Date   *time.Time     `json:"date"` // Day the rates are from, nil when no rates are loaded
+	Source string         `json:"source"`
+	Rates  []RateResponse `json:"rates"`
+}
+
+type ConvertResponse struct {
+	Amount          types.Money `json:"amount"`
+	From            string      `json:"from"`
+	To              string      `json:"to"`
+	Rate            float64     `json:"rate"`
+	ConvertedAmount types.Money `json:"converted_amount"`
+	Date            time.Time   `json:"date"`
+}
+
+type BaseCurrencyResponse struct {
+	PhoneNumber  string `json:"phone_number"`
+	BaseCurrency string `json:"base_currency"`
+}
@@ -0,0 +1,36 @@
+package currency
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	rateprovider "pannypal/internal/pkg/rate-provider"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/currency/dto"
+	"time"
+)
+
+type Service struct {
+	ctx      context.Context
+	redis    redis.IRedis
+	rp       repository.IRepository
+	provider rateprovider.IProvider
+}
+
+type IService interface {
+	RefreshRates(date time.Time) error
+
+	GetRatesRequest(payload dto.GetRatesRequest) *types.Response
+	RefreshRatesRequest() *types.Response
+	ConvertRequest(payload dto.ConvertRequest) *types.Response
+	UpdateBaseCurrencyRequest(payload dto.UpdateBaseCurrencyRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, provider rateprovider.IProvider) IService {
+	return &Service{
+		ctx:      ctx,
+		redis:    redis,
+		rp:       repository,
+		provider: provider,
+	}
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+// UpdateEnvelopeModeRequest turns envelope budgeting on or off, income and spending count from
+// StartDate, the first day of the current month by default
+type UpdateEnvelopeModeRequest struct {
+	PhoneNumber string     `json:"phone_number" validate:"required"`
+	Enabled     bool       `json:"enabled"`
+	StartDate   *time.Time `json:"start_date" validate:"omitempty"`
+}
+
+type EnvelopeModeResponse struct {
+	Enabled   bool       `json:"enabled"`
+	StartDate *time.Time `json:"start_date"`
+}
+
+// GetEnvelopesRequest reports the envelopes for Month/Year, the current month by default
+type GetEnvelopesRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	Month       *int   `form:"month" validate:"omitempty,min=1,max=12"`
+	Year        *int   `form:"year" validate:"required_with=Month,omitempty,min=2020"`
+}
+
+// AssignEnvelopeRequest puts money that is ready to assign into an envelope
+type AssignEnvelopeRequest struct {
+	PhoneNumber string      `json:"phone_number" validate:"required"`
+	CategoryID  uint        `json:"category_id" validate:"required"`
+	Amount      types.Money `json:"amount" validate:"required,gt=0"`
+	Note        string      `json:"note" validate:"omitempty,max=255"`
+	Date        *time.Time  `json:"date" validate:"omitempty"` // Defaults to today
+}
+
+// MoveEnvelopeRequest moves money between envelopes, leave FromCategoryID out to assign money that is
+// ready to assign or ToCategoryID out to make it ready to assign again
+type MoveEnvelopeRequest struct {
+	PhoneNumber    string      `json:"phone_number" validate:"required"`
+	FromCategoryID *uint       `json:"from_category_id" validate:"required_without=ToCategoryID"`
+	ToCategoryID   *uint       `json:"to_category_id" validate:"required_without=FromCategoryID"`
+	Amount         types.Money `json:"amount" validate:"required,gt=0"`
+	Note           string      `json:"note" validate:"omitempty,max=255"`
+	Date           *time.Time  `json:"date" validate:"omitempty"`
+}
+
+type GetAllocationsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	Month       *int   `form:"month" validate:"omitempty,min=1,max=12"`
+	Year        *int   `form:"year" validate:"required_with=Month,omitempty,min=2020"`
+}
+
+type EnvelopeResponse struct {
+	CategoryID   uint        `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	Icon         string      `json:"icon"`
+	Color        string      `json:"color"`
+	Assigned     types.Money `json:"assigned"`    // Put in this month
+	Activity     types.Money `json:"activity"`    // Spent this month
+	Available    types.Money `json:"available"`   // Left in the envelope, negative when overspent
+	Target       types.Money `json:"target"`      // The category's budget for the month
+	Underfunded  types.Money `json:"underfunded"` // Still to assign this month to reach Target
+	IsOverspent  bool        `json:"is_overspent"`
+}
+
+type EnvelopeListResponse struct {
+	ReadyToAssign  types.Money        `json:"ready_to_assign"` // Negative when more was assigned than came in
+	Income         types.Money        `json:"income"`
+	TotalAvailable types.Money        `json:"total_available"`
+	Currency       string             `json:"currency"`
+	StartDate      time.Time          `json:"start_date"` // Envelope budgeting counts from this day
+	PeriodStart    time.Time          `json:"period_start"`
+	PeriodEnd      time.Time          `json:"period_end"`
+	Month          int                `json:"month"`
+	Year           int                `json:"year"`
+	Envelopes      []EnvelopeResponse `json:"envelopes"`
+}
+
+type AllocationResponse struct {
+	ID               uint        `json:"id"`
+	FromCategoryID   *uint       `json:"from_category_id"` // Nil when the money was ready to assign
+	FromCategoryName string      `json:"from_category_name"`
+	ToCategoryID     *uint       `json:"to_category_id"` // Nil when the money was made ready to assign
+	ToCategoryName   string      `json:"to_category_name"`
+	Amount           types.Money `json:"amount"`
+	Note             string      `json:"note"`
+	Date             time.Time   `json:"date"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+type AllocationListResponse struct {
+	Allocations []AllocationResponse `json:"allocations"`
+}
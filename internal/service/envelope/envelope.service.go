@@ -0,0 +1,364 @@
+package envelope
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository/envelope"
+	"pannypal/internal/service/envelope/dto"
+	"time"
+)
+
+func (s *Service) UpdateEnvelopeModeRequest(payload dto.UpdateEnvelopeModeRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Allocations are kept while the mode is off, turning it back on picks them up again
+	user.EnvelopeStart = nil
+	if payload.Enabled {
+		now := time.Now()
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		if payload.StartDate != nil {
+			start = startOfDay(*payload.StartDate)
+		}
+		user.EnvelopeStart = &start
+	}
+
+	updated, err := s.rp.User.UpdateUser(*user)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update envelope budgeting",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Envelope budgeting updated successfully",
+		Data: dto.EnvelopeModeResponse{
+			Enabled:   updated.EnvelopeStart != nil,
+			StartDate: updated.EnvelopeStart,
+		},
+	})
+}
+
+func (s *Service) GetEnvelopesRequest(payload dto.GetEnvelopesRequest) *types.Response {
+	user, resp := s.getEnvelopeUser(payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	from, to := monthWindow(payload.Month, payload.Year)
+	since := *user.EnvelopeStart
+
+	envelopes, err := s.rp.Envelope.GetEnvelopes(user.ID, since, from, to)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get envelopes",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	ready, err := s.rp.Envelope.GetReadyToAssign(user.ID, since, to)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get envelopes",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.EnvelopeListResponse{
+		ReadyToAssign: readyToAssign(*ready),
+		Income:        ready.Income,
+		Currency:      user.BaseCurrency,
+		StartDate:     since,
+		PeriodStart:   from,
+		PeriodEnd:     to,
+		Month:         int(from.Month()),
+		Year:          from.Year(),
+		Envelopes:     make([]dto.EnvelopeResponse, len(envelopes)),
+	}
+	for i, data := range envelopes {
+		response.Envelopes[i] = toEnvelopeResponse(data)
+		response.TotalAvailable += response.Envelopes[i].Available
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Envelopes retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) AssignEnvelopeRequest(payload dto.AssignEnvelopeRequest) *types.Response {
+	return s.MoveEnvelopeRequest(dto.MoveEnvelopeRequest{
+		PhoneNumber:  payload.PhoneNumber,
+		ToCategoryID: &payload.CategoryID,
+		Amount:       payload.Amount,
+		Note:         payload.Note,
+		Date:         payload.Date,
+	})
+}
+
+func (s *Service) MoveEnvelopeRequest(payload dto.MoveEnvelopeRequest) *types.Response {
+	user, resp := s.getEnvelopeUser(payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.FromCategoryID != nil && payload.ToCategoryID != nil && *payload.FromCategoryID == *payload.ToCategoryID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Money can only be moved between two different envelopes",
+			Data:    nil,
+		})
+	}
+	for _, categoryID := range []*uint{payload.FromCategoryID, payload.ToCategoryID} {
+		if categoryID == nil {
+			continue
+		}
+		if resp := s.checkEnvelopeCategory(*categoryID, user.ID); resp != nil {
+			return resp
+		}
+	}
+
+	today := startOfDay(time.Now())
+	date := today
+	if payload.Date != nil {
+		date = startOfDay(*payload.Date)
+	}
+	if date.Before(*user.EnvelopeStart) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Allocations can not be dated before envelope budgeting started",
+			Data:    nil,
+		})
+	}
+
+	// The money has to be there on the day it moves, backdated moves are checked against today
+	asOf := today
+	if date.After(today) {
+		asOf = date
+	}
+	available, err := s.availableIn(user, payload.FromCategoryID, asOf)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get envelopes",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if available < payload.Amount {
+		message := "Not enough money in the envelope"
+		if payload.FromCategoryID == nil {
+			message = "Not enough money ready to assign"
+		}
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: message,
+			Data:    nil,
+		})
+	}
+
+	created, err := s.rp.Envelope.CreateAllocation(models.EnvelopeAllocation{
+		UserID:         user.ID,
+		FromCategoryID: payload.FromCategoryID,
+		ToCategoryID:   payload.ToCategoryID,
+		Amount:         payload.Amount,
+		Note:           payload.Note,
+		Date:           date,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to move money between envelopes",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Money moved successfully",
+		Data:    toAllocationResponse(*created),
+	})
+}
+
+func (s *Service) GetAllocationsRequest(payload dto.GetAllocationsRequest) *types.Response {
+	user, resp := s.getEnvelopeUser(payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	from, to := monthWindow(payload.Month, payload.Year)
+	allocations, err := s.rp.Envelope.GetAllocations(user.ID, from, to)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get allocations",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.AllocationListResponse{Allocations: make([]dto.AllocationResponse, len(allocations))}
+	for i, allocation := range allocations {
+		response.Allocations[i] = toAllocationResponse(allocation)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Allocations retrieved successfully",
+		Data:    response,
+	})
+}
+
+// getEnvelopeUser finds the user, who must have envelope budgeting on
+func (s *Service) getEnvelopeUser(phoneNumber string) (*models.User, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if user.EnvelopeStart == nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Envelope budgeting is not enabled",
+			Data:    nil,
+		})
+	}
+	return user, nil
+}
+
+// checkEnvelopeCategory allows the user's top-level expense categories, spending in a subcategory
+// draws from its parent's envelope
+func (s *Service) checkEnvelopeCategory(categoryID, userID uint) *types.Response {
+	category, err := s.rp.Category.GetCategoryByID(categoryID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if category.UserID == nil || *category.UserID != userID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	if !category.Kind.Allows(models.TypeExpense) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Envelopes need an expense category",
+			Data:    nil,
+		})
+	}
+	if category.ParentID != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Envelopes are kept on top-level categories",
+			Data:    nil,
+		})
+	}
+	return nil
+}
+
+// availableIn returns what is left in the envelope by the end of the day, nil for the money ready to assign
+func (s *Service) availableIn(user *models.User, categoryID *uint, date time.Time) (types.Money, error) {
+	if categoryID == nil {
+		ready, err := s.rp.Envelope.GetReadyToAssign(user.ID, *user.EnvelopeStart, date)
+		if err != nil {
+			return 0, err
+		}
+		return readyToAssign(*ready), nil
+	}
+
+	envelopes, err := s.rp.Envelope.GetEnvelopes(user.ID, *user.EnvelopeStart, date, date)
+	if err != nil {
+		return 0, err
+	}
+	for _, data := range envelopes {
+		if data.CategoryID == *categoryID {
+			return data.Allocated - data.Spent, nil
+		}
+	}
+	return 0, nil
+}
+
+func readyToAssign(data envelope.ReadyToAssignData) types.Money {
+	return data.Income - data.Assigned - data.Uncategorized
+}
+
+// monthWindow returns the first and last day of the month, the current one when month is nil
+func monthWindow(month, year *int) (time.Time, time.Time) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if month != nil && year != nil {
+		from = time.Date(*year, time.Month(*month), 1, 0, 0, 0, 0, now.Location())
+	}
+	return from, from.AddDate(0, 1, -1)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func toEnvelopeResponse(data envelope.EnvelopeData) dto.EnvelopeResponse {
+	available := data.Allocated - data.Spent
+	underfunded := data.Target - data.Assigned
+	if underfunded < 0 {
+		underfunded = 0
+	}
+	return dto.EnvelopeResponse{
+		CategoryID:   data.CategoryID,
+		CategoryName: data.CategoryName,
+		Icon:         data.Icon,
+		Color:        data.Color,
+		Assigned:     data.Assigned,
+		Activity:     data.Activity,
+		Available:    available,
+		Target:       data.Target,
+		Underfunded:  underfunded,
+		IsOverspent:  available < 0,
+	}
+}
+
+func toAllocationResponse(a models.EnvelopeAllocation) dto.AllocationResponse {
+	response := dto.AllocationResponse{
+		ID:             a.ID,
+		FromCategoryID: a.FromCategoryID,
+		ToCategoryID:   a.ToCategoryID,
+		Amount:         a.Amount,
+		Note:           a.Note,
+		Date:           a.Date,
+		CreatedAt:      a.CreatedAt,
+	}
+	if a.FromCategory != nil {
+		response.FromCategoryName = a.FromCategory.Name
+	}
+	if a.ToCategory != nil {
+		response.ToCategoryName = a.ToCategory.Name
+	}
+	return response
+}
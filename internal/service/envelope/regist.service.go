@@ -0,0 +1,31 @@
+package envelope
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/envelope/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	UpdateEnvelopeModeRequest(payload dto.UpdateEnvelopeModeRequest) *types.Response
+	GetEnvelopesRequest(payload dto.GetEnvelopesRequest) *types.Response
+	AssignEnvelopeRequest(payload dto.AssignEnvelopeRequest) *types.Response
+	MoveEnvelopeRequest(payload dto.MoveEnvelopeRequest) *types.Response
+	GetAllocationsRequest(payload dto.GetAllocationsRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
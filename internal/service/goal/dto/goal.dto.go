@@ -0,0 +1,92 @@
+package dto
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+// CreateGoalRequest adds a savings goal. Transactions with the tag, in the category or moving money in
+// or out of the account count towards the goal from StartDate on, today by default.
+type CreateGoalRequest struct {
+	PhoneNumber  string      `json:"phone_number" validate:"required"`
+	Name         string      `json:"name" validate:"required,max=100"`
+	TargetAmount types.Money `json:"target_amount" validate:"required,gt=0"` // In the user's base currency
+	Deadline     *time.Time  `json:"deadline" validate:"omitempty"`
+	StartDate    *time.Time  `json:"start_date" validate:"omitempty"`
+	AccountID    *uint       `json:"account_id" validate:"omitempty"`
+	CategoryID   *uint       `json:"category_id" validate:"omitempty"`
+	Tag          string      `json:"tag" validate:"omitempty,max=50"` // Without the leading #
+	Icon         string      `json:"icon" validate:"omitempty,max=50"`
+	Color        string      `json:"color" validate:"omitempty,max=20"`
+}
+
+// UpdateGoalRequest changes the goal, 0 for account_id or category_id and an empty tag unlink them
+type UpdateGoalRequest struct {
+	Name         *string      `json:"name" validate:"omitempty,max=100"`
+	TargetAmount *types.Money `json:"target_amount" validate:"omitempty,gt=0"`
+	Deadline     *time.Time   `json:"deadline" validate:"omitempty"`
+	StartDate    *time.Time   `json:"start_date" validate:"omitempty"`
+	AccountID    *uint        `json:"account_id" validate:"omitempty"`
+	CategoryID   *uint        `json:"category_id" validate:"omitempty"`
+	Tag          *string      `json:"tag" validate:"omitempty,max=50"`
+	Icon         *string      `json:"icon" validate:"omitempty,max=50"`
+	Color        *string      `json:"color" validate:"omitempty,max=20"`
+}
+
+type GetGoalsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+// AddContributionRequest adds money to the goal by hand, a negative amount takes money out
+type AddContributionRequest struct {
+	Amount types.Money `json:"amount" validate:"required"`
+	Note   string      `json:"note" validate:"omitempty,max=255"`
+	Date   *time.Time  `json:"date" validate:"omitempty"` // Defaults to today
+}
+
+type GoalResponse struct {
+	ID                  uint         `json:"id"`
+	Name                string       `json:"name"`
+	TargetAmount        types.Money  `json:"target_amount"`
+	SavedAmount         types.Money  `json:"saved_amount"`
+	RemainingAmount     types.Money  `json:"remaining_amount"`
+	Percentage          float64      `json:"percentage"`
+	Currency            string       `json:"currency"`
+	Deadline            *time.Time   `json:"deadline"`
+	StartDate           time.Time    `json:"start_date"`
+	AccountID           *uint        `json:"account_id"`
+	AccountName         string       `json:"account_name,omitempty"`
+	CategoryID          *uint        `json:"category_id"`
+	CategoryName        string       `json:"category_name,omitempty"`
+	Tag                 string       `json:"tag,omitempty"`
+	Icon                string       `json:"icon"`
+	Color               string       `json:"color"`
+	MonthlyVelocity     types.Money  `json:"monthly_velocity"`     // Average saved per month over the last three months
+	ProjectedCompletion *time.Time   `json:"projected_completion"` // Nil while nothing is being saved
+	RequiredMonthly     *types.Money `json:"required_monthly"`     // To reach the target by the deadline
+	IsOnTrack           *bool        `json:"is_on_track"`          // Projected to be reached by the deadline, nil without one
+	IsCompleted         bool         `json:"is_completed"`
+	CompletedAt         *time.Time   `json:"completed_at"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+}
+
+type GoalListResponse struct {
+	Goals       []GoalResponse `json:"goals"`
+	TotalTarget types.Money    `json:"total_target"`
+	TotalSaved  types.Money    `json:"total_saved"`
+}
+
+// GoalEntryResponse is a contribution added by hand or a transaction linked to the goal
+type GoalEntryResponse struct {
+	ContributionID *uint       `json:"contribution_id"`
+	TransactionID  *uint       `json:"transaction_id"`
+	Amount         types.Money `json:"amount"`
+	Description    string      `json:"description"`
+	Date           time.Time   `json:"date"`
+}
+
+type GoalDetailResponse struct {
+	GoalResponse
+	Entries []GoalEntryResponse `json:"entries"` // The latest contributions and linked transactions
+}
@@ -0,0 +1,362 @@
+package goal
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/goal/dto"
+	"time"
+)
+
+// goalEntriesLimit is how many contributions and linked transactions a goal's detail shows
+const goalEntriesLimit = 20
+
+func (s *Service) CreateGoalRequest(payload dto.CreateGoalRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	savingsGoal := models.SavingsGoal{
+		UserID:       user.ID,
+		Name:         payload.Name,
+		TargetAmount: payload.TargetAmount,
+		Deadline:     payload.Deadline,
+		StartDate:    startOfDay(time.Now()),
+		Icon:         payload.Icon,
+		Color:        payload.Color,
+	}
+	if payload.StartDate != nil {
+		savingsGoal.StartDate = startOfDay(*payload.StartDate)
+	}
+	if resp := s.linkGoal(&savingsGoal, payload.AccountID, payload.CategoryID, &payload.Tag); resp != nil {
+		return resp
+	}
+
+	created, err := s.rp.Goal.CreateGoal(savingsGoal)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create savings goal",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.goalResponse(http.StatusCreated, "Savings goal created successfully", *user, created.ID)
+}
+
+func (s *Service) GetGoalsRequest(payload dto.GetGoalsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	goals, err := s.GetGoalsProgress(*user, time.Now())
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get savings goals",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.GoalListResponse{Goals: goals}
+	for _, g := range goals {
+		response.TotalTarget += g.TargetAmount
+		response.TotalSaved += g.SavedAmount
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Savings goals retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetGoalByIDRequest(id uint, phoneNumber string) *types.Response {
+	user, _, resp := s.getUserGoal(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return s.goalResponse(http.StatusOK, "Savings goal retrieved successfully", *user, id)
+}
+
+func (s *Service) UpdateGoalRequest(id uint, payload dto.UpdateGoalRequest, phoneNumber string) *types.Response {
+	user, savingsGoal, resp := s.getUserGoal(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.Name != nil {
+		savingsGoal.Name = *payload.Name
+	}
+	if payload.TargetAmount != nil {
+		savingsGoal.TargetAmount = *payload.TargetAmount
+	}
+	if payload.Deadline != nil {
+		savingsGoal.Deadline = payload.Deadline
+	}
+	if payload.StartDate != nil {
+		savingsGoal.StartDate = startOfDay(*payload.StartDate)
+	}
+	if payload.Icon != nil {
+		savingsGoal.Icon = *payload.Icon
+	}
+	if payload.Color != nil {
+		savingsGoal.Color = *payload.Color
+	}
+	if resp := s.linkGoal(savingsGoal, payload.AccountID, payload.CategoryID, payload.Tag); resp != nil {
+		return resp
+	}
+
+	if _, err := s.rp.Goal.UpdateGoal(*savingsGoal); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update savings goal",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.goalResponse(http.StatusOK, "Savings goal updated successfully", *user, id)
+}
+
+func (s *Service) DeleteGoalRequest(id uint, phoneNumber string) *types.Response {
+	_, _, resp := s.getUserGoal(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Goal.DeleteGoal(id); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete savings goal",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Savings goal deleted successfully",
+		Data:    nil,
+	})
+}
+
+func (s *Service) AddContributionRequest(id uint, payload dto.AddContributionRequest, phoneNumber string) *types.Response {
+	user, savingsGoal, resp := s.getUserGoal(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	date := time.Now()
+	if payload.Date != nil {
+		date = *payload.Date
+	}
+
+	response, err := s.Contribute(*user, *savingsGoal, payload.Amount, payload.Note, date)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to add contribution",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Contribution added successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) DeleteContributionRequest(id, contributionID uint, phoneNumber string) *types.Response {
+	user, _, resp := s.getUserGoal(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	contribution, err := s.rp.Goal.GetContributionByID(contributionID)
+	if err != nil || contribution.GoalID != id {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Contribution not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if err := s.rp.Goal.DeleteContribution(contributionID); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete contribution",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.goalResponse(http.StatusOK, "Contribution deleted successfully", *user, id)
+}
+
+// getUserGoal loads the goal, which must belong to the user with the phone number
+func (s *Service) getUserGoal(id uint, phoneNumber string) (*models.User, *models.SavingsGoal, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	savingsGoal, err := s.rp.Goal.GetGoalByID(id)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Savings goal not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if savingsGoal.UserID != user.ID {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return user, savingsGoal, nil
+}
+
+// linkGoal links the goal to the user's account, category and tag, 0 and an empty tag unlink them
+func (s *Service) linkGoal(savingsGoal *models.SavingsGoal, accountID, categoryID *uint, tag *string) *types.Response {
+	if accountID != nil {
+		savingsGoal.AccountID = nil
+		if *accountID != 0 {
+			account, err := s.rp.Account.GetAccountByID(*accountID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Account not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if account.UserID != savingsGoal.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			savingsGoal.AccountID = &account.ID
+		}
+	}
+
+	if categoryID != nil {
+		savingsGoal.CategoryID = nil
+		if *categoryID != 0 {
+			category, err := s.rp.Category.GetCategoryByID(*categoryID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Category not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if category.UserID == nil || *category.UserID != savingsGoal.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			savingsGoal.CategoryID = &category.ID
+		}
+	}
+
+	if tag != nil {
+		savingsGoal.TagID = nil
+		if name := helper.NormalizeTag(*tag); name != "" {
+			tags, err := s.rp.Tag.GetOrCreateTags(savingsGoal.UserID, []string{name})
+			if err != nil || len(tags) == 0 {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusInternalServerError,
+					Message: "Failed to save tag",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			savingsGoal.TagID = &tags[0].ID
+		}
+	}
+	return nil
+}
+
+// goalResponse loads the goal again with its progress and latest entries
+func (s *Service) goalResponse(code int, message string, user models.User, id uint) *types.Response {
+	savingsGoal, err := s.rp.Goal.GetGoalByID(id)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get savings goal",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	responses, err := s.toGoalResponses(user, []models.SavingsGoal{*savingsGoal}, time.Now())
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get savings goal progress",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	entries, err := s.rp.Goal.GetGoalEntries(id, goalEntriesLimit)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get savings goal progress",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.GoalDetailResponse{
+		GoalResponse: responses[0],
+		Entries:      make([]dto.GoalEntryResponse, len(entries)),
+	}
+	for i, entry := range entries {
+		response.Entries[i] = dto.GoalEntryResponse{
+			ContributionID: entry.ContributionID,
+			TransactionID:  entry.TransactionID,
+			Amount:         entry.Amount,
+			Description:    entry.Description,
+			Date:           entry.Date,
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    code,
+		Message: message,
+		Data:    response,
+	})
+}
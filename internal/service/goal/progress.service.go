@@ -0,0 +1,177 @@
+package goal
+
+import (
+	"math"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/repository/goal"
+	"pannypal/internal/service/goal/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Projections follow what was saved over the last three months
+	velocityWindowDays = 90
+	// A goal started a few days ago is not projected from those days alone
+	minVelocityDays = 30
+	daysPerMonth    = 30.44
+)
+
+// FindGoal finds the user's goal by name, an exact match or the only goal whose name holds it.
+// When several goals match they are returned instead.
+func (s *Service) FindGoal(userID uint, name string) (*models.SavingsGoal, []models.SavingsGoal, error) {
+	goals, err := s.rp.Goal.GetGoalsByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	var matches []models.SavingsGoal
+	for _, g := range goals {
+		goalName := strings.ToLower(g.Name)
+		if goalName == name {
+			return &g, nil, nil
+		}
+		if strings.Contains(goalName, name) {
+			matches = append(matches, g)
+		}
+	}
+	if len(matches) == 1 {
+		return &matches[0], nil, nil
+	}
+	return nil, matches, nil
+}
+
+// Contribute adds money to the goal by hand and returns its progress after it
+func (s *Service) Contribute(user models.User, savingsGoal models.SavingsGoal, amount types.Money, note string, date time.Time) (*dto.GoalResponse, error) {
+	if _, err := s.rp.Goal.CreateContribution(models.GoalContribution{
+		GoalID: savingsGoal.ID,
+		UserID: user.ID,
+		Amount: amount,
+		Note:   note,
+		Date:   startOfDay(date),
+	}); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toGoalResponses(user, []models.SavingsGoal{savingsGoal}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// GetGoalsProgress returns the progress of all the user's goals
+func (s *Service) GetGoalsProgress(user models.User, now time.Time) ([]dto.GoalResponse, error) {
+	goals, err := s.rp.Goal.GetGoalsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toGoalResponses(user, goals, now)
+}
+
+// toGoalResponses works out the progress of the goals, completed goals go last. Completion is derived
+// from what was saved, so a goal falling below its target again reopens by itself.
+func (s *Service) toGoalResponses(user models.User, goals []models.SavingsGoal, now time.Time) ([]dto.GoalResponse, error) {
+	ids := make([]uint, len(goals))
+	for i, g := range goals {
+		ids[i] = g.ID
+	}
+
+	today := startOfDay(now)
+	progress, err := s.rp.Goal.GetGoalProgress(ids, today.AddDate(0, 0, -velocityWindowDays))
+	if err != nil {
+		return nil, err
+	}
+	byGoal := make(map[uint]goal.GoalProgressData, len(progress))
+	for _, p := range progress {
+		byGoal[p.GoalID] = p
+	}
+
+	responses := make([]dto.GoalResponse, len(goals))
+	for i, g := range goals {
+		responses[i] = toGoalResponse(g, byGoal[g.ID], user.BaseCurrency, today)
+	}
+	sort.SliceStable(responses, func(i, j int) bool {
+		return !responses[i].IsCompleted && responses[j].IsCompleted
+	})
+	return responses, nil
+}
+
+func toGoalResponse(g models.SavingsGoal, p goal.GoalProgressData, currency string, today time.Time) dto.GoalResponse {
+	remaining := g.TargetAmount - p.Saved
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	response := dto.GoalResponse{
+		ID:              g.ID,
+		Name:            g.Name,
+		TargetAmount:    g.TargetAmount,
+		SavedAmount:     p.Saved,
+		RemainingAmount: remaining,
+		Currency:        currency,
+		Deadline:        g.Deadline,
+		StartDate:       g.StartDate,
+		AccountID:       g.AccountID,
+		CategoryID:      g.CategoryID,
+		Icon:            g.Icon,
+		Color:           g.Color,
+		IsCompleted:     p.CompletedAt != nil,
+		CompletedAt:     p.CompletedAt,
+		CreatedAt:       g.CreatedAt,
+		UpdatedAt:       g.UpdatedAt,
+	}
+	if g.TargetAmount > 0 {
+		response.Percentage = math.Round(p.Saved.Float64()/g.TargetAmount.Float64()*10000) / 100
+	}
+	if g.Account != nil {
+		response.AccountName = g.Account.Name
+	}
+	if g.Category != nil {
+		response.CategoryName = g.Category.Name
+	}
+	if g.Tag != nil {
+		response.Tag = g.Tag.Name
+	}
+
+	// Saving per day over the window, which starts no earlier than the goal
+	windowStart := today.AddDate(0, 0, -velocityWindowDays)
+	if start := startOfDay(g.StartDate); start.After(windowStart) {
+		windowStart = start
+	}
+	days := math.Max(today.Sub(windowStart).Hours()/24+1, minVelocityDays)
+	daily := p.RecentSaved.Float64() / days
+	response.MonthlyVelocity = types.MoneyFromFloat(daily * daysPerMonth)
+
+	switch {
+	case remaining == 0:
+		projected := today
+		if p.CompletedAt != nil {
+			projected = startOfDay(*p.CompletedAt)
+		}
+		response.ProjectedCompletion = &projected
+	case daily > 0:
+		projected := today.AddDate(0, 0, int(math.Ceil(remaining.Float64()/daily)))
+		response.ProjectedCompletion = &projected
+	}
+
+	if g.Deadline != nil {
+		deadline := startOfDay(*g.Deadline)
+		required := remaining
+		if months := deadline.Sub(today).Hours() / 24 / daysPerMonth; months > 1 {
+			required = types.MoneyFromFloat(remaining.Float64() / months)
+		}
+		response.RequiredMonthly = &required
+
+		onTrack := response.ProjectedCompletion != nil && !response.ProjectedCompletion.After(deadline)
+		response.IsOnTrack = &onTrack
+	}
+	return response
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,39 @@
+package goal
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/goal/dto"
+	"time"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	FindGoal(userID uint, name string) (*models.SavingsGoal, []models.SavingsGoal, error)
+	Contribute(user models.User, savingsGoal models.SavingsGoal, amount types.Money, note string, date time.Time) (*dto.GoalResponse, error)
+	GetGoalsProgress(user models.User, now time.Time) ([]dto.GoalResponse, error)
+
+	CreateGoalRequest(payload dto.CreateGoalRequest) *types.Response
+	GetGoalsRequest(payload dto.GetGoalsRequest) *types.Response
+	GetGoalByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateGoalRequest(id uint, payload dto.UpdateGoalRequest, phoneNumber string) *types.Response
+	DeleteGoalRequest(id uint, phoneNumber string) *types.Response
+	AddContributionRequest(id uint, payload dto.AddContributionRequest, phoneNumber string) *types.Response
+	DeleteContributionRequest(id, contributionID uint, phoneNumber string) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"pannypal/internal/common/models"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
 	dtoAI "pannypal/internal/service/ai/dto"
 	"pannypal/internal/service/incoming/dto"
 	dtoOutgoing "pannypal/internal/service/outgoing/dto"
+	"strings"
 	"time"
 )
 
@@ -26,21 +29,20 @@ func (s *Service) HandleWebhookEventBaileys(payload interface{}) *types.Response
 		})
 	}
 
+	// After handling, so a user created by this very message is remembered too
+	defer s.rememberUserChat(incoming)
+
 	if incoming.QuotedMessage != nil {
 		return s.HandleExtendedTextMessage(incoming)
 	}
 
-	text := incoming.GetText()
-
-	if s.IsCashFlowFunction(text) {
-		err := s.HandleCashFlowFunction(incoming)
-		if err != nil {
-			return helper.ParseResponse(&types.Response{
-				Code:    http.StatusInternalServerError,
-				Message: "Error processing cashflow function",
-				Data:    err,
-			})
-		}
+	handled, err := s.RouteCommand(incoming)
+	if handled && err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Error processing command",
+			Data:    err,
+		})
 	}
 
 	return helper.ParseResponse(&types.Response{
@@ -70,6 +72,22 @@ func (s *Service) HandleExtendedTextMessage(message *dto.SimplifiedIncomingMessa
 		})
 	}
 
+	if messageToReply.FeatureType == enum.FeatureTypeAIcashflowSaved {
+		err := s.HandleSavedTransactionReplyAction(message, messageToReply)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Error processing saved transaction reply action",
+				Data:    err,
+			})
+		}
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusOK,
+			Message: "HandleSavedTransactionReplyAction success",
+			Data:    message,
+		})
+	}
+
 	if messageToReply.FeatureType == enum.FeatureTypeAIcashflow {
 		err := s.HandleCashFlowFunctionReplyAction(message, messageToReply)
 		if err != nil {
@@ -103,10 +121,19 @@ func (s *Service) HandleCashFlowFunction(message *dto.SimplifiedIncomingMessage)
 		Participant:    message.Participant,
 	}
 
-	text := message.GetText()
+	text, split, err := parseSplitClause(message.GetText())
+	if err != nil {
+		Outgoing.Message = "Maaf, pembagian tidak bisa dibaca: " + err.Error()
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	// Hashtags become tags of every transaction in the draft, the AI only sees the rest
+	tags, text := extractMessageTags(text)
 
 	payload := dtoAI.InputTextCashflow{
-		Message: text,
+		Message:     text,
+		PhoneNumber: message.SenderPhone(),
 	}
 
 	result, responseMessage, err := s.ai.InputTextCashflow(payload)
@@ -119,7 +146,22 @@ func (s *Service) HandleCashFlowFunction(message *dto.SimplifiedIncomingMessage)
 		return err
 	}
 
+	for i := range result.ReqPayload {
+		result.ReqPayload[i].Tags = tags
+	}
+
 	Outgoing.Message = responseMessage
+	if len(tags) > 0 {
+		Outgoing.Message += "\n\n🏷️ Tag: " + formatTags(tags)
+	}
+	attachments := s.storeReceipt(message)
+	if attachments != nil {
+		Outgoing.Message += "\n📎 Struk akan dilampirkan saat disimpan."
+	}
+	if warning := s.duplicateWarning(message.SenderPhone(), result.ReqPayload); warning != "" {
+		Outgoing.Message += "\n\n" + warning
+	}
+
 	reqBytes, err := json.Marshal(result.ReqPayload)
 	if err != nil {
 		Outgoing.Message = err.Error()
@@ -131,6 +173,18 @@ func (s *Service) HandleCashFlowFunction(message *dto.SimplifiedIncomingMessage)
 	}
 	rawMessage := json.RawMessage(reqBytes)
 
+	// The split is applied on save, keep it with the draft and show a preview
+	var splitSpec *json.RawMessage
+	if split != nil {
+		specBytes, err := json.Marshal(split)
+		if err != nil {
+			return err
+		}
+		rawSpec := json.RawMessage(specBytes)
+		splitSpec = &rawSpec
+		Outgoing.Message += "\n\n" + describeSplit(split)
+	}
+
 	messageOut, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
 	if err != nil {
 		return err
@@ -144,6 +198,8 @@ func (s *Service) HandleCashFlowFunction(message *dto.SimplifiedIncomingMessage)
 		Messsage:    responseMessage,
 		Additional:  &rawMessage,
 		Participant: message.Participant,
+		SplitSpec:   splitSpec,
+		Attachments: attachments,
 	}
 
 	_, err = s.rp.Bot.CreateMessageToReply(modelMessageToReply)
@@ -194,10 +250,13 @@ func (s *Service) HandleCashFlowFunctionReplyAction(message *dto.SimplifiedIncom
 }
 
 func (s *Service) SaveTransaction(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply, Outgoing dtoOutgoing.PayloadOutgoing) error {
-	// Use clean phone number directly (no longer needs cleaning, already clean)
-	phoneNumber := message.From
+	// Attribute the transaction to the person who sent it, in groups that is the participant
+	phoneNumber := message.SenderPhone()
 
 	user, err := s.GetUser(phoneNumber)
+	if err == nil && user == nil {
+		err = fmt.Errorf("sender phone number is empty")
+	}
 	if err != nil {
 		fmt.Println("Failed to get or create user:", err)
 		Outgoing.Message = "Maaf, terjadi kesalahan saat memproses pengguna."
@@ -205,6 +264,18 @@ func (s *Service) SaveTransaction(message *dto.SimplifiedIncomingMessage, messag
 		return err
 	}
 
+	ledger, err := s.GetLedger(message, user)
+	if err != nil {
+		logger.Error.Println("Failed to get ledger:", err)
+		Outgoing.Message = "Maaf, terjadi kesalahan saat memproses grup."
+		_, _ = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+	var ledgerID *uint
+	if ledger != nil {
+		ledgerID = &ledger.ID
+	}
+
 	dataTransaction, err := helper.JSONToStruct[[]dtoAI.TransactionPayload](messageToReply.Additional)
 	if err != nil {
 		fmt.Println("Error converting JSON to struct:", err)
@@ -219,8 +290,10 @@ func (s *Service) SaveTransaction(message *dto.SimplifiedIncomingMessage, messag
 		return fmt.Errorf("no transaction data found")
 	}
 
+	transactionIDs := []uint{}
+	saved := []models.Transaction{}
 	for _, tx := range *dataTransaction {
-		validCategoryID, err := s.validateOrCreateCategory(tx.CategoryId)
+		validCategoryID, err := s.validateOrCreateCategory(s.rp, user.ID, tx.CategoryId, tx.Type)
 		if err != nil {
 			fmt.Println("Failed to validate category:", err)
 			Outgoing.Message = "Maaf, terjadi kesalahan saat memvalidasi kategori."
@@ -230,28 +303,64 @@ func (s *Service) SaveTransaction(message *dto.SimplifiedIncomingMessage, messag
 
 		model := models.Transaction{
 			UserID:          user.ID,
+			LedgerID:        ledgerID,
+			AccountID:       tx.AccountID,
+			RecurringRuleID: tx.RecurringRuleID,
 			Type:            models.TransactionType(tx.Type),
-			Amount:          float64(tx.Amount),
+			Amount:          tx.Amount,
+			Currency:        strings.ToUpper(tx.Currency),
 			CategoryID:      validCategoryID,
 			Description:     tx.Description,
 			TransactionDate: time.Now(),
 		}
-		_, err = s.rp.Transaction.CreateTransaction(model)
+		if tx.TransactionDate != nil {
+			model.TransactionDate = *tx.TransactionDate
+		}
+		// The transaction, its tags and its revision are written together
+		var created *models.Transaction
+		err = s.rp.Atomic(func(rp repository.IRepository) error {
+			var err error
+			created, err = rp.Transaction.CreateTransaction(model)
+			if err != nil {
+				return err
+			}
+			if created.Tags, err = s.addTransactionTags(rp, created.UserID, created.ID, tx.Tags); err != nil {
+				return err
+			}
+			return s.recordRevision(rp, message, models.RevisionActionCreate, nil, created)
+		})
 		if err != nil {
 			fmt.Println("Failed to create transaction:", err)
 			Outgoing.Message = "Maaf, terjadi kesalahan saat menyimpan transaksi."
 			_, _ = s.outgoing.HandleWebhookEventWaha(Outgoing)
 			return err
 		}
+		transactionIDs = append(transactionIDs, created.ID)
+		saved = append(saved, *created)
 	}
 
-	Outgoing.Message = "Transaksi berhasil disimpan."
-	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	attachmentText := ""
+	if s.attachDraftFiles(messageToReply, saved) {
+		attachmentText = "\n📎 Struk dilampirkan."
+	}
+
+	splitText := ""
+	if spec := splitSpecFromDraft(messageToReply); spec != nil {
+		splitText = s.applySplit(spec, user.ID, saved)
+	}
+
+	Outgoing.Message = "Transaksi berhasil disimpan." + attachmentText + splitText + savedTransactionHint
+	messageOut, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
 	if err != nil {
 		fmt.Println("Error sending confirmation message:", err)
 		return err
 	}
 
+	// Keep a reference from the confirmation to the saved transactions so it can be undone or edited
+	if err := s.createSavedTransactionReply(messageOut, Outgoing.Message, transactionIDs, message.Participant); err != nil {
+		logger.Error.Println("Error saving MessageToReply for saved transactions:", err)
+	}
+
 	// Delete the MessageToReply after saving transactions
 	err = s.rp.Bot.DeleteMessageToReply(messageToReply.MessageID)
 	if err != nil {
@@ -263,20 +372,50 @@ func (s *Service) SaveTransaction(message *dto.SimplifiedIncomingMessage, messag
 }
 
 func (s *Service) EditTransaction(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply, Outgoing dtoOutgoing.PayloadOutgoing) error {
-	text := message.GetText()
+	existing, err := helper.JSONToStruct[[]dtoAI.TransactionPayload](messageToReply.Additional)
+	if err != nil || existing == nil {
+		existing = &[]dtoAI.TransactionPayload{}
+	}
 
-	payload := dtoAI.InputTextCashflow{
-		Message: text,
+	// Hashtags in the edit are added to the draft's tags
+	tags, text := extractMessageTags(message.GetText())
+
+	payload := dtoAI.EditTextCashflow{
+		Message:     text,
+		Existing:    *existing,
+		PhoneNumber: message.SenderPhone(),
 	}
 
-	result, responseMessage, err := s.ai.InputTextCashflow(payload)
+	result, responseMessage, err := s.ai.EditTextCashflow(payload)
 	if err != nil {
 		Outgoing.Message = "Maaf, terjadi kesalahan saat memproses permintaan Anda."
 		_, _ = s.outgoing.HandleWebhookEventWaha(Outgoing)
 		return err
 	}
 
+	// The AI only returns the schema fields, keep what the draft knew beyond them
+	draftTags := []string{}
+	for _, tx := range *existing {
+		draftTags = append(draftTags, tx.Tags...)
+	}
+	for i := range result.ReqPayload {
+		if i >= len(*existing) {
+			result.ReqPayload[i].Tags = helper.NormalizeTags(append(draftTags, tags...))
+			continue
+		}
+		result.ReqPayload[i].AccountID = (*existing)[i].AccountID
+		result.ReqPayload[i].RecurringRuleID = (*existing)[i].RecurringRuleID
+		result.ReqPayload[i].TransactionDate = (*existing)[i].TransactionDate
+		result.ReqPayload[i].Tags = helper.NormalizeTags(append((*existing)[i].Tags, tags...))
+	}
+
 	Outgoing.Message = responseMessage
+	if allTags := helper.NormalizeTags(append(draftTags, tags...)); len(allTags) > 0 {
+		Outgoing.Message += "\n\n🏷️ Tag: " + formatTags(allTags)
+	}
+	if spec := splitSpecFromDraft(messageToReply); spec != nil {
+		Outgoing.Message += "\n\n" + describeSplit(spec)
+	}
 
 	outResponse, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
 	if err != nil {
@@ -317,6 +456,7 @@ func (s *Service) CancelTransaction(message *dto.SimplifiedIncomingMessage, mess
 		_, _ = s.outgoing.HandleWebhookEventWaha(Outgoing)
 		return err
 	}
+	s.discardDraftFiles(messageToReply)
 
 	Outgoing.Message = "Draft transaksi telah dibatalkan."
 	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
@@ -0,0 +1,544 @@
+package incoming
+
+import (
+	"fmt"
+	"pannypal/internal/common/enum"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/analytics"
+	"pannypal/internal/repository/budget"
+	"pannypal/internal/repository/transaction"
+	dtoChatbot "pannypal/internal/service/chatbot/dto"
+	"pannypal/internal/service/incoming/dto"
+	dtoOutgoing "pannypal/internal/service/outgoing/dto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLastLimit = 5
+	maxLastLimit     = 20
+)
+
+// commandHandler handles a bot command, args are the words following the tag
+type commandHandler func(message *dto.SimplifiedIncomingMessage, args []string) error
+
+// commandRoutes maps every supported tag to its handler
+func (s *Service) commandRoutes() map[enum.WebhookIncomingTag]commandHandler {
+	return map[enum.WebhookIncomingTag]commandHandler{
+		enum.TagKeuangan: func(message *dto.SimplifiedIncomingMessage, _ []string) error {
+			return s.HandleCashFlowFunction(message)
+		},
+		enum.TagSaldo:    s.HandleSaldoCommand,
+		enum.TagHariIni:  s.HandleHariIniCommand,
+		enum.TagBulanIni: s.HandleBulanIniCommand,
+		enum.TagBudget:   s.HandleBudgetCommand,
+		enum.TagLast:     s.HandleLastCommand,
+		enum.TagUndo:     s.HandleUndoCommand,
+		enum.TagTanya:    s.HandleTanyaCommand,
+		enum.TagHutang:   s.HandleHutangCommand,
+		enum.TagCari:     s.HandleCariCommand,
+		enum.TagTabungan: s.HandleTabunganCommand,
+	}
+}
+
+// ParseCommand finds the first known tag in the text and returns the words after it
+func ParseCommand(text string) (enum.WebhookIncomingTag, []string, bool) {
+	words := strings.Fields(text)
+	for i, word := range words {
+		tag := enum.WebhookIncomingTag(strings.TrimRight(strings.ToLower(word), ".,:;!?"))
+		if tag.IsValid() {
+			return tag, words[i+1:], true
+		}
+	}
+	return "", nil, false
+}
+
+// RouteCommand dispatches the message to its command handler, it reports false when no command is found
+func (s *Service) RouteCommand(message *dto.SimplifiedIncomingMessage) (bool, error) {
+	tag, args, ok := ParseCommand(message.GetText())
+	if !ok {
+		return false, nil
+	}
+
+	handler, ok := s.commandRoutes()[tag]
+	if !ok {
+		return false, nil
+	}
+
+	return true, handler(message, args)
+}
+
+func (s *Service) HandleSaldoCommand(message *dto.SimplifiedIncomingMessage, _ []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	viewerID, currency := s.reportViewer(message)
+
+	summary, err := s.rp.Transaction.GetTransactionsSummary(userID, transaction.SummaryFilters{
+		LedgerID: ledgerID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := "*Saldo Kamu*\n\n"
+	if ledgerID != nil {
+		text = "*Saldo Grup*\n\n"
+	}
+	text += "💰 Pemasukan: " + formatMoney(summary.TotalIncome, currency) + "\n"
+	text += "💸 Pengeluaran: " + formatMoney(summary.TotalExpense, currency) + "\n"
+	text += "🏦 Saldo: *" + formatMoney(summary.TotalIncome-summary.TotalExpense, currency) + "*\n"
+	text += fmt.Sprintf("\nDari %d transaksi.", summary.TransactionCount)
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) HandleHariIniCommand(message *dto.SimplifiedIncomingMessage, _ []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	viewerID, currency := s.reportViewer(message)
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	summary, err := s.rp.Transaction.GetTransactionsSummary(userID, transaction.SummaryFilters{
+		LedgerID:  ledgerID,
+		ViewerID:  viewerID,
+		StartDate: &startOfDay,
+		EndDate:   &now,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	transactions, _, err := s.rp.Transaction.GetTransactionsByUserID(userID, transaction.TransactionFilters{
+		LedgerID:  ledgerID,
+		StartDate: &startOfDay,
+		EndDate:   &now,
+		Page:      1,
+		Limit:     maxLastLimit,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := "*Hari Ini* (" + formatIndonesianDate(now) + ")\n\n"
+	if len(transactions) == 0 {
+		text += "Belum ada transaksi hari ini."
+	} else {
+		text += formatTransactionLines(transactions)
+		text += "\n💰 Pemasukan: " + formatMoney(summary.TotalIncome, currency) + "\n"
+		text += "💸 Pengeluaran: " + formatMoney(summary.TotalExpense, currency)
+	}
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) HandleBulanIniCommand(message *dto.SimplifiedIncomingMessage, _ []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	viewerID, currency := s.reportViewer(message)
+
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
+
+	if ledgerID != nil {
+		if viewerID == nil {
+			return s.replyCommandError(Outgoing, fmt.Errorf("sender not found"))
+		}
+		return s.replyLedgerMonth(Outgoing, *ledgerID, *viewerID, startOfMonth, endOfMonth, currency)
+	}
+
+	dashboard, err := s.rp.Analytics.GetDashboardAnalytics(userID, nil, startOfMonth, endOfMonth)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	expenseType := models.TypeExpense
+	categories, err := s.rp.Analytics.GetCategoryAnalytics(userID, analytics.CategoryAnalyticsFilters{
+		StartDate: &startOfMonth,
+		EndDate:   &endOfMonth,
+		Type:      &expenseType,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := fmt.Sprintf("*Bulan Ini* (%s %d)\n\n", indonesianMonths[now.Month()], now.Year())
+	text += "💰 Pemasukan: " + formatMoney(dashboard.CurrentIncome, currency) + "\n"
+	text += "💸 Pengeluaran: " + formatMoney(dashboard.CurrentExpense, currency) + formatChange(dashboard.CurrentExpense, dashboard.PreviousExpense) + "\n"
+	text += "🏦 Selisih: *" + formatMoney(dashboard.CurrentIncome-dashboard.CurrentExpense, currency) + "*\n"
+
+	if len(categories) > 0 {
+		text += "\n*Pengeluaran terbesar:*\n"
+		for i, category := range categories {
+			if i == 3 {
+				break
+			}
+			text += fmt.Sprintf("%d. %s: %s\n", i+1, category.CategoryName, formatMoney(category.TotalAmount, currency))
+		}
+	}
+
+	Outgoing.Message = strings.TrimRight(text, "\n")
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) HandleBudgetCommand(message *dto.SimplifiedIncomingMessage, _ []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	_, currency := s.reportViewer(message)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	statuses, err := s.rp.Budget.GetBudgetStatus(userID, budget.BudgetStatusFilters{
+		LedgerID: ledgerID,
+		From:     today,
+		To:       today,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := fmt.Sprintf("*Budget %s %d*\n\n", indonesianMonths[now.Month()], now.Year())
+	if len(statuses) == 0 {
+		text += "Belum ada budget untuk periode ini."
+	}
+
+	totalBudget := types.Money(0)
+	totalSpent := types.Money(0)
+	for _, status := range statuses {
+		available := status.BudgetAmount + status.CarriedOver
+		percentageUsed := float64(0)
+		if available > 0 {
+			percentageUsed = (status.SpentAmount.Float64() / available.Float64()) * 100
+		}
+
+		icon := "✅"
+		if status.SpentAmount > available {
+			icon = "⚠️"
+		}
+
+		text += fmt.Sprintf("%s %s: %s / %s (%.0f%%)%s\n", icon, status.CategoryName,
+			formatMoney(status.SpentAmount, currency), formatMoney(available, currency), percentageUsed, budgetPeriodNote(status))
+
+		totalBudget += available
+		totalSpent += status.SpentAmount
+	}
+
+	if len(statuses) > 0 {
+		text += "\nSisa budget: *" + formatMoney(totalBudget-totalSpent, currency) + "*"
+	}
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+// budgetPeriodNote tells when a budget's period ends unless it is the calendar month, e.g. " · s/d 24 November 2026"
+func budgetPeriodNote(status budget.BudgetStatusData) string {
+	if status.Period == models.BudgetPeriodMonthly && status.PeriodStart.Day() == 1 {
+		return ""
+	}
+	return " · s/d " + formatIndonesianDate(status.PeriodEnd)
+}
+
+func (s *Service) HandleLastCommand(message *dto.SimplifiedIncomingMessage, args []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	limit := defaultLastLimit
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	if limit > maxLastLimit {
+		limit = maxLastLimit
+	}
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	transactions, _, err := s.rp.Transaction.GetTransactionsByUserID(userID, transaction.TransactionFilters{
+		LedgerID: ledgerID,
+		Page:     1,
+		Limit:    limit,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := fmt.Sprintf("*%d Transaksi Terakhir*\n\n", len(transactions))
+	if len(transactions) == 0 {
+		text += "Belum ada transaksi."
+	} else {
+		text += formatTransactionLines(transactions)
+	}
+
+	Outgoing.Message = strings.TrimRight(text, "\n")
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+// HandleCariCommand searches transactions, "#cari kopi >20rb #kantor"
+func (s *Service) HandleCariCommand(message *dto.SimplifiedIncomingMessage, args []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	text := strings.Join(args, " ")
+	query := helper.ParseSearchQuery(text)
+	if query.Text == "" && len(query.Tags) == 0 && query.MinAmount == nil && query.MaxAmount == nil {
+		Outgoing.Message = "Tulis yang dicari setelah #cari, contoh:\n#cari kopi\n#cari makan >50rb\n#cari #liburan-bali"
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	transactions, total, err := s.rp.Transaction.SearchTransactions(userID, transaction.SearchFilters{
+		Query:     query.Text,
+		Tags:      query.Tags,
+		MinAmount: query.MinAmount,
+		MaxAmount: query.MaxAmount,
+		LedgerID:  ledgerID,
+		Page:      1,
+		Limit:     maxLastLimit,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if len(transactions) == 0 {
+		Outgoing.Message = fmt.Sprintf("🔍 Tidak ada transaksi yang cocok dengan \"%s\".", text)
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	reply := fmt.Sprintf("🔍 *Hasil pencarian \"%s\"* (%d transaksi)\n\n", text, total) + formatTransactionLines(transactions)
+	if total > int64(len(transactions)) {
+		reply += fmt.Sprintf("\n...dan %d transaksi lainnya, perjelas pencarianmu.", total-int64(len(transactions)))
+	}
+
+	Outgoing.Message = strings.TrimRight(reply, "\n")
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) HandleUndoCommand(message *dto.SimplifiedIncomingMessage, _ []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	last, err := s.rp.Transaction.GetLatestTransactionByUserID(user.ID)
+	if err != nil {
+		Outgoing.Message = "Tidak ada transaksi yang bisa dibatalkan."
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if err := rp.Transaction.DeleteTransaction(last.ID); err != nil {
+			return err
+		}
+		if err := rp.Split.DeleteSplitByTransactionID(last.ID); err != nil {
+			return err
+		}
+		return s.recordRevision(rp, message, models.RevisionActionDelete, last, nil)
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	Outgoing.Message = "↩️ Transaksi terakhir dibatalkan:\n" + formatTransactionLines([]models.Transaction{*last})
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) HandleTanyaCommand(message *dto.SimplifiedIncomingMessage, args []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	if len(args) == 0 {
+		Outgoing.Message = "Tulis pertanyaanmu setelah #tanya, contoh:\n#tanya pengeluaran terbesar bulan ini apa?\n\nKetik *#tanya reset* untuk memulai percakapan baru."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	if strings.ToLower(args[0]) == "reset" && len(args) == 1 {
+		if err := s.chatbot.ResetChat(message.ChatID); err != nil {
+			return s.replyCommandError(Outgoing, err)
+		}
+		Outgoing.Message = "Percakapan direset, silakan mulai pertanyaan baru."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	userID, ledgerID, err := s.commandScope(message)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	reply, err := s.chatbot.ReplyToChat(dtoChatbot.ChatReplyRequest{
+		ChatID:   message.ChatID,
+		Message:  strings.Join(args, " "),
+		UserID:   userID,
+		LedgerID: ledgerID,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	Outgoing.Message = reply.Text
+	if _, err := s.outgoing.HandleWebhookEventWaha(Outgoing); err != nil {
+		return err
+	}
+
+	if reply.ChartURL != nil {
+		chart := s.newCommandReply(message)
+		chart.Type = "image"
+		chart.Media = &dtoOutgoing.Media{
+			URL:      *reply.ChartURL,
+			MimeType: "image/png",
+			FileName: "chart.png",
+		}
+		res, err := s.outgoing.HandleWebhookEventWaha(chart)
+		if err == nil && res != nil {
+			return nil
+		}
+		logger.Error.Println("Error sending chart image, falling back to table:", err)
+	}
+
+	if reply.ChartTable != "" {
+		table := s.newCommandReply(message)
+		table.Message = reply.ChartTable
+		_, err = s.outgoing.HandleWebhookEventWaha(table)
+		return err
+	}
+
+	return nil
+}
+
+// replyLedgerMonth reports the group month: totals and how much each member spent
+func (s *Service) replyLedgerMonth(Outgoing dtoOutgoing.PayloadOutgoing, ledgerID, viewerID uint, startOfMonth, endOfMonth time.Time, currency string) error {
+	summary, err := s.rp.Transaction.GetTransactionsSummary(nil, transaction.SummaryFilters{
+		LedgerID:  &ledgerID,
+		ViewerID:  &viewerID,
+		StartDate: &startOfMonth,
+		EndDate:   &endOfMonth,
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	members, err := s.rp.Ledger.GetMemberAnalytics(ledgerID, viewerID, &startOfMonth, &endOfMonth)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := fmt.Sprintf("*Grup Bulan Ini* (%s %d)\n\n", indonesianMonths[startOfMonth.Month()], startOfMonth.Year())
+	text += "💰 Pemasukan: " + formatMoney(summary.TotalIncome, currency) + "\n"
+	text += "💸 Pengeluaran: " + formatMoney(summary.TotalExpense, currency) + "\n"
+	text += "🏦 Selisih: *" + formatMoney(summary.TotalIncome-summary.TotalExpense, currency) + "*\n"
+
+	if len(members) > 0 {
+		text += "\n*Pengeluaran per anggota:*\n"
+		for _, member := range members {
+			name := member.Name
+			if name == "" {
+				name = member.PhoneNumber
+			}
+			text += fmt.Sprintf("• %s: %s\n", name, formatMoney(member.TotalExpense, currency))
+		}
+	}
+
+	Outgoing.Message = strings.TrimRight(text, "\n")
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+// commandScope resolves who a report command is about: the sender in private chats,
+// the shared ledger in group chats
+func (s *Service) commandScope(message *dto.SimplifiedIncomingMessage) (*uint, *uint, error) {
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, fmt.Errorf("sender phone number is empty")
+	}
+
+	ledger, err := s.GetLedger(message, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ledger != nil {
+		return nil, &ledger.ID, nil
+	}
+
+	return &user.ID, nil, nil
+}
+
+// reportViewer is who report commands show totals to, the sender, and the currency they are shown in,
+// the sender's base currency. Group totals are converted into it.
+func (s *Service) reportViewer(message *dto.SimplifiedIncomingMessage) (*uint, string) {
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil || user == nil {
+		return nil, models.DefaultCurrency
+	}
+	if user.BaseCurrency == "" {
+		return &user.ID, models.DefaultCurrency
+	}
+	return &user.ID, user.BaseCurrency
+}
+
+// newCommandReply builds the outgoing reply skeleton for a command message
+func (s *Service) newCommandReply(message *dto.SimplifiedIncomingMessage) dtoOutgoing.PayloadOutgoing {
+	return dtoOutgoing.PayloadOutgoing{
+		To:             message.ChatID,
+		AccountId:      message.SessionID,
+		ReplyToMessage: &message.MessageID,
+		Type:           "text",
+		Participant:    message.Participant,
+	}
+}
+
+// replyCommandError tells the user the command failed and returns the original error
+func (s *Service) replyCommandError(Outgoing dtoOutgoing.PayloadOutgoing, err error) error {
+	logger.Error.Println("Error processing command:", err)
+	Outgoing.Message = "Maaf, terjadi kesalahan saat memproses perintah."
+	_, _ = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "strings"
+
 // ===============================
 // Simplified Incoming Message
 // ===============================
@@ -97,3 +99,16 @@ func (m *SimplifiedIncomingMessage) GetText() string {
 	}
 	return ""
 }
+
+// SenderPhone returns the phone number of the person who sent the message,
+// in groups that is the participant rather than the chat
+func (m *SimplifiedIncomingMessage) SenderPhone() string {
+	if m.IsGroup && m.Participant != nil && *m.Participant != "" {
+		phone := *m.Participant
+		if idx := strings.IndexAny(phone, "@:"); idx >= 0 {
+			phone = phone[:idx]
+		}
+		return phone
+	}
+	return m.From
+}
@@ -0,0 +1,58 @@
+package incoming
+
+import (
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository/transaction"
+	dtoAI "pannypal/internal/service/ai/dto"
+	"strings"
+	"time"
+)
+
+// duplicateWarning lists saved transactions the draft may repeat, such as a payment typed in
+// after its receipt was already sent. Empty when nothing looks alike or the check fails,
+// a missed warning must not block the draft.
+func (s *Service) duplicateWarning(phoneNumber string, payloads []dtoAI.TransactionPayload) string {
+	if phoneNumber == "" {
+		return ""
+	}
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil || user == nil {
+		return ""
+	}
+
+	seen := map[uint]bool{}
+	matches := []models.Transaction{}
+	for _, payload := range payloads {
+		date := time.Now()
+		if payload.TransactionDate != nil {
+			date = *payload.TransactionDate
+		}
+
+		found, err := s.rp.Transaction.FindDuplicates(user.ID, transaction.DuplicateProbe{
+			Type:            models.TransactionType(payload.Type),
+			Amount:          payload.Amount,
+			Currency:        strings.ToUpper(payload.Currency),
+			Text:            payload.Description,
+			AccountID:       payload.AccountID,
+			TransactionDate: date,
+			MinScore:        transaction.DefaultDuplicateScore,
+		}, 1)
+		if err != nil {
+			logger.Error.Println("Failed to check for duplicate transactions:", err)
+			return ""
+		}
+		for _, match := range found {
+			if !seen[match.Transaction.ID] {
+				seen[match.Transaction.ID] = true
+				matches = append(matches, match.Transaction)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	return "⚠️ Mungkin duplikat dengan:\n" + formatTransactionLines(matches) +
+		"Abaikan jika memang transaksi berbeda."
+}
@@ -0,0 +1,118 @@
+package incoming
+
+import (
+	"fmt"
+	"pannypal/internal/pkg/helper"
+	dtoGoal "pannypal/internal/service/goal/dto"
+	"pannypal/internal/service/incoming/dto"
+	"strings"
+	"time"
+)
+
+// HandleTabunganCommand shows the sender's savings goals, "#tabungan liburan 500rb" adds to a goal and
+// "#tabungan ambil liburan 200rb" takes money out of it
+func (s *Service) HandleTabunganCommand(message *dto.SimplifiedIncomingMessage, args []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	if user == nil {
+		return s.replyCommandError(Outgoing, fmt.Errorf("sender phone number is empty"))
+	}
+
+	if len(args) == 0 {
+		goals, err := s.goal.GetGoalsProgress(*user, time.Now())
+		if err != nil {
+			return s.replyCommandError(Outgoing, err)
+		}
+
+		text := "*Target Tabungan*\n\n"
+		if len(goals) == 0 {
+			text += "Belum ada target tabungan."
+		}
+		for _, goal := range goals {
+			text += formatGoalLines(goal) + "\n"
+		}
+
+		Outgoing.Message = strings.TrimRight(text, "\n")
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	withdraw := strings.ToLower(args[0]) == "ambil"
+	if withdraw {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		Outgoing.Message = "Format: *#tabungan* untuk melihat target tabungan\n*#tabungan [nama] [jumlah]* untuk menabung\n*#tabungan ambil [nama] [jumlah]* untuk mengambil tabungan"
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	amount, err := helper.ParseAmount(args[len(args)-1])
+	if err != nil || amount <= 0 {
+		Outgoing.Message = fmt.Sprintf("Nominal %q tidak valid.", args[len(args)-1])
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	name := strings.Join(args[:len(args)-1], " ")
+	goal, matches, err := s.goal.FindGoal(user.ID, name)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	if goal == nil {
+		Outgoing.Message = fmt.Sprintf("Target tabungan %q tidak ditemukan. Ketik *#tabungan* untuk melihat daftarnya.", name)
+		if len(matches) > 1 {
+			names := make([]string, len(matches))
+			for i, match := range matches {
+				names[i] = match.Name
+			}
+			Outgoing.Message = "Ada beberapa target tabungan yang cocok: " + strings.Join(names, ", ") + ". Tulis namanya lebih lengkap."
+		}
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	text := fmt.Sprintf("✅ %s ditabung ke *%s*.\n\n", formatMoney(amount, user.BaseCurrency), goal.Name)
+	if withdraw {
+		text = fmt.Sprintf("✅ %s diambil dari *%s*.\n\n", formatMoney(amount, user.BaseCurrency), goal.Name)
+		amount = -amount
+	}
+
+	progress, err := s.goal.Contribute(*user, *goal, amount, "WhatsApp #tabungan", time.Now())
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	text += formatGoalLines(*progress)
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+// formatGoalLines shows a goal's progress and when it is expected to be reached
+func formatGoalLines(goal dtoGoal.GoalResponse) string {
+	text := fmt.Sprintf("🎯 *%s*: %s / %s (%.0f%%)\n", goal.Name,
+		formatMoney(goal.SavedAmount, goal.Currency), formatMoney(goal.TargetAmount, goal.Currency), goal.Percentage)
+
+	switch {
+	case goal.IsCompleted:
+		text += "    Target tercapai 🎉\n"
+	case goal.ProjectedCompletion != nil:
+		text += "    Perkiraan tercapai " + formatIndonesianDate(*goal.ProjectedCompletion) + "\n"
+	default:
+		text += "    Belum ada tabungan dalam 3 bulan terakhir\n"
+	}
+
+	if goal.Deadline != nil && !goal.IsCompleted {
+		text += "    Tenggat " + formatIndonesianDate(*goal.Deadline)
+		if goal.IsOnTrack != nil && !*goal.IsOnTrack && goal.RequiredMonthly != nil {
+			text += ", perlu " + formatMoney(*goal.RequiredMonthly, goal.Currency) + "/bulan"
+		}
+		text += "\n"
+	}
+	return text
+}
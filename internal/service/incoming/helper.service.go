@@ -2,36 +2,39 @@ package incoming
 
 import (
 	"fmt"
-	"pannypal/internal/common/enum"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/incoming/dto"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-// DetectAction detects the user's action from their message
+// DetectAction detects the user's action from the first word of their message, so an edit such as
+// "edit save 20rb for later" is not taken for a save
 func DetectAction(msg string) string {
-	msg = strings.ToLower(msg)
-
-	saveKeywords := []string{"save"}
-	cancelKeywords := []string{"cancel"}
-	editKeywords := []string{"edit"}
-
-	for _, w := range saveKeywords {
-		if strings.Contains(msg, w) {
-			return "save"
-		}
+	words := strings.Fields(strings.ToLower(msg))
+	if len(words) == 0 {
+		return "none"
 	}
+	word := strings.Trim(words[0], ".,!?")
 
-	for _, w := range cancelKeywords {
-		if strings.Contains(msg, w) {
-			return "cancel"
-		}
+	actionKeywords := map[string][]string{
+		"save":   {"save"},
+		"cancel": {"cancel"},
+		"edit":   {"edit"},
+		"undo":   {"undo"},
 	}
 
-	for _, w := range editKeywords {
-		if strings.Contains(msg, w) {
-			return "edit"
+	for action, keywords := range actionKeywords {
+		for _, w := range keywords {
+			if word == w {
+				return action
+			}
 		}
 	}
 
@@ -66,57 +69,82 @@ func (s *Service) GetUser(phoneNumber string) (*models.User, error) {
 	return user, nil
 }
 
-// validateOrCreateCategory validates if a category exists, or creates/returns a default one
-func (s *Service) validateOrCreateCategory(categoryID int) (*uint, error) {
-	if categoryID <= 0 {
-		// If no category or invalid ID, get the first available category or create default
-		categories, err := s.rp.Category.GetAllCategories()
-		if err != nil {
-			return nil, err
-		}
+// rememberUserChat stores the private chat of the sender so the worker can message them later
+func (s *Service) rememberUserChat(message *dto.SimplifiedIncomingMessage) {
+	if message.IsGroup || message.ChatID == "" {
+		return
+	}
 
-		if len(categories) == 0 {
-			// Create default category if none exists
-			defaultCategory, err := s.rp.Category.CreateCategory(models.Category{
-				Name: "Other",
-			})
-			if err != nil {
-				return nil, err
-			}
-			return &defaultCategory.ID, nil
-		}
+	// Only known users, a stray message should not create an account
+	user, err := s.rp.User.GetUserByPhone(message.SenderPhone())
+	if err != nil {
+		return
+	}
+
+	if user.WhatsappChatID == message.ChatID && user.BotAccountID == message.SessionID {
+		return
+	}
+
+	user.WhatsappChatID = message.ChatID
+	user.BotAccountID = message.SessionID
+	if _, err := s.rp.User.UpdateUser(*user); err != nil {
+		logger.Error.Println("Error saving user chat:", err)
+	}
+}
 
-		// Use first available category
-		return &categories[0].ID, nil
+// GetLedger returns the shared ledger of a group chat and registers the sender as a member,
+// private chats have no ledger
+func (s *Service) GetLedger(message *dto.SimplifiedIncomingMessage, user *models.User) (*models.Ledger, error) {
+	if !message.IsGroup || user == nil {
+		return nil, nil
 	}
 
-	// Check if category exists
-	_, err := s.rp.Category.GetCategoryByID(uint(categoryID))
+	ledger, err := s.rp.Ledger.GetLedgerByChatID(message.ChatID)
 	if err != nil {
-		// Category doesn't exist, get first available category or create default
-		categories, err := s.rp.Category.GetAllCategories()
+		return nil, err
+	}
+
+	if ledger == nil {
+		ledger, err = s.rp.Ledger.CreateLedger(models.Ledger{
+			ChatID: message.ChatID,
+		})
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		if len(categories) == 0 {
-			// Create default category if none exists
-			defaultCategory, err := s.rp.Category.CreateCategory(models.Category{
-				Name: "Lainnya",
-			})
-			if err != nil {
-				return nil, err
-			}
-			return &defaultCategory.ID, nil
-		}
+	if _, err := s.rp.Ledger.AddMember(ledger.ID, user.ID); err != nil {
+		return nil, err
+	}
+
+	return ledger, nil
+}
 
-		// Use first available category
-		return &categories[0].ID, nil
+// validateOrCreateCategory keeps the category the AI picked when it is the user's own and fits the
+// transaction type, anything else goes to the user's fallback category. rp is the repository the
+// transaction is written with, a fallback category created here is committed with it.
+func (s *Service) validateOrCreateCategory(rp repository.IRepository, userID uint, categoryID int, transactionType string) (*uint, error) {
+	txType := models.TransactionType(transactionType)
+	if categoryID > 0 {
+		category, err := rp.Category.GetCategoryByID(uint(categoryID))
+		// The AI is shown the template when the sender has no account yet, take the user's copy of it
+		if err == nil && category.UserID == nil {
+			category, err = rp.Category.GetCategoryByName(&userID, category.Name)
+		}
+		if err == nil && category.UserID != nil && *category.UserID == userID && category.Kind.Allows(txType) {
+			return &category.ID, nil
+		}
 	}
 
-	// Category exists, return as pointer
-	validID := uint(categoryID)
-	return &validID, nil
+	fallback, err := rp.Category.GetFallbackCategory(userID)
+	if err != nil {
+		return nil, err
+	}
+	// The user narrowed the fallback category down, the transaction stays uncategorized
+	if !fallback.Kind.Allows(txType) {
+		return nil, nil
+	}
+	return &fallback.ID, nil
 }
 
 // formatCurrency formats an amount as currency with dots
@@ -137,6 +165,53 @@ func (s *Service) formatCurrency(amount int) string {
 	return string(result)
 }
 
-func (s *Service) IsCashFlowFunction(payload string) bool {
-	return strings.Contains(payload, string(enum.TagKeuangan))
+var indonesianMonths = []string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// formatRupiah formats an amount as "Rp. 1.250.000", keeping the sign for negative balances
+func formatRupiah(amount types.Money) string {
+	return helper.FormatMoney(amount, models.DefaultCurrency)
+}
+
+// formatMoney formats an amount in its currency, Rupiah keeps the "Rp. 1.250.000" style
+func formatMoney(amount types.Money, currency string) string {
+	return helper.FormatMoney(amount, currency)
+}
+
+// formatIndonesianDate formats a date as "18 Oktober 2026"
+func formatIndonesianDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()], t.Year())
+}
+
+// formatChange describes the change against the previous period, e.g. " (▲ 12% dari bulan lalu)"
+func formatChange(current, previous types.Money) string {
+	if previous <= 0 {
+		return ""
+	}
+	change := ((current - previous).Float64() / previous.Float64()) * 100
+	if change >= 0 {
+		return fmt.Sprintf(" (▲ %.0f%% dari bulan lalu)", change)
+	}
+	return fmt.Sprintf(" (▼ %.0f%% dari bulan lalu)", -change)
+}
+
+// formatTransactionLines renders transactions as one chat line each
+func formatTransactionLines(transactions []models.Transaction) string {
+	text := ""
+	for _, tx := range transactions {
+		icon := "💸"
+		switch tx.Type {
+		case models.TypeIncome:
+			icon = "💰"
+		case models.TypeTransfer:
+			icon = "🔁"
+		}
+		text += fmt.Sprintf("%s %s %s - %s (%s)", icon, tx.TransactionDate.Format("02/01"),
+			tx.Description, formatMoney(tx.Amount, tx.Currency), tx.Category.Name)
+		if len(tx.Tags) > 0 {
+			text += " " + formatTags(tagNames(tx.Tags))
+		}
+		text += "\n"
+	}
+	return text
 }
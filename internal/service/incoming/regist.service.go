@@ -4,9 +4,13 @@ import (
 	"context"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/redis"
+	s3aws "pannypal/internal/pkg/storage/s3"
 	"pannypal/internal/repository"
 	AI "pannypal/internal/service/ai"
+	chatbotService "pannypal/internal/service/chatbot"
+	goalService "pannypal/internal/service/goal"
 	"pannypal/internal/service/outgoing"
+	splitService "pannypal/internal/service/split"
 )
 
 type Service struct {
@@ -15,17 +19,25 @@ type Service struct {
 	rp       repository.IRepository
 	ai       AI.IService
 	outgoing outgoing.IService
+	chatbot  chatbotService.IService
+	split    splitService.IService
+	goal     goalService.IService
+	storage  s3aws.Is3
 }
 type IService interface {
 	HandleWebhookEventBaileys(payload interface{}) *types.Response
 }
 
-func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, ai AI.IService, outgoing outgoing.IService) IService {
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, ai AI.IService, outgoing outgoing.IService, chatbot chatbotService.IService, split splitService.IService, goal goalService.IService, storage s3aws.Is3) IService {
 	return &Service{
 		ctx:      ctx,
 		redis:    redis,
 		rp:       repository,
 		ai:       ai,
 		outgoing: outgoing,
+		chatbot:  chatbot,
+		split:    split,
+		goal:     goal,
+		storage:  storage,
 	}
 }
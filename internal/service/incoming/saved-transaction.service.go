@@ -0,0 +1,346 @@
+package incoming
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pannypal/internal/common/enum"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	dtoAI "pannypal/internal/service/ai/dto"
+	"pannypal/internal/service/incoming/dto"
+	dtoOutgoing "pannypal/internal/service/outgoing/dto"
+	splitService "pannypal/internal/service/split"
+	"strings"
+	"time"
+)
+
+const savedTransactionHint = "\nBalas _'undo'_ untuk membatalkan atau _'edit <perubahan>'_ untuk mengubah."
+
+// HandleSavedTransactionReplyAction handles replies to the "Transaksi berhasil disimpan" confirmation
+func (s *Service) HandleSavedTransactionReplyAction(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply) error {
+	Outgoing := s.newCommandReply(message)
+
+	// Only an explicit "undo" deletes the saved transactions, "cancel" belongs to the draft confirmation
+	switch DetectAction(message.GetText()) {
+	case "undo":
+		logger.Info.Println("Action detected: undo")
+		return s.UndoSavedTransaction(message, messageToReply, Outgoing)
+	case "edit":
+		logger.Info.Println("Action detected: edit saved")
+		return s.EditSavedTransaction(message, messageToReply, Outgoing)
+	default:
+		Outgoing.Message = "Maaf, saya tidak mengerti tindakan yang Anda maksud. Silakan balas dengan 'undo' atau 'edit <perubahan>'."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+}
+
+func (s *Service) UndoSavedTransaction(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply, Outgoing dtoOutgoing.PayloadOutgoing) error {
+	transactions, err := s.loadSavedTransactions(message, messageToReply)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if len(transactions) == 0 {
+		Outgoing.Message = "Transaksi sudah tidak tersedia."
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		for _, tx := range transactions {
+			if err := rp.Transaction.DeleteTransaction(tx.ID); err != nil {
+				return err
+			}
+			if err := rp.Split.DeleteSplitByTransactionID(tx.ID); err != nil {
+				return err
+			}
+			if err := s.recordRevision(rp, message, models.RevisionActionDelete, &tx, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if err := s.rp.Bot.DeleteMessageToReply(messageToReply.MessageID); err != nil {
+		logger.Error.Println("Error deleting MessageToReply:", err)
+	}
+
+	Outgoing.Message = "↩️ Transaksi dibatalkan:\n" + strings.TrimRight(formatTransactionLines(transactions), "\n")
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) EditSavedTransaction(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply, Outgoing dtoOutgoing.PayloadOutgoing) error {
+	change := strings.TrimSpace(message.GetText())
+	if strings.HasPrefix(strings.ToLower(change), "edit") {
+		change = strings.TrimSpace(change[len("edit"):])
+	}
+	// "edit #kantor-reimburse" only tags, hashtags next to other changes are added as well
+	tags, change := extractMessageTags(change)
+	if change == "" && len(tags) == 0 {
+		Outgoing.Message = "Tulis perubahannya setelah 'edit', contoh: _edit makan siang jadi 30rb_"
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	transactions, err := s.loadSavedTransactions(message, messageToReply)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if len(transactions) == 0 {
+		Outgoing.Message = "Transaksi sudah tidak tersedia."
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	// The AI works out the change before the database transaction is opened
+	var payloads []dtoAI.TransactionPayload
+	if change != "" {
+		payloads, err = s.editSavedTransactionPayloads(message, transactions, change)
+		if err != nil {
+			return s.replyCommandError(Outgoing, err)
+		}
+		// Every saved transaction has to come back, a missing one cannot be matched to its row
+		if len(payloads) < len(transactions) {
+			logger.Warning.Printf("Chat edit returned %d transactions for %d saved ones", len(payloads), len(transactions))
+			Outgoing.Message = "Maaf, perubahannya tidak bisa diterapkan ke semua transaksi. Coba tulis ulang perubahannya, atau balas _'undo'_ untuk menghapus transaksinya."
+			_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+			return err
+		}
+	}
+
+	before := map[uint]models.Transaction{}
+	transactionIDs := []uint{}
+	for _, tx := range transactions {
+		before[tx.ID] = tx
+		transactionIDs = append(transactionIDs, tx.ID)
+	}
+
+	// The change, the tags, the splits following new amounts and the revisions describing them are written together
+	updated := make([]models.Transaction, 0, len(transactionIDs))
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if change != "" {
+			changedIDs, resplitIDs, err := s.applySavedTransactionChange(rp, transactions, payloads)
+			if err != nil {
+				return err
+			}
+			for _, id := range resplitIDs {
+				if _, err := splitService.Resplit(rp, id); err != nil {
+					return err
+				}
+			}
+			transactionIDs = changedIDs
+		}
+
+		for _, id := range transactionIDs {
+			if _, err := s.addTransactionTags(rp, transactions[0].UserID, id, tags); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range transactionIDs {
+			tx, err := rp.Transaction.GetTransactionByID(id)
+			if err != nil {
+				return err
+			}
+			updated = append(updated, *tx)
+
+			if previous, ok := before[id]; ok {
+				err = s.recordRevision(rp, message, models.RevisionActionUpdate, &previous, tx)
+			} else {
+				err = s.recordRevision(rp, message, models.RevisionActionCreate, nil, tx)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, splitService.ErrInvalidSplit) {
+		Outgoing.Message = "Transaksi tidak diubah, pembagian tagihannya tidak bisa dihitung ulang dengan nominal baru."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if err := s.rp.Bot.DeleteMessageToReply(messageToReply.MessageID); err != nil {
+		logger.Error.Println("Error deleting MessageToReply:", err)
+	}
+
+	Outgoing.Message = "✏️ Transaksi berhasil diperbarui:\n" + formatTransactionLines(updated) + savedTransactionHint
+	messageOut, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+	if err != nil {
+		return err
+	}
+
+	if err := s.createSavedTransactionReply(messageOut, Outgoing.Message, transactionIDs, message.Participant); err != nil {
+		logger.Error.Println("Error saving MessageToReply for saved transactions:", err)
+	}
+
+	return nil
+}
+
+// editSavedTransactionPayloads lets the AI apply a chat edit to saved transactions, the result keeps
+// the saved transactions in order and adds the new ones after them
+func (s *Service) editSavedTransactionPayloads(message *dto.SimplifiedIncomingMessage, transactions []models.Transaction, change string) ([]dtoAI.TransactionPayload, error) {
+	existing := make([]dtoAI.TransactionPayload, 0, len(transactions))
+	for _, tx := range transactions {
+		categoryID := 0
+		if tx.CategoryID != nil {
+			categoryID = int(*tx.CategoryID)
+		}
+		existing = append(existing, dtoAI.TransactionPayload{
+			Type:        string(tx.Type),
+			Amount:      tx.Amount,
+			Currency:    tx.Currency,
+			CategoryId:  categoryID,
+			Description: tx.Description,
+		})
+	}
+
+	result, _, err := s.ai.EditTextCashflow(dtoAI.EditTextCashflow{
+		Message:     change,
+		Existing:    existing,
+		PhoneNumber: message.SenderPhone(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.ReqPayload, nil
+}
+
+// applySavedTransactionChange writes the AI's edit of saved transactions and returns the IDs of the
+// transactions after the edit, entries beyond the saved ones are created. The IDs of transactions
+// whose amount changed are returned as well, their splits have to be redone.
+func (s *Service) applySavedTransactionChange(rp repository.IRepository, transactions []models.Transaction, payloads []dtoAI.TransactionPayload) ([]uint, []uint, error) {
+	transactionIDs := []uint{}
+	resplitIDs := []uint{}
+	for i, payload := range payloads {
+		validCategoryID, err := s.validateOrCreateCategory(rp, transactions[0].UserID, payload.CategoryId, payload.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The merge prompt keeps existing transactions in order, extra entries are new ones
+		if i < len(transactions) {
+			tx := transactions[i]
+
+			tx.Type = models.TransactionType(payload.Type)
+			tx.Amount = payload.Amount
+			tx.CategoryID = validCategoryID
+			tx.Description = payload.Description
+			if payload.Currency != "" {
+				tx.Currency = strings.ToUpper(payload.Currency)
+			}
+			tx.Category = models.Category{}
+
+			updated, err := rp.Transaction.UpdateTransaction(tx)
+			if err != nil {
+				return nil, nil, err
+			}
+			if updated.Amount != transactions[i].Amount {
+				resplitIDs = append(resplitIDs, updated.ID)
+			}
+			transactionIDs = append(transactionIDs, updated.ID)
+			continue
+		}
+
+		created, err := rp.Transaction.CreateTransaction(models.Transaction{
+			UserID:          transactions[0].UserID,
+			LedgerID:        transactions[0].LedgerID,
+			Type:            models.TransactionType(payload.Type),
+			Amount:          payload.Amount,
+			Currency:        strings.ToUpper(payload.Currency),
+			CategoryID:      validCategoryID,
+			Description:     payload.Description,
+			TransactionDate: time.Now(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		transactionIDs = append(transactionIDs, created.ID)
+	}
+
+	return transactionIDs, resplitIDs, nil
+}
+
+// createSavedTransactionReply links a confirmation message to the transactions it reports
+func (s *Service) createSavedTransactionReply(messageOut *dtoOutgoing.ResponseOutgoing, text string, transactionIDs []uint, participant *string) error {
+	if messageOut == nil {
+		return fmt.Errorf("failed to send outgoing message: no response from outgoing service")
+	}
+
+	idsBytes, err := json.Marshal(transactionIDs)
+	if err != nil {
+		return err
+	}
+	rawMessage := json.RawMessage(idsBytes)
+
+	_, err = s.rp.Bot.CreateMessageToReply(models.MessageToReply{
+		MessageID:   messageOut.Id,
+		FeatureType: enum.FeatureTypeAIcashflowSaved,
+		Messsage:    text,
+		Additional:  &rawMessage,
+		Participant: participant,
+	})
+	return err
+}
+
+// loadSavedTransactions returns the sender's transactions still referenced by the confirmation,
+// deleted ones and ones owned by another user are skipped
+func (s *Service) loadSavedTransactions(message *dto.SimplifiedIncomingMessage, messageToReply *models.MessageToReply) ([]models.Transaction, error) {
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	ids, err := helper.JSONToStruct[[]uint](messageToReply.Additional)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		return nil, nil
+	}
+
+	transactions := []models.Transaction{}
+	for _, id := range *ids {
+		tx, err := s.rp.Transaction.GetTransactionByID(id)
+		if err != nil || tx.UserID != user.ID {
+			continue
+		}
+		transactions = append(transactions, *tx)
+	}
+
+	return transactions, nil
+}
+
+// recordRevision appends a change made from chat to the transaction's history, rp should be the one
+// the change was written with so both are committed or neither is
+func (s *Service) recordRevision(rp repository.IRepository, message *dto.SimplifiedIncomingMessage, action models.RevisionAction, before, after *models.Transaction) error {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	actor := models.RevisionActor{
+		ActorType: models.RevisionActorBot,
+		ActorID:   &current.UserID,
+		Channel:   message.From,
+		MessageID: message.MessageID,
+		Source:    models.RevisionSourceWhatsapp,
+	}
+	return rp.Revision.Record(action, actor, before, after)
+}
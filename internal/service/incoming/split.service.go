@@ -0,0 +1,324 @@
+package incoming
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/service/incoming/dto"
+	dtoOutgoing "pannypal/internal/service/outgoing/dto"
+	splitService "pannypal/internal/service/split"
+	dtoSplit "pannypal/internal/service/split/dto"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// splitSpec is the split clause of a #keuangan message, kept with the draft until it is saved
+type splitSpec struct {
+	Method       models.SplitMethod `json:"method"`
+	Parts        int                `json:"parts"`
+	Participants []splitParticipant `json:"participants"`
+}
+
+type splitParticipant struct {
+	PhoneNumber string       `json:"phone_number"`
+	Amount      *types.Money `json:"amount,omitempty"`
+	Percentage  *float64     `json:"percentage,omitempty"`
+}
+
+// splitClausePattern matches "bagi 3 dengan @62811 @62822" or "dengan @62811 30% @62822 40%" at the end of a message
+var splitClausePattern = regexp.MustCompile(`(?i)\s+(?:bagi\s+(\d+)\s+)?dengan\s+(@.*)$`)
+
+// parseSplitClause removes the split clause from a #keuangan message, the error text is shown to the user
+func parseSplitClause(text string) (string, *splitSpec, error) {
+	match := splitClausePattern.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text, nil, nil
+	}
+
+	spec := &splitSpec{Method: models.SplitMethodEqual}
+	if match[2] >= 0 {
+		spec.Parts, _ = strconv.Atoi(text[match[2]:match[3]])
+	}
+
+	for _, token := range strings.Fields(text[match[4]:match[5]]) {
+		token = strings.TrimRight(token, ",;")
+		lower := strings.ToLower(token)
+		last := len(spec.Participants) - 1
+
+		switch {
+		case lower == "" || lower == "dan" || lower == "&" || lower == "rata":
+			continue
+		case strings.HasPrefix(token, "@"):
+			phone := normalizePhone(token[1:])
+			if phone == "" {
+				return "", nil, fmt.Errorf("nomor %s tidak valid", token)
+			}
+			spec.Participants = append(spec.Participants, splitParticipant{PhoneNumber: phone})
+		case last < 0:
+			return "", nil, fmt.Errorf("sebutkan orangnya dengan @nomor sebelum %q", token)
+		case strings.HasSuffix(token, "%"):
+			value, err := strconv.ParseFloat(strings.Replace(strings.TrimSuffix(token, "%"), ",", ".", 1), 64)
+			if err != nil || value <= 0 || value >= 100 {
+				return "", nil, fmt.Errorf("persentase %q tidak valid", token)
+			}
+			spec.Participants[last].Percentage = &value
+		default:
+			amount, err := helper.ParseAmount(token)
+			if err != nil || amount <= 0 {
+				return "", nil, fmt.Errorf("nominal %q tidak valid", token)
+			}
+			spec.Participants[last].Amount = &amount
+		}
+	}
+
+	percentages, amounts := 0, 0
+	for _, p := range spec.Participants {
+		if p.Percentage != nil {
+			percentages++
+		}
+		if p.Amount != nil {
+			amounts++
+		}
+	}
+
+	switch {
+	case percentages > 0 && amounts > 0:
+		return "", nil, fmt.Errorf("gunakan persentase atau nominal saja, jangan dicampur")
+	case percentages > 0:
+		if percentages != len(spec.Participants) {
+			return "", nil, fmt.Errorf("setiap orang perlu persentase")
+		}
+		spec.Method = models.SplitMethodPercentage
+	case amounts > 0:
+		if amounts != len(spec.Participants) {
+			return "", nil, fmt.Errorf("setiap orang perlu nominal")
+		}
+		spec.Method = models.SplitMethodExact
+	}
+
+	return strings.TrimSpace(text[:match[0]]), spec, nil
+}
+
+// normalizePhone turns "@0812..." or "+62812..." mentions into the stored 62812... format
+func normalizePhone(value string) string {
+	digits := strings.Builder{}
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	phone := digits.String()
+	if strings.HasPrefix(phone, "0") {
+		phone = "62" + phone[1:]
+	}
+	return phone
+}
+
+// describeSplit renders the split preview shown under a draft
+func describeSplit(spec *splitSpec) string {
+	names := make([]string, len(spec.Participants))
+	for i, p := range spec.Participants {
+		names[i] = "@" + p.PhoneNumber
+		switch {
+		case p.Percentage != nil:
+			names[i] += fmt.Sprintf(" (%s%%)", strconv.FormatFloat(*p.Percentage, 'f', -1, 64))
+		case p.Amount != nil:
+			names[i] += " (" + formatRupiah(*p.Amount) + ")"
+		}
+	}
+
+	switch spec.Method {
+	case models.SplitMethodEqual:
+		parts := len(spec.Participants) + 1
+		if spec.Parts > parts {
+			parts = spec.Parts
+		}
+		return fmt.Sprintf("👥 Dibagi rata %d orang dengan %s", parts, strings.Join(names, ", "))
+	default:
+		return "👥 Dibagi dengan " + strings.Join(names, ", ") + ", sisanya bagianmu"
+	}
+}
+
+// applySplit splits every saved expense according to the draft and reports each share,
+// a failed split does not undo the saved transaction
+func (s *Service) applySplit(spec *splitSpec, payerID uint, transactions []models.Transaction) string {
+	participants := []dtoSplit.ShareInput{}
+	for _, p := range spec.Participants {
+		user, err := s.GetUser(p.PhoneNumber)
+		if err != nil || user == nil {
+			logger.Error.Println("Failed to get split participant:", err)
+			return "\n\n⚠️ Gagal membagi transaksi, peserta tidak ditemukan."
+		}
+		participants = append(participants, dtoSplit.ShareInput{
+			UserID:     user.ID,
+			Amount:     p.Amount,
+			Percentage: p.Percentage,
+		})
+	}
+
+	text := ""
+	for _, tx := range transactions {
+		if tx.Type != models.TypeExpense {
+			continue
+		}
+
+		split, err := s.split.SplitTransaction(dtoSplit.SplitInput{
+			TransactionID: tx.ID,
+			PayerID:       payerID,
+			Method:        spec.Method,
+			Parts:         spec.Parts,
+			Participants:  participants,
+		})
+		if err != nil {
+			logger.Error.Println("Failed to split transaction:", err)
+			text += fmt.Sprintf("\n⚠️ Gagal membagi %s", tx.Description)
+			continue
+		}
+
+		shares := []string{}
+		for _, share := range split.Shares {
+			if share.UserID == payerID {
+				continue
+			}
+			shares = append(shares, fmt.Sprintf("@%s %s", share.User.PhoneNumber, formatRupiah(share.Amount)))
+		}
+		text += fmt.Sprintf("\n👥 %s: %s", tx.Description, strings.Join(shares, ", "))
+	}
+
+	if text == "" {
+		return ""
+	}
+	return "\n" + text
+}
+
+// splitSpecFromDraft reads the split clause stored with a draft, drafts without one return nil
+func splitSpecFromDraft(messageToReply *models.MessageToReply) *splitSpec {
+	if messageToReply.SplitSpec == nil {
+		return nil
+	}
+	var spec splitSpec
+	if err := json.Unmarshal(*messageToReply.SplitSpec, &spec); err != nil {
+		logger.Error.Println("Error parsing split spec:", err)
+		return nil
+	}
+	return &spec
+}
+
+// HandleHutangCommand lists who owes whom, or records a repayment:
+// "#hutang bayar @628.. [jumlah]" when the sender pays back, "#hutang terima @628.. [jumlah]" when the sender is paid back
+func (s *Service) HandleHutangCommand(message *dto.SimplifiedIncomingMessage, args []string) error {
+	Outgoing := s.newCommandReply(message)
+
+	user, err := s.GetUser(message.SenderPhone())
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+	if user == nil {
+		return s.replyCommandError(Outgoing, fmt.Errorf("sender phone number is empty"))
+	}
+
+	if len(args) == 0 {
+		return s.replyDebtBalances(Outgoing, user.ID)
+	}
+
+	action := strings.ToLower(args[0])
+	if (action != "bayar" && action != "terima") || len(args) < 2 || !strings.HasPrefix(args[1], "@") {
+		Outgoing.Message = "Format: *#hutang* untuk melihat hutang piutang\n*#hutang bayar @nomor [jumlah]* saat kamu membayar\n*#hutang terima @nomor [jumlah]* saat kamu dibayar"
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	phone := normalizePhone(args[1])
+	if phone == "" || phone == user.PhoneNumber {
+		Outgoing.Message = "Nomor lawan tidak valid."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	var amount *types.Money
+	if len(args) > 2 {
+		value, err := helper.ParseAmount(strings.Join(args[2:], ""))
+		if err != nil || value <= 0 {
+			Outgoing.Message = fmt.Sprintf("Nominal %q tidak valid.", strings.Join(args[2:], " "))
+			_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+			return err
+		}
+		amount = &value
+	}
+
+	counterparty, err := s.GetUser(phone)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	debtorID, creditorID := user.ID, counterparty.ID
+	if action == "terima" {
+		debtorID, creditorID = counterparty.ID, user.ID
+	}
+
+	debt, remaining, err := s.split.Settle(debtorID, creditorID, amount, "WhatsApp #hutang")
+	if errors.Is(err, splitService.ErrInvalidSplit) {
+		Outgoing.Message = "Tidak ada hutang yang perlu dilunasi dengan @" + phone + "."
+		_, err := s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	text := fmt.Sprintf("✅ Pembayaran %s dicatat.\n", formatRupiah(debt.Amount))
+	switch {
+	case remaining > 0:
+		text += "Sisa hutang: " + formatRupiah(remaining)
+	case remaining < 0:
+		text += "Kelebihan bayar " + formatRupiah(-remaining) + " dicatat sebagai hutang balik."
+	default:
+		text += "Hutang lunas! 🎉"
+	}
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
+
+func (s *Service) replyDebtBalances(Outgoing dtoOutgoing.PayloadOutgoing, userID uint) error {
+	balances, err := s.rp.Split.GetBalances(userID)
+	if err != nil {
+		return s.replyCommandError(Outgoing, err)
+	}
+
+	if len(balances) == 0 {
+		Outgoing.Message = "Tidak ada hutang piutang. 🎉"
+		_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+		return err
+	}
+
+	text := "*Hutang Piutang*\n\n"
+	owedToYou, youOwe := types.Money(0), types.Money(0)
+	for _, b := range balances {
+		name := b.Name
+		if name == "" {
+			name = "@" + b.PhoneNumber
+		}
+		if b.Balance > 0 {
+			text += fmt.Sprintf("• %s berhutang ke kamu %s\n", name, formatRupiah(b.Balance))
+			owedToYou += b.Balance
+		} else {
+			text += fmt.Sprintf("• Kamu berhutang ke %s %s\n", name, formatRupiah(-b.Balance))
+			youOwe += -b.Balance
+		}
+	}
+
+	text += "\n📥 Piutang: " + formatRupiah(owedToYou) + "\n"
+	text += "📤 Hutang: " + formatRupiah(youOwe)
+
+	Outgoing.Message = text
+	_, err = s.outgoing.HandleWebhookEventWaha(Outgoing)
+	return err
+}
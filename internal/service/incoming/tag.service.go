@@ -0,0 +1,202 @@
+package incoming
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/enum"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/incoming/dto"
+	transactionService "pannypal/internal/service/transaction"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxReceiptSize skips media too large to be a receipt photo
+const maxReceiptSize = 10 << 20
+
+// draftAttachment is a receipt uploaded while the transaction is still a draft,
+// it becomes an Attachment once the draft is saved
+type draftAttachment struct {
+	FileKey     string `json:"file_key"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// extractMessageTags takes the hashtags out of a message, command tags such as #keuangan stay in the text
+func extractMessageTags(text string) ([]string, string) {
+	return helper.ExtractHashtags(text, func(word string) bool {
+		return enum.WebhookIncomingTag(strings.TrimRight(strings.ToLower(word), ".,:;!?")).IsValid()
+	})
+}
+
+// formatTags renders tags the way users type them, "#liburan-bali #kantor"
+func formatTags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = "#" + tag
+	}
+	return strings.Join(parts, " ")
+}
+
+// tagNames lists the names of loaded tags
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// addTransactionTags adds the tags to the transaction, creating the user's missing tags, and returns them.
+// rp is the service's repository or one bound to the database transaction writing the transaction
+func (s *Service) addTransactionTags(rp repository.IRepository, userID, transactionID uint, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	tags, err := rp.Tag.GetOrCreateTags(userID, helper.NormalizeTags(names))
+	if err != nil {
+		return nil, err
+	}
+	return tags, rp.Tag.AddTransactionTags(transactionID, tags)
+}
+
+// storeReceipt uploads the image or PDF sent with a message so it can be attached on save.
+// It returns nil when there is nothing to store, failures are logged and the draft goes on without it.
+func (s *Service) storeReceipt(message *dto.SimplifiedIncomingMessage) *json.RawMessage {
+	if s.storage == nil || message.Content.DownloadInstructions == nil {
+		return nil
+	}
+	if message.MessageType != "image" && message.MessageType != "document" {
+		return nil
+	}
+	if message.Content.FileSize != nil && *message.Content.FileSize > maxReceiptSize {
+		return nil
+	}
+
+	contentType := ""
+	if message.Content.Mimetype != nil {
+		contentType = *message.Content.Mimetype
+	}
+	if contentType != "" && !transactionService.IsAllowedAttachmentType(contentType) {
+		return nil
+	}
+
+	instructions := message.Content.DownloadInstructions
+	data, err := s.outgoing.DownloadMedia(message.SessionID, instructions.Method, instructions.Endpoint, instructions.Body)
+	if err != nil {
+		logger.Error.Println("Error downloading receipt:", err)
+		return nil
+	}
+	if len(data) > maxReceiptSize {
+		return nil
+	}
+
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+		if !transactionService.IsAllowedAttachmentType(contentType) {
+			return nil
+		}
+	}
+
+	fileName := "receipt"
+	if message.Content.FileName != nil && *message.Content.FileName != "" {
+		fileName = *message.Content.FileName
+	}
+	ext := filepath.Ext(fileName)
+	if ext == "" {
+		ext = receiptExtension(contentType)
+		fileName += ext
+	}
+
+	key := fmt.Sprintf("attachments/whatsapp/%s/%s%s", normalizePhone(message.SenderPhone()), uuid.New().String(), ext)
+	if err := s.storage.UploadFile(key, data, contentType); err != nil {
+		logger.Error.Println("Error uploading receipt:", err)
+		return nil
+	}
+
+	attachmentBytes, err := json.Marshal([]draftAttachment{{
+		FileKey:     key,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}})
+	if err != nil {
+		return nil
+	}
+	raw := json.RawMessage(attachmentBytes)
+	return &raw
+}
+
+// attachDraftFiles turns the receipts kept with a draft into attachments of the first saved transaction,
+// a receipt with several items is still one file
+func (s *Service) attachDraftFiles(messageToReply *models.MessageToReply, saved []models.Transaction) bool {
+	files := draftAttachmentsFromDraft(messageToReply)
+	if len(files) == 0 || len(saved) == 0 {
+		return false
+	}
+
+	attached := false
+	for _, file := range files {
+		_, err := s.rp.Attachment.CreateAttachment(models.Attachment{
+			TransactionID: saved[0].ID,
+			UserID:        saved[0].UserID,
+			FileKey:       file.FileKey,
+			FileName:      file.FileName,
+			ContentType:   file.ContentType,
+			Size:          file.Size,
+			Source:        models.RevisionSourceWhatsapp,
+		})
+		if err != nil {
+			logger.Error.Println("Error saving attachment:", err)
+			continue
+		}
+		attached = true
+	}
+	return attached
+}
+
+// discardDraftFiles removes the receipts of a cancelled draft from storage
+func (s *Service) discardDraftFiles(messageToReply *models.MessageToReply) {
+	if s.storage == nil {
+		return
+	}
+	for _, file := range draftAttachmentsFromDraft(messageToReply) {
+		if err := s.storage.DeleteFile(file.FileKey); err != nil {
+			logger.Error.Println("Error deleting receipt:", err)
+		}
+	}
+}
+
+func draftAttachmentsFromDraft(messageToReply *models.MessageToReply) []draftAttachment {
+	if messageToReply.Attachments == nil {
+		return nil
+	}
+	var files []draftAttachment
+	if err := json.Unmarshal(*messageToReply.Attachments, &files); err != nil {
+		logger.Error.Println("Error parsing draft attachments:", err)
+		return nil
+	}
+	return files
+}
+
+func receiptExtension(contentType string) string {
+	switch strings.ToLower(contentType) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "application/pdf":
+		return ".pdf"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type GetLedgersRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+type LedgerAnalyticsRequest struct {
+	PhoneNumber string     `form:"phone_number" validate:"required"`
+	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"`
+	EndDate     *time.Time `form:"end_date" validate:"omitempty" time_format:"2006-01-02"`
+}
+
+type LedgerMemberResponse struct {
+	UserID      uint      `json:"user_id"`
+	PhoneNumber string    `json:"phone_number"`
+	Name        string    `json:"name"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type LedgerResponse struct {
+	ID        uint                   `json:"id"`
+	ChatID    string                 `json:"chat_id"`
+	Name      string                 `json:"name"`
+	Members   []LedgerMemberResponse `json:"members"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type LedgerListResponse struct {
+	Ledgers []LedgerResponse `json:"ledgers"`
+}
+
+type MemberDataPoint struct {
+	UserID           uint        `json:"user_id"`
+	PhoneNumber      string      `json:"phone_number"`
+	Name             string      `json:"name"`
+	TotalIncome      types.Money `json:"total_income"`
+	TotalExpense     types.Money `json:"total_expense"`
+	ExpenseShare     float64     `json:"expense_share"` // Percentage of the ledger expense
+	TransactionCount int64       `json:"transaction_count"`
+}
+
+type LedgerCategoryDataPoint struct {
+	CategoryID   uint        `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	Type         string      `json:"type"`
+	TotalAmount  types.Money `json:"total_amount"`
+	Count        int64       `json:"count"`
+}
+
+type LedgerAnalyticsResponse struct {
+	LedgerID         uint                      `json:"ledger_id"`
+	TotalIncome      types.Money               `json:"total_income"`
+	TotalExpense     types.Money               `json:"total_expense"`
+	Balance          types.Money               `json:"balance"`
+	Currency         string                    `json:"currency"` // The requesting member's base currency, every amount is in it
+	TransactionCount int64                     `json:"transaction_count"`
+	Members          []MemberDataPoint         `json:"members"`
+	Categories       []LedgerCategoryDataPoint `json:"categories"`
+	StartDate        *time.Time                `json:"start_date"`
+	EndDate          *time.Time                `json:"end_date"`
+}
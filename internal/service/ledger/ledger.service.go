@@ -0,0 +1,190 @@
+package ledger
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository/transaction"
+	"pannypal/internal/service/ledger/dto"
+)
+
+func (s *Service) GetLedgersRequest(payload dto.GetLedgersRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	ledgers, err := s.rp.Ledger.GetLedgersByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get ledgers",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	ledgerResponses := make([]dto.LedgerResponse, len(ledgers))
+	for i, l := range ledgers {
+		ledgerResponses[i] = toLedgerResponse(l)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Ledgers retrieved successfully",
+		Data:    dto.LedgerListResponse{Ledgers: ledgerResponses},
+	})
+}
+
+func (s *Service) GetLedgerByIDRequest(id uint, phoneNumber string) *types.Response {
+	_, ledger, resp := s.getMemberLedger(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Ledger retrieved successfully",
+		Data:    toLedgerResponse(*ledger),
+	})
+}
+
+func (s *Service) GetLedgerAnalyticsRequest(id uint, payload dto.LedgerAnalyticsRequest) *types.Response {
+	user, ledger, resp := s.getMemberLedger(id, payload.PhoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	summary, err := s.rp.Transaction.GetTransactionsSummary(nil, transaction.SummaryFilters{
+		LedgerID:  &ledger.ID,
+		ViewerID:  &user.ID,
+		StartDate: payload.StartDate,
+		EndDate:   payload.EndDate,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get ledger summary",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	members, err := s.rp.Ledger.GetMemberAnalytics(ledger.ID, user.ID, payload.StartDate, payload.EndDate)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get member analytics",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	memberData := make([]dto.MemberDataPoint, len(members))
+	for i, m := range members {
+		expenseShare := float64(0)
+		if summary.TotalExpense > 0 {
+			expenseShare = (m.TotalExpense.Float64() / summary.TotalExpense.Float64()) * 100
+		}
+		memberData[i] = dto.MemberDataPoint{
+			UserID:           m.UserID,
+			PhoneNumber:      m.PhoneNumber,
+			Name:             m.Name,
+			TotalIncome:      m.TotalIncome,
+			TotalExpense:     m.TotalExpense,
+			ExpenseShare:     expenseShare,
+			TransactionCount: m.TransactionCount,
+		}
+	}
+
+	categoryData := make([]dto.LedgerCategoryDataPoint, len(summary.CategorySummary))
+	for i, c := range summary.CategorySummary {
+		categoryData[i] = dto.LedgerCategoryDataPoint{
+			CategoryID:   c.CategoryID,
+			CategoryName: c.CategoryName,
+			Type:         string(c.Type),
+			TotalAmount:  c.TotalAmount,
+			Count:        c.Count,
+		}
+	}
+
+	response := dto.LedgerAnalyticsResponse{
+		LedgerID:         ledger.ID,
+		TotalIncome:      summary.TotalIncome,
+		TotalExpense:     summary.TotalExpense,
+		Balance:          summary.TotalIncome - summary.TotalExpense,
+		Currency:         user.BaseCurrency,
+		TransactionCount: summary.TransactionCount,
+		Members:          memberData,
+		Categories:       categoryData,
+		StartDate:        payload.StartDate,
+		EndDate:          payload.EndDate,
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Ledger analytics retrieved successfully",
+		Data:    response,
+	})
+}
+
+// getMemberLedger loads the ledger and checks the phone number belongs to one of its members
+func (s *Service) getMemberLedger(id uint, phoneNumber string) (*models.User, *models.Ledger, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	ledger, err := s.rp.Ledger.GetLedgerByID(id)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Ledger not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	for _, member := range ledger.Members {
+		if member.UserID == user.ID {
+			return user, ledger, nil
+		}
+	}
+
+	return nil, nil, helper.ParseResponse(&types.Response{
+		Code:    http.StatusForbidden,
+		Message: "Access denied",
+		Data:    nil,
+	})
+}
+
+func toLedgerResponse(l models.Ledger) dto.LedgerResponse {
+	members := make([]dto.LedgerMemberResponse, len(l.Members))
+	for i, m := range l.Members {
+		members[i] = dto.LedgerMemberResponse{
+			UserID:      m.UserID,
+			PhoneNumber: m.User.PhoneNumber,
+			Name:        m.User.Name,
+			JoinedAt:    m.CreatedAt,
+		}
+	}
+
+	return dto.LedgerResponse{
+		ID:        l.ID,
+		ChatID:    l.ChatID,
+		Name:      l.Name,
+		Members:   members,
+		CreatedAt: l.CreatedAt,
+	}
+}
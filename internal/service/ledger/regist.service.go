@@ -0,0 +1,29 @@
+package ledger
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/ledger/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	GetLedgersRequest(payload dto.GetLedgersRequest) *types.Response
+	GetLedgerByIDRequest(id uint, phoneNumber string) *types.Response
+	GetLedgerAnalyticsRequest(id uint, payload dto.LedgerAnalyticsRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+// CreateLoanRequest adds a loan and builds its amortization schedule. InterestRate is the yearly rate
+// in percent, the installments fall due monthly from FirstDueDate on.
+type CreateLoanRequest struct {
+	PhoneNumber  string      `json:"phone_number" validate:"required"`
+	Name         string      `json:"name" validate:"required,max=100"`
+	Lender       string      `json:"lender" validate:"omitempty,max=100"`
+	Principal    types.Money `json:"principal" validate:"required,gt=0"`
+	Currency     string      `json:"currency" validate:"omitempty,iso4217"` // Defaults to the account's or the user's currency
+	InterestRate float64     `json:"interest_rate" validate:"gte=0,lte=100"`
+	InterestType string      `json:"interest_type" validate:"omitempty,oneof=ANNUITY FLAT"` // Defaults to ANNUITY
+	TenorMonths  int         `json:"tenor_months" validate:"required,min=1,max=600"`
+	FirstDueDate time.Time   `json:"first_due_date" validate:"required"`
+	AccountID    *uint       `json:"account_id" validate:"omitempty"`
+	CategoryID   *uint       `json:"category_id" validate:"omitempty"`
+}
+
+// UpdateLoanRequest changes the loan. Changing the terms rebuilds the schedule, which is only possible
+// while no installment is paid. 0 for account_id or category_id unlinks them.
+type UpdateLoanRequest struct {
+	Name         *string      `json:"name" validate:"omitempty,max=100"`
+	Lender       *string      `json:"lender" validate:"omitempty,max=100"`
+	Principal    *types.Money `json:"principal" validate:"omitempty,gt=0"`
+	InterestRate *float64     `json:"interest_rate" validate:"omitempty,gte=0,lte=100"`
+	InterestType *string      `json:"interest_type" validate:"omitempty,oneof=ANNUITY FLAT"`
+	TenorMonths  *int         `json:"tenor_months" validate:"omitempty,min=1,max=600"`
+	FirstDueDate *time.Time   `json:"first_due_date" validate:"omitempty"`
+	AccountID    *uint        `json:"account_id" validate:"omitempty"`
+	CategoryID   *uint        `json:"category_id" validate:"omitempty"`
+}
+
+type GetLoansRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+// PayInstallmentRequest links an expense transaction to the installment. Without transaction_id an
+// expense of the installment amount is recorded on the loan's account and category.
+type PayInstallmentRequest struct {
+	TransactionID *uint      `json:"transaction_id" validate:"omitempty"`
+	Date          *time.Time `json:"date" validate:"omitempty"` // Date of the recorded expense, defaults to today
+}
+
+type InstallmentResponse struct {
+	Number        int         `json:"number"`
+	DueDate       time.Time   `json:"due_date"`
+	Amount        types.Money `json:"amount"`
+	Principal     types.Money `json:"principal"`
+	Interest      types.Money `json:"interest"`
+	Balance       types.Money `json:"balance"` // Principal outstanding after this installment
+	TransactionID *uint       `json:"transaction_id"`
+	PaidAt        *time.Time  `json:"paid_at"`
+	IsPaid        bool        `json:"is_paid"`
+	IsOverdue     bool        `json:"is_overdue"`
+}
+
+type LoanResponse struct {
+	ID                   uint                    `json:"id"`
+	Name                 string                  `json:"name"`
+	Lender               string                  `json:"lender"`
+	Principal            types.Money             `json:"principal"`
+	Currency             string                  `json:"currency"`
+	InterestRate         float64                 `json:"interest_rate"`
+	InterestType         models.LoanInterestType `json:"interest_type"`
+	TenorMonths          int                     `json:"tenor_months"`
+	FirstDueDate         time.Time               `json:"first_due_date"`
+	AccountID            *uint                   `json:"account_id"`
+	AccountName          string                  `json:"account_name,omitempty"`
+	CategoryID           *uint                   `json:"category_id"`
+	CategoryName         string                  `json:"category_name,omitempty"`
+	MonthlyInstallment   types.Money             `json:"monthly_installment"` // Amount of the next installment, the first once paid off
+	TotalInterest        types.Money             `json:"total_interest"`
+	TotalPayment         types.Money             `json:"total_payment"`
+	PrincipalPaid        types.Money             `json:"principal_paid"`
+	InterestPaid         types.Money             `json:"interest_paid"`
+	OutstandingPrincipal types.Money             `json:"outstanding_principal"`
+	InstallmentsPaid     int                     `json:"installments_paid"`
+	InstallmentsLeft     int                     `json:"installments_left"`
+	NextInstallment      *InstallmentResponse    `json:"next_installment"` // Earliest unpaid installment
+	IsPaidOff            bool                    `json:"is_paid_off"`
+	PaidOffAt            *time.Time              `json:"paid_off_at"`
+	CreatedAt            time.Time               `json:"created_at"`
+	UpdatedAt            time.Time               `json:"updated_at"`
+}
+
+type LoanListResponse struct {
+	Loans       []LoanResponse         `json:"loans"`
+	Outstanding map[string]types.Money `json:"outstanding"` // Outstanding principal per currency
+}
+
+type LoanDetailResponse struct {
+	LoanResponse
+	Schedule []InstallmentResponse `json:"schedule"`
+}
@@ -0,0 +1,492 @@
+package loan
+
+import (
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/loan/dto"
+	"strings"
+	"time"
+)
+
+func (s *Service) CreateLoanRequest(payload dto.CreateLoanRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	loan := models.Loan{
+		UserID:       user.ID,
+		Name:         payload.Name,
+		Lender:       payload.Lender,
+		Principal:    payload.Principal,
+		Currency:     strings.ToUpper(payload.Currency),
+		InterestRate: payload.InterestRate,
+		InterestType: models.LoanInterestAnnuity,
+		TenorMonths:  payload.TenorMonths,
+		FirstDueDate: startOfDay(payload.FirstDueDate),
+	}
+	if payload.InterestType != "" {
+		loan.InterestType = models.LoanInterestType(payload.InterestType)
+	}
+	if resp := s.linkLoan(&loan, payload.AccountID, payload.CategoryID); resp != nil {
+		return resp
+	}
+	if loan.Currency == "" {
+		loan.Currency = user.BaseCurrency
+		if loan.Account != nil {
+			loan.Currency = loan.Account.Currency
+		}
+	}
+
+	created, err := s.rp.Loan.CreateLoan(loan, buildSchedule(loan))
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create loan",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.loanResponse(http.StatusCreated, "Loan created successfully", created.ID)
+}
+
+func (s *Service) GetLoansRequest(payload dto.GetLoansRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	loans, err := s.rp.Loan.GetLoansByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get loans",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	today := startOfDay(time.Now())
+	response := dto.LoanListResponse{
+		Loans:       make([]dto.LoanResponse, len(loans)),
+		Outstanding: map[string]types.Money{},
+	}
+	for i, loan := range loans {
+		response.Loans[i] = toLoanResponse(loan, today)
+		response.Outstanding[loan.Currency] += response.Loans[i].OutstandingPrincipal
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Loans retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetLoanByIDRequest(id uint, phoneNumber string) *types.Response {
+	_, resp := s.getUserLoan(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return s.loanResponse(http.StatusOK, "Loan retrieved successfully", id)
+}
+
+func (s *Service) UpdateLoanRequest(id uint, payload dto.UpdateLoanRequest, phoneNumber string) *types.Response {
+	loan, resp := s.getUserLoan(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.Name != nil {
+		loan.Name = *payload.Name
+	}
+	if payload.Lender != nil {
+		loan.Lender = *payload.Lender
+	}
+	if resp := s.linkLoan(loan, payload.AccountID, payload.CategoryID); resp != nil {
+		return resp
+	}
+
+	if !termsChanged(payload) {
+		if _, err := s.rp.Loan.UpdateLoan(*loan); err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update loan",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		return s.loanResponse(http.StatusOK, "Loan updated successfully", id)
+	}
+
+	for _, installment := range loan.Installments {
+		if installment.PaidAt != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusConflict,
+				Message: "Loan terms can not change once installments are paid",
+				Data:    nil,
+			})
+		}
+	}
+
+	if payload.Principal != nil {
+		loan.Principal = *payload.Principal
+	}
+	if payload.InterestRate != nil {
+		loan.InterestRate = *payload.InterestRate
+	}
+	if payload.InterestType != nil {
+		loan.InterestType = models.LoanInterestType(*payload.InterestType)
+	}
+	if payload.TenorMonths != nil {
+		loan.TenorMonths = *payload.TenorMonths
+	}
+	if payload.FirstDueDate != nil {
+		loan.FirstDueDate = startOfDay(*payload.FirstDueDate)
+	}
+
+	if _, err := s.rp.Loan.ReplaceSchedule(*loan, buildSchedule(*loan)); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update loan",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return s.loanResponse(http.StatusOK, "Loan updated successfully", id)
+}
+
+func (s *Service) DeleteLoanRequest(id uint, phoneNumber string) *types.Response {
+	_, resp := s.getUserLoan(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Loan.DeleteLoan(id); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete loan",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Loan deleted successfully",
+		Data:    nil,
+	})
+}
+
+func (s *Service) PayInstallmentRequest(id uint, number int, payload dto.PayInstallmentRequest, phoneNumber string) *types.Response {
+	loan, resp := s.getUserLoan(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	installment, err := s.rp.Loan.GetInstallment(id, number)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Installment not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if installment.PaidAt != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Installment is already paid",
+			Data:    nil,
+		})
+	}
+
+	var payment *models.Transaction
+	if payload.TransactionID != nil {
+		payment, resp = s.installmentTransaction(*payload.TransactionID, loan.UserID)
+		if resp != nil {
+			return resp
+		}
+	}
+
+	// A created payment and the installment it pays are saved together, a failure leaves neither behind
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if payment == nil {
+			date := time.Now()
+			if payload.Date != nil {
+				date = *payload.Date
+			}
+			created, err := rp.Transaction.CreateTransaction(models.Transaction{
+				UserID:          loan.UserID,
+				AccountID:       loan.AccountID,
+				CategoryID:      loan.CategoryID,
+				Type:            models.TypeExpense,
+				Amount:          installment.Amount,
+				Currency:        loan.Currency,
+				Description:     fmt.Sprintf("Cicilan %s ke-%d", loan.Name, installment.Number),
+				TransactionDate: date,
+			})
+			if err != nil {
+				return err
+			}
+			payment = created
+
+			actor := models.RevisionActor{
+				ActorType: models.RevisionActorUser,
+				ActorID:   &loan.UserID,
+				Source:    models.RevisionSourceLoan,
+			}
+			if err := rp.Revision.Record(models.RevisionActionCreate, actor, nil, created); err != nil {
+				return err
+			}
+		}
+
+		installment.TransactionID = &payment.ID
+		installment.PaidAt = &payment.TransactionDate
+		_, err := rp.Loan.UpdateInstallment(*installment)
+		return err
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to pay installment",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	s.syncPaidOff(loan.ID)
+
+	return s.loanResponse(http.StatusOK, "Installment paid successfully", id)
+}
+
+func (s *Service) UnpayInstallmentRequest(id uint, number int, phoneNumber string) *types.Response {
+	loan, resp := s.getUserLoan(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	installment, err := s.rp.Loan.GetInstallment(id, number)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Installment not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if installment.PaidAt == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Installment is not paid",
+			Data:    nil,
+		})
+	}
+
+	// The transaction stays as a plain expense, delete it separately when it was recorded by mistake
+	installment.TransactionID = nil
+	installment.PaidAt = nil
+	if _, err := s.rp.Loan.UpdateInstallment(*installment); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to unpay installment",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	s.syncPaidOff(loan.ID)
+
+	return s.loanResponse(http.StatusOK, "Installment payment removed successfully", id)
+}
+
+// getUserLoan loads the loan with its schedule, it must belong to the user with the phone number
+func (s *Service) getUserLoan(id uint, phoneNumber string) (*models.Loan, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	loan, err := s.rp.Loan.GetLoanByID(id)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Loan not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if loan.UserID != user.ID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	return loan, nil
+}
+
+// linkLoan links the loan to the user's account and expense category, 0 unlinks them
+func (s *Service) linkLoan(loan *models.Loan, accountID, categoryID *uint) *types.Response {
+	if accountID != nil {
+		loan.AccountID = nil
+		loan.Account = nil
+		if *accountID != 0 {
+			account, err := s.rp.Account.GetAccountByID(*accountID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Account not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if account.UserID != loan.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			loan.AccountID = &account.ID
+			loan.Account = account
+		}
+	}
+
+	if categoryID != nil {
+		loan.CategoryID = nil
+		loan.Category = nil
+		if *categoryID != 0 {
+			category, err := s.rp.Category.GetCategoryByID(*categoryID)
+			if err != nil {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusNotFound,
+					Message: "Category not found",
+					Data:    nil,
+					Error:   err,
+				})
+			}
+			if category.UserID == nil || *category.UserID != loan.UserID {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusForbidden,
+					Message: "Access denied",
+					Data:    nil,
+				})
+			}
+			if !category.Kind.Allows(models.TypeExpense) {
+				return helper.ParseResponse(&types.Response{
+					Code:    http.StatusBadRequest,
+					Message: "Installments need an expense category",
+					Data:    nil,
+				})
+			}
+			loan.CategoryID = &category.ID
+			loan.Category = category
+		}
+	}
+	return nil
+}
+
+// installmentTransaction checks the transaction can pay an installment, it has to be one of the user's
+// expenses that pays no other installment yet
+func (s *Service) installmentTransaction(transactionID, userID uint) (*models.Transaction, *types.Response) {
+	transaction, err := s.rp.Transaction.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if transaction.UserID != userID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	if transaction.Type != models.TypeExpense {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Installments are paid by expense transactions",
+			Data:    nil,
+		})
+	}
+	if linked, err := s.rp.Loan.GetInstallmentByTransactionID(transactionID); err == nil && linked != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "Transaction already pays an installment",
+			Data:    nil,
+		})
+	}
+	return transaction, nil
+}
+
+// syncPaidOff marks the loan paid off once every installment is paid and reopens it otherwise
+func (s *Service) syncPaidOff(id uint) {
+	loan, err := s.rp.Loan.GetLoanByID(id)
+	if err != nil {
+		logger.Error.Printf("Error loading loan %d: %v", id, err)
+		return
+	}
+
+	var lastPaid *time.Time
+	for _, installment := range loan.Installments {
+		if installment.PaidAt == nil {
+			lastPaid = nil
+			break
+		}
+		if lastPaid == nil || installment.PaidAt.After(*lastPaid) {
+			lastPaid = installment.PaidAt
+		}
+	}
+	if (lastPaid != nil) == (loan.PaidOffAt != nil) {
+		return
+	}
+
+	loan.PaidOffAt = lastPaid
+	if _, err := s.rp.Loan.UpdateLoan(*loan); err != nil {
+		logger.Error.Printf("Error updating paid off date of loan %d: %v", id, err)
+	}
+}
+
+// loanResponse loads the loan again with its schedule
+func (s *Service) loanResponse(code int, message string, id uint) *types.Response {
+	loan, err := s.rp.Loan.GetLoanByID(id)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get loan",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    code,
+		Message: message,
+		Data:    toLoanDetailResponse(*loan, startOfDay(time.Now())),
+	})
+}
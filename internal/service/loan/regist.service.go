@@ -0,0 +1,33 @@
+package loan
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/loan/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	CreateLoanRequest(payload dto.CreateLoanRequest) *types.Response
+	GetLoansRequest(payload dto.GetLoansRequest) *types.Response
+	GetLoanByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateLoanRequest(id uint, payload dto.UpdateLoanRequest, phoneNumber string) *types.Response
+	DeleteLoanRequest(id uint, phoneNumber string) *types.Response
+	PayInstallmentRequest(id uint, number int, payload dto.PayInstallmentRequest, phoneNumber string) *types.Response
+	UnpayInstallmentRequest(id uint, number int, phoneNumber string) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
@@ -0,0 +1,154 @@
+package loan
+
+import (
+	"math"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/service/loan/dto"
+	"time"
+)
+
+// buildSchedule amortizes the loan over its tenor. Annuity loans pay the same amount every month with
+// interest on the outstanding principal, flat loans pay the principal in equal parts with interest on the
+// original principal. The last installment settles what rounding left over.
+func buildSchedule(loan models.Loan) []models.LoanInstallment {
+	months := loan.TenorMonths
+	monthlyRate := loan.InterestRate / 100 / 12
+
+	weights := make([]int64, months)
+	for i := range weights {
+		weights[i] = 1
+	}
+	equalParts := loan.Principal.Allocate(weights)
+
+	var payment types.Money
+	if monthlyRate > 0 {
+		payment = loan.Principal.Mul(monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(months))))
+	}
+
+	installments := make([]models.LoanInstallment, months)
+	balance := loan.Principal
+	for i := range installments {
+		var principal, interest types.Money
+		if loan.InterestType == models.LoanInterestFlat || monthlyRate == 0 {
+			principal = equalParts[i]
+			interest = loan.Principal.Mul(monthlyRate)
+		} else {
+			interest = balance.Mul(monthlyRate)
+			principal = payment - interest
+			if i == months-1 || principal > balance {
+				principal = balance
+			}
+		}
+		balance -= principal
+
+		installments[i] = models.LoanInstallment{
+			Number:    i + 1,
+			DueDate:   dueDate(loan.FirstDueDate, i),
+			Amount:    principal + interest,
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		}
+	}
+	return installments
+}
+
+// dueDate is the first due date moved by the months, clamped to the month length so a loan due on the
+// 31st falls on the 28th or 29th in February
+func dueDate(first time.Time, months int) time.Time {
+	firstOfMonth := time.Date(first.Year(), first.Month()+time.Month(months), 1, 0, 0, 0, 0, first.Location())
+	day := first.Day()
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, first.Location())
+}
+
+// termsChanged tells whether the update touches what the schedule is built from
+func termsChanged(payload dto.UpdateLoanRequest) bool {
+	return payload.Principal != nil || payload.InterestRate != nil || payload.InterestType != nil ||
+		payload.TenorMonths != nil || payload.FirstDueDate != nil
+}
+
+func toLoanResponse(loan models.Loan, today time.Time) dto.LoanResponse {
+	response := dto.LoanResponse{
+		ID:                   loan.ID,
+		Name:                 loan.Name,
+		Lender:               loan.Lender,
+		Principal:            loan.Principal,
+		Currency:             loan.Currency,
+		InterestRate:         loan.InterestRate,
+		InterestType:         loan.InterestType,
+		TenorMonths:          loan.TenorMonths,
+		FirstDueDate:         loan.FirstDueDate,
+		AccountID:            loan.AccountID,
+		CategoryID:           loan.CategoryID,
+		OutstandingPrincipal: loan.Principal,
+		IsPaidOff:            loan.PaidOffAt != nil,
+		PaidOffAt:            loan.PaidOffAt,
+		CreatedAt:            loan.CreatedAt,
+		UpdatedAt:            loan.UpdatedAt,
+	}
+	if loan.Account != nil {
+		response.AccountName = loan.Account.Name
+	}
+	if loan.Category != nil {
+		response.CategoryName = loan.Category.Name
+	}
+
+	for _, installment := range loan.Installments {
+		response.TotalInterest += installment.Interest
+		response.TotalPayment += installment.Amount
+		if installment.PaidAt == nil {
+			response.InstallmentsLeft++
+			if response.NextInstallment == nil {
+				next := toInstallmentResponse(installment, today)
+				response.NextInstallment = &next
+			}
+			continue
+		}
+		response.InstallmentsPaid++
+		response.PrincipalPaid += installment.Principal
+		response.InterestPaid += installment.Interest
+	}
+	response.OutstandingPrincipal -= response.PrincipalPaid
+
+	switch {
+	case response.NextInstallment != nil:
+		response.MonthlyInstallment = response.NextInstallment.Amount
+	case len(loan.Installments) > 0:
+		response.MonthlyInstallment = loan.Installments[0].Amount
+	}
+	return response
+}
+
+func toLoanDetailResponse(loan models.Loan, today time.Time) dto.LoanDetailResponse {
+	response := dto.LoanDetailResponse{
+		LoanResponse: toLoanResponse(loan, today),
+		Schedule:     make([]dto.InstallmentResponse, len(loan.Installments)),
+	}
+	for i, installment := range loan.Installments {
+		response.Schedule[i] = toInstallmentResponse(installment, today)
+	}
+	return response
+}
+
+func toInstallmentResponse(installment models.LoanInstallment, today time.Time) dto.InstallmentResponse {
+	return dto.InstallmentResponse{
+		Number:        installment.Number,
+		DueDate:       installment.DueDate,
+		Amount:        installment.Amount,
+		Principal:     installment.Principal,
+		Interest:      installment.Interest,
+		Balance:       installment.Balance,
+		TransactionID: installment.TransactionID,
+		PaidAt:        installment.PaidAt,
+		IsPaid:        installment.PaidAt != nil,
+		IsOverdue:     installment.PaidAt == nil && installment.DueDate.Before(today),
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,93 @@
+package loan
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"testing"
+	"time"
+)
+
+func TestBuildSchedule(t *testing.T) {
+	firstDue := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		loan          models.Loan
+		wantFirst     models.LoanInstallment
+		wantLastTotal types.Money
+	}{
+		{
+			name:          "no interest splits the principal evenly",
+			loan:          models.Loan{Principal: 1000, TenorMonths: 3, InterestType: models.LoanInterestAnnuity},
+			wantFirst:     models.LoanInstallment{Amount: 334, Principal: 334, Interest: 0, Balance: 666},
+			wantLastTotal: 333,
+		},
+		{
+			name:          "flat interest on the original principal",
+			loan:          models.Loan{Principal: 1200000, InterestRate: 12, TenorMonths: 12, InterestType: models.LoanInterestFlat},
+			wantFirst:     models.LoanInstallment{Amount: 112000, Principal: 100000, Interest: 12000, Balance: 1100000},
+			wantLastTotal: 112000,
+		},
+		{
+			name:          "annuity pays interest on the outstanding principal",
+			loan:          models.Loan{Principal: 1000000, InterestRate: 12, TenorMonths: 12, InterestType: models.LoanInterestAnnuity},
+			wantFirst:     models.LoanInstallment{Amount: 88849, Principal: 78849, Interest: 10000, Balance: 921151},
+			wantLastTotal: 88847,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.loan.FirstDueDate = firstDue
+		schedule := buildSchedule(tt.loan)
+		if len(schedule) != tt.loan.TenorMonths {
+			t.Errorf("%s: %d installments, want %d", tt.name, len(schedule), tt.loan.TenorMonths)
+			continue
+		}
+
+		first := schedule[0]
+		if first.Number != 1 || first.Amount != tt.wantFirst.Amount || first.Principal != tt.wantFirst.Principal ||
+			first.Interest != tt.wantFirst.Interest || first.Balance != tt.wantFirst.Balance {
+			t.Errorf("%s: first installment = %+v, want %+v", tt.name, first, tt.wantFirst)
+		}
+
+		last := schedule[len(schedule)-1]
+		if last.Balance != 0 {
+			t.Errorf("%s: last balance = %d, want 0", tt.name, last.Balance)
+		}
+		if last.Amount != tt.wantLastTotal {
+			t.Errorf("%s: last installment = %d, want %d", tt.name, last.Amount, tt.wantLastTotal)
+		}
+
+		principal := types.Money(0)
+		for _, installment := range schedule {
+			principal += installment.Principal
+			if installment.Amount != installment.Principal+installment.Interest {
+				t.Errorf("%s: installment %d amount %d is not principal plus interest", tt.name, installment.Number, installment.Amount)
+			}
+		}
+		if principal != tt.loan.Principal {
+			t.Errorf("%s: principal paid = %d, want %d", tt.name, principal, tt.loan.Principal)
+		}
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	first := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		months int
+		want   time.Time
+	}{
+		{0, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{1, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{2, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{3, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{13, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := dueDate(first, tt.months); !got.Equal(tt.want) {
+			t.Errorf("dueDate(%d) = %s, want %s", tt.months, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
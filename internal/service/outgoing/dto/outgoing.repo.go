@@ -11,6 +11,14 @@ type PayloadOutgoing struct {
 	AccountId      string  `json:"account_id"`
 	To             string  `json:"to"`
 	Participant    *string `json:"participant,omitempty"`
+	Media          *Media  `json:"media,omitempty"`
+}
+
+// Media is the attachment sent with IMAGE/image messages, Message becomes its caption
+type Media struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mimetype"`
+	FileName string `json:"filename,omitempty"`
 }
 
 type ReqWahaText struct {
@@ -29,6 +37,34 @@ func (p *PayloadOutgoing) ToReqWahaText(account models.AccountBot) *ReqWahaText
 	}
 }
 
+type ReqWahaFile struct {
+	MimeType string `json:"mimetype"`
+	URL      string `json:"url"`
+	FileName string `json:"filename,omitempty"`
+}
+
+type ReqWahaImage struct {
+	ChatID  string      `json:"chatId"`
+	File    ReqWahaFile `json:"file"`
+	Caption string      `json:"caption,omitempty"`
+	ReplyTo *string     `json:"reply_to,omitempty"`
+	Session string      `json:"session"`
+}
+
+func (p *PayloadOutgoing) ToReqWahaImage(account models.AccountBot) *ReqWahaImage {
+	return &ReqWahaImage{
+		ChatID: p.To,
+		File: ReqWahaFile{
+			MimeType: p.Media.MimeType,
+			URL:      p.Media.URL,
+			FileName: p.Media.FileName,
+		},
+		Caption: p.Message,
+		ReplyTo: p.ReplyToMessage,
+		Session: account.SessionID,
+	}
+}
+
 type ResponseOutgoing struct {
 	Message string `json:"message"`
 	Id      string `json:"id"`
@@ -53,3 +89,29 @@ func (p *PayloadOutgoing) ToReqBaileysText(account models.AccountBot) *ReqBailey
 		Participant: p.Participant,
 	}
 }
+
+type ReqBaileysMedia struct {
+	Session     string  `json:"sessionId"`
+	Type        string  `json:"type"`
+	ChatID      string  `json:"to"`
+	URL         string  `json:"url"`
+	MimeType    string  `json:"mimetype"`
+	FileName    string  `json:"fileName,omitempty"`
+	Caption     string  `json:"caption,omitempty"`
+	ReplyTo     *string `json:"replyTo,omitempty"`
+	Participant *string `json:"participant,omitempty"`
+}
+
+func (p *PayloadOutgoing) ToReqBaileysMedia(account models.AccountBot) *ReqBaileysMedia {
+	return &ReqBaileysMedia{
+		ChatID:      p.To,
+		URL:         p.Media.URL,
+		MimeType:    p.Media.MimeType,
+		FileName:    p.Media.FileName,
+		Caption:     p.Message,
+		ReplyTo:     p.ReplyToMessage,
+		Session:     account.SessionID,
+		Type:        p.Type,
+		Participant: p.Participant,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"pannypal/internal/common/models"
 	"pannypal/internal/pkg/helper"
 	"pannypal/internal/service/outgoing/dto"
+	"strings"
 )
 
 func (s *Service) HandleWebhookEventWaha(payload dto.PayloadOutgoing) (*dto.ResponseOutgoing, error) {
@@ -29,12 +30,106 @@ func (s *Service) HandleWebhookEventWaha(payload dto.PayloadOutgoing) (*dto.Resp
 	}
 }
 
+// SendText starts a conversation instead of replying to one, the message type follows the bot
+func (s *Service) SendText(accountID, to, message string) (*dto.ResponseOutgoing, error) {
+	accountBot, err := s.rp.Bot.GetBotByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountBot == nil {
+		return nil, fmt.Errorf("bot account %s not found", accountID)
+	}
+
+	payload := dto.PayloadOutgoing{
+		Message:   message,
+		AccountId: accountID,
+		To:        to,
+	}
+
+	switch accountBot.BotType {
+	case enum.BotTypeWaha:
+		payload.Type = "TEXT"
+		return s.handleWebhookEventWaha(accountBot, payload)
+	case enum.BotTypeBaileys:
+		payload.Type = "text"
+		return s.handleWebhookEventBaileys(accountBot, payload)
+	default:
+		return nil, fmt.Errorf("unsupported bot type %s", accountBot.BotType)
+	}
+}
+
+// DownloadMedia fetches the media of an incoming message from the bot following the download
+// instructions it sent with the webhook, endpoints are relative to the bot base URL
+func (s *Service) DownloadMedia(accountID string, method string, endpoint string, body interface{}) ([]byte, error) {
+	accountBot, err := s.rp.Bot.GetBotByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountBot == nil {
+		return nil, fmt.Errorf("bot account %s not found", accountID)
+	}
+
+	headers := http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+	switch accountBot.BotType {
+	case enum.BotTypeWaha:
+		headers.Set("X-Api-Key", accountBot.Key)
+	case enum.BotTypeBaileys:
+		headers.Set("Authorization", "Bearer "+accountBot.Key)
+	}
+
+	url := endpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		url = strings.TrimRight(accountBot.BaseURL, "/") + "/" + strings.TrimLeft(endpoint, "/")
+	}
+
+	httpMethod := enum.HTTPMethodEnum(strings.ToUpper(method))
+	if httpMethod == "" {
+		httpMethod = enum.GET
+	}
+
+	resp, err := helper.HTTPRequest(&helper.HTTPRequestPayload{
+		Method: httpMethod,
+		URL:    url,
+		Body:   body,
+	},
+		&helper.HTTPRequestConfig{
+			Headers: headers,
+			Ctx:     s.ctx,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("media download failed with status %d", resp.StatusCode)
+	}
+
+	data, ok := resp.Data.([]byte)
+	if !ok || len(data) == 0 {
+		return nil, fmt.Errorf("media download returned no file")
+	}
+
+	return data, nil
+}
+
 func (s *Service) handleWebhookEventWaha(accountBot *models.AccountBot, payload dto.PayloadOutgoing) (*dto.ResponseOutgoing, error) {
 	var req interface{}
+	var endpoint string
 
 	switch payload.Type {
 	case "TEXT":
 		req = payload.ToReqWahaText(*accountBot)
+		endpoint = "/api/sendText"
+	case "IMAGE":
+		if payload.Media == nil {
+			return nil, fmt.Errorf("media is required for image message")
+		}
+		req = payload.ToReqWahaImage(*accountBot)
+		endpoint = "/api/sendImage"
 	default:
 		return nil, nil
 	}
@@ -45,7 +140,7 @@ func (s *Service) handleWebhookEventWaha(accountBot *models.AccountBot, payload
 
 	resp, err := helper.HTTPRequest(&helper.HTTPRequestPayload{
 		Method: enum.POST,
-		URL:    accountBot.BaseURL + "/api/sendText",
+		URL:    accountBot.BaseURL + endpoint,
 		Body:   req,
 	},
 		&helper.HTTPRequestConfig{
@@ -80,6 +175,11 @@ func (s *Service) handleWebhookEventBaileys(accountBot *models.AccountBot, paylo
 	switch payload.Type {
 	case "text":
 		req = payload.ToReqBaileysText(*accountBot)
+	case "image":
+		if payload.Media == nil {
+			return nil, fmt.Errorf("media is required for image message")
+		}
+		req = payload.ToReqBaileysMedia(*accountBot)
 	default:
 		return nil, nil
 	}
@@ -14,6 +14,8 @@ type Service struct {
 }
 type IService interface {
 	HandleWebhookEventWaha(payload dto.PayloadOutgoing) (*dto.ResponseOutgoing, error)
+	SendText(accountID, to, message string) (*dto.ResponseOutgoing, error)
+	DownloadMedia(accountID string, method string, endpoint string, body interface{}) ([]byte, error)
 }
 
 func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
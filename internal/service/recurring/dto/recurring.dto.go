@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type CreateRecurringRuleRequest struct {
+	PhoneNumber    string      `json:"phone_number" validate:"required"`
+	Type           string      `json:"type" validate:"required,oneof=INCOME EXPENSE"`
+	Amount         types.Money `json:"amount" validate:"required,gt=0"`
+	Currency       string      `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID     *uint       `json:"category_id" validate:"omitempty"`
+	AccountID      *uint       `json:"account_id" validate:"omitempty"`
+	Description    string      `json:"description" validate:"required"`
+	Frequency      string      `json:"frequency" validate:"required,oneof=WEEKLY MONTHLY YEARLY"`
+	Interval       int         `json:"interval" validate:"omitempty,min=1,max=12"` // Every N weeks, months or years, defaults to 1
+	DayOfMonth     *int        `json:"day_of_month" validate:"omitempty,min=1,max=31"`
+	DayOfWeek      *int        `json:"day_of_week" validate:"omitempty,min=0,max=6"`
+	MonthOfYear    *int        `json:"month_of_year" validate:"omitempty,min=1,max=12"`
+	StartDate      *time.Time  `json:"start_date" validate:"omitempty"` // Defaults to today
+	EndDate        *time.Time  `json:"end_date" validate:"omitempty"`
+	Mode           string      `json:"mode" validate:"omitempty,oneof=AUTO DRAFT"` // Defaults to AUTO
+	IsSubscription bool        `json:"is_subscription" validate:"omitempty"`
+}
+
+type UpdateRecurringRuleRequest struct {
+	Type           *string      `json:"type" validate:"omitempty,oneof=INCOME EXPENSE"`
+	Amount         *types.Money `json:"amount" validate:"omitempty,gt=0"`
+	Currency       *string      `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID     *uint        `json:"category_id" validate:"omitempty"`
+	AccountID      *uint        `json:"account_id" validate:"omitempty"`
+	Description    *string      `json:"description" validate:"omitempty"`
+	Frequency      *string      `json:"frequency" validate:"omitempty,oneof=WEEKLY MONTHLY YEARLY"`
+	Interval       *int         `json:"interval" validate:"omitempty,min=1,max=12"`
+	DayOfMonth     *int         `json:"day_of_month" validate:"omitempty,min=1,max=31"`
+	DayOfWeek      *int         `json:"day_of_week" validate:"omitempty,min=0,max=6"`
+	MonthOfYear    *int         `json:"month_of_year" validate:"omitempty,min=1,max=12"`
+	EndDate        *time.Time   `json:"end_date" validate:"omitempty"`
+	Mode           *string      `json:"mode" validate:"omitempty,oneof=AUTO DRAFT"`
+	IsActive       *bool        `json:"is_active" validate:"omitempty"`
+	IsSubscription *bool        `json:"is_subscription" validate:"omitempty"`
+}
+
+type GetRecurringRulesRequest struct {
+	PhoneNumber    string `form:"phone_number" validate:"required"`
+	IsActive       *bool  `form:"is_active" validate:"omitempty"`
+	IsSubscription *bool  `form:"is_subscription" validate:"omitempty"`
+}
+
+type DetectSubscriptionsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	Months      int    `form:"months" validate:"omitempty,min=2,max=24" default:"6"` // History to scan
+}
+
+type RecurringRuleResponse struct {
+	ID             uint                      `json:"id"`
+	Type           models.TransactionType    `json:"type"`
+	Amount         types.Money               `json:"amount"`
+	Currency       string                    `json:"currency"`
+	CategoryID     *uint                     `json:"category_id"`
+	CategoryName   string                    `json:"category_name"`
+	AccountID      *uint                     `json:"account_id"`
+	Description    string                    `json:"description"`
+	Frequency      models.RecurringFrequency `json:"frequency"`
+	Interval       int                       `json:"interval"`
+	DayOfMonth     *int                      `json:"day_of_month"`
+	DayOfWeek      *int                      `json:"day_of_week"`
+	MonthOfYear    *int                      `json:"month_of_year"`
+	StartDate      time.Time                 `json:"start_date"`
+	EndDate        *time.Time                `json:"end_date"`
+	NextRunAt      time.Time                 `json:"next_run_at"`
+	LastRunAt      *time.Time                `json:"last_run_at"`
+	Mode           models.RecurringMode      `json:"mode"`
+	IsActive       bool                      `json:"is_active"`
+	IsSubscription bool                      `json:"is_subscription"`
+	MonthlyAmount  types.Money               `json:"monthly_amount"` // Amount normalized to a month
+}
+
+type RecurringRuleListResponse struct {
+	Rules          []RecurringRuleResponse `json:"rules"`
+	MonthlyExpense types.Money             `json:"monthly_expense"` // Active recurring expenses per month
+	MonthlyIncome  types.Money             `json:"monthly_income"`
+}
+
+type SubscriptionCandidate struct {
+	Description    string                    `json:"description"`
+	Amount         types.Money               `json:"amount"`
+	CategoryID     *uint                     `json:"category_id"`
+	AccountID      *uint                     `json:"account_id"`
+	Frequency      models.RecurringFrequency `json:"frequency"`
+	DayOfMonth     *int                      `json:"day_of_month"`
+	DayOfWeek      *int                      `json:"day_of_week"`
+	Occurrences    int64                     `json:"occurrences"`
+	FirstDate      time.Time                 `json:"first_date"`
+	LastDate       time.Time                 `json:"last_date"`
+	NextExpected   time.Time                 `json:"next_expected"`
+	AlreadyTracked bool                      `json:"already_tracked"`
+}
+
+type SubscriptionCandidateListResponse struct {
+	Candidates []SubscriptionCandidate `json:"candidates"`
+}
@@ -0,0 +1,410 @@
+package recurring
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository/recurring"
+	"pannypal/internal/service/recurring/dto"
+	"strings"
+	"time"
+)
+
+func (s *Service) CreateRecurringRuleRequest(payload dto.CreateRecurringRuleRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	startDate := time.Now()
+	if payload.StartDate != nil {
+		startDate = *payload.StartDate
+	}
+
+	rule := models.RecurringRule{
+		UserID:         user.ID,
+		CategoryID:     payload.CategoryID,
+		AccountID:      payload.AccountID,
+		Type:           models.TransactionType(payload.Type),
+		Amount:         payload.Amount,
+		Currency:       strings.ToUpper(payload.Currency),
+		Description:    payload.Description,
+		Frequency:      models.RecurringFrequency(payload.Frequency),
+		Interval:       payload.Interval,
+		DayOfMonth:     payload.DayOfMonth,
+		DayOfWeek:      payload.DayOfWeek,
+		MonthOfYear:    payload.MonthOfYear,
+		StartDate:      startOfDay(startDate),
+		EndDate:        payload.EndDate,
+		Mode:           models.RecurringModeAuto,
+		IsActive:       true,
+		IsSubscription: payload.IsSubscription,
+	}
+	if payload.Mode != "" {
+		rule.Mode = models.RecurringMode(payload.Mode)
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	fillScheduleDefaults(&rule)
+	rule.NextRunAt = firstOccurrence(rule)
+
+	if resp := s.validateRule(*user, rule); resp != nil {
+		return resp
+	}
+
+	created, err := s.rp.Recurring.CreateRule(rule)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create recurring rule",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Recurring rule created successfully",
+		Data:    toRuleResponse(*created),
+	})
+}
+
+func (s *Service) GetRecurringRulesRequest(payload dto.GetRecurringRulesRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	rules, err := s.rp.Recurring.GetRulesByUserID(user.ID, recurring.RuleFilters{
+		IsActive:       payload.IsActive,
+		IsSubscription: payload.IsSubscription,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get recurring rules",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.RecurringRuleListResponse{Rules: make([]dto.RecurringRuleResponse, len(rules))}
+	for i, rule := range rules {
+		response.Rules[i] = toRuleResponse(rule)
+		if !rule.IsActive {
+			continue
+		}
+		if rule.Type == models.TypeIncome {
+			response.MonthlyIncome += response.Rules[i].MonthlyAmount
+		} else {
+			response.MonthlyExpense += response.Rules[i].MonthlyAmount
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Recurring rules retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) GetRecurringRuleByIDRequest(id uint, phoneNumber string) *types.Response {
+	_, rule, resp := s.getUserRule(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Recurring rule retrieved successfully",
+		Data:    toRuleResponse(*rule),
+	})
+}
+
+func (s *Service) UpdateRecurringRuleRequest(id uint, payload dto.UpdateRecurringRuleRequest, phoneNumber string) *types.Response {
+	user, rule, resp := s.getUserRule(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	scheduleChanged := payload.Frequency != nil || payload.Interval != nil || payload.DayOfMonth != nil ||
+		payload.DayOfWeek != nil || payload.MonthOfYear != nil || (payload.IsActive != nil && *payload.IsActive && !rule.IsActive)
+
+	// Update fields if provided
+	if payload.Type != nil {
+		rule.Type = models.TransactionType(*payload.Type)
+	}
+	if payload.Amount != nil {
+		rule.Amount = *payload.Amount
+	}
+	if payload.Currency != nil {
+		rule.Currency = strings.ToUpper(*payload.Currency)
+	}
+	if payload.CategoryID != nil {
+		rule.CategoryID = payload.CategoryID
+	}
+	if payload.AccountID != nil {
+		rule.AccountID = payload.AccountID
+	}
+	if payload.Description != nil {
+		rule.Description = *payload.Description
+	}
+	if payload.Frequency != nil {
+		rule.Frequency = models.RecurringFrequency(*payload.Frequency)
+	}
+	if payload.Interval != nil {
+		rule.Interval = *payload.Interval
+	}
+	if payload.DayOfMonth != nil {
+		rule.DayOfMonth = payload.DayOfMonth
+	}
+	if payload.DayOfWeek != nil {
+		rule.DayOfWeek = payload.DayOfWeek
+	}
+	if payload.MonthOfYear != nil {
+		rule.MonthOfYear = payload.MonthOfYear
+	}
+	if payload.EndDate != nil {
+		rule.EndDate = payload.EndDate
+	}
+	if payload.Mode != nil {
+		rule.Mode = models.RecurringMode(*payload.Mode)
+	}
+	if payload.IsActive != nil {
+		rule.IsActive = *payload.IsActive
+	}
+	if payload.IsSubscription != nil {
+		rule.IsSubscription = *payload.IsSubscription
+	}
+
+	// A new schedule starts from today, past occurrences are not replayed
+	if scheduleChanged {
+		fillScheduleDefaults(rule)
+		from := *rule
+		if today := startOfDay(time.Now()); from.StartDate.Before(today) {
+			from.StartDate = today
+		}
+		rule.NextRunAt = firstOccurrence(from)
+	}
+
+	if resp := s.validateRule(*user, *rule); resp != nil {
+		return resp
+	}
+
+	rule.Category = models.Category{}
+	updated, err := s.rp.Recurring.UpdateRule(*rule)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update recurring rule",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Recurring rule updated successfully",
+		Data:    toRuleResponse(*updated),
+	})
+}
+
+func (s *Service) DeleteRecurringRuleRequest(id uint, phoneNumber string) *types.Response {
+	_, rule, resp := s.getUserRule(id, phoneNumber)
+	if resp != nil {
+		return resp
+	}
+
+	if err := s.rp.Recurring.DeleteRule(rule.ID); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete recurring rule",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Recurring rule deleted successfully",
+		Data:    nil,
+	})
+}
+
+// validateRule checks the rule can run: a sensible end date, accounts and a category of the owner
+// and a known WhatsApp chat for drafts
+func (s *Service) validateRule(user models.User, rule models.RecurringRule) *types.Response {
+	if rule.EndDate != nil && rule.EndDate.Before(rule.StartDate) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "End date must be after start date",
+			Data:    nil,
+		})
+	}
+
+	if rule.Mode == models.RecurringModeDraft && user.WhatsappChatID == "" {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Draft mode needs a WhatsApp chat with the bot, use AUTO mode or message the bot first",
+			Data:    nil,
+		})
+	}
+
+	if rule.AccountID != nil {
+		account, err := s.rp.Account.GetAccountByID(*rule.AccountID)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "Account not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		if account.UserID != user.ID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusForbidden,
+				Message: "Access denied",
+				Data:    nil,
+			})
+		}
+	}
+
+	if rule.CategoryID != nil {
+		category, err := s.rp.Category.GetCategoryByID(*rule.CategoryID)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "Category not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		if category.UserID == nil || *category.UserID != user.ID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusForbidden,
+				Message: "Access denied",
+				Data:    nil,
+			})
+		}
+		if !category.Kind.Allows(rule.Type) {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Category " + category.Name + " cannot hold " + string(rule.Type) + " transactions",
+				Data:    nil,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) getUserRule(id uint, phoneNumber string) (*models.User, *models.RecurringRule, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	rule, err := s.rp.Recurring.GetRuleByID(id)
+	if err != nil {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Recurring rule not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if rule.UserID != user.ID {
+		return nil, nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	return user, rule, nil
+}
+
+// fillScheduleDefaults pins the schedule to the start date when the day is not given,
+// so later runs do not drift
+func fillScheduleDefaults(rule *models.RecurringRule) {
+	start := rule.StartDate
+	switch rule.Frequency {
+	case models.FrequencyWeekly:
+		if rule.DayOfWeek == nil {
+			weekday := int(start.Weekday())
+			rule.DayOfWeek = &weekday
+		}
+	case models.FrequencyYearly:
+		if rule.MonthOfYear == nil {
+			month := int(start.Month())
+			rule.MonthOfYear = &month
+		}
+		fallthrough
+	default:
+		if rule.DayOfMonth == nil {
+			day := start.Day()
+			rule.DayOfMonth = &day
+		}
+	}
+}
+
+// monthlyAmount spreads the rule amount over a month for totals
+func monthlyAmount(rule models.RecurringRule) types.Money {
+	interval := float64(rule.Interval)
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch rule.Frequency {
+	case models.FrequencyWeekly:
+		return rule.Amount.Mul(52.0 / 12 / interval)
+	case models.FrequencyYearly:
+		return rule.Amount.Mul(1.0 / 12 / interval)
+	default:
+		return rule.Amount.Mul(1 / interval)
+	}
+}
+
+func toRuleResponse(rule models.RecurringRule) dto.RecurringRuleResponse {
+	return dto.RecurringRuleResponse{
+		ID:             rule.ID,
+		Type:           rule.Type,
+		Amount:         rule.Amount,
+		Currency:       rule.Currency,
+		CategoryID:     rule.CategoryID,
+		CategoryName:   rule.Category.Name,
+		AccountID:      rule.AccountID,
+		Description:    rule.Description,
+		Frequency:      rule.Frequency,
+		Interval:       rule.Interval,
+		DayOfMonth:     rule.DayOfMonth,
+		DayOfWeek:      rule.DayOfWeek,
+		MonthOfYear:    rule.MonthOfYear,
+		StartDate:      rule.StartDate,
+		EndDate:        rule.EndDate,
+		NextRunAt:      rule.NextRunAt,
+		LastRunAt:      rule.LastRunAt,
+		Mode:           rule.Mode,
+		IsActive:       rule.IsActive,
+		IsSubscription: rule.IsSubscription,
+		MonthlyAmount:  monthlyAmount(rule),
+	}
+}
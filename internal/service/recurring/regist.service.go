@@ -0,0 +1,38 @@
+package recurring
+
+import (
+	"context"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/outgoing"
+	"pannypal/internal/service/recurring/dto"
+	"time"
+)
+
+type Service struct {
+	ctx      context.Context
+	redis    redis.IRedis
+	rp       repository.IRepository
+	outgoing outgoing.IService
+}
+
+type IService interface {
+	ProcessDueRules(now time.Time) error
+
+	CreateRecurringRuleRequest(payload dto.CreateRecurringRuleRequest) *types.Response
+	GetRecurringRulesRequest(payload dto.GetRecurringRulesRequest) *types.Response
+	GetRecurringRuleByIDRequest(id uint, phoneNumber string) *types.Response
+	UpdateRecurringRuleRequest(id uint, payload dto.UpdateRecurringRuleRequest, phoneNumber string) *types.Response
+	DeleteRecurringRuleRequest(id uint, phoneNumber string) *types.Response
+	DetectSubscriptionsRequest(payload dto.DetectSubscriptionsRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, outgoing outgoing.IService) IService {
+	return &Service{
+		ctx:      ctx,
+		redis:    redis,
+		rp:       repository,
+		outgoing: outgoing,
+	}
+}
@@ -0,0 +1,70 @@
+package recurring
+
+import (
+	"pannypal/internal/common/models"
+	"time"
+)
+
+// firstOccurrence returns the first date on or after the start date that matches the rule
+func firstOccurrence(rule models.RecurringRule) time.Time {
+	start := startOfDay(rule.StartDate)
+
+	switch rule.Frequency {
+	case models.FrequencyWeekly:
+		offset := (intValue(rule.DayOfWeek, int(start.Weekday())) - int(start.Weekday()) + 7) % 7
+		return start.AddDate(0, 0, offset)
+
+	case models.FrequencyYearly:
+		month := time.Month(intValue(rule.MonthOfYear, int(start.Month())))
+		candidate := dateInMonth(start.Year(), month, intValue(rule.DayOfMonth, start.Day()), start.Location())
+		if candidate.Before(start) {
+			candidate = dateInMonth(start.Year()+1, month, intValue(rule.DayOfMonth, start.Day()), start.Location())
+		}
+		return candidate
+
+	default:
+		candidate := dateInMonth(start.Year(), start.Month(), intValue(rule.DayOfMonth, start.Day()), start.Location())
+		if candidate.Before(start) {
+			candidate = dateInMonth(start.Year(), start.Month()+1, intValue(rule.DayOfMonth, start.Day()), start.Location())
+		}
+		return candidate
+	}
+}
+
+// nextOccurrence returns the occurrence following the given one
+func nextOccurrence(rule models.RecurringRule, current time.Time) time.Time {
+	interval := rule.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch rule.Frequency {
+	case models.FrequencyWeekly:
+		return current.AddDate(0, 0, 7*interval)
+	case models.FrequencyYearly:
+		return dateInMonth(current.Year()+interval, current.Month(), intValue(rule.DayOfMonth, current.Day()), current.Location())
+	default:
+		return dateInMonth(current.Year(), current.Month()+time.Month(interval), intValue(rule.DayOfMonth, current.Day()), current.Location())
+	}
+}
+
+// dateInMonth builds a date clamping the day to the month length, day 31 in February is the 28th or 29th
+func dateInMonth(year int, month time.Month, day int, loc *time.Location) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, loc)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func intValue(value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
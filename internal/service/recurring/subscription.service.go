@@ -0,0 +1,115 @@
+package recurring
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository/recurring"
+	"pannypal/internal/service/recurring/dto"
+	"strings"
+	"time"
+)
+
+// DetectSubscriptionsRequest looks for expenses repeating with the same description and amount
+// at a weekly, monthly or yearly rhythm
+func (s *Service) DetectSubscriptionsRequest(payload dto.DetectSubscriptionsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	months := payload.Months
+	if months == 0 {
+		months = 6
+	}
+	now := time.Now()
+
+	repeating, err := s.rp.Recurring.GetRepeatingTransactions(user.ID, now.AddDate(0, -months, 0), 2)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to detect subscriptions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	rules, err := s.rp.Recurring.GetRulesByUserID(user.ID, recurring.RuleFilters{})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get recurring rules",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	tracked := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		tracked[strings.ToLower(strings.TrimSpace(rule.Description))] = true
+	}
+
+	response := dto.SubscriptionCandidateListResponse{Candidates: []dto.SubscriptionCandidate{}}
+	for _, data := range repeating {
+		frequency, ok := detectFrequency(data)
+		if !ok {
+			continue
+		}
+
+		// A rhythm that stopped a while ago is no longer a running subscription
+		staleAfter := time.Duration(data.AvgGapDays*1.5*24) * time.Hour
+		if data.LastDate.Add(staleAfter).Before(now) {
+			continue
+		}
+
+		candidate := dto.SubscriptionCandidate{
+			Description:    data.Description,
+			Amount:         data.Amount,
+			CategoryID:     data.CategoryID,
+			AccountID:      data.AccountID,
+			Frequency:      frequency,
+			Occurrences:    data.Count,
+			FirstDate:      data.FirstDate,
+			LastDate:       data.LastDate,
+			AlreadyTracked: tracked[strings.ToLower(strings.TrimSpace(data.Description))],
+		}
+
+		rule := models.RecurringRule{Frequency: frequency, Interval: 1}
+		switch frequency {
+		case models.FrequencyWeekly:
+			weekday := int(data.LastDate.Weekday())
+			candidate.DayOfWeek = &weekday
+		default:
+			day := data.LastDay
+			candidate.DayOfMonth = &day
+			rule.DayOfMonth = &day
+		}
+		candidate.NextExpected = nextOccurrence(rule, startOfDay(data.LastDate))
+
+		response.Candidates = append(response.Candidates, candidate)
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Subscription candidates retrieved successfully",
+		Data:    response,
+	})
+}
+
+func detectFrequency(data recurring.RepeatingTransactionData) (models.RecurringFrequency, bool) {
+	switch {
+	case data.AvgGapDays >= 5 && data.AvgGapDays <= 9 && data.Count >= 3:
+		return models.FrequencyWeekly, true
+	case data.AvgGapDays >= 25 && data.AvgGapDays <= 35 && data.Count >= 3:
+		return models.FrequencyMonthly, true
+	case data.AvgGapDays >= 340 && data.AvgGapDays <= 390:
+		return models.FrequencyYearly, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,162 @@
+package recurring
+
+import (
+	"encoding/json"
+	"fmt"
+	"pannypal/internal/common/enum"
+	"pannypal/internal/common/models"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	dtoAI "pannypal/internal/service/ai/dto"
+	"time"
+)
+
+const (
+	dueRulesBatchSize = 100
+	// A rule that was paused for long is not replayed all at once
+	maxCatchUpOccurrences = 12
+)
+
+// ProcessDueRules materializes every occurrence that is due, AUTO rules become transactions
+// and DRAFT rules are sent to the user's WhatsApp chat for confirmation
+func (s *Service) ProcessDueRules(now time.Time) error {
+	rules, err := s.rp.Recurring.GetDueRules(now, dueRulesBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if err := s.processRule(rule, now); err != nil {
+			logger.Error.Printf("Error processing recurring rule %d: %v", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) processRule(rule models.RecurringRule, now time.Time) error {
+	for i := 0; i < maxCatchUpOccurrences && !rule.NextRunAt.After(now); i++ {
+		occurrence := rule.NextRunAt
+		if rule.EndDate != nil && occurrence.After(*rule.EndDate) {
+			rule.IsActive = false
+			break
+		}
+
+		if err := s.materialize(rule, occurrence); err != nil {
+			// The occurrence stays due and is retried on the next run
+			break
+		}
+
+		rule.LastRunAt = &occurrence
+		rule.NextRunAt = nextOccurrence(rule, occurrence)
+	}
+
+	if rule.EndDate != nil && rule.NextRunAt.After(*rule.EndDate) {
+		rule.IsActive = false
+	}
+
+	rule.User = models.User{}
+	rule.Category = models.Category{}
+	_, err := s.rp.Recurring.UpdateRule(rule)
+	return err
+}
+
+func (s *Service) materialize(rule models.RecurringRule, occurrence time.Time) error {
+	if rule.Mode == models.RecurringModeDraft {
+		err := s.sendDraft(rule, occurrence)
+		if err != nil {
+			logger.Error.Printf("Error sending recurring draft for rule %d: %v", rule.ID, err)
+		}
+		return err
+	}
+
+	exists, err := s.rp.Recurring.HasOccurrence(rule.ID, occurrence)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return s.rp.Atomic(func(rp repository.IRepository) error {
+		created, err := rp.Transaction.CreateTransaction(models.Transaction{
+			UserID:          rule.UserID,
+			CategoryID:      rule.CategoryID,
+			AccountID:       rule.AccountID,
+			RecurringRuleID: &rule.ID,
+			Type:            rule.Type,
+			Amount:          rule.Amount,
+			Currency:        rule.Currency,
+			Description:     rule.Description,
+			TransactionDate: occurrence,
+		})
+		if err != nil {
+			return err
+		}
+
+		actor := models.RevisionActor{
+			ActorType: models.RevisionActorSystem,
+			Channel:   fmt.Sprintf("recurring rule %d", rule.ID),
+			Source:    models.RevisionSourceRecurring,
+		}
+		return rp.Revision.Record(models.RevisionActionCreate, actor, nil, created)
+	})
+}
+
+// sendDraft sends the occurrence as a cashflow draft, the usual save/edit/cancel reply saves it
+func (s *Service) sendDraft(rule models.RecurringRule, occurrence time.Time) error {
+	if rule.User.WhatsappChatID == "" || rule.User.BotAccountID == "" {
+		return fmt.Errorf("user %d has no WhatsApp chat with the bot", rule.UserID)
+	}
+
+	categoryID := 0
+	if rule.CategoryID != nil {
+		categoryID = int(*rule.CategoryID)
+	}
+	date := occurrence
+	payload := []dtoAI.TransactionPayload{{
+		Type:            string(rule.Type),
+		Amount:          rule.Amount,
+		Currency:        rule.Currency,
+		CategoryId:      categoryID,
+		Description:     rule.Description,
+		AccountID:       rule.AccountID,
+		RecurringRuleID: &rule.ID,
+		TransactionDate: &date,
+	}}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	rawMessage := json.RawMessage(payloadBytes)
+
+	currency := rule.Currency
+	if currency == "" {
+		currency = rule.User.BaseCurrency
+	}
+
+	text := "🔁 *Transaksi rutin*\n\n"
+	text += fmt.Sprintf("%s: %s\n", rule.Description, helper.FormatMoney(rule.Amount, currency))
+	if rule.Category.Name != "" {
+		text += "Kategori: " + rule.Category.Name + "\n"
+	}
+	text += "Tanggal: " + occurrence.Format("02-01-2006") + "\n"
+	text += "\nBalas dengan _'save'_, _'edit'_, atau _'cancel'_."
+
+	messageOut, err := s.outgoing.SendText(rule.User.BotAccountID, rule.User.WhatsappChatID, text)
+	if err != nil {
+		return err
+	}
+	if messageOut == nil {
+		return fmt.Errorf("failed to send outgoing message: no response from outgoing service")
+	}
+
+	_, err = s.rp.Bot.CreateMessageToReply(models.MessageToReply{
+		MessageID:   messageOut.Id,
+		FeatureType: enum.FeatureTypeAIcashflow,
+		Messsage:    text,
+		Additional:  &rawMessage,
+	})
+	return err
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type SplitParticipantRequest struct {
+	PhoneNumber string       `json:"phone_number" validate:"required"`
+	Amount      *types.Money `json:"amount" validate:"omitempty,gt=0"`            // EXACT splits
+	Percentage  *float64     `json:"percentage" validate:"omitempty,gt=0,lt=100"` // PERCENTAGE splits
+}
+
+type CreateSplitRequest struct {
+	PhoneNumber   string                    `json:"phone_number" validate:"required"` // Payer
+	TransactionID uint                      `json:"transaction_id" validate:"required"`
+	Method        string                    `json:"method" validate:"required,oneof=EQUAL PERCENTAGE EXACT"`
+	Parts         int                       `json:"parts" validate:"omitempty,min=2"` // EQUAL splits, people sharing including the payer
+	Participants  []SplitParticipantRequest `json:"participants" validate:"required,min=1,dive"`
+}
+
+type GetSplitsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	Page        int    `form:"page" validate:"omitempty,min=1" default:"1"`
+	Limit       int    `form:"limit" validate:"omitempty,min=1,max=100" default:"10"`
+}
+
+type GetBalancesRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+type SettleRequest struct {
+	PhoneNumber       string       `json:"phone_number" validate:"required"`
+	CounterpartyPhone string       `json:"counterparty_phone" validate:"required"`
+	Direction         string       `json:"direction" validate:"required,oneof=PAY RECEIVE"` // PAY when the user repays, RECEIVE when the user is repaid
+	Amount            *types.Money `json:"amount" validate:"omitempty,gt=0"`                // Defaults to the outstanding balance
+	Note              string       `json:"note" validate:"omitempty"`
+}
+
+// ShareInput is a resolved participant of a split
+type ShareInput struct {
+	UserID     uint
+	Amount     *types.Money
+	Percentage *float64
+}
+
+// SplitInput describes how a transaction is divided, used by the API and the bot
+type SplitInput struct {
+	TransactionID uint
+	PayerID       uint
+	Method        models.SplitMethod
+	Parts         int
+	Participants  []ShareInput
+}
+
+type SplitShareResponse struct {
+	UserID      uint        `json:"user_id"`
+	PhoneNumber string      `json:"phone_number"`
+	Name        string      `json:"name"`
+	Amount      types.Money `json:"amount"`
+	Percentage  *float64    `json:"percentage,omitempty"`
+}
+
+type SplitResponse struct {
+	ID            uint                 `json:"id"`
+	TransactionID uint                 `json:"transaction_id"`
+	Description   string               `json:"description"`
+	PayerID       uint                 `json:"payer_id"`
+	PayerPhone    string               `json:"payer_phone"`
+	LedgerID      *uint                `json:"ledger_id"`
+	Method        models.SplitMethod   `json:"method"`
+	TotalAmount   types.Money          `json:"total_amount"`
+	Shares        []SplitShareResponse `json:"shares"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+type SplitListResponse struct {
+	Splits     []SplitResponse    `json:"splits"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type PaginationResponse struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+type BalanceResponse struct {
+	UserID      uint        `json:"user_id"`
+	PhoneNumber string      `json:"phone_number"`
+	Name        string      `json:"name"`
+	Balance     types.Money `json:"balance"` // Positive when they owe you
+}
+
+type BalanceListResponse struct {
+	Balances    []BalanceResponse `json:"balances"`
+	OwedToYou   types.Money       `json:"owed_to_you"`
+	YouOwe      types.Money       `json:"you_owe"`
+	NetPosition types.Money       `json:"net_position"`
+}
+
+type SettleResponse struct {
+	DebtID           uint        `json:"debt_id"`
+	DebtorID         uint        `json:"debtor_id"`
+	CreditorID       uint        `json:"creditor_id"`
+	Amount           types.Money `json:"amount"`
+	RemainingBalance types.Money `json:"remaining_balance"` // Still owed by the debtor after this payment
+}
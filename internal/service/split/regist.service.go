@@ -0,0 +1,35 @@
+package split
+
+import (
+	"context"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/redis"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/split/dto"
+)
+
+type Service struct {
+	ctx   context.Context
+	redis redis.IRedis
+	rp    repository.IRepository
+}
+
+type IService interface {
+	SplitTransaction(input dto.SplitInput) (*models.Split, error)
+	Settle(debtorID, creditorID uint, amount *types.Money, note string) (*models.Debt, types.Money, error)
+
+	CreateSplitRequest(payload dto.CreateSplitRequest) *types.Response
+	GetSplitsRequest(payload dto.GetSplitsRequest) *types.Response
+	GetSplitByIDRequest(id uint, phoneNumber string) *types.Response
+	GetBalancesRequest(payload dto.GetBalancesRequest) *types.Response
+	SettleRequest(payload dto.SettleRequest) *types.Response
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+	return &Service{
+		ctx:   ctx,
+		redis: redis,
+		rp:    repository,
+	}
+}
@@ -0,0 +1,553 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/split"
+	"pannypal/internal/service/split/dto"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSplit marks errors caused by the request rather than the database
+var ErrInvalidSplit = errors.New("invalid split")
+
+// SplitTransaction divides an expense between the payer and the participants,
+// every non-payer share becomes a debt to the payer
+func (s *Service) SplitTransaction(input dto.SplitInput) (*models.Split, error) {
+	return splitTransaction(s.rp, input)
+}
+
+func splitTransaction(rp repository.IRepository, input dto.SplitInput) (*models.Split, error) {
+	tx, err := rp.Transaction.GetTransactionByID(input.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSplittable(tx, input.PayerID); err != nil {
+		return nil, err
+	}
+
+	existing, err := rp.Split.GetSplitByTransactionID(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: transaction is already split", ErrInvalidSplit)
+	}
+
+	shares, err := ComputeShares(input.Method, tx.Amount, input.PayerID, input.Parts, input.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := 0
+	if input.Method == models.SplitMethodEqual {
+		parts = len(input.Participants) + 1
+		if input.Parts > parts {
+			parts = input.Parts
+		}
+	}
+
+	debts := []models.Debt{}
+	for _, share := range shares {
+		if share.UserID == input.PayerID {
+			continue
+		}
+		debts = append(debts, models.Debt{
+			DebtorID:   share.UserID,
+			CreditorID: input.PayerID,
+			Type:       models.DebtTypeShare,
+			Amount:     share.Amount,
+			Note:       tx.Description,
+		})
+	}
+
+	created, err := rp.Split.CreateSplit(models.Split{
+		TransactionID: tx.ID,
+		PayerID:       input.PayerID,
+		LedgerID:      tx.LedgerID,
+		Method:        input.Method,
+		TotalAmount:   tx.Amount,
+		Parts:         parts,
+		Shares:        shares,
+	}, debts)
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.Split.GetSplitByID(created.ID)
+}
+
+// Resplit recomputes the split of an edited transaction with the same participants and method,
+// transactions without a split are left alone. The new shares are checked before the old split is
+// removed, rp should be the repository the edit was written with so a failure undoes the edit too.
+func Resplit(rp repository.IRepository, transactionID uint) (*models.Split, error) {
+	existing, err := rp.Split.GetSplitByTransactionID(transactionID)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	participants := []dto.ShareInput{}
+	for _, share := range existing.Shares {
+		if share.UserID == existing.PayerID {
+			continue
+		}
+		amount := share.Amount
+		participants = append(participants, dto.ShareInput{
+			UserID:     share.UserID,
+			Amount:     &amount,
+			Percentage: share.Percentage,
+		})
+	}
+	input := dto.SplitInput{
+		TransactionID: transactionID,
+		PayerID:       existing.PayerID,
+		Method:        existing.Method,
+		Parts:         existing.Parts,
+		Participants:  participants,
+	}
+
+	tx, err := rp.Transaction.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSplittable(tx, input.PayerID); err != nil {
+		return nil, err
+	}
+	if _, err := ComputeShares(input.Method, tx.Amount, input.PayerID, input.Parts, input.Participants); err != nil {
+		return nil, err
+	}
+
+	if err := rp.Split.DeleteSplitByTransactionID(transactionID); err != nil {
+		return nil, err
+	}
+	return splitTransaction(rp, input)
+}
+
+func checkSplittable(tx *models.Transaction, payerID uint) error {
+	if tx.UserID != payerID {
+		return fmt.Errorf("%w: transaction does not belong to the payer", ErrInvalidSplit)
+	}
+	if tx.Type != models.TypeExpense {
+		return fmt.Errorf("%w: only expenses can be split", ErrInvalidSplit)
+	}
+	return nil
+}
+
+// Settle records a repayment from the debtor to the creditor, a nil amount settles the whole
+// outstanding balance. It returns what the debtor still owes afterwards.
+func (s *Service) Settle(debtorID, creditorID uint, amount *types.Money, note string) (*models.Debt, types.Money, error) {
+	if debtorID == creditorID {
+		return nil, 0, fmt.Errorf("%w: cannot settle with yourself", ErrInvalidSplit)
+	}
+
+	outstanding, err := s.rp.Split.GetBalanceBetween(creditorID, debtorID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value := outstanding
+	if amount != nil {
+		value = *amount
+	}
+	if value <= 0 {
+		return nil, 0, fmt.Errorf("%w: no outstanding debt to settle", ErrInvalidSplit)
+	}
+
+	debt, err := s.rp.Split.CreateDebt(models.Debt{
+		DebtorID:   debtorID,
+		CreditorID: creditorID,
+		Type:       models.DebtTypeSettlement,
+		Amount:     value,
+		Note:       note,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return debt, outstanding - value, nil
+}
+
+// ComputeShares returns the share of every participant and of the payer, the payer
+// absorbs rounding differences and whatever is not assigned to a participant
+func ComputeShares(method models.SplitMethod, total types.Money, payerID uint, parts int, participants []dto.ShareInput) ([]models.SplitShare, error) {
+	if len(participants) == 0 {
+		return nil, fmt.Errorf("%w: at least one participant is required", ErrInvalidSplit)
+	}
+
+	seen := map[uint]bool{payerID: true}
+	for _, p := range participants {
+		if seen[p.UserID] {
+			return nil, fmt.Errorf("%w: participants must be unique and exclude the payer", ErrInvalidSplit)
+		}
+		seen[p.UserID] = true
+	}
+
+	shares := []models.SplitShare{}
+	assigned := types.Money(0)
+
+	switch method {
+	case models.SplitMethodEqual:
+		count := len(participants) + 1
+		if parts > count {
+			count = parts
+		}
+		each := total / types.Money(count)
+		for _, p := range participants {
+			shares = append(shares, models.SplitShare{UserID: p.UserID, Amount: each})
+			assigned += each
+		}
+
+	case models.SplitMethodPercentage:
+		totalPercentage := float64(0)
+		for _, p := range participants {
+			if p.Percentage == nil || *p.Percentage <= 0 {
+				return nil, fmt.Errorf("%w: every participant needs a percentage", ErrInvalidSplit)
+			}
+			totalPercentage += *p.Percentage
+			amount := total.Mul(*p.Percentage / 100)
+			percentage := *p.Percentage
+			shares = append(shares, models.SplitShare{UserID: p.UserID, Amount: amount, Percentage: &percentage})
+			assigned += amount
+		}
+		if totalPercentage > 100 {
+			return nil, fmt.Errorf("%w: percentages exceed 100%%", ErrInvalidSplit)
+		}
+		payerPercentage := 100 - totalPercentage
+		if payerPercentage > 0 {
+			shares = append(shares, models.SplitShare{
+				UserID:     payerID,
+				Amount:     total - assigned,
+				Percentage: &payerPercentage,
+			})
+		}
+		return shares, nil
+
+	case models.SplitMethodExact:
+		for _, p := range participants {
+			if p.Amount == nil || *p.Amount <= 0 {
+				return nil, fmt.Errorf("%w: every participant needs an amount", ErrInvalidSplit)
+			}
+			amount := *p.Amount
+			shares = append(shares, models.SplitShare{UserID: p.UserID, Amount: amount})
+			assigned += amount
+		}
+		if assigned > total {
+			return nil, fmt.Errorf("%w: shares exceed the transaction amount", ErrInvalidSplit)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: unknown method %s", ErrInvalidSplit, method)
+	}
+
+	if payerShare := total - assigned; payerShare > 0 {
+		shares = append(shares, models.SplitShare{UserID: payerID, Amount: payerShare})
+	}
+
+	return shares, nil
+}
+
+func (s *Service) CreateSplitRequest(payload dto.CreateSplitRequest) *types.Response {
+	payer, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	tx, err := s.rp.Transaction.GetTransactionByID(payload.TransactionID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if tx.UserID != payer.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	participants := make([]dto.ShareInput, 0, len(payload.Participants))
+	for _, p := range payload.Participants {
+		user, err := s.getOrCreateUser(p.PhoneNumber)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to get participant",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		participants = append(participants, dto.ShareInput{
+			UserID:     user.ID,
+			Amount:     p.Amount,
+			Percentage: p.Percentage,
+		})
+	}
+
+	created, err := s.SplitTransaction(dto.SplitInput{
+		TransactionID: tx.ID,
+		PayerID:       payer.ID,
+		Method:        models.SplitMethod(payload.Method),
+		Parts:         payload.Parts,
+		Participants:  participants,
+	})
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSplit) {
+			code = http.StatusBadRequest
+		}
+		return helper.ParseResponse(&types.Response{
+			Code:    code,
+			Message: "Failed to split transaction",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Split created successfully",
+		Data:    toSplitResponse(*created),
+	})
+}
+
+func (s *Service) GetSplitsRequest(payload dto.GetSplitsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Set default values
+	if payload.Page == 0 {
+		payload.Page = 1
+	}
+	if payload.Limit == 0 {
+		payload.Limit = 10
+	}
+
+	splits, total, err := s.rp.Split.GetSplitsByUserID(user.ID, split.SplitFilters{
+		Page:  payload.Page,
+		Limit: payload.Limit,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get splits",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	splitResponses := make([]dto.SplitResponse, len(splits))
+	for i, sp := range splits {
+		splitResponses[i] = toSplitResponse(sp)
+	}
+
+	totalPages := int((total + int64(payload.Limit) - 1) / int64(payload.Limit))
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Splits retrieved successfully",
+		Data: dto.SplitListResponse{
+			Splits: splitResponses,
+			Pagination: dto.PaginationResponse{
+				Page:       payload.Page,
+				Limit:      payload.Limit,
+				Total:      total,
+				TotalPages: totalPages,
+			},
+		},
+	})
+}
+
+func (s *Service) GetSplitByIDRequest(id uint, phoneNumber string) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	sp, err := s.rp.Split.GetSplitByID(id)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Split not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	hasAccess := sp.PayerID == user.ID
+	for _, share := range sp.Shares {
+		if share.UserID == user.ID {
+			hasAccess = true
+		}
+	}
+	if !hasAccess {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Split retrieved successfully",
+		Data:    toSplitResponse(*sp),
+	})
+}
+
+func (s *Service) GetBalancesRequest(payload dto.GetBalancesRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	balances, err := s.rp.Split.GetBalances(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get balances",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := dto.BalanceListResponse{Balances: make([]dto.BalanceResponse, len(balances))}
+	for i, b := range balances {
+		response.Balances[i] = dto.BalanceResponse{
+			UserID:      b.CounterpartyID,
+			PhoneNumber: b.PhoneNumber,
+			Name:        b.Name,
+			Balance:     b.Balance,
+		}
+		if b.Balance > 0 {
+			response.OwedToYou += b.Balance
+		} else {
+			response.YouOwe += -b.Balance
+		}
+	}
+	response.NetPosition = response.OwedToYou - response.YouOwe
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Balances retrieved successfully",
+		Data:    response,
+	})
+}
+
+func (s *Service) SettleRequest(payload dto.SettleRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	counterparty, err := s.rp.User.GetUserByPhone(payload.CounterpartyPhone)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Counterparty not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	debtorID, creditorID := user.ID, counterparty.ID
+	if payload.Direction == "RECEIVE" {
+		debtorID, creditorID = counterparty.ID, user.ID
+	}
+
+	debt, remaining, err := s.Settle(debtorID, creditorID, payload.Amount, payload.Note)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSplit) {
+			code = http.StatusBadRequest
+		}
+		return helper.ParseResponse(&types.Response{
+			Code:    code,
+			Message: "Failed to settle debt",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Debt settled successfully",
+		Data: dto.SettleResponse{
+			DebtID:           debt.ID,
+			DebtorID:         debt.DebtorID,
+			CreditorID:       debt.CreditorID,
+			Amount:           debt.Amount,
+			RemainingBalance: remaining,
+		},
+	})
+}
+
+// getOrCreateUser lets a split include people who have not talked to the bot yet
+func (s *Service) getOrCreateUser(phoneNumber string) (*models.User, error) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err == gorm.ErrRecordNotFound {
+		return s.rp.User.CreateUser(models.User{PhoneNumber: phoneNumber})
+	}
+	return user, err
+}
+
+func toSplitResponse(sp models.Split) dto.SplitResponse {
+	shares := make([]dto.SplitShareResponse, len(sp.Shares))
+	for i, share := range sp.Shares {
+		shares[i] = dto.SplitShareResponse{
+			UserID:      share.UserID,
+			PhoneNumber: share.User.PhoneNumber,
+			Name:        share.User.Name,
+			Amount:      share.Amount,
+			Percentage:  share.Percentage,
+		}
+	}
+
+	return dto.SplitResponse{
+		ID:            sp.ID,
+		TransactionID: sp.TransactionID,
+		Description:   sp.Transaction.Description,
+		PayerID:       sp.PayerID,
+		PayerPhone:    sp.Payer.PhoneNumber,
+		LedgerID:      sp.LedgerID,
+		Method:        sp.Method,
+		TotalAmount:   sp.TotalAmount,
+		Shares:        shares,
+		CreatedAt:     sp.CreatedAt,
+	}
+}
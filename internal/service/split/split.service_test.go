@@ -0,0 +1,124 @@
+package split
+
+import (
+	"errors"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/service/split/dto"
+	"reflect"
+	"testing"
+)
+
+func TestComputeShares(t *testing.T) {
+	money := func(m types.Money) *types.Money { return &m }
+	percent := func(p float64) *float64 { return &p }
+
+	tests := []struct {
+		name         string
+		method       models.SplitMethod
+		total        types.Money
+		parts        int
+		participants []dto.ShareInput
+		want         map[uint]types.Money
+		wantErr      bool
+	}{
+		{
+			name:         "equal split gives the payer the remainder",
+			method:       models.SplitMethodEqual,
+			total:        10000,
+			participants: []dto.ShareInput{{UserID: 2}, {UserID: 3}},
+			want:         map[uint]types.Money{2: 3333, 3: 3333, 1: 3334},
+		},
+		{
+			name:         "equal split over more parts than members",
+			method:       models.SplitMethodEqual,
+			total:        10000,
+			parts:        4,
+			participants: []dto.ShareInput{{UserID: 2}},
+			want:         map[uint]types.Money{2: 2500, 1: 7500},
+		},
+		{
+			name:   "percentages round and the payer takes the rest",
+			method: models.SplitMethodPercentage,
+			total:  1001,
+			participants: []dto.ShareInput{
+				{UserID: 2, Percentage: percent(50)},
+				{UserID: 3, Percentage: percent(25)},
+			},
+			want: map[uint]types.Money{2: 501, 3: 250, 1: 250},
+		},
+		{
+			name:         "percentages of 100 leave the payer out",
+			method:       models.SplitMethodPercentage,
+			total:        5000,
+			participants: []dto.ShareInput{{UserID: 2, Percentage: percent(100)}},
+			want:         map[uint]types.Money{2: 5000},
+		},
+		{
+			name:   "exact amounts",
+			method: models.SplitMethodExact,
+			total:  10000,
+			participants: []dto.ShareInput{
+				{UserID: 2, Amount: money(2500)},
+				{UserID: 3, Amount: money(1500)},
+			},
+			want: map[uint]types.Money{2: 2500, 3: 1500, 1: 6000},
+		},
+		{
+			name:         "exact amounts above the total",
+			method:       models.SplitMethodExact,
+			total:        1000,
+			participants: []dto.ShareInput{{UserID: 2, Amount: money(1001)}},
+			wantErr:      true,
+		},
+		{
+			name:         "percentages above 100",
+			method:       models.SplitMethodPercentage,
+			total:        1000,
+			participants: []dto.ShareInput{{UserID: 2, Percentage: percent(60)}, {UserID: 3, Percentage: percent(50)}},
+			wantErr:      true,
+		},
+		{
+			name:         "missing percentage",
+			method:       models.SplitMethodPercentage,
+			total:        1000,
+			participants: []dto.ShareInput{{UserID: 2}},
+			wantErr:      true,
+		},
+		{
+			name:         "payer as participant",
+			method:       models.SplitMethodEqual,
+			total:        1000,
+			participants: []dto.ShareInput{{UserID: 1}},
+			wantErr:      true,
+		},
+		{
+			name:    "no participants",
+			method:  models.SplitMethodEqual,
+			total:   1000,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		shares, err := ComputeShares(tt.method, tt.total, 1, tt.parts, tt.participants)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSplit) {
+				t.Errorf("%s: error = %v, want ErrInvalidSplit", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+
+		got := map[uint]types.Money{}
+		for _, share := range shares {
+			got[share.UserID] += share.Amount
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: shares = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
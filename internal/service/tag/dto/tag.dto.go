@@ -0,0 +1,11 @@
+package dto
+
+type GetTagsRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+}
+
+type TagResponse struct {
+	ID               uint   `json:"id"`
+	Name             string `json:"name"`
+	TransactionCount int64  `json:"transaction_count"`
+}
@@ -0,0 +1,45 @@
+package tag
+
+import (
+	"net/http"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/service/tag/dto"
+)
+
+func (s *Service) GetTagsRequest(payload dto.GetTagsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	tags, err := s.rp.Tag.GetTagsByUserID(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get tags",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	response := make([]dto.TagResponse, len(tags))
+	for i, t := range tags {
+		response[i] = dto.TagResponse{
+			ID:               t.ID,
+			Name:             t.Name,
+			TransactionCount: t.TransactionCount,
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Tags retrieved successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,209 @@
+package transaction
+
+import (
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"strings"
+)
+
+// maxAttachmentSize keeps receipts reasonable, photos from a phone are well under this
+const maxAttachmentSize = 10 << 20
+
+func (s *Service) UploadAttachmentRequest(id uint, phoneNumber string, file types.UploadFile) *types.Response {
+	if s.storage == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusServiceUnavailable,
+			Message: "File storage is not configured",
+			Data:    nil,
+		})
+	}
+
+	transaction, res := s.getOwnedTransaction(id, phoneNumber)
+	if res != nil {
+		return res
+	}
+
+	if file.Header.Size > maxAttachmentSize {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("File is too large, maximum size is %d MB", maxAttachmentSize>>20),
+			Data:    nil,
+		})
+	}
+
+	file.Path = fmt.Sprintf("attachments/%d", transaction.UserID)
+	upload, err := helper.PrepareFileUploadPayload(file)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read file",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if !IsAllowedAttachmentType(upload.ContentType) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Only images and PDF files can be attached",
+			Data:    nil,
+		})
+	}
+
+	if err := s.storage.UploadFile(upload.OriginalFiles, upload.FileBytes, upload.ContentType); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to upload file",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	attachment, err := s.rp.Attachment.CreateAttachment(models.Attachment{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		FileKey:       upload.OriginalFiles,
+		FileName:      upload.FileName,
+		ContentType:   upload.ContentType,
+		Size:          int64(len(upload.FileBytes)),
+		Source:        "API",
+	})
+	if err != nil {
+		if delErr := s.storage.DeleteFile(upload.OriginalFiles); delErr != nil {
+			logger.Error.Println("Failed to remove orphaned attachment file:", delErr)
+		}
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save attachment",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusCreated,
+		Message: "Attachment uploaded successfully",
+		Data:    s.withAttachmentURLs([]models.Attachment{*attachment})[0],
+	})
+}
+
+func (s *Service) GetAttachmentsRequest(id uint, phoneNumber string) *types.Response {
+	transaction, res := s.getOwnedTransaction(id, phoneNumber)
+	if res != nil {
+		return res
+	}
+
+	attachments, err := s.rp.Attachment.GetAttachmentsByTransactionID(transaction.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get attachments",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Attachments retrieved successfully",
+		Data:    s.withAttachmentURLs(attachments),
+	})
+}
+
+func (s *Service) DeleteAttachmentRequest(id uint, attachmentID uint, phoneNumber string) *types.Response {
+	transaction, res := s.getOwnedTransaction(id, phoneNumber)
+	if res != nil {
+		return res
+	}
+
+	attachment, err := s.rp.Attachment.GetAttachmentByID(attachmentID)
+	if err != nil || attachment.TransactionID != transaction.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Attachment not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if err := s.rp.Attachment.DeleteAttachment(attachment.ID); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete attachment",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// The row is gone already, a file left behind only costs storage
+	if s.storage != nil {
+		if err := s.storage.DeleteFile(attachment.FileKey); err != nil {
+			logger.Error.Println("Failed to delete attachment file:", err)
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Attachment deleted successfully",
+		Data:    nil,
+	})
+}
+
+// getOwnedTransaction loads the transaction and checks it belongs to the user of the phone number
+func (s *Service) getOwnedTransaction(id uint, phoneNumber string) (*models.Transaction, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	transaction, err := s.rp.Transaction.GetTransactionByID(id)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if transaction.UserID != user.ID {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	return transaction, nil
+}
+
+// withAttachmentURLs fills the download URL of each attachment from the storage backend
+func (s *Service) withAttachmentURLs(attachments []models.Attachment) []models.Attachment {
+	if s.storage == nil {
+		return attachments
+	}
+	for i := range attachments {
+		url, err := s.storage.GetPresignedURL(attachments[i].FileKey)
+		if err != nil {
+			logger.Error.Println("Failed to get attachment URL:", err)
+			continue
+		}
+		attachments[i].URL = url
+	}
+	return attachments
+}
+
+// IsAllowedAttachmentType accepts images and PDFs, the only things receipts come as
+func IsAllowedAttachmentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return strings.HasPrefix(contentType, "image/") || contentType == "application/pdf"
+}
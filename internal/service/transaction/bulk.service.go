@@ -0,0 +1,337 @@
+package transaction
+
+import (
+	"fmt"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/transaction"
+	"pannypal/internal/service/transaction/dto"
+)
+
+// maxBulkItems caps one bulk operation, larger filters must be narrowed down
+const maxBulkItems = 500
+
+const (
+	bulkStatusChanged     = "CHANGED"
+	bulkStatusWouldChange = "WOULD_CHANGE"
+	bulkStatusUnchanged   = "UNCHANGED"
+	bulkStatusSkipped     = "SKIPPED"
+	bulkStatusNotFound    = "NOT_FOUND"
+)
+
+// bulkPlan is what a bulk operation found, the changes are only applied when it is not a dry run
+type bulkPlan struct {
+	user       *models.User
+	selected   []models.Transaction
+	results    []dto.BulkItemResult
+	changedIDs []uint
+}
+
+func (s *Service) BulkCategorizeRequest(payload dto.BulkCategorizeRequest) *types.Response {
+	category, err := s.rp.Category.GetCategoryByID(payload.CategoryID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	plan, res := s.planBulk(payload.BulkRequest, false, func(tx models.Transaction) (string, []string, string) {
+		if tx.Type == models.TypeTransfer {
+			return bulkStatusSkipped, nil, "Transfers have no category"
+		}
+		if category.UserID == nil || *category.UserID != tx.UserID {
+			return bulkStatusSkipped, nil, "Category belongs to another user"
+		}
+		if !category.Kind.Allows(tx.Type) {
+			return bulkStatusSkipped, nil, fmt.Sprintf("Category cannot hold %s transactions", tx.Type)
+		}
+		if tx.CategoryID != nil && *tx.CategoryID == category.ID {
+			return bulkStatusUnchanged, nil, ""
+		}
+		return bulkStatusChanged, []string{fmt.Sprintf("category: %s → %s", categoryName(tx.Category), category.Name)}, ""
+	})
+	if res != nil {
+		return res
+	}
+
+	return s.applyBulk("CATEGORIZE", models.RevisionActionUpdate, payload.DryRun, plan, func(rp repository.IRepository) error {
+		return rp.Transaction.BulkUpdateCategory(plan.changedIDs, category.ID)
+	})
+}
+
+func (s *Service) BulkTagRequest(payload dto.BulkTagRequest) *types.Response {
+	add := helper.NormalizeTags(payload.AddTags)
+	remove := helper.NormalizeTags(payload.RemoveTags)
+	if len(add) == 0 && len(remove) == 0 {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "add_tags or remove_tags is required",
+			Data:    nil,
+		})
+	}
+
+	// Only tags some selected transaction carries can be removed, their IDs come with it
+	removeIDs := map[uint]bool{}
+	plan, res := s.planBulk(payload.BulkRequest, false, func(tx models.Transaction) (string, []string, string) {
+		current := map[string]uint{}
+		for _, tag := range tx.Tags {
+			current[tag.Name] = tag.ID
+		}
+
+		changes := []string{}
+		for _, name := range add {
+			if _, ok := current[name]; !ok {
+				changes = append(changes, "+#"+name)
+			}
+		}
+		for _, name := range remove {
+			if id, ok := current[name]; ok {
+				changes = append(changes, "-#"+name)
+				removeIDs[id] = true
+			}
+		}
+
+		if len(changes) == 0 {
+			return bulkStatusUnchanged, nil, ""
+		}
+		return bulkStatusChanged, changes, ""
+	})
+	if res != nil {
+		return res
+	}
+
+	return s.applyBulk("TAG", models.RevisionActionUpdate, payload.DryRun, plan, func(rp repository.IRepository) error {
+		tags, err := rp.Tag.GetOrCreateTags(plan.user.ID, add)
+		if err != nil {
+			return err
+		}
+		ids := make([]uint, 0, len(removeIDs))
+		for id := range removeIDs {
+			ids = append(ids, id)
+		}
+		return rp.Transaction.BulkUpdateTags(plan.changedIDs, tags, ids)
+	})
+}
+
+func (s *Service) BulkDeleteRequest(payload dto.BulkRequest) *types.Response {
+	plan, res := s.planBulk(payload, false, func(tx models.Transaction) (string, []string, string) {
+		return bulkStatusChanged, []string{"deleted"}, ""
+	})
+	if res != nil {
+		return res
+	}
+
+	return s.applyBulk("DELETE", models.RevisionActionDelete, payload.DryRun, plan, func(rp repository.IRepository) error {
+		return rp.Transaction.BulkDeleteTransactions(plan.changedIDs)
+	})
+}
+
+func (s *Service) BulkRestoreRequest(payload dto.BulkRequest) *types.Response {
+	plan, res := s.planBulk(payload, true, func(tx models.Transaction) (string, []string, string) {
+		return bulkStatusChanged, []string{"restored"}, ""
+	})
+	if res != nil {
+		return res
+	}
+
+	return s.applyBulk("RESTORE", models.RevisionActionRestore, payload.DryRun, plan, func(rp repository.IRepository) error {
+		return rp.Transaction.BulkRestoreTransactions(plan.changedIDs)
+	})
+}
+
+// planBulk loads the selected transactions and asks decide what would happen to each,
+// requested IDs that are missing or belong to someone else are reported as not found
+func (s *Service) planBulk(payload dto.BulkRequest, deleted bool, decide func(tx models.Transaction) (string, []string, string)) (*bulkPlan, *types.Response) {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	selection, res := bulkSelection(payload, deleted)
+	if res != nil {
+		return nil, res
+	}
+
+	selected, err := s.rp.Transaction.GetTransactionsForBulk(user.ID, selection)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if len(selected) > maxBulkItems {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Filter matches more than %d transactions, narrow it down", maxBulkItems),
+			Data:    nil,
+		})
+	}
+
+	plan := &bulkPlan{user: user, selected: selected}
+	byID := map[uint]models.Transaction{}
+	order := []uint{}
+	for _, tx := range selected {
+		byID[tx.ID] = tx
+		order = append(order, tx.ID)
+	}
+	if len(payload.IDs) > 0 {
+		order = uniqueIDs(payload.IDs)
+	}
+
+	for _, id := range order {
+		tx, ok := byID[id]
+		if !ok {
+			plan.results = append(plan.results, dto.BulkItemResult{ID: id, Status: bulkStatusNotFound})
+			continue
+		}
+
+		status, changes, reason := decide(tx)
+		plan.results = append(plan.results, dto.BulkItemResult{
+			ID:          tx.ID,
+			Description: tx.Description,
+			Status:      status,
+			Changes:     changes,
+			Reason:      reason,
+		})
+		if status == bulkStatusChanged {
+			plan.changedIDs = append(plan.changedIDs, tx.ID)
+		}
+	}
+
+	return plan, nil
+}
+
+// applyBulk runs the change unless it is a dry run, apply and the revisions share one database
+// transaction so either every item changes and is recorded or none does
+func (s *Service) applyBulk(action string, revisionAction models.RevisionAction, dryRun bool, plan *bulkPlan, apply func(rp repository.IRepository) error) *types.Response {
+	if dryRun {
+		for i := range plan.results {
+			if plan.results[i].Status == bulkStatusChanged {
+				plan.results[i].Status = bulkStatusWouldChange
+			}
+		}
+	} else if len(plan.changedIDs) > 0 {
+		err := s.rp.Atomic(func(rp repository.IRepository) error {
+			if err := apply(rp); err != nil {
+				return err
+			}
+			return recordBulkRevisions(rp, revisionAction, plan)
+		})
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to apply bulk operation, no transaction was changed",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+	}
+
+	message := "Bulk operation completed successfully"
+	if dryRun {
+		message = "Dry run completed, no transaction was changed"
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: message,
+		Data: dto.BulkResponse{
+			Action:  action,
+			DryRun:  dryRun,
+			Matched: len(plan.selected),
+			Changed: len(plan.changedIDs),
+			Results: plan.results,
+		},
+	})
+}
+
+func bulkSelection(payload dto.BulkRequest, deleted bool) (transaction.BulkSelection, *types.Response) {
+	selection := transaction.BulkSelection{
+		Deleted: deleted,
+		Limit:   maxBulkItems + 1,
+	}
+
+	switch {
+	case len(payload.IDs) > 0 && payload.Filter != nil:
+		return selection, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Use either ids or filter, not both",
+			Data:    nil,
+		})
+	case len(payload.IDs) > 0:
+		selection.IDs = uniqueIDs(payload.IDs)
+		if len(selection.IDs) == 0 {
+			return selection, helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "ids must not be zero",
+				Data:    nil,
+			})
+		}
+		return selection, nil
+	case payload.Filter == nil:
+		return selection, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Either ids or filter is required",
+			Data:    nil,
+		})
+	}
+
+	f := payload.Filter
+	filters := transaction.TransactionFilters{
+		CategoryID: f.CategoryID,
+		AccountID:  f.AccountID,
+		Tags:       helper.NormalizeTags(f.Tags),
+		StartDate:  f.StartDate,
+		EndDate:    f.EndDate,
+	}
+	if f.Type != nil {
+		transactionType := models.TransactionType(*f.Type)
+		filters.Type = &transactionType
+	}
+
+	// An empty filter would pick every transaction of the user
+	if filters.Type == nil && filters.CategoryID == nil && filters.AccountID == nil &&
+		len(filters.Tags) == 0 && filters.StartDate == nil && filters.EndDate == nil {
+		return selection, helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Filter needs at least one condition",
+			Data:    nil,
+		})
+	}
+
+	selection.Filters = &filters
+	return selection, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	unique := []uint{}
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+func categoryName(category models.Category) string {
+	if category.Name == "" {
+		return "-"
+	}
+	return category.Name
+}
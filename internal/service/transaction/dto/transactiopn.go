@@ -1,35 +1,59 @@
 package dto
 
 import (
+	"encoding/json"
 	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
 	"time"
 )
 
 type CreateTransactionRequest struct {
-	PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty"`
-	Amount      float64 `json:"amount" validate:"required"`
-	CategoryID  *int    `json:"category_id" validate:"omitempty"`
-	Type        string  `json:"type" validate:"required,oneof=INCOME EXPENSE"`
-	Description string  `json:"description" validate:"omitempty"`
+	PhoneNumber *string     `json:"phone_number,omitempty" validate:"omitempty"`
+	Amount      types.Money `json:"amount" validate:"required"`
+	CategoryID  *int        `json:"category_id" validate:"omitempty"`
+	Type        string      `json:"type" validate:"required,oneof=INCOME EXPENSE TRANSFER"`
+	Description string      `json:"description" validate:"omitempty"`
+	Merchant    string      `json:"merchant" validate:"omitempty,max=150"`
+	Notes       string      `json:"notes" validate:"omitempty"`
+	Tags        []string    `json:"tags" validate:"omitempty,max=20,dive,max=50"` // With or without the leading #
+	Currency    string      `json:"currency" validate:"omitempty,iso4217"`        // Defaults to the account's currency, then the user's base currency
+	AccountID   *uint       `json:"account_id" validate:"omitempty"`              // Source account of a TRANSFER
+	ToAccountID *uint       `json:"to_account_id" validate:"omitempty"`           // Required for TRANSFER
 }
 
 type UpdateTransactionRequest struct {
-	Amount      *float64 `json:"amount" validate:"omitempty,gt=0"`
-	CategoryID  *int     `json:"category_id" validate:"omitempty"`
-	Type        *string  `json:"type" validate:"omitempty,oneof=INCOME EXPENSE"`
-	Description *string  `json:"description" validate:"omitempty"`
+	Amount      *types.Money `json:"amount" validate:"omitempty,gt=0"`
+	CategoryID  *int         `json:"category_id" validate:"omitempty"`
+	Type        *string      `json:"type" validate:"omitempty,oneof=INCOME EXPENSE TRANSFER"`
+	Description *string      `json:"description" validate:"omitempty"`
+	Merchant    *string      `json:"merchant" validate:"omitempty,max=150"`
+	Notes       *string      `json:"notes" validate:"omitempty"`
+	Tags        *[]string    `json:"tags" validate:"omitempty,max=20,dive,max=50"` // Replaces the tags, an empty list clears them
+	Currency    *string      `json:"currency" validate:"omitempty,iso4217"`
+	AccountID   *uint        `json:"account_id" validate:"omitempty"`
+	ToAccountID *uint        `json:"to_account_id" validate:"omitempty"`
 }
 
 type GetTransactionsRequest struct {
 	PhoneNumber *string    `form:"phone_number,omitempty" validate:"omitempty"`
-	Type        *string    `form:"type" validate:"omitempty,oneof=INCOME EXPENSE"`
+	Type        *string    `form:"type" validate:"omitempty,oneof=INCOME EXPENSE TRANSFER"`
 	CategoryID  *int       `form:"category_id" validate:"omitempty"`
+	AccountID   *uint      `form:"account_id" validate:"omitempty"`
+	Tags        []string   `form:"tags" validate:"omitempty"` // Repeated or comma separated, transactions must carry all of them
 	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"`
 	EndDate     *time.Time `form:"end_date" validate:"omitempty" time_format:"2006-01-02"`
 	Page        int        `form:"page" validate:"omitempty,min=1" default:"1"`
 	Limit       int        `form:"limit" validate:"omitempty,min=1,max=100" default:"10"`
 }
 
+type SearchTransactionsRequest struct {
+	PhoneNumber string  `form:"phone_number" validate:"required"`
+	Q           string  `form:"q" validate:"required,max=200"` // Text, #tags and amount conditions like >100rb or 50rb-100rb
+	Type        *string `form:"type" validate:"omitempty,oneof=INCOME EXPENSE TRANSFER"`
+	Page        int     `form:"page" validate:"omitempty,min=1" default:"1"`
+	Limit       int     `form:"limit" validate:"omitempty,min=1,max=100" default:"10"`
+}
+
 type TransactionSummaryRequest struct {
 	PhoneNumber *string    `form:"phone_number,omitempty" validate:"omitempty"`
 	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"`
@@ -43,8 +67,15 @@ type TransactionResponse struct {
 	UserID          uint                   `json:"user_id"`
 	CategoryID      *uint                  `json:"category_id"`
 	Category        models.Category        `json:"category"`
-	Amount          float64                `json:"amount"`
+	AccountID       *uint                  `json:"account_id"`
+	ToAccountID     *uint                  `json:"to_account_id"`
+	Amount          types.Money            `json:"amount"`
+	Currency        string                 `json:"currency"`
 	Description     string                 `json:"description"`
+	Merchant        string                 `json:"merchant"`
+	Notes           string                 `json:"notes"`
+	Tags            []string               `json:"tags"`
+	Attachments     []models.Attachment    `json:"attachments,omitempty"`
 	TransactionDate time.Time              `json:"transaction_date"`
 	Type            models.TransactionType `json:"type"`
 	CreatedAt       time.Time              `json:"created_at"`
@@ -56,6 +87,20 @@ type TransactionListResponse struct {
 	Pagination   PaginationResponse    `json:"pagination"`
 }
 
+type TransactionSearchResponse struct {
+	Query        SearchQueryInfo       `json:"query"`
+	Transactions []TransactionResponse `json:"transactions"`
+	Pagination   PaginationResponse    `json:"pagination"`
+}
+
+// SearchQueryInfo shows how the search text was understood
+type SearchQueryInfo struct {
+	Text      string       `json:"text"`
+	Tags      []string     `json:"tags"`
+	MinAmount *types.Money `json:"min_amount"`
+	MaxAmount *types.Money `json:"max_amount"`
+}
+
 type PaginationResponse struct {
 	Page       int   `json:"page"`
 	Limit      int   `json:"limit"`
@@ -64,9 +109,10 @@ type PaginationResponse struct {
 }
 
 type TransactionSummaryResponse struct {
-	TotalIncome      float64           `json:"total_income"`
-	TotalExpense     float64           `json:"total_expense"`
-	Balance          float64           `json:"balance"`
+	Currency         string            `json:"currency"` // Totals are converted into the user's base currency
+	TotalIncome      types.Money       `json:"total_income"`
+	TotalExpense     types.Money       `json:"total_expense"`
+	Balance          types.Money       `json:"balance"`
 	TransactionCount int64             `json:"transaction_count"`
 	IncomeCount      int64             `json:"income_count"`
 	ExpenseCount     int64             `json:"expense_count"`
@@ -78,7 +124,7 @@ type CategorySummary struct {
 	CategoryID   uint                   `json:"category_id"`
 	CategoryName string                 `json:"category_name"`
 	Type         models.TransactionType `json:"type"`
-	TotalAmount  float64                `json:"total_amount"`
+	TotalAmount  types.Money            `json:"total_amount"`
 	Count        int64                  `json:"count"`
 	Percentage   float64                `json:"percentage"`
 }
@@ -89,3 +135,110 @@ type PeriodInfo struct {
 	Month     *int       `json:"month"`
 	Year      *int       `json:"year"`
 }
+
+// BulkRequest picks the transactions of a bulk operation, either by IDs or by filter
+type BulkRequest struct {
+	PhoneNumber string      `json:"phone_number" validate:"required"`
+	IDs         []uint      `json:"ids" validate:"omitempty,max=500"`
+	Filter      *BulkFilter `json:"filter" validate:"omitempty"`
+	DryRun      bool        `json:"dry_run"` // Report what would change without changing anything
+}
+
+type BulkFilter struct {
+	Type       *string    `json:"type" validate:"omitempty,oneof=INCOME EXPENSE TRANSFER"`
+	CategoryID *uint      `json:"category_id" validate:"omitempty"`
+	AccountID  *uint      `json:"account_id" validate:"omitempty"`
+	Tags       []string   `json:"tags" validate:"omitempty"`
+	StartDate  *time.Time `json:"start_date" validate:"omitempty"`
+	EndDate    *time.Time `json:"end_date" validate:"omitempty"`
+}
+
+type BulkCategorizeRequest struct {
+	BulkRequest
+	CategoryID uint `json:"category_id" validate:"required"`
+}
+
+type BulkTagRequest struct {
+	BulkRequest
+	AddTags    []string `json:"add_tags" validate:"omitempty,max=20,dive,max=50"`
+	RemoveTags []string `json:"remove_tags" validate:"omitempty,max=20,dive,max=50"`
+}
+
+type BulkResponse struct {
+	Action  string           `json:"action"`
+	DryRun  bool             `json:"dry_run"`
+	Matched int              `json:"matched"`
+	Changed int              `json:"changed"` // Changed, or would change on a dry run
+	Results []BulkItemResult `json:"results"`
+}
+
+type BulkItemResult struct {
+	ID          uint     `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"` // CHANGED, WOULD_CHANGE, UNCHANGED, SKIPPED, NOT_FOUND
+	Changes     []string `json:"changes,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+}
+
+type TransactionHistoryResponse struct {
+	TransactionID uint                          `json:"transaction_id"`
+	Deleted       bool                          `json:"deleted"`
+	Revisions     []TransactionRevisionResponse `json:"revisions"`
+}
+
+type TransactionRevisionResponse struct {
+	ID        uint                     `json:"id"`
+	Action    models.RevisionAction    `json:"action"`
+	ActorType models.RevisionActorType `json:"actor_type"`
+	ActorID   *uint                    `json:"actor_id"`
+	Channel   string                   `json:"channel,omitempty"`
+	MessageID string                   `json:"message_id,omitempty"`
+	Source    string                   `json:"source"`
+	Changes   []RevisionFieldChange    `json:"changes"` // Fields that differ between before and after
+	Before    *json.RawMessage         `json:"before"`
+	After     *json.RawMessage         `json:"after"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+type RevisionFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type ScanDuplicatesRequest struct {
+	PhoneNumber string     `form:"phone_number" validate:"required"`
+	StartDate   *time.Time `form:"start_date" validate:"omitempty" time_format:"2006-01-02"` // Defaults to 90 days before the end date
+	EndDate     *time.Time `form:"end_date" validate:"omitempty" time_format:"2006-01-02"`   // Defaults to today
+	MinScore    *float64   `form:"min_score" validate:"omitempty,gt=0,lte=1"`
+}
+
+type MergeDuplicatesRequest struct {
+	PhoneNumber  string `json:"phone_number" validate:"required"`
+	KeepID       uint   `json:"keep_id" validate:"required"`
+	DuplicateIDs []uint `json:"duplicate_ids" validate:"required,min=1,max=20,dive,required"`
+}
+
+type DuplicateScanResponse struct {
+	StartDate time.Time        `json:"start_date"`
+	EndDate   time.Time        `json:"end_date"`
+	MinScore  float64          `json:"min_score"`
+	Groups    []DuplicateGroup `json:"groups"`
+}
+
+// DuplicateGroup is transactions that are likely the same one recorded more than once
+type DuplicateGroup struct {
+	SuggestedKeepID uint                   `json:"suggested_keep_id"` // The most complete one
+	Score           float64                `json:"score"`             // Highest pair score in the group
+	Transactions    []DuplicateTransaction `json:"transactions"`
+}
+
+type DuplicateTransaction struct {
+	TransactionResponse
+	Score float64 `json:"score"` // Best score against another transaction of the group
+}
+
+type MergeDuplicatesResponse struct {
+	Transaction TransactionResponse `json:"transaction"`
+	MergedIDs   []uint              `json:"merged_ids"`
+}
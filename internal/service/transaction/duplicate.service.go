@@ -0,0 +1,282 @@
+package transaction
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/revision"
+	"pannypal/internal/repository/transaction"
+	"pannypal/internal/service/transaction/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	duplicateScanDays      = 90
+	duplicateScanPairLimit = 500
+)
+
+func (s *Service) ScanDuplicatesRequest(payload dto.ScanDuplicatesRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	now := time.Now()
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if payload.EndDate != nil {
+		endDate = *payload.EndDate
+	}
+	startDate := endDate.AddDate(0, 0, -duplicateScanDays)
+	if payload.StartDate != nil {
+		startDate = *payload.StartDate
+	}
+	if startDate.After(endDate) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Start date must be before end date",
+			Data:    nil,
+		})
+	}
+	minScore := transaction.DefaultDuplicateScore
+	if payload.MinScore != nil {
+		minScore = *payload.MinScore
+	}
+
+	pairs, err := s.rp.Transaction.FindDuplicatePairs(user.ID, transaction.DuplicateScanFilters{
+		StartDate: startDate,
+		EndDate:   endDate.AddDate(0, 0, 1).Add(-time.Nanosecond),
+		MinScore:  minScore,
+		Limit:     duplicateScanPairLimit,
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to scan for duplicate transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	groups, err := s.groupDuplicates(user.ID, pairs)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get duplicate transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Duplicate transactions retrieved successfully",
+		Data: dto.DuplicateScanResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			MinScore:  minScore,
+			Groups:    groups,
+		},
+	})
+}
+
+func (s *Service) MergeDuplicatesRequest(payload dto.MergeDuplicatesRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	duplicateIDs := []uint{}
+	seen := map[uint]bool{payload.KeepID: true}
+	for _, id := range payload.DuplicateIDs {
+		if !seen[id] {
+			seen[id] = true
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "A transaction cannot be merged into itself",
+			Data:    nil,
+		})
+	}
+
+	ids := append([]uint{payload.KeepID}, duplicateIDs...)
+	selected, err := s.rp.Transaction.GetTransactionsForBulk(user.ID, transaction.BulkSelection{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	// Only the user's own transactions are selected, someone else's reads as not found
+	if len(selected) != len(ids) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Transaction not found",
+			Data:    nil,
+		})
+	}
+
+	var keep models.Transaction
+	duplicates := []models.Transaction{}
+	for _, tx := range selected {
+		if tx.ID == payload.KeepID {
+			keep = tx
+		} else {
+			duplicates = append(duplicates, tx)
+		}
+	}
+	for _, duplicate := range duplicates {
+		if duplicate.Type != keep.Type || !strings.EqualFold(duplicate.Currency, keep.Currency) {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Only transactions of the same type and currency can be merged",
+				Data:    nil,
+			})
+		}
+	}
+
+	var merged *models.Transaction
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if err := rp.Transaction.MergeTransactions(keep, duplicateIDs); err != nil {
+			return err
+		}
+		updated, err := rp.Transaction.GetTransactionByID(keep.ID)
+		if err != nil {
+			return err
+		}
+		merged = updated
+
+		actor := apiActor(user.ID, models.RevisionSourceMerge)
+		if err := recordRevision(rp, models.RevisionActionUpdate, actor, &keep, merged); err != nil {
+			return err
+		}
+		changes := make([]revision.Change, len(duplicates))
+		for i := range duplicates {
+			changes[i].Before = &duplicates[i]
+		}
+		return rp.Revision.RecordChanges(models.RevisionActionDelete, actor, changes)
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to merge transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Transactions merged successfully",
+		Data: dto.MergeDuplicatesResponse{
+			Transaction: toTransactionResponse(*merged),
+			MergedIDs:   duplicateIDs,
+		},
+	})
+}
+
+// groupDuplicates joins pairs that share a transaction into one group, so a payment recorded three
+// times shows up once, groups with the best match come first
+func (s *Service) groupDuplicates(userID uint, pairs []transaction.DuplicatePair) ([]dto.DuplicateGroup, error) {
+	groups := []dto.DuplicateGroup{}
+	if len(pairs) == 0 {
+		return groups, nil
+	}
+
+	parent := map[uint]uint{}
+	var find func(id uint) uint
+	find = func(id uint) uint {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	best := map[uint]float64{}
+	ids := []uint{}
+	for _, pair := range pairs {
+		for _, id := range []uint{pair.FirstID, pair.SecondID} {
+			if _, ok := best[id]; !ok {
+				ids = append(ids, id)
+			}
+			if pair.Score > best[id] {
+				best[id] = pair.Score
+			}
+		}
+		if a, b := find(pair.FirstID), find(pair.SecondID); a != b {
+			parent[b] = a
+		}
+	}
+
+	transactions, err := s.rp.Transaction.GetTransactionsForBulk(userID, transaction.BulkSelection{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].ID < transactions[j].ID })
+
+	index := map[uint]int{}
+	for _, tx := range transactions {
+		root := find(tx.ID)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, dto.DuplicateGroup{SuggestedKeepID: tx.ID})
+		}
+
+		group := &groups[i]
+		group.Transactions = append(group.Transactions, dto.DuplicateTransaction{
+			TransactionResponse: toTransactionResponse(tx),
+			Score:               best[tx.ID],
+		})
+		if best[tx.ID] > group.Score {
+			group.Score = best[tx.ID]
+		}
+	}
+
+	// Keep the most complete transaction, the earliest recorded on a tie
+	for i := range groups {
+		keep := groups[i].Transactions[0]
+		for _, tx := range groups[i].Transactions[1:] {
+			if completeness(tx.TransactionResponse) > completeness(keep.TransactionResponse) {
+				keep = tx
+			}
+		}
+		groups[i].SuggestedKeepID = keep.ID
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Score > groups[j].Score })
+	return groups, nil
+}
+
+// completeness counts the details a transaction has filled in
+func completeness(t dto.TransactionResponse) int {
+	count := len(t.Tags)
+	for _, filled := range []bool{t.CategoryID != nil, t.AccountID != nil, t.Merchant != "", t.Notes != "", t.Description != ""} {
+		if filled {
+			count++
+		}
+	}
+	return count
+}
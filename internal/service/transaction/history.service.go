@@ -0,0 +1,175 @@
+package transaction
+
+import (
+	"encoding/json"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/revision"
+	"pannypal/internal/repository/transaction"
+	"pannypal/internal/service/transaction/dto"
+	"reflect"
+	"sort"
+)
+
+func (s *Service) GetTransactionHistoryRequest(id uint, phoneNumber string) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	// Deleted transactions keep their history until they are purged from the trash
+	deleted := false
+	transaction, err := s.rp.Transaction.GetTransactionByID(id)
+	if err != nil {
+		transaction, err = s.rp.Trash.GetDeletedTransactionByID(id)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "Transaction not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		deleted = true
+	}
+
+	if transaction.UserID != user.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	revisions, err := s.rp.Revision.GetRevisionsByTransactionID(transaction.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get transaction history",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.TransactionRevisionResponse, len(revisions))
+	for i, r := range revisions {
+		responses[i] = dto.TransactionRevisionResponse{
+			ID:        r.ID,
+			Action:    r.Action,
+			ActorType: r.ActorType,
+			ActorID:   r.ActorID,
+			Channel:   r.Channel,
+			MessageID: r.MessageID,
+			Source:    r.Source,
+			Changes:   revisionChanges(r.Before, r.After),
+			Before:    r.Before,
+			After:     r.After,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Transaction history retrieved successfully",
+		Data: dto.TransactionHistoryResponse{
+			TransactionID: transaction.ID,
+			Deleted:       deleted,
+			Revisions:     responses,
+		},
+	})
+}
+
+// recordRevision appends to the transaction's history, rp must be the repository of the database
+// transaction making the write so the change and its revision commit together
+func recordRevision(rp repository.IRepository, action models.RevisionAction, actor models.RevisionActor, before, after *models.Transaction) error {
+	return rp.Revision.Record(action, actor, before, after)
+}
+
+// recordBulkRevisions records the changed transactions of a bulk operation, their new state is read back
+// so the snapshots show what the database holds
+func recordBulkRevisions(rp repository.IRepository, action models.RevisionAction, plan *bulkPlan) error {
+	after := map[uint]models.Transaction{}
+	if action != models.RevisionActionDelete {
+		updated, err := rp.Transaction.GetTransactionsForBulk(plan.user.ID, transaction.BulkSelection{
+			IDs:   plan.changedIDs,
+			Limit: len(plan.changedIDs),
+		})
+		if err != nil {
+			return err
+		}
+		for _, tx := range updated {
+			after[tx.ID] = tx
+		}
+	}
+
+	before := map[uint]models.Transaction{}
+	for _, tx := range plan.selected {
+		before[tx.ID] = tx
+	}
+
+	changes := make([]revision.Change, 0, len(plan.changedIDs))
+	for _, id := range plan.changedIDs {
+		change := revision.Change{}
+		if tx, ok := before[id]; ok && action != models.RevisionActionRestore {
+			change.Before = &tx
+		}
+		if tx, ok := after[id]; ok {
+			change.After = &tx
+		} else if action != models.RevisionActionDelete {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return rp.Revision.RecordChanges(action, apiActor(plan.user.ID, models.RevisionSourceBulk), changes)
+}
+
+func apiActor(userID uint, source string) models.RevisionActor {
+	return models.RevisionActor{
+		ActorType: models.RevisionActorUser,
+		ActorID:   &userID,
+		Source:    source,
+	}
+}
+
+// revisionChanges lists the snapshot fields that differ, in alphabetical order
+func revisionChanges(before, after *json.RawMessage) []dto.RevisionFieldChange {
+	changes := []dto.RevisionFieldChange{}
+	if before == nil || after == nil {
+		return changes
+	}
+
+	var from, to map[string]interface{}
+	if err := json.Unmarshal(*before, &from); err != nil {
+		return changes
+	}
+	if err := json.Unmarshal(*after, &to); err != nil {
+		return changes
+	}
+
+	fields := []string{}
+	for field := range from {
+		fields = append(fields, field)
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes = append(changes, dto.RevisionFieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	return changes
+}
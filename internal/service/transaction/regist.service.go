@@ -4,14 +4,16 @@ import (
 	"context"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/redis"
+	s3aws "pannypal/internal/pkg/storage/s3"
 	"pannypal/internal/repository"
 	"pannypal/internal/service/transaction/dto"
 )
 
 type Service struct {
-	ctx   context.Context
-	redis redis.IRedis
-	rp    repository.IRepository
+	ctx     context.Context
+	redis   redis.IRedis
+	rp      repository.IRepository
+	storage s3aws.Is3
 }
 
 type IService interface {
@@ -20,13 +22,26 @@ type IService interface {
 	GetTransactionByIDRequest(id uint, phoneNumber string) *types.Response
 	UpdateTransactionRequest(id uint, payload dto.UpdateTransactionRequest, phoneNumber string) *types.Response
 	DeleteTransactionRequest(id uint, phoneNumber string) *types.Response
+	GetTransactionHistoryRequest(id uint, phoneNumber string) *types.Response
 	GetTransactionsSummaryRequest(payload dto.TransactionSummaryRequest) *types.Response
+	SearchTransactionsRequest(payload dto.SearchTransactionsRequest) *types.Response
+	BulkCategorizeRequest(payload dto.BulkCategorizeRequest) *types.Response
+	BulkTagRequest(payload dto.BulkTagRequest) *types.Response
+	BulkDeleteRequest(payload dto.BulkRequest) *types.Response
+	BulkRestoreRequest(payload dto.BulkRequest) *types.Response
+	ScanDuplicatesRequest(payload dto.ScanDuplicatesRequest) *types.Response
+	MergeDuplicatesRequest(payload dto.MergeDuplicatesRequest) *types.Response
+	UploadAttachmentRequest(id uint, phoneNumber string, file types.UploadFile) *types.Response
+	GetAttachmentsRequest(id uint, phoneNumber string) *types.Response
+	DeleteAttachmentRequest(id uint, attachmentID uint, phoneNumber string) *types.Response
 }
 
-func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository) IService {
+// NewService takes the file storage for attachments, nil disables them
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, storage s3aws.Is3) IService {
 	return &Service{
-		ctx:   ctx,
-		redis: redis,
-		rp:    repository,
+		ctx:     ctx,
+		redis:   redis,
+		rp:      repository,
+		storage: storage,
 	}
 }
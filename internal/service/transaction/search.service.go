@@ -0,0 +1,89 @@
+package transaction
+
+import (
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository/transaction"
+	"pannypal/internal/service/transaction/dto"
+)
+
+func (s *Service) SearchTransactionsRequest(payload dto.SearchTransactionsRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if payload.Page == 0 {
+		payload.Page = 1
+	}
+	if payload.Limit == 0 {
+		payload.Limit = 10
+	}
+
+	query := helper.ParseSearchQuery(payload.Q)
+	if query.Text == "" && len(query.Tags) == 0 && query.MinAmount == nil && query.MaxAmount == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Search query is empty",
+			Data:    nil,
+		})
+	}
+
+	filters := transaction.SearchFilters{
+		Query:     query.Text,
+		Tags:      query.Tags,
+		MinAmount: query.MinAmount,
+		MaxAmount: query.MaxAmount,
+		Page:      payload.Page,
+		Limit:     payload.Limit,
+	}
+	if payload.Type != nil {
+		transactionType := models.TransactionType(*payload.Type)
+		filters.Type = &transactionType
+	}
+
+	transactions, total, err := s.rp.Transaction.SearchTransactions(&user.ID, filters)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to search transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	transactionResponses := make([]dto.TransactionResponse, len(transactions))
+	for i, t := range transactions {
+		transactionResponses[i] = toTransactionResponse(t)
+	}
+
+	totalPages := int((total + int64(payload.Limit) - 1) / int64(payload.Limit))
+	response := dto.TransactionSearchResponse{
+		Query: dto.SearchQueryInfo{
+			Text:      query.Text,
+			Tags:      query.Tags,
+			MinAmount: query.MinAmount,
+			MaxAmount: query.MaxAmount,
+		},
+		Transactions: transactionResponses,
+		Pagination: dto.PaginationResponse{
+			Page:       payload.Page,
+			Limit:      payload.Limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Transactions retrieved successfully",
+		Data:    response,
+	})
+}
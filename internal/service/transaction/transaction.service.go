@@ -1,12 +1,17 @@
 package transaction
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"pannypal/internal/common/models"
 	types "pannypal/internal/common/type"
 	"pannypal/internal/pkg/helper"
+	"pannypal/internal/repository"
 	"pannypal/internal/repository/transaction"
+	splitService "pannypal/internal/service/split"
 	"pannypal/internal/service/transaction/dto"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -54,14 +59,39 @@ func (s *Service) CreateTransactionRequest(payload dto.CreateTransactionRequest)
 	transaction := models.Transaction{
 		UserID:          user.ID,
 		Amount:          payload.Amount,
+		Description:     payload.Description,
+		Merchant:        payload.Merchant,
+		Notes:           payload.Notes,
+		Currency:        strings.ToUpper(payload.Currency),
 		TransactionDate: time.Now(),
 		Type:            models.TransactionType(payload.Type),
+		AccountID:       payload.AccountID,
+		ToAccountID:     payload.ToAccountID,
 	}
 	if payload.CategoryID != nil {
 		categoryID := uint(*payload.CategoryID)
 		transaction.CategoryID = &categoryID
 	}
-	createdTransaction, err := s.rp.Transaction.CreateTransaction(transaction)
+	if resp := s.validateTransactionAccounts(&transaction); resp != nil {
+		return resp
+	}
+	if resp := s.validateTransactionCategory(&transaction); resp != nil {
+		return resp
+	}
+	var createdTransaction *models.Transaction
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		created, err := rp.Transaction.CreateTransaction(transaction)
+		if err != nil {
+			return err
+		}
+		if len(payload.Tags) > 0 {
+			if err := setTransactionTags(rp, created, payload.Tags); err != nil {
+				return err
+			}
+		}
+		createdTransaction = created
+		return recordRevision(rp, models.RevisionActionCreate, apiActor(user.ID, models.RevisionSourceAPI), nil, created)
+	})
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -121,6 +151,12 @@ func (s *Service) GetTransactionsRequest(payload dto.GetTransactionsRequest) *ty
 	if payload.EndDate != nil {
 		filters.EndDate = payload.EndDate
 	}
+	if payload.AccountID != nil {
+		filters.AccountID = payload.AccountID
+	}
+	if len(payload.Tags) > 0 {
+		filters.Tags = helper.NormalizeTags(payload.Tags)
+	}
 
 	transactions, total, err := s.rp.Transaction.GetTransactionsByUserID(userID, filters)
 	if err != nil {
@@ -135,18 +171,7 @@ func (s *Service) GetTransactionsRequest(payload dto.GetTransactionsRequest) *ty
 	// Convert to response format
 	transactionResponses := make([]dto.TransactionResponse, len(transactions))
 	for i, t := range transactions {
-		transactionResponses[i] = dto.TransactionResponse{
-			ID:              t.ID,
-			UserID:          t.UserID,
-			CategoryID:      t.CategoryID,
-			Category:        t.Category,
-			Amount:          t.Amount,
-			Description:     t.Description,
-			TransactionDate: t.TransactionDate,
-			Type:            t.Type,
-			CreatedAt:       t.CreatedAt,
-			UpdatedAt:       t.UpdatedAt,
-		}
+		transactionResponses[i] = toTransactionResponse(t)
 	}
 
 	totalPages := int((total + int64(payload.Limit) - 1) / int64(payload.Limit))
@@ -197,18 +222,8 @@ func (s *Service) GetTransactionByIDRequest(id uint, phoneNumber string) *types.
 		})
 	}
 
-	response := dto.TransactionResponse{
-		ID:              transaction.ID,
-		UserID:          transaction.UserID,
-		CategoryID:      transaction.CategoryID,
-		Category:        transaction.Category,
-		Amount:          transaction.Amount,
-		Description:     transaction.Description,
-		TransactionDate: transaction.TransactionDate,
-		Type:            transaction.Type,
-		CreatedAt:       transaction.CreatedAt,
-		UpdatedAt:       transaction.UpdatedAt,
-	}
+	response := toTransactionResponse(*transaction)
+	response.Attachments = s.withAttachmentURLs(transaction.Attachments)
 
 	return helper.ParseResponse(&types.Response{
 		Code:    http.StatusOK,
@@ -247,6 +262,8 @@ func (s *Service) UpdateTransactionRequest(id uint, payload dto.UpdateTransactio
 		})
 	}
 
+	before := *transaction
+
 	// Update fields if provided
 	if payload.Amount != nil {
 		transaction.Amount = *payload.Amount
@@ -261,8 +278,59 @@ func (s *Service) UpdateTransactionRequest(id uint, payload dto.UpdateTransactio
 	if payload.Description != nil {
 		transaction.Description = *payload.Description
 	}
+	if payload.Merchant != nil {
+		transaction.Merchant = *payload.Merchant
+	}
+	if payload.Notes != nil {
+		transaction.Notes = *payload.Notes
+	}
+	if payload.Currency != nil {
+		transaction.Currency = strings.ToUpper(*payload.Currency)
+	}
+	if payload.AccountID != nil {
+		transaction.AccountID = payload.AccountID
+	}
+	if payload.ToAccountID != nil {
+		transaction.ToAccountID = payload.ToAccountID
+	}
+	if resp := s.validateTransactionAccounts(transaction); resp != nil {
+		return resp
+	}
+	if payload.CategoryID != nil || payload.Type != nil {
+		if resp := s.validateTransactionCategory(transaction); resp != nil {
+			return resp
+		}
+	}
+	transaction.Category = models.Category{}
 
-	updatedTransaction, err := s.rp.Transaction.UpdateTransaction(*transaction)
+	var updatedTransaction *models.Transaction
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		updated, err := rp.Transaction.UpdateTransaction(*transaction)
+		if err != nil {
+			return err
+		}
+		if payload.Tags != nil {
+			if err := setTransactionTags(rp, updated, *payload.Tags); err != nil {
+				return err
+			}
+		}
+		// Shares follow the new amount
+		if updated.Amount != before.Amount {
+			if _, err := splitService.Resplit(rp, updated.ID); err != nil {
+				return err
+			}
+		}
+		updatedTransaction = updated
+		return recordRevision(rp, models.RevisionActionUpdate, apiActor(user.ID, models.RevisionSourceAPI), &before, updated)
+	})
+	if errors.Is(err, splitService.ErrInvalidSplit) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: "The split of the transaction cannot be recomputed for the new amount",
+			Data:    nil,
+			Error:   err,
+		})
+	}
 	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
@@ -309,7 +377,17 @@ func (s *Service) DeleteTransactionRequest(id uint, phoneNumber string) *types.R
 		})
 	}
 
-	if err := s.rp.Transaction.DeleteTransaction(id); err != nil {
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if err := rp.Transaction.DeleteTransaction(id); err != nil {
+			return err
+		}
+		// Debts from a split go away with the transaction
+		if err := rp.Split.DeleteSplitByTransactionID(id); err != nil {
+			return err
+		}
+		return recordRevision(rp, models.RevisionActionDelete, apiActor(user.ID, models.RevisionSourceAPI), transaction, nil)
+	})
+	if err != nil {
 		return helper.ParseResponse(&types.Response{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to delete transaction",
@@ -327,6 +405,7 @@ func (s *Service) DeleteTransactionRequest(id uint, phoneNumber string) *types.R
 
 func (s *Service) GetTransactionsSummaryRequest(payload dto.TransactionSummaryRequest) *types.Response {
 	var userID *uint
+	currency := models.DefaultCurrency
 
 	if payload.PhoneNumber != nil && *payload.PhoneNumber != "" {
 		user, err := s.rp.User.GetUserByPhone(*payload.PhoneNumber)
@@ -339,6 +418,7 @@ func (s *Service) GetTransactionsSummaryRequest(payload dto.TransactionSummaryRe
 			})
 		}
 		userID = &user.ID
+		currency = user.BaseCurrency
 	}
 
 	filters := transaction.SummaryFilters{
@@ -364,7 +444,7 @@ func (s *Service) GetTransactionsSummaryRequest(payload dto.TransactionSummaryRe
 	for i, cs := range summary.CategorySummary {
 		percentage := float64(0)
 		if totalAmount > 0 {
-			percentage = (cs.TotalAmount / totalAmount) * 100
+			percentage = (cs.TotalAmount.Float64() / totalAmount.Float64()) * 100
 		}
 		categorySummary[i] = dto.CategorySummary{
 			CategoryID:   cs.CategoryID,
@@ -377,6 +457,7 @@ func (s *Service) GetTransactionsSummaryRequest(payload dto.TransactionSummaryRe
 	}
 
 	response := dto.TransactionSummaryResponse{
+		Currency:         currency,
 		TotalIncome:      summary.TotalIncome,
 		TotalExpense:     summary.TotalExpense,
 		Balance:          summary.TotalIncome - summary.TotalExpense,
@@ -398,3 +479,123 @@ func (s *Service) GetTransactionsSummaryRequest(payload dto.TransactionSummaryRe
 		Data:    response,
 	})
 }
+
+// validateTransactionAccounts checks the accounts belong to the transaction owner, a transfer needs
+// two different accounts while income and expense have no destination
+func (s *Service) validateTransactionAccounts(transaction *models.Transaction) *types.Response {
+	if transaction.Type != models.TypeTransfer {
+		transaction.ToAccountID = nil
+	} else {
+		if transaction.AccountID == nil || transaction.ToAccountID == nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Transfer requires account_id and to_account_id",
+				Data:    nil,
+			})
+		}
+		if *transaction.AccountID == *transaction.ToAccountID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusBadRequest,
+				Message: "Transfer accounts must be different",
+				Data:    nil,
+			})
+		}
+		// Transfers are not spending, they never belong to a category
+		transaction.CategoryID = nil
+	}
+
+	for _, accountID := range []*uint{transaction.AccountID, transaction.ToAccountID} {
+		if accountID == nil {
+			continue
+		}
+		account, err := s.rp.Account.GetAccountByID(*accountID)
+		if err != nil {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusNotFound,
+				Message: "Account not found",
+				Data:    nil,
+				Error:   err,
+			})
+		}
+		if account.UserID != transaction.UserID {
+			return helper.ParseResponse(&types.Response{
+				Code:    http.StatusForbidden,
+				Message: "Access denied",
+				Data:    nil,
+			})
+		}
+	}
+
+	return nil
+}
+
+// validateTransactionCategory checks the category is one of the owner's and can hold the transaction's type
+func (s *Service) validateTransactionCategory(transaction *models.Transaction) *types.Response {
+	if transaction.CategoryID == nil {
+		return nil
+	}
+
+	category, err := s.rp.Category.GetCategoryByID(*transaction.CategoryID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "Category not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	if category.UserID == nil || *category.UserID != transaction.UserID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+	if !category.Kind.Allows(transaction.Type) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Category %s cannot hold %s transactions", category.Name, transaction.Type),
+			Data:    nil,
+		})
+	}
+	return nil
+}
+
+// setTransactionTags replaces the tags of the transaction, creating the user's missing tags
+func setTransactionTags(rp repository.IRepository, transaction *models.Transaction, names []string) error {
+	tags, err := rp.Tag.GetOrCreateTags(transaction.UserID, helper.NormalizeTags(names))
+	if err != nil {
+		return err
+	}
+	if err := rp.Tag.ReplaceTransactionTags(transaction.ID, tags); err != nil {
+		return err
+	}
+	transaction.Tags = tags
+	return nil
+}
+
+func toTransactionResponse(t models.Transaction) dto.TransactionResponse {
+	tags := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		tags[i] = tag.Name
+	}
+
+	return dto.TransactionResponse{
+		ID:              t.ID,
+		UserID:          t.UserID,
+		CategoryID:      t.CategoryID,
+		Category:        t.Category,
+		AccountID:       t.AccountID,
+		ToAccountID:     t.ToAccountID,
+		Amount:          t.Amount,
+		Currency:        t.Currency,
+		Description:     t.Description,
+		Merchant:        t.Merchant,
+		Notes:           t.Notes,
+		Tags:            tags,
+		TransactionDate: t.TransactionDate,
+		Type:            t.Type,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+}
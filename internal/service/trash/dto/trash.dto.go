@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"time"
+)
+
+type GetTrashRequest struct {
+	PhoneNumber string `form:"phone_number" validate:"required"`
+	Page        int    `form:"page" validate:"omitempty,min=1" default:"1"`
+	Limit       int    `form:"limit" validate:"omitempty,min=1,max=100" default:"10"`
+}
+
+type TrashTransactionResponse struct {
+	ID              uint                   `json:"id"`
+	CategoryID      *uint                  `json:"category_id"`
+	CategoryName    string                 `json:"category_name"`
+	Amount          types.Money            `json:"amount"`
+	Currency        string                 `json:"currency"`
+	Description     string                 `json:"description"`
+	Merchant        string                 `json:"merchant"`
+	TransactionDate time.Time              `json:"transaction_date"`
+	Type            models.TransactionType `json:"type"`
+	DeletedAt       time.Time              `json:"deleted_at"`
+	PurgeAt         time.Time              `json:"purge_at"` // Removed for good after this
+}
+
+type TrashTransactionListResponse struct {
+	Transactions  []TrashTransactionResponse `json:"transactions"`
+	Pagination    PaginationResponse         `json:"pagination"`
+	RetentionDays int                        `json:"retention_days"`
+}
+
+type PaginationResponse struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+type TrashBudgetResponse struct {
+	ID           uint        `json:"id"`
+	CategoryID   uint        `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	LedgerID     *uint       `json:"ledger_id"`
+	Amount       types.Money `json:"amount"`
+	Month        int         `json:"month"`
+	Year         int         `json:"year"`
+	DeletedAt    time.Time   `json:"deleted_at"`
+	PurgeAt      time.Time   `json:"purge_at"`
+}
+
+type TrashCategoryResponse struct {
+	ID         uint      `json:"id"`
+	ParentID   *uint     `json:"parent_id"`
+	ParentName string    `json:"parent_name,omitempty"`
+	Name       string    `json:"name"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAt    time.Time `json:"purge_at"`
+}
+
+type RestoreCategoryResponse struct {
+	ID                   uint   `json:"id"`
+	Name                 string `json:"name"`
+	TransactionsRestored int64  `json:"transactions_restored"`
+	BudgetsRestored      int64  `json:"budgets_restored"`
+}
@@ -0,0 +1,50 @@
+package trash
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/redis"
+	s3aws "pannypal/internal/pkg/storage/s3"
+	"pannypal/internal/repository"
+	"pannypal/internal/service/trash/dto"
+)
+
+// defaultRetentionDays is how long deleted records stay restorable when TRASH_RETENTION_DAYS is not set
+const defaultRetentionDays = 30
+
+type Service struct {
+	ctx           context.Context
+	redis         redis.IRedis
+	rp            repository.IRepository
+	storage       s3aws.Is3
+	retentionDays int
+}
+
+type IService interface {
+	GetTrashTransactionsRequest(payload dto.GetTrashRequest) *types.Response
+	GetTrashBudgetsRequest(phoneNumber string) *types.Response
+	GetTrashCategoriesRequest(phoneNumber string) *types.Response
+	RestoreTransactionRequest(id uint, phoneNumber string) *types.Response
+	RestoreBudgetRequest(id uint, phoneNumber string) *types.Response
+	RestoreCategoryRequest(id uint, phoneNumber string) *types.Response
+	PurgeExpired(now time.Time) error
+}
+
+func NewService(ctx context.Context, redis redis.IRedis, repository repository.IRepository, storage s3aws.Is3) IService {
+	retentionDays := defaultRetentionDays
+	if days, err := strconv.Atoi(helper.GetEnv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		retentionDays = days
+	}
+
+	return &Service{
+		ctx:           ctx,
+		redis:         redis,
+		rp:            repository,
+		storage:       storage,
+		retentionDays: retentionDays,
+	}
+}
@@ -0,0 +1,409 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+	"pannypal/internal/common/models"
+	types "pannypal/internal/common/type"
+	"pannypal/internal/pkg/helper"
+	"pannypal/internal/pkg/logger"
+	"pannypal/internal/repository"
+	"pannypal/internal/repository/revision"
+	"pannypal/internal/repository/trash"
+	"pannypal/internal/service/trash/dto"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *Service) GetTrashTransactionsRequest(payload dto.GetTrashRequest) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(payload.PhoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	if payload.Page == 0 {
+		payload.Page = 1
+	}
+	if payload.Limit == 0 {
+		payload.Limit = 10
+	}
+
+	transactions, total, err := s.rp.Trash.GetDeletedTransactions(user.ID, payload.Page, payload.Limit)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get deleted transactions",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.TrashTransactionResponse, len(transactions))
+	for i, t := range transactions {
+		responses[i] = dto.TrashTransactionResponse{
+			ID:              t.ID,
+			CategoryID:      t.CategoryID,
+			CategoryName:    t.Category.Name,
+			Amount:          t.Amount,
+			Currency:        t.Currency,
+			Description:     t.Description,
+			Merchant:        t.Merchant,
+			TransactionDate: t.TransactionDate,
+			Type:            t.Type,
+			DeletedAt:       t.DeletedAt.Time,
+			PurgeAt:         s.purgeAt(t.DeletedAt),
+		}
+	}
+
+	totalPages := int((total + int64(payload.Limit) - 1) / int64(payload.Limit))
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Deleted transactions retrieved successfully",
+		Data: dto.TrashTransactionListResponse{
+			Transactions: responses,
+			Pagination: dto.PaginationResponse{
+				Page:       payload.Page,
+				Limit:      payload.Limit,
+				Total:      total,
+				TotalPages: totalPages,
+			},
+			RetentionDays: s.retentionDays,
+		},
+	})
+}
+
+func (s *Service) GetTrashBudgetsRequest(phoneNumber string) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	budgets, err := s.rp.Trash.GetDeletedBudgets(user.ID)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get deleted budgets",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.TrashBudgetResponse, len(budgets))
+	for i, b := range budgets {
+		responses[i] = dto.TrashBudgetResponse{
+			ID:           b.ID,
+			CategoryID:   b.CategoryID,
+			CategoryName: b.Category.Name,
+			LedgerID:     b.LedgerID,
+			Amount:       b.Amount,
+			Month:        b.Month,
+			Year:         b.Year,
+			DeletedAt:    b.DeletedAt.Time,
+			PurgeAt:      s.purgeAt(b.DeletedAt),
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Deleted budgets retrieved successfully",
+		Data:    responses,
+	})
+}
+
+func (s *Service) GetTrashCategoriesRequest(phoneNumber string) *types.Response {
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+
+	categories, err := s.rp.Trash.GetDeletedCategories(owner)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get deleted categories",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	responses := make([]dto.TrashCategoryResponse, len(categories))
+	for i, c := range categories {
+		responses[i] = dto.TrashCategoryResponse{
+			ID:        c.ID,
+			ParentID:  c.ParentID,
+			Name:      c.Name,
+			DeletedAt: c.DeletedAt.Time,
+			PurgeAt:   s.purgeAt(c.DeletedAt),
+		}
+		if c.Parent != nil {
+			responses[i].ParentName = c.Parent.Name
+		}
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Deleted categories retrieved successfully",
+		Data:    responses,
+	})
+}
+
+func (s *Service) RestoreTransactionRequest(id uint, phoneNumber string) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	transaction, err := s.rp.Trash.GetDeletedTransactionByID(id)
+	if err != nil {
+		return notInTrash("Transaction", err)
+	}
+	if transaction.UserID != user.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	// A category still in the trash would hide the transaction from category reports, it comes back uncategorized
+	categoryDeleted := transaction.CategoryID != nil && transaction.Category.DeletedAt.Valid
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		if err := rp.Trash.RestoreTransaction(transaction.ID, categoryDeleted); err != nil {
+			return err
+		}
+		restored, err := rp.Transaction.GetTransactionByID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		actor := models.RevisionActor{
+			ActorType: models.RevisionActorUser,
+			ActorID:   &user.ID,
+			Source:    models.RevisionSourceTrash,
+		}
+		return rp.Revision.Record(models.RevisionActionRestore, actor, nil, restored)
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to restore transaction",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	message := "Transaction restored successfully"
+	if categoryDeleted {
+		message = "Transaction restored without a category, its category is still deleted"
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    nil,
+	})
+}
+
+func (s *Service) RestoreBudgetRequest(id uint, phoneNumber string) *types.Response {
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	budget, err := s.rp.Trash.GetDeletedBudgetByID(id)
+	if err != nil {
+		return notInTrash("Budget", err)
+	}
+	if budget.UserID != user.ID {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	// Unlike a transaction a budget means nothing without its category
+	if budget.Category.DeletedAt.Valid {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The budget's category is deleted, restore the category first",
+			Data:    nil,
+		})
+	}
+
+	// Only one budget per category and period, the one set since the delete stays
+	if _, err := s.rp.Budget.GetBudgetForPeriod(*budget); err == nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The category already has a budget for this period",
+			Data:    nil,
+		})
+	}
+
+	if err := s.rp.Trash.RestoreBudget(budget.ID); err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to restore budget",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Budget restored successfully",
+		Data:    nil,
+	})
+}
+
+func (s *Service) RestoreCategoryRequest(id uint, phoneNumber string) *types.Response {
+	owner, failed := s.categoryOwner(phoneNumber)
+	if failed != nil {
+		return failed
+	}
+
+	category, err := s.rp.Trash.GetDeletedCategoryByID(id)
+	if err != nil {
+		return notInTrash("Category", err)
+	}
+	if (category.UserID == nil) != (owner == nil) || (owner != nil && *category.UserID != *owner) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusForbidden,
+			Message: "Access denied",
+			Data:    nil,
+		})
+	}
+
+	// Same as a budget, a subcategory waits for its parent
+	if category.Parent != nil && category.Parent.DeletedAt.Valid {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusConflict,
+			Message: "The parent category is deleted, restore it first",
+			Data:    nil,
+		})
+	}
+
+	var result *trash.RestoreResult
+	err = s.rp.Atomic(func(rp repository.IRepository) error {
+		restored, err := rp.Trash.RestoreCategory(*category)
+		if err != nil {
+			return err
+		}
+		result = restored
+
+		changes := make([]revision.Change, len(restored.Transactions))
+		for i := range restored.Transactions {
+			changes[i].After = &restored.Transactions[i]
+		}
+		actor := models.RevisionActor{
+			ActorType: models.RevisionActorUser,
+			ActorID:   owner,
+			Source:    models.RevisionSourceCategory,
+		}
+		return rp.Revision.RecordChanges(models.RevisionActionRestore, actor, changes)
+	})
+	if err != nil {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to restore category",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusOK,
+		Message: "Category restored successfully",
+		Data: dto.RestoreCategoryResponse{
+			ID:                   category.ID,
+			Name:                 category.Name,
+			TransactionsRestored: int64(len(result.Transactions)),
+			BudgetsRestored:      result.Budgets,
+		},
+	})
+}
+
+// PurgeExpired removes for good what has been in the trash longer than the retention period,
+// run by the worker once a day
+func (s *Service) PurgeExpired(now time.Time) error {
+	result, err := s.rp.Trash.Purge(now.AddDate(0, 0, -s.retentionDays))
+	if err != nil {
+		return err
+	}
+
+	// The rows are gone already, a file left behind only costs storage
+	if s.storage != nil {
+		for _, key := range result.FileKeys {
+			if err := s.storage.DeleteFile(key); err != nil {
+				logger.Error.Println("Failed to delete purged attachment file:", err)
+			}
+		}
+	}
+
+	if result.Transactions > 0 || result.Budgets > 0 || result.Categories > 0 {
+		logger.Info.Printf("Trash purged: %d transactions, %d budgets, %d categories\n",
+			result.Transactions, result.Budgets, result.Categories)
+	}
+	return nil
+}
+
+// categoryOwner resolves whose categories are listed or restored, nil is the template and is used
+// when no phone number is given
+func (s *Service) categoryOwner(phoneNumber string) (*uint, *types.Response) {
+	if phoneNumber == "" {
+		return nil, nil
+	}
+
+	user, err := s.rp.User.GetUserByPhone(phoneNumber)
+	if err != nil {
+		return nil, helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	return &user.ID, nil
+}
+
+func (s *Service) purgeAt(deletedAt gorm.DeletedAt) time.Time {
+	return deletedAt.Time.AddDate(0, 0, s.retentionDays)
+}
+
+func notInTrash(entity string, err error) *types.Response {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return helper.ParseResponse(&types.Response{
+			Code:    http.StatusNotFound,
+			Message: entity + " not found in trash",
+			Data:    nil,
+			Error:   err,
+		})
+	}
+	return helper.ParseResponse(&types.Response{
+		Code:    http.StatusInternalServerError,
+		Message: "Failed to get " + strings.ToLower(entity),
+		Data:    nil,
+		Error:   err,
+	})
+}